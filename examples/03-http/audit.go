@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cexll/agentsdk-go/pkg/middleware"
+)
+
+// auditRecord captures a single /v1/run exchange. Prompts are masked before
+// they reach the sink so secrets pasted into a prompt never hit disk.
+type auditRecord struct {
+	RunID      string    `json:"run_id"`
+	SessionID  string    `json:"session_id"`
+	Endpoint   string    `json:"endpoint"`
+	Prompt     string    `json:"prompt"`
+	Output     string    `json:"output,omitempty"`
+	StopReason string    `json:"stop_reason,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// auditSink persists audit records. Implementations must be safe for
+// concurrent use because handlers record from their own goroutines.
+type auditSink interface {
+	Record(auditRecord) error
+}
+
+// fileAuditSink appends records to a JSON Lines file.
+type fileAuditSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+func newFileAuditSink(path string) *fileAuditSink {
+	return &fileAuditSink{path: path}
+}
+
+func (s *fileAuditSink) Record(rec auditRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// maskAudit redacts common credential shapes from audited prompt, output
+// and error text.
+func maskAudit(text string) string {
+	return middleware.RedactSecrets(text, func(string) string { return "***" })
+}
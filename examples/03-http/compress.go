@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"strconv"
+	"strings"
+)
+
+// defaultCompressMinBytes is the smallest JSON body worth compressing; below
+// it the encoding overhead outweighs the savings.
+const defaultCompressMinBytes = 1024
+
+// negotiateEncoding picks gzip or deflate from an Accept-Encoding header,
+// preferring gzip on ties. An encoding that is not listed takes the q-value
+// of "*", so an explicit q=0 refuses it even when a wildcard is present. It
+// returns "" when neither is acceptable.
+func negotiateEncoding(header string) string {
+	explicit := map[string]float64{}
+	wildcard := 0.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		switch name {
+		case "gzip", "deflate":
+			explicit[name] = q
+		case "*":
+			wildcard = q
+		}
+	}
+	best, bestQ := "", 0.0
+	for _, name := range []string{"gzip", "deflate"} {
+		q, ok := explicit[name]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+// compressBody encodes body with the negotiated encoding. HTTP "deflate" is
+// the zlib format, not raw deflate.
+func compressBody(encoding string, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch encoding {
+	case "gzip":
+		zw := gzip.NewWriter(&buf)
+		if _, err = zw.Write(body); err == nil {
+			err = zw.Close()
+		}
+	case "deflate":
+		zw := zlib.NewWriter(&buf)
+		if _, err = zw.Write(body); err == nil {
+			err = zw.Close()
+		}
+	default:
+		return body, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// responseEncoding returns the encoding for a JSON body of size n, or "" to
+// send it as is.
+func (s *httpServer) responseEncoding(acceptEncoding string, n int) string {
+	minBytes := s.compressMinBytes
+	if minBytes == 0 {
+		minBytes = defaultCompressMinBytes
+	}
+	if minBytes < 0 || n < minBytes {
+		return ""
+	}
+	return negotiateEncoding(acceptEncoding)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	cases := map[string]string{
+		"":                         "",
+		"identity":                 "",
+		"gzip":                     "gzip",
+		"deflate":                  "deflate",
+		"deflate, gzip":            "gzip",
+		"gzip;q=0.5, deflate":      "deflate",
+		"gzip;q=0":                 "",
+		"*":                        "gzip",
+		"br, *;q=0":                "",
+		"GZIP ; q=0.8, br;q=1.0":   "gzip",
+		"gzip;q=0, *":              "deflate",
+		"gzip;q=0, deflate;q=0, *": "",
+		"deflate;q=0.5, *;q=0.2":   "deflate",
+	}
+	for header, want := range cases {
+		if got := negotiateEncoding(header); got != want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestHandleRunGzipsLargeResponses(t *testing.T) {
+	srv := newTestServer(t, nil)
+	srv.compressMinBytes = 1
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/run", strings.NewReader(`{"prompt":"hi","session_id":"gz"}`))
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	srv.handleRun(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected gzip encoding, got %q", got)
+	}
+	if got := rec.Header().Get("Content-Length"); got != strconv.Itoa(rec.Body.Len()) {
+		t.Fatalf("content-length %s does not match body size %d", got, rec.Body.Len())
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	var resp runResponse
+	if err := json.NewDecoder(zr).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.SessionID != "gz" || resp.Output != "all done" {
+		t.Fatalf("unexpected payload %+v", resp)
+	}
+}
+
+func TestWriteJSONCompressionThreshold(t *testing.T) {
+	srv := &httpServer{}
+	large := map[string]string{"output": strings.Repeat("transcript ", 200)}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "deflate")
+	rec := httptest.NewRecorder()
+	srv.writeJSON(rec, req, http.StatusOK, large)
+	if rec.Header().Get("Content-Encoding") != "deflate" {
+		t.Fatalf("expected deflate, headers %v", rec.Header())
+	}
+	zr, err := zlib.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("zlib reader: %v", err)
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("inflate: %v", err)
+	}
+	var decoded map[string]string
+	if err := json.Unmarshal(raw, &decoded); err != nil || decoded["output"] != large["output"] {
+		t.Fatalf("round trip mismatch: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.writeJSON(rec, req, http.StatusOK, errorResponse{"small"})
+	if enc := rec.Header().Get("Content-Encoding"); enc != "" {
+		t.Fatalf("small body should not be encoded, got %q", enc)
+	}
+	if rec.Header().Get("Content-Length") != strconv.Itoa(rec.Body.Len()) {
+		t.Fatalf("content-length mismatch for identity body")
+	}
+	if !strings.Contains(rec.Body.String(), "small") {
+		t.Fatalf("unexpected body %q", rec.Body.String())
+	}
+
+	srv.compressMinBytes = -1
+	rec = httptest.NewRecorder()
+	srv.writeJSON(rec, req, http.StatusOK, large)
+	if enc := rec.Header().Get("Content-Encoding"); enc != "" {
+		t.Fatalf("compression disabled but got %q", enc)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import "sync/atomic"
+
+// runLimiter caps the number of runs executing at once across all endpoints.
+// A nil limiter admits every run.
+type runLimiter struct {
+	slots    chan struct{}
+	inFlight atomic.Int64
+}
+
+// newRunLimiter returns a limiter admitting at most limit concurrent runs, or
+// nil when limit is not positive.
+func newRunLimiter(limit int) *runLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &runLimiter{slots: make(chan struct{}, limit)}
+}
+
+// tryAcquire claims a slot without blocking and reports whether one was free.
+// Each successful call must be paired with release.
+func (l *runLimiter) tryAcquire() bool {
+	if l == nil {
+		return true
+	}
+	select {
+	case l.slots <- struct{}{}:
+		l.inFlight.Add(1)
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *runLimiter) release() {
+	if l == nil {
+		return
+	}
+	l.inFlight.Add(-1)
+	<-l.slots
+}
+
+// InFlight reports the number of runs currently holding a slot.
+func (l *runLimiter) InFlight() int {
+	if l == nil {
+		return 0
+	}
+	return int(l.inFlight.Load())
+}
+
+// Capacity reports the configured limit; zero means unlimited.
+func (l *runLimiter) Capacity() int {
+	if l == nil {
+		return 0
+	}
+	return cap(l.slots)
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -52,6 +53,25 @@ func main() {
 		defaultTimeout: defaultRunTimeout,
 		staticDir:      staticDir,
 	}
+	if path := strings.TrimSpace(os.Getenv("AGENTSDK_HTTP_AUDIT_FILE")); path != "" {
+		srv.audit = newFileAuditSink(path)
+		log.Printf("audit capture enabled: %s", path)
+	}
+	if raw := strings.TrimSpace(os.Getenv("AGENTSDK_HTTP_MAX_CONCURRENT_RUNS")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			log.Fatalf("invalid AGENTSDK_HTTP_MAX_CONCURRENT_RUNS %q: %v", raw, err)
+		}
+		srv.limiter = newRunLimiter(limit)
+		log.Printf("concurrent run limit: %d", limit)
+	}
+	if raw := strings.TrimSpace(os.Getenv("AGENTSDK_HTTP_COMPRESS_MIN_BYTES")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			log.Fatalf("invalid AGENTSDK_HTTP_COMPRESS_MIN_BYTES %q: %v", raw, err)
+		}
+		srv.compressMinBytes = n
+	}
 	mux := http.NewServeMux()
 	srv.registerRoutes(mux)
 
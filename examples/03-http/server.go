@@ -6,27 +6,45 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cexll/agentsdk-go/pkg/api"
 	modelpkg "github.com/cexll/agentsdk-go/pkg/model"
+	"github.com/google/uuid"
 )
 
 const (
 	maxBodyBytes     = 1 << 20
 	streamPingPeriod = 15 * time.Second
+	// busyRetryAfter is the Retry-After hint, in seconds, sent with 429s.
+	busyRetryAfter = "1"
 )
 
 type httpServer struct {
 	runtime        *api.Runtime
 	defaultTimeout time.Duration
 	staticDir      string
+	// audit, when set, receives a masked record of every run. Nil disables
+	// audit capture.
+	audit auditSink
+	// limiter, when set, caps concurrent runs across /v1/run and
+	// /v1/run/stream; saturated requests get 429.
+	limiter *runLimiter
+	// compressMinBytes is the smallest JSON body gzipped or deflated when the
+	// client accepts it. Zero uses defaultCompressMinBytes; negative disables
+	// compression. SSE streams are never compressed.
+	compressMinBytes int
 }
 
 func (s *httpServer) registerRoutes(mux *http.ServeMux) {
 	// API routes
 	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/v1/describe", s.handleDescribe)
+	mux.HandleFunc("/v1/explain", s.handleExplain)
 	mux.HandleFunc("/v1/run", s.handleRun)
 	mux.HandleFunc("/v1/run/stream", s.handleStream)
 
@@ -46,28 +64,82 @@ func (s *httpServer) registerRoutes(mux *http.ServeMux) {
 
 func (s *httpServer) handleHealth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		s.writeJSON(w, http.StatusMethodNotAllowed, errorResponse{"only GET supported"})
+		s.writeJSON(w, r, http.StatusMethodNotAllowed, errorResponse{"only GET supported"})
 		return
 	}
-	s.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	s.writeJSON(w, r, http.StatusOK, healthResponse{
+		Status:      "ok",
+		InFlight:    s.limiter.InFlight(),
+		MaxInFlight: s.limiter.Capacity(),
+	})
+}
+
+// handleDescribe reports the runtime configuration with secrets masked.
+func (s *httpServer) handleDescribe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeJSON(w, r, http.StatusMethodNotAllowed, errorResponse{"only GET supported"})
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, s.runtime.Describe())
+}
+
+// handleExplain dry-runs a prompt: skill scores, the subagent it would
+// resolve to and sandbox verdicts per tool. It executes nothing, so it does
+// not take a run slot.
+func (s *httpServer) handleExplain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeJSON(w, r, http.StatusMethodNotAllowed, errorResponse{"only POST supported"})
+		return
+	}
+	var req runRequest
+	if err := s.decode(r, &req); err != nil {
+		s.writeJSON(w, r, http.StatusBadRequest, errorResponse{err.Error()})
+		return
+	}
+	if req.Prompt == "" {
+		s.writeJSON(w, r, http.StatusBadRequest, errorResponse{"prompt is required"})
+		return
+	}
+	exp, err := s.runtime.Explain(r.Context(), api.Request{Prompt: req.Prompt, SessionID: req.SessionID})
+	if err != nil {
+		s.writeJSON(w, r, http.StatusInternalServerError, errorResponse{err.Error()})
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, exp)
+}
+
+// admit claims a run slot, writing a 429 and returning false when the server
+// is saturated. Callers must defer s.limiter.release() on success.
+func (s *httpServer) admit(w http.ResponseWriter, r *http.Request) bool {
+	if s.limiter.tryAcquire() {
+		return true
+	}
+	w.Header().Set("Retry-After", busyRetryAfter)
+	s.writeJSON(w, r, http.StatusTooManyRequests, errorResponse{"too many concurrent runs"})
+	return false
 }
 
 func (s *httpServer) handleRun(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		s.writeJSON(w, http.StatusMethodNotAllowed, errorResponse{"only POST supported"})
+		s.writeJSON(w, r, http.StatusMethodNotAllowed, errorResponse{"only POST supported"})
 		return
 	}
 
 	var req runRequest
 	if err := s.decode(r, &req); err != nil {
-		s.writeJSON(w, http.StatusBadRequest, errorResponse{err.Error()})
+		s.writeJSON(w, r, http.StatusBadRequest, errorResponse{err.Error()})
 		return
 	}
 	if req.Prompt == "" {
-		s.writeJSON(w, http.StatusBadRequest, errorResponse{"prompt is required"})
+		s.writeJSON(w, r, http.StatusBadRequest, errorResponse{"prompt is required"})
 		return
 	}
 
+	if !s.admit(w, r) {
+		return
+	}
+	defer s.limiter.release()
+
 	// Runtime serializes per SessionID. If multiple HTTP requests share a session_id concurrently,
 	// one of them can fail with api.ErrConcurrentExecution (treat it as "session busy").
 	// Use request-id (stateless) or user/client session-id (stateful) as session_id to isolate work.
@@ -75,50 +147,64 @@ func (s *httpServer) handleRun(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := s.requestContext(r.Context(), req.TimeoutMs)
 	defer cancel()
 
+	rec := s.beginAudit("/v1/run", sessionID, req.Prompt)
 	resp, err := s.runtime.Run(ctx, api.Request{
 		Prompt:    req.Prompt,
 		SessionID: sessionID,
+		RequestID: rec.RunID,
 	})
 	if err != nil {
-		s.writeJSON(w, http.StatusBadGateway, errorResponse{err.Error()})
+		rec.Error = err.Error()
+		s.finishAudit(rec)
+		s.writeJSON(w, r, runErrorStatus(err), errorResponse{err.Error()})
 		return
 	}
 	result := resp.Result
 	if result == nil {
-		s.writeJSON(w, http.StatusInternalServerError, errorResponse{"agent response is empty"})
+		rec.Error = "agent response is empty"
+		s.finishAudit(rec)
+		s.writeJSON(w, r, http.StatusInternalServerError, errorResponse{"agent response is empty"})
 		return
 	}
+	rec.Output = result.Output
+	rec.StopReason = result.StopReason
+	s.finishAudit(rec)
 
-	s.writeJSON(w, http.StatusOK, runResponse{
+	s.writeJSON(w, r, http.StatusOK, runResponse{
 		SessionID:  sessionID,
 		Output:     result.Output,
 		StopReason: result.StopReason,
 		Usage:      result.Usage,
 		ToolCalls:  result.ToolCalls,
+		ToolUsage:  resp.ToolUsage,
 	})
 }
 
 func (s *httpServer) handleStream(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		s.writeJSON(w, http.StatusMethodNotAllowed, errorResponse{"only POST supported"})
+		s.writeJSON(w, r, http.StatusMethodNotAllowed, errorResponse{"only POST supported"})
 		return
 	}
 
 	var req runRequest
 	if err := s.decode(r, &req); err != nil {
-		s.writeJSON(w, http.StatusBadRequest, errorResponse{err.Error()})
+		s.writeJSON(w, r, http.StatusBadRequest, errorResponse{err.Error()})
 		return
 	}
 	if req.Prompt == "" {
-		s.writeJSON(w, http.StatusBadRequest, errorResponse{"prompt is required"})
+		s.writeJSON(w, r, http.StatusBadRequest, errorResponse{"prompt is required"})
 		return
 	}
 
 	flusher, ok := w.(http.Flusher)
 	if !ok {
-		s.writeJSON(w, http.StatusInternalServerError, errorResponse{"streaming unsupported"})
+		s.writeJSON(w, r, http.StatusInternalServerError, errorResponse{"streaming unsupported"})
+		return
+	}
+	if !s.admit(w, r) {
 		return
 	}
+	defer s.limiter.release()
 
 	// Same as /v1/run: isolate concurrent requests with distinct session_id values to avoid
 	// per-session concurrency conflicts (api.ErrConcurrentExecution).
@@ -126,14 +212,23 @@ func (s *httpServer) handleStream(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := s.requestContext(r.Context(), req.TimeoutMs)
 	defer cancel()
 
+	rec := s.beginAudit("/v1/run/stream", sessionID, req.Prompt)
 	events, err := s.runtime.RunStream(ctx, api.Request{
 		Prompt:    req.Prompt,
 		SessionID: sessionID,
+		RequestID: rec.RunID,
 	})
 	if err != nil {
-		s.writeJSON(w, http.StatusBadGateway, errorResponse{err.Error()})
+		rec.Error = err.Error()
+		s.finishAudit(rec)
+		s.writeJSON(w, r, runErrorStatus(err), errorResponse{err.Error()})
 		return
 	}
+	var output strings.Builder
+	defer func() {
+		rec.Output = output.String()
+		s.finishAudit(rec)
+	}()
 
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -148,6 +243,9 @@ func (s *httpServer) handleStream(w http.ResponseWriter, r *http.Request) {
 			if !ok {
 				return
 			}
+			if s.audit != nil {
+				captureStreamEvent(rec, &output, event)
+			}
 			payload, err := json.Marshal(event)
 			if err != nil {
 				return
@@ -163,6 +261,59 @@ func (s *httpServer) handleStream(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// runErrorStatus maps runtime errors to HTTP status codes.
+func runErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, api.ErrPromptTooLarge):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// beginAudit starts an audit record for a run. The returned record is always
+// non-nil so callers can populate it unconditionally; it is only persisted
+// when audit capture is enabled.
+func (s *httpServer) beginAudit(endpoint, sessionID, prompt string) *auditRecord {
+	rec := &auditRecord{
+		RunID:     uuid.NewString(),
+		SessionID: sessionID,
+		Endpoint:  endpoint,
+		StartedAt: time.Now(),
+	}
+	if s.audit != nil {
+		rec.Prompt = maskAudit(prompt)
+	}
+	return rec
+}
+
+func (s *httpServer) finishAudit(rec *auditRecord) {
+	if s.audit == nil || rec == nil {
+		return
+	}
+	rec.DurationMs = time.Since(rec.StartedAt).Milliseconds()
+	rec.Output = maskAudit(rec.Output)
+	rec.Error = maskAudit(rec.Error)
+	if err := s.audit.Record(*rec); err != nil {
+		log.Printf("audit capture failed for run %s: %v", rec.RunID, err)
+	}
+}
+
+func captureStreamEvent(rec *auditRecord, output *strings.Builder, event api.StreamEvent) {
+	if event.Delta == nil {
+		if event.Type == api.EventError {
+			rec.Error = fmt.Sprint(event.Output)
+		}
+		return
+	}
+	if event.Delta.Type == "text_delta" {
+		output.WriteString(event.Delta.Text)
+	}
+	if event.Delta.StopReason != "" {
+		rec.StopReason = event.Delta.StopReason
+	}
+}
+
 func (s *httpServer) decode(r *http.Request, dest any) error {
 	if r.Body == nil {
 		return errors.New("request body is empty")
@@ -195,10 +346,25 @@ func (s *httpServer) requestContext(parent context.Context, timeoutMs int) (cont
 	return context.WithTimeout(parent, timeout)
 }
 
-func (s *httpServer) writeJSON(w http.ResponseWriter, status int, payload any) {
-	w.Header().Set("Content-Type", "application/json")
+func (s *httpServer) writeJSON(w http.ResponseWriter, r *http.Request, status int, payload any) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	body = append(body, '\n')
+	h := w.Header()
+	h.Set("Content-Type", "application/json")
+	h.Add("Vary", "Accept-Encoding")
+	if enc := s.responseEncoding(r.Header.Get("Accept-Encoding"), len(body)); enc != "" {
+		if compressed, err := compressBody(enc, body); err == nil {
+			h.Set("Content-Encoding", enc)
+			body = compressed
+		}
+	}
+	h.Set("Content-Length", strconv.Itoa(len(body)))
 	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(payload)
+	_, _ = w.Write(body)
 }
 
 type runRequest struct {
@@ -220,6 +386,15 @@ type runResponse struct {
 	StopReason string              `json:"stop_reason"`
 	Usage      modelpkg.Usage      `json:"usage"`
 	ToolCalls  []modelpkg.ToolCall `json:"tool_calls"`
+	// ToolUsage maps tool names to per-run counts, errors and durations
+	// (nanoseconds) for cost attribution.
+	ToolUsage map[string]api.ToolUsageStat `json:"tool_usage,omitempty"`
+}
+
+type healthResponse struct {
+	Status      string `json:"status"`
+	InFlight    int    `json:"in_flight"`
+	MaxInFlight int    `json:"max_in_flight,omitempty"`
 }
 
 type errorResponse struct {
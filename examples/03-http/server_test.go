@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cexll/agentsdk-go/pkg/api"
+	modelpkg "github.com/cexll/agentsdk-go/pkg/model"
+)
+
+// staticModel answers every prompt with content, "all done" by default.
+type staticModel struct{ content string }
+
+func (m staticModel) Complete(context.Context, modelpkg.Request) (*modelpkg.Response, error) {
+	content := m.content
+	if content == "" {
+		content = "all done"
+	}
+	return &modelpkg.Response{
+		Message:    modelpkg.Message{Role: "assistant", Content: content},
+		StopReason: "end_turn",
+	}, nil
+}
+
+func (m staticModel) CompleteStream(ctx context.Context, req modelpkg.Request, cb modelpkg.StreamHandler) error {
+	resp, err := m.Complete(ctx, req)
+	if err != nil {
+		return err
+	}
+	return cb(modelpkg.StreamResult{Final: true, Response: resp})
+}
+
+type memoryAuditSink struct {
+	mu      sync.Mutex
+	records []auditRecord
+}
+
+func (s *memoryAuditSink) Record(rec auditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, rec)
+	return nil
+}
+
+func newTestServer(t *testing.T, sink auditSink) *httpServer {
+	t.Helper()
+	rt, err := api.New(context.Background(), api.Options{ProjectRoot: t.TempDir(), Model: staticModel{}})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+	return &httpServer{runtime: rt, defaultTimeout: defaultRunTimeout, audit: sink}
+}
+
+func TestHandleRunRecordsMaskedAudit(t *testing.T) {
+	sink := &memoryAuditSink{}
+	srv := newTestServer(t, sink)
+
+	body := `{"prompt":"deploy with api_key=abc123 please","session_id":"s1"}`
+	rec := httptest.NewRecorder()
+	srv.handleRun(rec, httptest.NewRequest(http.MethodPost, "/v1/run", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if len(sink.records) != 1 {
+		t.Fatalf("expected one audit record, got %d", len(sink.records))
+	}
+	got := sink.records[0]
+	if strings.Contains(got.Prompt, "abc123") || !strings.Contains(got.Prompt, "***") {
+		t.Fatalf("expected masked prompt, got %q", got.Prompt)
+	}
+	if got.StopReason != "end_turn" || got.Output != "all done" {
+		t.Fatalf("unexpected audit response fields: %+v", got)
+	}
+	if got.RunID == "" || got.SessionID != "s1" || got.Endpoint != "/v1/run" {
+		t.Fatalf("unexpected audit identity fields: %+v", got)
+	}
+}
+
+func TestHandleRunMasksSecretsInAuditedOutput(t *testing.T) {
+	const secret = "sk-abcdefghijklmnopqrstuvwxyz123456"
+	rt, err := api.New(context.Background(), api.Options{ProjectRoot: t.TempDir(), Model: staticModel{content: "your key is " + secret}})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+	sink := &memoryAuditSink{}
+	srv := &httpServer{runtime: rt, defaultTimeout: defaultRunTimeout, audit: sink}
+
+	for _, path := range []string{"/v1/run", "/v1/run/stream"} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"prompt":"echo my key"}`))
+		if path == "/v1/run" {
+			srv.handleRun(rec, req)
+		} else {
+			srv.handleStream(rec, req)
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: unexpected status %d: %s", path, rec.Code, rec.Body.String())
+		}
+	}
+	if len(sink.records) != 2 {
+		t.Fatalf("expected two audit records, got %d", len(sink.records))
+	}
+	for _, got := range sink.records {
+		if strings.Contains(got.Output, secret) || got.Output != "your key is ***" {
+			t.Fatalf("%s: expected masked output, got %q", got.Endpoint, got.Output)
+		}
+	}
+}
+
+func TestHandleRunWithoutAuditSink(t *testing.T) {
+	srv := newTestServer(t, nil)
+	rec := httptest.NewRecorder()
+	srv.handleRun(rec, httptest.NewRequest(http.MethodPost, "/v1/run", strings.NewReader(`{"prompt":"hi"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandleRunMapsPromptTooLargeTo413(t *testing.T) {
+	rt, err := api.New(context.Background(), api.Options{ProjectRoot: t.TempDir(), Model: staticModel{}, MaxPromptBytes: 4})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+	srv := &httpServer{runtime: rt, defaultTimeout: defaultRunTimeout}
+
+	rec := httptest.NewRecorder()
+	srv.handleRun(rec, httptest.NewRequest(http.MethodPost, "/v1/run", strings.NewReader(`{"prompt":"far too long"}`)))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// blockingModel parks every completion until release is closed.
+type blockingModel struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (m blockingModel) Complete(ctx context.Context, req modelpkg.Request) (*modelpkg.Response, error) {
+	m.started <- struct{}{}
+	select {
+	case <-m.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return staticModel{}.Complete(ctx, req)
+}
+
+func (m blockingModel) CompleteStream(ctx context.Context, req modelpkg.Request, cb modelpkg.StreamHandler) error {
+	resp, err := m.Complete(ctx, req)
+	if err != nil {
+		return err
+	}
+	return cb(modelpkg.StreamResult{Final: true, Response: resp})
+}
+
+func TestRunLimiterRejectsWhenSaturated(t *testing.T) {
+	mdl := blockingModel{started: make(chan struct{}, 1), release: make(chan struct{})}
+	rt, err := api.New(context.Background(), api.Options{ProjectRoot: t.TempDir(), Model: mdl})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+	srv := &httpServer{runtime: rt, defaultTimeout: defaultRunTimeout, limiter: newRunLimiter(1)}
+
+	// A streaming run occupies the only slot.
+	done := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		srv.handleStream(rec, httptest.NewRequest(http.MethodPost, "/v1/run/stream", strings.NewReader(`{"prompt":"first"}`)))
+		done <- rec.Code
+	}()
+	<-mdl.started
+	if got := srv.limiter.InFlight(); got != 1 {
+		t.Fatalf("expected 1 run in flight, got %d", got)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.handleRun(rec, httptest.NewRequest(http.MethodPost, "/v1/run", strings.NewReader(`{"prompt":"second"}`)))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected Retry-After header")
+	}
+
+	close(mdl.release)
+	if code := <-done; code != http.StatusOK {
+		t.Fatalf("stream finished with %d", code)
+	}
+	if got := srv.limiter.InFlight(); got != 0 {
+		t.Fatalf("expected slot to be released, got %d in flight", got)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.handleRun(rec, httptest.NewRequest(http.MethodPost, "/v1/run", strings.NewReader(`{"prompt":"third"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 after release, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandleDescribe(t *testing.T) {
+	srv := newTestServer(t, nil)
+	rec := httptest.NewRecorder()
+	srv.handleDescribe(rec, httptest.NewRequest(http.MethodGet, "/v1/describe", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+	var info api.RuntimeInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if info.Model.Name != "primary" || len(info.Sandbox.Roots) == 0 {
+		t.Fatalf("unexpected describe payload %+v", info)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.handleDescribe(rec, httptest.NewRequest(http.MethodPost, "/v1/describe", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rec.Code)
+	}
+}
+
+func TestHandleExplain(t *testing.T) {
+	srv := newTestServer(t, nil)
+	rec := httptest.NewRecorder()
+	srv.handleExplain(rec, httptest.NewRequest(http.MethodPost, "/v1/explain", strings.NewReader(`{"prompt":"hello"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+	var exp api.Explanation
+	if err := json.Unmarshal(rec.Body.Bytes(), &exp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.handleExplain(rec, httptest.NewRequest(http.MethodPost, "/v1/explain", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.handleExplain(rec, httptest.NewRequest(http.MethodGet, "/v1/explain", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rec.Code)
+	}
+}
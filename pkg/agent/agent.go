@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/cexll/agentsdk-go/pkg/internal/pool"
 	"github.com/cexll/agentsdk-go/pkg/middleware"
 	"github.com/cexll/agentsdk-go/pkg/model"
 )
@@ -24,6 +25,25 @@ type ToolExecutor interface {
 	Execute(ctx context.Context, call ToolCall, c *Context) (ToolResult, error)
 }
 
+// StagedToolExecutor is optionally implemented by a ToolExecutor whose calls
+// have side effects, such as hooks or history writes, that must stay in call
+// order. With ParallelTools, PrepareTool is called for every call in order,
+// the prepared calls run concurrently, and Finish is called for each in call
+// order once they have all returned.
+type StagedToolExecutor interface {
+	ToolExecutor
+	PrepareTool(ctx context.Context, call ToolCall, c *Context) PreparedTool
+}
+
+// PreparedTool is a call returned by StagedToolExecutor.PrepareTool.
+type PreparedTool interface {
+	// Run executes the call and may run concurrently with other calls. It is
+	// skipped when ctx is cancelled before the call starts.
+	Run(ctx context.Context)
+	// Finish completes the call and returns its outcome.
+	Finish(ctx context.Context) (ToolResult, error)
+}
+
 // ToolCall describes a discrete tool invocation request.
 type ToolCall struct {
 	ID    string
@@ -145,45 +165,124 @@ func (a *Agent) Run(ctx context.Context, c *Context) (*ModelOutput, error) {
 			return out, nil
 		}
 
-		var firstMiddlewareErr error
+		run := a.runTools
+		if a.opts.ParallelTools && len(out.ToolCalls) > 1 {
+			run = a.runToolsParallel
+		}
+		if err := run(ctx, c, state, out.ToolCalls); err != nil {
+			return last, err
+		}
+
+		iteration++
+	}
+}
 
-		for _, call := range out.ToolCalls {
-			state.ToolCall = call
-			if err := a.mw.Execute(ctx, middleware.StageBeforeTool, state); err != nil && firstMiddlewareErr == nil {
+// runTools executes calls one after another, each between its BeforeTool and
+// AfterTool stages. The first middleware error is returned once every call
+// has run, unless ctx was cancelled.
+func (a *Agent) runTools(ctx context.Context, c *Context, state *middleware.State, calls []ToolCall) error {
+	var firstMiddlewareErr error
+	for _, call := range calls {
+		state.ToolCall = call
+		if err := a.mw.Execute(ctx, middleware.StageBeforeTool, state); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			if firstMiddlewareErr == nil {
 				firstMiddlewareErr = err
 			}
+		}
 
-			if a.tools == nil {
-				return last, fmt.Errorf("tool executor is nil for call %s", call.Name)
-			}
+		if a.tools == nil {
+			return fmt.Errorf("tool executor is nil for call %s", call.Name)
+		}
+		res := a.executeTool(ctx, call, c)
 
-			res, err := a.tools.Execute(ctx, call, c)
-			if err != nil {
-				if res.Name == "" {
-					res.Name = call.Name
-				}
-				if res.Metadata == nil {
-					res.Metadata = map[string]any{}
-				}
-				res.Metadata["is_error"] = true
-				res.Metadata["error"] = err.Error()
-				if res.Output == "" {
-					res.Output = fmt.Sprintf("Tool execution failed: %v", err)
-				}
-			}
+		c.ToolResults = append(c.ToolResults, res)
+		state.ToolResult = res
 
-			c.ToolResults = append(c.ToolResults, res)
-			state.ToolResult = res
+		if err := a.mw.Execute(ctx, middleware.StageAfterTool, state); err != nil && firstMiddlewareErr == nil {
+			firstMiddlewareErr = err
+		}
+	}
+	return firstMiddlewareErr
+}
 
-			if err := a.mw.Execute(ctx, middleware.StageAfterTool, state); err != nil && firstMiddlewareErr == nil {
+// runToolsParallel runs BeforeTool for every call in order, executes the
+// calls concurrently, then records results and runs AfterTool in call order.
+func (a *Agent) runToolsParallel(ctx context.Context, c *Context, state *middleware.State, calls []ToolCall) error {
+	var firstMiddlewareErr error
+	for _, call := range calls {
+		state.ToolCall = call
+		if err := a.mw.Execute(ctx, middleware.StageBeforeTool, state); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			if firstMiddlewareErr == nil {
 				firstMiddlewareErr = err
 			}
 		}
+	}
+	if a.tools == nil {
+		return fmt.Errorf("tool executor is nil for call %s", calls[0].Name)
+	}
 
-		if firstMiddlewareErr != nil {
-			return last, firstMiddlewareErr
+	var finish func(i int) ToolResult
+	if staged, ok := a.tools.(StagedToolExecutor); ok {
+		prepared := make([]PreparedTool, len(calls))
+		for i, call := range calls {
+			prepared[i] = staged.PrepareTool(ctx, call, c)
+		}
+		pool.Map(ctx, 0, len(calls), func(ctx context.Context, i int) (struct{}, error) {
+			prepared[i].Run(ctx)
+			return struct{}{}, nil
+		})
+		finish = func(i int) ToolResult {
+			res, err := prepared[i].Finish(ctx)
+			return foldToolError(calls[i], res, err)
 		}
+	} else {
+		results := pool.Map(ctx, 0, len(calls), func(ctx context.Context, i int) (ToolResult, error) {
+			return a.tools.Execute(ctx, calls[i], c)
+		})
+		finish = func(i int) ToolResult {
+			return foldToolError(calls[i], results[i].Value, results[i].Err)
+		}
+	}
 
-		iteration++
+	for i, call := range calls {
+		res := finish(i)
+		state.ToolCall = call
+		c.ToolResults = append(c.ToolResults, res)
+		state.ToolResult = res
+		if err := a.mw.Execute(ctx, middleware.StageAfterTool, state); err != nil && firstMiddlewareErr == nil {
+			firstMiddlewareErr = err
+		}
+	}
+	return firstMiddlewareErr
+}
+
+// executeTool runs one call, folding an execution error into the result so
+// the model sees it.
+func (a *Agent) executeTool(ctx context.Context, call ToolCall, c *Context) ToolResult {
+	res, err := a.tools.Execute(ctx, call, c)
+	return foldToolError(call, res, err)
+}
+
+// foldToolError records err, if any, on the result of call.
+func foldToolError(call ToolCall, res ToolResult, err error) ToolResult {
+	if err != nil {
+		if res.Name == "" {
+			res.Name = call.Name
+		}
+		if res.Metadata == nil {
+			res.Metadata = map[string]any{}
+		}
+		res.Metadata["is_error"] = true
+		res.Metadata["error"] = err.Error()
+		if res.Output == "" {
+			res.Output = fmt.Sprintf("Tool execution failed: %v", err)
+		}
 	}
+	return res
 }
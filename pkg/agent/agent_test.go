@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -361,6 +362,29 @@ func TestRunBeforeToolMiddlewareError(t *testing.T) {
 	}
 }
 
+func TestRunBeforeToolCancellationSkipsTool(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mw := middleware.Funcs{
+		OnBeforeTool: func(ctx context.Context, _ *middleware.State) error {
+			cancel()
+			return ctx.Err()
+		},
+	}
+	tools := &stubTools{}
+	model := &scriptedModel{outputs: []*ModelOutput{{ToolCalls: []ToolCall{{Name: "a"}, {Name: "b"}}}}}
+	ag, err := New(model, tools, Options{Middleware: middleware.NewChain([]middleware.Middleware{mw})})
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	if _, err := ag.Run(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(tools.calls) != 0 {
+		t.Fatalf("tools executed after cancellation: %+v", tools.calls)
+	}
+}
+
 func TestRunToolExecutionError(t *testing.T) {
 	sentinel := errors.New("tool exec fail")
 	model := &scriptedModel{outputs: []*ModelOutput{{ToolCalls: []ToolCall{{Name: "call"}}}}}
@@ -493,3 +517,107 @@ func TestAgent_Run_ToolExecutionError(t *testing.T) {
 		})
 	}
 }
+
+type blockingTools struct {
+	mu      sync.Mutex
+	started int
+	release chan struct{}
+}
+
+func (b *blockingTools) Execute(ctx context.Context, call ToolCall, _ *Context) (ToolResult, error) {
+	b.mu.Lock()
+	b.started++
+	if b.started == 3 {
+		close(b.release)
+	}
+	b.mu.Unlock()
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+		return ToolResult{}, ctx.Err()
+	}
+	return ToolResult{Name: call.Name, Output: call.Name}, nil
+}
+
+func TestRunParallelToolsKeepsCallOrder(t *testing.T) {
+	var after []string
+	mw := middleware.Funcs{OnAfterTool: func(_ context.Context, st *middleware.State) error {
+		after = append(after, st.ToolResult.(ToolResult).Name)
+		return nil
+	}}
+	model := &scriptedModel{outputs: []*ModelOutput{
+		{ToolCalls: []ToolCall{{Name: "a"}, {Name: "b"}, {Name: "c"}}},
+		{Content: "done", Done: true},
+	}}
+	// Each call blocks until all three have started, so a sequential loop
+	// would never finish before the timeout.
+	tools := &blockingTools{release: make(chan struct{})}
+	ag, err := New(model, tools, Options{
+		ParallelTools: true,
+		Timeout:       time.Second,
+		Middleware:    middleware.NewChain([]middleware.Middleware{mw}),
+	})
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	c := NewContext()
+	if _, err := ag.Run(context.Background(), c); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(c.ToolResults) != 3 || c.ToolResults[0].Name != "a" || c.ToolResults[2].Name != "c" {
+		t.Fatalf("unexpected result order %+v", c.ToolResults)
+	}
+	if strings.Join(after, ",") != "a,b,c" {
+		t.Fatalf("AfterTool must run in call order, got %v", after)
+	}
+}
+
+// stagedTools records its prepare and finish stages around blockingTools.
+type stagedTools struct {
+	blockingTools
+	stages []string
+}
+
+type stagedCall struct {
+	s    *stagedTools
+	call ToolCall
+	res  ToolResult
+	err  error
+}
+
+func (s *stagedTools) PrepareTool(_ context.Context, call ToolCall, _ *Context) PreparedTool {
+	s.stages = append(s.stages, "prepare:"+call.Name)
+	return &stagedCall{s: s, call: call}
+}
+
+func (p *stagedCall) Run(ctx context.Context) {
+	p.res, p.err = p.s.blockingTools.Execute(ctx, p.call, nil)
+}
+
+func (p *stagedCall) Finish(context.Context) (ToolResult, error) {
+	p.s.stages = append(p.s.stages, "finish:"+p.call.Name)
+	return p.res, p.err
+}
+
+func TestRunParallelToolsStagesInCallOrder(t *testing.T) {
+	model := &scriptedModel{outputs: []*ModelOutput{
+		{ToolCalls: []ToolCall{{Name: "a"}, {Name: "b"}, {Name: "c"}}},
+		{Content: "done", Done: true},
+	}}
+	tools := &stagedTools{blockingTools: blockingTools{release: make(chan struct{})}}
+	ag, err := New(model, tools, Options{ParallelTools: true, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	c := NewContext()
+	if _, err := ag.Run(context.Background(), c); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	want := "prepare:a,prepare:b,prepare:c,finish:a,finish:b,finish:c"
+	if got := strings.Join(tools.stages, ","); got != want {
+		t.Fatalf("stages = %s, want %s", got, want)
+	}
+	if len(c.ToolResults) != 3 || c.ToolResults[1].Output != "b" {
+		t.Fatalf("unexpected results %+v", c.ToolResults)
+	}
+}
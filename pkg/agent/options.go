@@ -15,6 +15,12 @@ type Options struct {
 	Timeout time.Duration
 	// Middleware chain. Defaults to an empty chain when nil.
 	Middleware *middleware.Chain
+	// ParallelTools executes a turn's tool calls concurrently instead of one
+	// by one. BeforeTool runs for every call first and AfterTool afterwards,
+	// both in call order. The ToolExecutor must be safe for concurrent use
+	// and is responsible for bounding concurrency; a StagedToolExecutor also
+	// keeps its own side effects in call order.
+	ParallelTools bool
 }
 
 func (o Options) withDefaults() Options {
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/cexll/agentsdk-go/pkg/model"
+)
+
+type recordingSessionMetrics struct {
+	mu     sync.Mutex
+	deltas []int64
+}
+
+func (s *recordingSessionMetrics) AddActiveSessions(delta int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deltas = append(s.deltas, delta)
+}
+
+func (s *recordingSessionMetrics) gauge() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var total int64
+	for _, d := range s.deltas {
+		total += d
+	}
+	return total
+}
+
+func TestRuntimeActiveSessionsGauge(t *testing.T) {
+	mdl := &stubModel{responses: []*model.Response{{Message: model.Message{Role: "assistant", Content: "ok"}}}}
+	metrics := &recordingSessionMetrics{}
+	rt, err := New(context.Background(), Options{
+		ProjectRoot:         t.TempDir(),
+		Model:               mdl,
+		EnabledBuiltinTools: []string{},
+		MaxSessions:         2,
+		SessionMetrics:      metrics,
+	})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+
+	// s0 is reused, s2 evicts s0 and s3 evicts s1.
+	for i, sessionID := range []string{"s0", "s1", "s0", "s2", "s3"} {
+		if _, err := rt.Run(context.Background(), Request{Prompt: "hi", SessionID: sessionID}); err != nil {
+			t.Fatalf("run %d: %v", i, err)
+		}
+		want := min(i+1, 2)
+		if got := rt.ActiveSessions(); got != want {
+			t.Fatalf("after run %d (%s) expected %d active sessions, got %d", i, sessionID, want, got)
+		}
+		if got := metrics.gauge(); got != int64(want) {
+			t.Fatalf("after run %d (%s) metric reports %d, want %d", i, sessionID, got, want)
+		}
+	}
+	if got := rt.Describe().ActiveSessions; got != 2 {
+		t.Fatalf("describe reports %d active sessions", got)
+	}
+	want := []int64{1, 1, 1, -1, 1, -1}
+	if fmt.Sprint(metrics.deltas) != fmt.Sprint(want) {
+		t.Fatalf("deltas = %v, want %v", metrics.deltas, want)
+	}
+
+	if err := rt.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if got := rt.ActiveSessions(); got != 0 || metrics.gauge() != 0 {
+		t.Fatalf("expected sessions released on close, gauge %d metric %d", got, metrics.gauge())
+	}
+}
@@ -6,14 +6,17 @@ import (
 	"fmt"
 	"log"
 	"maps"
+	"net/http"
 	"net/url"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cexll/agentsdk-go/pkg/agent"
 	"github.com/cexll/agentsdk-go/pkg/config"
+	"github.com/cexll/agentsdk-go/pkg/contextkeys"
 	coreevents "github.com/cexll/agentsdk-go/pkg/core/events"
 	corehooks "github.com/cexll/agentsdk-go/pkg/core/hooks"
 	"github.com/cexll/agentsdk-go/pkg/message"
@@ -73,6 +76,7 @@ type Runtime struct {
 	histories        *historyStore
 	historyPersister *diskHistoryPersister
 	sessionGate      *sessionGate
+	activeSessions   atomic.Int64
 
 	cmdExec   *commands.Executor
 	skReg     *skills.Registry
@@ -161,6 +165,9 @@ func New(ctx context.Context, opts Options) (*Runtime, error) {
 		return nil, err
 	}
 	executor := tool.NewExecutor(registry, sbox).WithOutputPersister(tool.NewOutputPersister())
+	if len(opts.ApprovalRequiredTools) > 0 {
+		executor = executor.WithApprovalRequired(opts.ApprovalRequiredTools...)
+	}
 
 	recorder := defaultHookRecorder()
 	hooks := newHookExecutor(opts, recorder, settings)
@@ -224,6 +231,7 @@ func New(ctx context.Context, opts Options) (*Runtime, error) {
 		ownsTaskStore:    ownsTaskStore,
 	}
 	rt.sessionGate = newSessionGate()
+	histories.onActive = rt.addActiveSessions
 
 	if taskTool != nil {
 		taskTool.SetRunner(rt.taskRunner())
@@ -250,6 +258,9 @@ func (rt *Runtime) Run(ctx context.Context, req Request) (*Response, error) {
 	if rt == nil {
 		return nil, ErrRuntimeClosed
 	}
+	if err := checkPromptSize(rt.opts.MaxPromptBytes, req); err != nil {
+		return nil, err
+	}
 	if err := rt.beginRun(); err != nil {
 		return nil, err
 	}
@@ -286,6 +297,9 @@ func (rt *Runtime) RunStream(ctx context.Context, req Request) (<-chan StreamEve
 	if strings.TrimSpace(req.Prompt) == "" && len(req.ContentBlocks) == 0 {
 		return nil, errors.New("api: prompt is empty")
 	}
+	if err := checkPromptSize(rt.opts.MaxPromptBytes, req); err != nil {
+		return nil, err
+	}
 	sessionID := strings.TrimSpace(req.SessionID)
 	if sessionID == "" {
 		sessionID = defaultSessionID(rt.mode.EntryPoint)
@@ -389,6 +403,9 @@ func (rt *Runtime) Close() error {
 		if shutdownErr != nil {
 			err = errors.Join(err, shutdownErr)
 		}
+		if rt.histories != nil {
+			rt.addActiveSessions(-int64(len(rt.histories.SessionIDs())))
+		}
 		if shutdownErr == nil && rt.histories != nil {
 			for _, sessionID := range rt.histories.SessionIDs() {
 				if cleanupErr := cleanupBashOutputSessionDir(sessionID); cleanupErr != nil {
@@ -422,6 +439,26 @@ func (rt *Runtime) Close() error {
 	return rt.closeErr
 }
 
+// ActiveSessions reports how many sessions the runtime currently retains. A
+// session counts from the run that creates its history until MaxSessions
+// evicts it or the runtime closes.
+func (rt *Runtime) ActiveSessions() int {
+	if rt == nil {
+		return 0
+	}
+	return int(rt.activeSessions.Load())
+}
+
+func (rt *Runtime) addActiveSessions(delta int64) {
+	if delta == 0 {
+		return
+	}
+	rt.activeSessions.Add(delta)
+	if rt.opts.SessionMetrics != nil {
+		rt.opts.SessionMetrics.AddActiveSessions(delta)
+	}
+}
+
 // Config returns the last loaded project config.
 func (rt *Runtime) Config() *config.Settings {
 	rt.mu.RLock()
@@ -455,6 +492,52 @@ func (rt *Runtime) GetTotalStats() *SessionTokenStats {
 	return rt.tokens.GetTotalStats()
 }
 
+// Compact applies the configured auto-compaction to sessionID's history now
+// rather than at the start of its next run, persisting the result and firing
+// the usual PreCompact/ContextCompacted events, whose hook results are
+// returned in the event's HookEvents. The threshold still applies: it returns
+// ErrNothingToCompact when the history is below it or a hook declined, and
+// ErrCompactionDisabled when auto compaction is off.
+func (rt *Runtime) Compact(ctx context.Context, sessionID string) (*CompactEvent, error) {
+	if rt == nil {
+		return nil, ErrRuntimeClosed
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	sessionID = strings.TrimSpace(sessionID)
+	if sessionID == "" {
+		return nil, errors.New("api: session id is required")
+	}
+	if err := rt.beginRun(); err != nil {
+		return nil, err
+	}
+	defer rt.endRun()
+	if rt.compactor == nil || !rt.compactor.cfg.Enabled {
+		return nil, ErrCompactionDisabled
+	}
+
+	if err := rt.sessionGate.Acquire(ctx, sessionID); err != nil {
+		return nil, err
+	}
+	defer rt.sessionGate.Release(sessionID)
+
+	ctx = contextkeys.WithSessionID(ctx, sessionID)
+	history := rt.histories.Get(sessionID)
+	recorder := defaultHookRecorder()
+	res, compacted, err := rt.compactor.maybeCompact(ctx, history, sessionID, recorder)
+	if err != nil {
+		return nil, err
+	}
+	if !compacted {
+		return nil, ErrNothingToCompact
+	}
+	rt.persistHistory(sessionID, history)
+	evt := newCompactEvent(sessionID, res, time.Now().UTC())
+	evt.HookEvents = recorder.Drain()
+	return &evt, nil
+}
+
 // ----------------- internal helpers -----------------
 
 type preparedRun struct {
@@ -466,15 +549,18 @@ type preparedRun struct {
 	recorder       *hookRecorder
 	commandResults []CommandExecution
 	skillResults   []SkillExecution
+	skillTrace     skills.MatchReport
 	subagentResult *subagents.Result
 	mode           ModeContext
 	toolWhitelist  map[string]struct{}
 }
 
 type runResult struct {
-	output *agent.ModelOutput
-	usage  model.Usage
-	reason string
+	output    *agent.ModelOutput
+	usage     model.Usage
+	reason    string
+	provider  string
+	toolUsage map[string]ToolUsageStat
 }
 
 func (rt *Runtime) prepare(ctx context.Context, req Request) (preparedRun, error) {
@@ -496,6 +582,8 @@ func (rt *Runtime) prepare(ctx context.Context, req Request) (preparedRun, error
 	if normalized.RequestID == "" {
 		normalized.RequestID = uuid.New().String()
 	}
+	ctx = contextkeys.WithSessionID(ctx, normalized.SessionID)
+	ctx = contextkeys.WithRunID(ctx, normalized.RequestID)
 
 	history := rt.histories.Get(normalized.SessionID)
 	recorder := defaultHookRecorder()
@@ -515,7 +603,7 @@ func (rt *Runtime) prepare(ctx context.Context, req Request) (preparedRun, error
 	prompt = cleanPrompt
 	activation.Prompt = prompt
 
-	skillRes, promptAfterSkills, err := rt.executeSkills(ctx, prompt, activation, &normalized)
+	skillRes, skillTrace, promptAfterSkills, err := rt.executeSkills(ctx, prompt, activation, &normalized)
 	if err != nil {
 		return preparedRun{}, err
 	}
@@ -537,6 +625,7 @@ func (rt *Runtime) prepare(ctx context.Context, req Request) (preparedRun, error
 		recorder:       recorder,
 		commandResults: cmdRes,
 		skillResults:   skillRes,
+		skillTrace:     skillTrace,
 		subagentResult: subRes,
 		mode:           normalized.Mode,
 		toolWhitelist:  whitelist,
@@ -577,7 +666,7 @@ func (rt *Runtime) runAgentWithMiddleware(prep preparedRun, extras ...middleware
 		prompt:        prep.prompt,
 		contentBlocks: prep.contentBlocks,
 		trimmer:       rt.newTrimmer(),
-		tools:         availableTools(rt.registry, prep.toolWhitelist),
+		tools:         rt.executor.ToolDefinitions(setKeys(prep.toolWhitelist)...),
 		systemPrompt:  rt.opts.SystemPrompt,
 		rulesLoader:   rt.rulesLoader,
 		enableCache:   enableCache,
@@ -585,6 +674,7 @@ func (rt *Runtime) runAgentWithMiddleware(prep preparedRun, extras ...middleware
 		recorder:      prep.recorder,
 		compactor:     rt.compactor,
 		sessionID:     prep.normalized.SessionID,
+		buildRequest:  rt.opts.RequestBuilder,
 	}
 
 	toolExec := &runtimeToolExecutor{
@@ -595,6 +685,8 @@ func (rt *Runtime) runAgentWithMiddleware(prep preparedRun, extras ...middleware
 		root:               rt.sbRoot,
 		host:               "localhost",
 		sessionID:          prep.normalized.SessionID,
+		usage:              &toolUsage{},
+		slots:              newToolSlots(rt.opts.MaxConcurrentTools),
 		permissionResolver: buildPermissionResolver(hookAdapter, rt.opts.PermissionRequestHandler, rt.opts.ApprovalQueue, rt.opts.ApprovalApprover, rt.opts.ApprovalWhitelistTTL, rt.opts.ApprovalWait),
 	}
 
@@ -610,6 +702,7 @@ func (rt *Runtime) runAgentWithMiddleware(prep preparedRun, extras ...middleware
 		MaxIterations: rt.opts.MaxIterations,
 		Timeout:       rt.opts.Timeout,
 		Middleware:    chain,
+		ParallelTools: toolExec.slots != nil,
 	})
 	if err != nil {
 		return runResult{}, err
@@ -664,7 +757,13 @@ func (rt *Runtime) runAgentWithMiddleware(prep preparedRun, extras ...middleware
 			})
 		}
 	}
-	return runResult{output: out, usage: modelAdapter.usage, reason: modelAdapter.stopReason}, nil
+	return runResult{
+		output:    out,
+		usage:     modelAdapter.usage,
+		reason:    modelAdapter.stopReason,
+		provider:  modelAdapter.provider,
+		toolUsage: toolExec.usage.snapshot(),
+	}, nil
 }
 
 func (rt *Runtime) buildResponse(prep preparedRun, result runResult) *Response {
@@ -678,12 +777,14 @@ func (rt *Runtime) buildResponse(prep preparedRun, result runResult) *Response {
 		Result:          convertRunResult(result),
 		CommandResults:  prep.commandResults,
 		SkillResults:    prep.skillResults,
+		SkillTrace:      prep.skillTrace,
 		Subagent:        prep.subagentResult,
 		HookEvents:      events,
 		ProjectConfig:   rt.Settings(),
 		Settings:        rt.Settings(),
 		SandboxSnapshot: rt.sandboxReport(),
 		Tags:            maps.Clone(prep.normalized.Tags),
+		ToolUsage:       result.toolUsage,
 	}
 	return resp
 }
@@ -737,6 +838,7 @@ func convertRunResult(res runResult) *Result {
 		ToolCalls:  toolCalls,
 		Usage:      res.usage,
 		StopReason: res.reason,
+		Provider:   res.provider,
 	}
 }
 
@@ -767,42 +869,55 @@ func (rt *Runtime) executeCommands(ctx context.Context, prompt string, req *Requ
 	return execs, cleanPrompt, nil
 }
 
-func (rt *Runtime) executeSkills(ctx context.Context, prompt string, activation skills.ActivationContext, req *Request) ([]SkillExecution, string, error) {
+func (rt *Runtime) executeSkills(ctx context.Context, prompt string, activation skills.ActivationContext, req *Request) ([]SkillExecution, skills.MatchReport, string, error) {
 	if rt.skReg == nil {
-		return nil, prompt, nil
-	}
-	matches := rt.skReg.Match(activation)
-	forced := orderedForcedSkills(rt.skReg, req.ForceSkills)
-	matches = append(matches, forced...)
-	if len(matches) == 0 {
-		return nil, prompt, nil
+		return nil, skills.MatchReport{}, prompt, nil
 	}
 	prefix := ""
-	execs := make([]SkillExecution, 0, len(matches))
+	var execs []SkillExecution
 	seen := map[string]struct{}{}
-	for _, match := range matches {
+	run := func(match skills.Activation) error {
 		skill := match.Skill
 		if skill == nil {
-			continue
+			return nil
 		}
 		name := skill.Definition().Name
 		if _, ok := seen[name]; ok {
-			continue
+			return nil
 		}
 		seen[name] = struct{}{}
 		res, err := skill.Execute(ctx, activation)
 		execs = append(execs, SkillExecution{Definition: skill.Definition(), Result: res, Err: err})
+		if errors.Is(err, skills.ErrSkillTimeout) {
+			// A slow skill is recorded but must not hold up the rest.
+			return nil
+		}
 		if err != nil {
-			return execs, "", err
+			return err
 		}
 		prefix = combinePrompt(prefix, res.Output)
 		activation.Metadata = mergeMetadata(activation.Metadata, res.Metadata)
 		mergeTags(req, res.Metadata)
 		applyCommandMetadata(req, res.Metadata)
+		return nil
+	}
+	// Auto-activated skills go through the registry's ActivationPolicy;
+	// forced skills bypass it.
+	report, err := rt.skReg.ExecuteMatchedFunc(ctx, activation, run)
+	if err != nil {
+		return execs, report, "", err
+	}
+	for _, forced := range orderedForcedSkills(rt.skReg, req.ForceSkills) {
+		if err := run(forced); err != nil {
+			return execs, report, "", err
+		}
+	}
+	if len(seen) == 0 {
+		return nil, report, prompt, nil
 	}
 	prompt = prependPrompt(prompt, prefix)
 	prompt = applyPromptMetadata(prompt, activation.Metadata)
-	return execs, prompt, nil
+	return execs, report, prompt, nil
 }
 
 func (rt *Runtime) executeSubagent(ctx context.Context, prompt string, activation skills.ActivationContext, req *Request) (*subagents.Result, string, error) {
@@ -914,7 +1029,8 @@ func (rt *Runtime) runTaskInvocation(ctx context.Context, req toolbuiltin.TaskRe
 }
 
 func convertTaskToolResult(res subagents.Result) *tool.ToolResult {
-	output := strings.TrimSpace(fmt.Sprint(res.Output))
+	frag := NewSubagentFragment(res)
+	output := frag.Output
 	if output == "" {
 		if res.Subagent != "" {
 			output = fmt.Sprintf("subagent %s completed", res.Subagent)
@@ -928,11 +1044,11 @@ func convertTaskToolResult(res subagents.Result) *tool.ToolResult {
 	if len(res.Metadata) > 0 {
 		data["metadata"] = res.Metadata
 	}
-	if res.Error != "" {
+	if frag.Err != nil {
 		data["error"] = res.Error
 	}
 	return &tool.ToolResult{
-		Success: res.Error == "",
+		Success: frag.Err == nil,
 		Output:  output,
 		Data:    data,
 	}
@@ -989,10 +1105,12 @@ type conversationModel struct {
 	enableCache   bool // Enable prompt caching for this conversation
 	usage         model.Usage
 	stopReason    string
+	provider      string
 	hooks         *runtimeHookAdapter
 	recorder      *hookRecorder
 	compactor     *compactor
 	sessionID     string
+	buildRequest  RequestBuilder
 }
 
 func (m *conversationModel) Generate(ctx context.Context, _ *agent.Context) (*agent.ModelOutput, error) {
@@ -1038,6 +1156,11 @@ func (m *conversationModel) Generate(ctx context.Context, _ *agent.Context) (*ag
 		Temperature:       nil,
 		EnablePromptCache: m.enableCache,
 	}
+	if m.buildRequest != nil {
+		if err := m.buildRequest(ctx, &req); err != nil {
+			return nil, fmt.Errorf("api: request builder: %w", err)
+		}
+	}
 
 	// Populate middleware state with model request if available
 	if st, ok := ctx.Value(model.MiddlewareStateKey).(*middleware.State); ok && st != nil {
@@ -1065,6 +1188,9 @@ func (m *conversationModel) Generate(ctx context.Context, _ *agent.Context) (*ag
 	}
 	m.usage = resp.Usage
 	m.stopReason = resp.StopReason
+	if resp.Provider != "" {
+		m.provider = resp.Provider
+	}
 
 	// Populate middleware state with model response and usage
 	if st, ok := ctx.Value(model.MiddlewareStateKey).(*middleware.State); ok && st != nil {
@@ -1075,6 +1201,9 @@ func (m *conversationModel) Generate(ctx context.Context, _ *agent.Context) (*ag
 		st.Values["model.response"] = resp
 		st.Values["model.usage"] = resp.Usage
 		st.Values["model.stop_reason"] = resp.StopReason
+		if resp.Provider != "" {
+			st.Values["model.provider"] = resp.Provider
+		}
 	}
 
 	assistant := message.Message{Role: resp.Message.Role, Content: strings.TrimSpace(resp.Message.Content), ReasoningContent: resp.Message.ReasoningContent}
@@ -1086,7 +1215,14 @@ func (m *conversationModel) Generate(ctx context.Context, _ *agent.Context) (*ag
 	}
 	m.history.Append(assistant)
 
-	out := &agent.ModelOutput{Content: assistant.Content, Done: len(assistant.ToolCalls) == 0}
+	// Branch on the canonical stop reason rather than provider strings. Tool
+	// calls still run when a proxy reports a plain end of turn alongside them.
+	done := resp.Reason() != model.StopReasonToolUse && len(assistant.ToolCalls) == 0
+	if resp.Reason() == model.StopReasonMaxTokens && len(assistant.ToolCalls) > 0 {
+		log.Printf("WARNING: response hit max_tokens while emitting %d tool call(s); "+
+			"arguments may be truncated", len(assistant.ToolCalls))
+	}
+	out := &agent.ModelOutput{Content: assistant.Content, Done: done}
 	if len(assistant.ToolCalls) > 0 {
 		out.ToolCalls = make([]agent.ToolCall, len(assistant.ToolCalls))
 		for i, call := range assistant.ToolCalls {
@@ -1110,6 +1246,10 @@ type runtimeToolExecutor struct {
 	root      string
 	host      string
 	sessionID string
+	// usage, when set, collects per-tool stats for Response.ToolUsage.
+	usage *toolUsage
+	// slots bounds concurrent calls when Options.MaxConcurrentTools > 1.
+	slots chan struct{}
 
 	permissionResolver tool.PermissionResolver
 }
@@ -1145,11 +1285,60 @@ func (t *runtimeToolExecutor) isAllowed(ctx context.Context, name string) bool {
 }
 
 func (t *runtimeToolExecutor) Execute(ctx context.Context, call agent.ToolCall, _ *agent.Context) (agent.ToolResult, error) {
+	p := t.prepare(ctx, call)
+	p.Run(ctx)
+	return p.Finish(ctx)
+}
+
+// PrepareTool implements agent.StagedToolExecutor, so parallel calls run
+// their hooks and reach history in call order.
+func (t *runtimeToolExecutor) PrepareTool(ctx context.Context, call agent.ToolCall, _ *agent.Context) agent.PreparedTool {
+	return t.prepare(ctx, call)
+}
+
+// newToolSlots returns the per-run semaphore for Options.MaxConcurrentTools,
+// or nil when calls run sequentially.
+func newToolSlots(n int) chan struct{} {
+	if n <= 1 {
+		return nil
+	}
+	return make(chan struct{}, n)
+}
+
+// preparedToolCall is one call split into stages: prepare runs the
+// whitelist, argument and PreToolUse checks, Run executes the tool, and
+// Finish runs PostToolUse and appends the result to history.
+type preparedToolCall struct {
+	t    *runtimeToolExecutor
+	call agent.ToolCall
+	// settled is set once the call has an outcome; a call settled by
+	// prepare never executes.
+	settled bool
+	// executed is set when the tool ran, so Finish runs PostToolUse.
+	executed bool
+	// record makes Finish append content to history.
+	record  bool
+	content string
+	callRes *tool.CallResult
+	result  agent.ToolResult
+	err     error
+}
+
+func (p *preparedToolCall) settle(res agent.ToolResult, err error) {
+	p.settled = true
+	p.result = res
+	p.err = err
+}
+
+func (t *runtimeToolExecutor) prepare(ctx context.Context, call agent.ToolCall) *preparedToolCall {
+	p := &preparedToolCall{t: t, call: call}
 	if t.executor == nil {
-		return agent.ToolResult{}, errors.New("tool executor not initialised")
+		p.settle(agent.ToolResult{}, errors.New("tool executor not initialised"))
+		return p
 	}
 	if !t.isAllowed(ctx, call.Name) {
-		return agent.ToolResult{}, fmt.Errorf("tool %s is not whitelisted", call.Name)
+		p.settle(agent.ToolResult{}, fmt.Errorf("tool %s is not whitelisted", call.Name))
+		return p
 	}
 
 	// Defensive check: if tool call has empty/nil arguments but the tool requires
@@ -1164,40 +1353,18 @@ func (t *runtimeToolExecutor) Execute(ctx context.Context, call agent.ToolCall,
 							"the API proxy likely stripped tool_use.input — check proxy configuration",
 						call.Name, schema.Required)
 					log.Printf("WARNING: %s (id=%s)", errMsg, call.ID)
-					if t.history != nil {
-						t.history.Append(message.Message{
-							Role: "tool",
-							ToolCalls: []message.ToolCall{{
-								ID:     call.ID,
-								Name:   call.Name,
-								Result: errMsg,
-							}},
-						})
-					}
-					return agent.ToolResult{
+					p.record, p.content = true, errMsg
+					p.settle(agent.ToolResult{
 						Name:     call.Name,
 						Output:   errMsg,
 						Metadata: map[string]any{"error": "empty_arguments"},
-					}, nil
+					}, nil)
+					return p
 				}
 			}
 		}
 	}
 
-	// Helper to append tool result to history
-	appendToolResult := func(content string) {
-		if t.history != nil {
-			t.history.Append(message.Message{
-				Role: "tool",
-				ToolCalls: []message.ToolCall{{
-					ID:     call.ID,
-					Name:   call.Name,
-					Result: content,
-				}},
-			})
-		}
-	}
-
 	params, preErr := t.hooks.PreToolUse(ctx, coreToolUsePayload(call))
 	if preErr != nil {
 		if errors.Is(preErr, ErrToolUseRequiresApproval) && t.permissionResolver != nil {
@@ -1231,11 +1398,34 @@ func (t *runtimeToolExecutor) Execute(ctx context.Context, call agent.ToolCall,
 	if preErr != nil {
 		// Hook denied execution - still need to add tool_result to history
 		errContent := fmt.Sprintf(`{"error":%q}`, preErr.Error())
-		appendToolResult(errContent)
-		return agent.ToolResult{Name: call.Name, Output: errContent, Metadata: map[string]any{"error": preErr.Error()}}, preErr
+		p.record, p.content = true, errContent
+		p.settle(agent.ToolResult{Name: call.Name, Output: errContent, Metadata: map[string]any{"error": preErr.Error()}}, preErr)
+		return p
 	}
 	if params != nil {
-		call.Input = params
+		p.call.Input = params
+	}
+	return p
+}
+
+// Run executes the call unless prepare settled it, waiting for a
+// MaxConcurrentTools slot first.
+func (p *preparedToolCall) Run(ctx context.Context) {
+	if p.settled {
+		return
+	}
+	t, call := p.t, p.call
+	var wait time.Duration
+	if t.slots != nil {
+		queued := time.Now()
+		select {
+		case t.slots <- struct{}{}:
+		case <-ctx.Done():
+			p.settle(agent.ToolResult{}, ctx.Err())
+			return
+		}
+		defer func() { <-t.slots }()
+		wait = time.Since(queued)
 	}
 
 	callSpec := tool.Call{
@@ -1266,6 +1456,7 @@ func (t *runtimeToolExecutor) Execute(ctx context.Context, call agent.ToolCall,
 		exec = exec.WithPermissionResolver(t.permissionResolver)
 	}
 	result, err := exec.Execute(ctx, callSpec)
+	t.usage.record(call.Name, result, err, wait)
 	toolResult := agent.ToolResult{Name: call.Name}
 	meta := map[string]any{}
 	content := ""
@@ -1284,15 +1475,38 @@ func (t *runtimeToolExecutor) Execute(ctx context.Context, call agent.ToolCall,
 	if len(meta) > 0 {
 		toolResult.Metadata = meta
 	}
+	p.executed, p.record, p.content, p.callRes = true, true, content, result
+	p.settle(toolResult, err)
+}
 
-	if hookErr := t.hooks.PostToolUse(ctx, coreToolResultPayload(call, result, err)); hookErr != nil && err == nil {
-		// Hook failed - still need to add tool_result to history
-		appendToolResult(content)
-		return toolResult, hookErr
-	}
-
-	appendToolResult(content)
-	return toolResult, err
+// Finish runs PostToolUse for an executed call and appends its result to
+// history. A call that never ran reports the context error.
+func (p *preparedToolCall) Finish(ctx context.Context) (agent.ToolResult, error) {
+	if !p.settled {
+		err := ctx.Err()
+		if err == nil {
+			err = context.Canceled
+		}
+		p.settle(agent.ToolResult{}, err)
+	}
+	t := p.t
+	if p.executed {
+		if hookErr := t.hooks.PostToolUse(ctx, coreToolResultPayload(p.call, p.callRes, p.err)); hookErr != nil && p.err == nil {
+			// Hook failed - the tool_result still goes to history
+			p.err = hookErr
+		}
+	}
+	if p.record && t.history != nil {
+		t.history.Append(message.Message{
+			Role: "tool",
+			ToolCalls: []message.ToolCall{{
+				ID:     p.call.ID,
+				Name:   p.call.Name,
+				Result: p.content,
+			}},
+		})
+	}
+	return p.result, p.err
 }
 
 func coreToolUsePayload(call agent.ToolCall) coreevents.ToolUsePayload {
@@ -1463,7 +1677,29 @@ func registerTools(registry *tool.Registry, opts Options, settings *config.Setti
 			cmdExec = commands.NewExecutor()
 		}
 
-		factories := builtinToolFactories(opts.ProjectRoot, sandboxDisabled, entry, settings, skReg, cmdExec, opts.TaskStore)
+		factories := builtinToolFactories(opts.ProjectRoot, sandboxDisabled, entry, settings, skReg, cmdExec, opts.TaskStore, opts.HTTPClient)
+		limits := opts.Sandbox.ResourceLimit
+		monitor := opts.Sandbox.EnforceResourceLimits && limits != (sandbox.ResourceLimits{})
+		rlimits := limits.MaxProcesses > 0 || limits.MaxOpenFiles > 0
+		if !sandboxDisabled && (monitor || rlimits) {
+			baseBash := factories["bash"]
+			factories["bash"] = func() tool.Tool {
+				built := baseBash()
+				if bash, ok := built.(*toolbuiltin.BashTool); ok {
+					if monitor {
+						bash.SetResourceMonitor(sandbox.NewResourceLimiter(limits), nil, 0)
+					}
+					bash.SetProcessLimits(limits)
+				}
+				return built
+			}
+		}
+		if opts.Sandbox.RestrictWebFetch && !sandboxDisabled {
+			policy := sandbox.NewDomainAllowList(opts.Sandbox.NetworkAllow...)
+			factories["web_fetch"] = func() tool.Tool {
+				return toolbuiltin.NewWebFetchTool(&toolbuiltin.WebFetchOptions{HTTPClient: opts.HTTPClient, NetworkPolicy: policy})
+			}
+		}
 		names := builtinOrder(entry)
 		selectedNames := filterBuiltinNames(opts.EnabledBuiltinTools, names)
 		for _, name := range selectedNames {
@@ -1535,7 +1771,7 @@ func registerTools(registry *tool.Registry, opts Options, settings *config.Setti
 	return taskTool, nil
 }
 
-func builtinToolFactories(root string, sandboxDisabled bool, entry EntryPoint, settings *config.Settings, skReg *skills.Registry, cmdExec *commands.Executor, taskStore tasks.Store) map[string]func() tool.Tool {
+func builtinToolFactories(root string, sandboxDisabled bool, entry EntryPoint, settings *config.Settings, skReg *skills.Registry, cmdExec *commands.Executor, taskStore tasks.Store, httpClient *http.Client) map[string]func() tool.Tool {
 	factories := map[string]func() tool.Tool{}
 
 	var (
@@ -1625,8 +1861,12 @@ func builtinToolFactories(root string, sandboxDisabled bool, entry EntryPoint, s
 	factories["file_edit"] = editCtor
 	factories["grep"] = grepCtor
 	factories["glob"] = globCtor
-	factories["web_fetch"] = func() tool.Tool { return toolbuiltin.NewWebFetchTool(nil) }
-	factories["web_search"] = func() tool.Tool { return toolbuiltin.NewWebSearchTool(nil) }
+	factories["web_fetch"] = func() tool.Tool {
+		return toolbuiltin.NewWebFetchTool(&toolbuiltin.WebFetchOptions{HTTPClient: httpClient})
+	}
+	factories["web_search"] = func() tool.Tool {
+		return toolbuiltin.NewWebSearchTool(&toolbuiltin.WebSearchOptions{HTTPClient: httpClient})
+	}
 	factories["bash_output"] = func() tool.Tool { return toolbuiltin.NewBashOutputTool(nil) }
 	factories["bash_status"] = func() tool.Tool { return toolbuiltin.NewBashStatusTool() }
 	factories["kill_task"] = func() tool.Tool { return toolbuiltin.NewKillTaskTool() }
@@ -1789,12 +2029,29 @@ func enforceSandboxHost(manager *sandbox.Manager, server string) error {
 	return nil
 }
 
+// httpClientUser is implemented by model factories that can adopt the
+// runtime's shared HTTP client. WithHTTPClient must not modify the receiver.
+type httpClientUser interface {
+	WithHTTPClient(*http.Client) model.Provider
+}
+
+// withSharedHTTPClient returns factory bound to client when it can adopt one.
+func withSharedHTTPClient(factory ModelFactory, client *http.Client) ModelFactory {
+	if setter, ok := factory.(httpClientUser); ok && client != nil {
+		return setter.WithHTTPClient(client)
+	}
+	return factory
+}
+
 func resolveModel(ctx context.Context, opts Options) (model.Model, error) {
+	if len(opts.ModelFallbacks) > 0 {
+		return resolveFailoverModel(opts)
+	}
 	if opts.Model != nil {
 		return opts.Model, nil
 	}
 	if opts.ModelFactory != nil {
-		mdl, err := opts.ModelFactory.Model(ctx)
+		mdl, err := withSharedHTTPClient(opts.ModelFactory, opts.HTTPClient).Model(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("api: model factory: %w", err)
 		}
@@ -1803,6 +2060,35 @@ func resolveModel(ctx context.Context, opts Options) (model.Model, error) {
 	return nil, ErrMissingModel
 }
 
+// resolveFailoverModel chains the primary model with Options.ModelFallbacks.
+// Factories are resolved lazily per call so a primary that cannot be built
+// at startup fails over instead of failing New.
+func resolveFailoverModel(opts Options) (model.Model, error) {
+	var candidates []model.FailoverCandidate
+	primary := model.Provider(withSharedHTTPClient(opts.ModelFactory, opts.HTTPClient))
+	if opts.Model != nil {
+		fixed := opts.Model
+		primary = model.ProviderFunc(func(context.Context) (model.Model, error) { return fixed, nil })
+	}
+	if primary != nil {
+		candidates = append(candidates, model.FailoverCandidate{Name: "primary", Provider: primary})
+	}
+	for i, fb := range opts.ModelFallbacks {
+		name := strings.TrimSpace(fb.Name)
+		if name == "" {
+			name = fmt.Sprintf("fallback-%d", i+1)
+		}
+		if fb.Factory != nil {
+			candidates = append(candidates, model.FailoverCandidate{Name: name, Provider: withSharedHTTPClient(fb.Factory, opts.HTTPClient)})
+		}
+	}
+	mdl, err := model.NewFailover(candidates...)
+	if errors.Is(err, model.ErrNoFailoverCandidates) {
+		return nil, ErrMissingModel
+	}
+	return mdl, err
+}
+
 func defaultSessionID(entry EntryPoint) string {
 	prefix := strings.TrimSpace(string(entry))
 	if prefix == "" {
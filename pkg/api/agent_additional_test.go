@@ -375,57 +375,6 @@ func TestRuntimeToolExecutorEnforcesResourceLimits(t *testing.T) {
 	}
 }
 
-func TestAvailableToolsNilRegistry(t *testing.T) {
-	if defs := availableTools(nil, nil); defs != nil {
-		t.Fatalf("expected nil definitions, got %+v", defs)
-	}
-}
-
-func TestAvailableToolsFiltersWhitelist(t *testing.T) {
-	reg := tool.NewRegistry()
-	if err := reg.Register(&echoTool{}); err != nil {
-		t.Fatalf("register tool: %v", err)
-	}
-	defs := availableTools(reg, map[string]struct{}{"missing": {}})
-	if len(defs) != 0 {
-		t.Fatalf("expected tools filtered out, got %v", defs)
-	}
-}
-
-func TestAvailableToolsReturnsDefinitions(t *testing.T) {
-	reg := tool.NewRegistry()
-	if err := reg.Register(&echoTool{}); err != nil {
-		t.Fatalf("register tool: %v", err)
-	}
-	defs := availableTools(reg, nil)
-	if len(defs) != 1 || defs[0].Name != "echo" {
-		t.Fatalf("unexpected tool defs: %+v", defs)
-	}
-	if defs[0].Description == "" {
-		t.Fatal("expected description to be populated")
-	}
-}
-
-func TestAvailableToolsWhitelistAllowsMatch(t *testing.T) {
-	reg := tool.NewRegistry()
-	if err := reg.Register(&echoTool{}); err != nil {
-		t.Fatalf("register tool: %v", err)
-	}
-	defs := availableTools(reg, map[string]struct{}{"echo": {}})
-	if len(defs) != 1 || defs[0].Name != "echo" {
-		t.Fatalf("expected whitelisted tool, got %+v", defs)
-	}
-}
-
-func TestSchemaToMap(t *testing.T) {
-	schema := &tool.JSONSchema{Type: "object", Required: []string{"x"}, Properties: map[string]any{"x": map[string]any{"type": "string"}}}
-	mapped := schemaToMap(schema)
-	reqField, ok := mapped["required"].([]string)
-	if mapped["type"] != "object" || !ok || len(reqField) != 1 {
-		t.Fatalf("unexpected map: %+v", mapped)
-	}
-}
-
 func TestHistoryStoreCreatesOnce(t *testing.T) {
 	store := newHistoryStore(0)
 	a := store.Get("s1")
@@ -934,3 +883,51 @@ func (s *taskStoreCloseSpy) Close() error {
 	atomic.AddInt32(&s.closeCalls, 1)
 	return s.closeErr
 }
+
+func TestRunAppliesSkillPolicyAndReportsSuppressions(t *testing.T) {
+	var ran []string
+	register := func(name string, priority int) SkillRegistration {
+		return SkillRegistration{
+			Definition: skills.Definition{Name: name, Priority: priority, Matchers: []skills.Matcher{skills.KeywordMatcher{Any: []string{"deploy"}}}},
+			Handler: skills.HandlerFunc(func(context.Context, skills.ActivationContext) (skills.Result, error) {
+				ran = append(ran, name)
+				return skills.Result{Output: name}, nil
+			}),
+		}
+	}
+	rt, err := New(context.Background(), Options{
+		ProjectRoot:         newClaudeProject(t),
+		Model:               &stubModel{responses: []*model.Response{{Message: model.Message{Role: "assistant", Content: "ok"}}}},
+		EnabledBuiltinTools: []string{},
+		Skills:              []SkillRegistration{register("first", 2), register("second", 1)},
+		SkillPolicy:         skills.ActivationPolicy{MaxPerActivation: 1, Cooldown: time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+
+	resp, err := rt.Run(context.Background(), Request{Prompt: "deploy now", SessionID: "policy"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(ran) != 1 || ran[0] != "first" || len(resp.SkillResults) != 1 {
+		t.Fatalf("expected only the top skill to run, ran=%v", ran)
+	}
+	trace := resp.SkillTrace
+	if len(trace.Activations) != 1 || len(trace.Suppressed) != 1 ||
+		trace.Suppressed[0].Skill.Definition().Name != "second" || trace.Suppressed[0].Cause != skills.SuppressedByCap {
+		t.Fatalf("unexpected skill trace %+v", trace)
+	}
+
+	resp, err = rt.Run(context.Background(), Request{Prompt: "deploy again", SessionID: "policy"})
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if len(ran) != 2 || ran[1] != "second" {
+		t.Fatalf("expected cooldown to hand the next run to the other skill, ran=%v", ran)
+	}
+	if s := resp.SkillTrace.Suppressed; len(s) != 1 || s[0].Cause != skills.SuppressedByCooldown {
+		t.Fatalf("expected cooldown suppression, got %+v", s)
+	}
+}
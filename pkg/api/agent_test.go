@@ -7,14 +7,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/cexll/agentsdk-go/pkg/agent"
 	"github.com/cexll/agentsdk-go/pkg/config"
+	"github.com/cexll/agentsdk-go/pkg/contextkeys"
 	coreevents "github.com/cexll/agentsdk-go/pkg/core/events"
 	corehooks "github.com/cexll/agentsdk-go/pkg/core/hooks"
 	"github.com/cexll/agentsdk-go/pkg/message"
+	"github.com/cexll/agentsdk-go/pkg/middleware"
 	"github.com/cexll/agentsdk-go/pkg/model"
 	"github.com/cexll/agentsdk-go/pkg/runtime/commands"
 	"github.com/cexll/agentsdk-go/pkg/runtime/skills"
@@ -223,6 +226,48 @@ func TestRuntimePermissionAskAutoWhitelist(t *testing.T) {
 	}
 }
 
+func TestRuntimeApprovalRequiredToolsQueuePending(t *testing.T) {
+	root := newClaudeProject(t)
+	mdl := &stubModel{responses: []*model.Response{
+		{Message: model.Message{Role: "assistant", ToolCalls: []model.ToolCall{
+			{ID: "1", Name: "echo", Arguments: map[string]any{"text": "hi"}},
+			{ID: "2", Name: "sleepy", Arguments: map[string]any{}},
+		}}},
+		{Message: model.Message{Role: "assistant", Content: "done"}},
+	}}
+	queue, err := security.NewApprovalQueue(filepath.Join(t.TempDir(), "approvals.json"))
+	if err != nil {
+		t.Fatalf("approval queue: %v", err)
+	}
+	gated := &echoTool{}
+	rt, err := New(context.Background(), Options{
+		ProjectRoot:           root,
+		Model:                 mdl,
+		Tools:                 []tool.Tool{gated, &sleepyTool{}},
+		ApprovalQueue:         queue,
+		ApprovalRequiredTools: []string{"echo"},
+	})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+
+	resp, err := rt.Run(context.Background(), Request{Prompt: "call tools", SessionID: "sess-approval"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if gated.calls != 0 {
+		t.Fatalf("approval-required tool ran %d times", gated.calls)
+	}
+	if resp.ToolUsage["sleepy"].Count != 1 || resp.ToolUsage["sleepy"].Errors != 0 {
+		t.Fatalf("free tool should execute immediately, usage %+v", resp.ToolUsage)
+	}
+	pending := queue.ListPending()
+	if len(pending) != 1 || pending[0].SessionID != "sess-approval" || !strings.HasPrefix(pending[0].Command, "echo") {
+		t.Fatalf("expected one pending echo approval, got %+v", pending)
+	}
+}
+
 func TestRuntimeHookAskUsesPermissionHandler(t *testing.T) {
 	root := newClaudeProject(t)
 	mdl := &stubModel{responses: []*model.Response{
@@ -518,6 +563,44 @@ func TestRuntimeCommandAndSkillIntegration(t *testing.T) {
 	}
 }
 
+func TestRuntimeSkillTimeoutDoesNotBlockOtherSkills(t *testing.T) {
+	root := newClaudeProject(t)
+	mdl := &stubModel{responses: []*model.Response{{Message: model.Message{Role: "assistant", Content: "ok"}}}}
+	trigger := []skills.Matcher{skills.KeywordMatcher{Any: []string{"trigger"}}}
+	slow := SkillRegistration{
+		Definition: skills.Definition{Name: "slow", Priority: 1, Matchers: trigger, Timeout: 10 * time.Millisecond},
+		Handler: skills.HandlerFunc(func(ctx context.Context, _ skills.ActivationContext) (skills.Result, error) {
+			<-ctx.Done()
+			return skills.Result{}, ctx.Err()
+		}),
+	}
+	fast := SkillRegistration{
+		Definition: skills.Definition{Name: "fast", Matchers: trigger},
+		Handler: skills.HandlerFunc(func(context.Context, skills.ActivationContext) (skills.Result, error) {
+			return skills.Result{Output: "fast-prefix"}, nil
+		}),
+	}
+	rt, err := New(context.Background(), Options{ProjectRoot: root, Model: mdl, Skills: []SkillRegistration{slow, fast}})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+
+	resp, err := rt.Run(context.Background(), Request{Prompt: "trigger"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(resp.SkillResults) != 2 {
+		t.Fatalf("expected both skills recorded, got %+v", resp.SkillResults)
+	}
+	if resp.SkillResults[0].Definition.Name != "slow" || !errors.Is(resp.SkillResults[0].Err, skills.ErrSkillTimeout) {
+		t.Fatalf("expected slow skill timeout first, got %+v", resp.SkillResults[0])
+	}
+	if resp.SkillResults[1].Err != nil || resp.SkillResults[1].Result.Output != "fast-prefix" {
+		t.Fatalf("expected fast skill to run, got %+v", resp.SkillResults[1])
+	}
+}
+
 func newClaudeProject(t *testing.T) string {
 	t.Helper()
 	root := t.TempDir()
@@ -680,3 +763,67 @@ func (f *failingTool) Schema() *tool.JSONSchema { return &tool.JSONSchema{Type:
 func (f *failingTool) Execute(context.Context, map[string]interface{}) (*tool.ToolResult, error) {
 	return nil, f.err
 }
+
+func TestRuntimeRejectsOversizedPromptBeforeModel(t *testing.T) {
+	root := newClaudeProject(t)
+	mdl := &stubModel{responses: []*model.Response{{Message: model.Message{Role: "assistant", Content: "done"}}}}
+	rt, err := New(context.Background(), Options{ProjectRoot: root, Model: mdl, MaxPromptBytes: 8})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+
+	_, err = rt.Run(context.Background(), Request{Prompt: "this prompt is too long"})
+	var tooLarge *PromptTooLargeError
+	if !errors.Is(err, ErrPromptTooLarge) || !errors.As(err, &tooLarge) {
+		t.Fatalf("expected ErrPromptTooLarge, got %v", err)
+	}
+	if tooLarge.Limit != 8 || tooLarge.Size != len("this prompt is too long") {
+		t.Fatalf("unexpected limit/size: %+v", tooLarge)
+	}
+	if _, err := rt.RunStream(context.Background(), Request{Prompt: "this prompt is too long"}); !errors.Is(err, ErrPromptTooLarge) {
+		t.Fatalf("expected stream rejection, got %v", err)
+	}
+	if len(mdl.requests) != 0 {
+		t.Fatalf("model should not be called for oversized prompts, got %d calls", len(mdl.requests))
+	}
+
+	_, err = rt.Run(context.Background(), Request{Prompt: "hello", MaxPromptBytes: 4})
+	if !errors.As(err, &tooLarge) || tooLarge.Limit != 4 {
+		t.Fatalf("expected per-request limit to apply, got %v", err)
+	}
+
+	resp, err := rt.Run(context.Background(), Request{Prompt: "hello", MaxPromptBytes: 100})
+	if err != nil {
+		t.Fatalf("within-limit prompt should run: %v", err)
+	}
+	if resp.Result == nil || resp.Result.Output != "done" {
+		t.Fatalf("unexpected result: %+v", resp.Result)
+	}
+}
+
+func TestRuntimeStoresTypedContextKeys(t *testing.T) {
+	root := newClaudeProject(t)
+	var sessionID, runID string
+	mw := middleware.Funcs{
+		Identifier: "capture",
+		OnBeforeAgent: func(ctx context.Context, _ *middleware.State) error {
+			sessionID = contextkeys.SessionIDFrom(ctx)
+			runID = contextkeys.RunIDFrom(ctx)
+			return nil
+		},
+	}
+	mdl := &stubModel{responses: []*model.Response{{Message: model.Message{Role: "assistant", Content: "done"}}}}
+	rt, err := New(context.Background(), Options{ProjectRoot: root, Model: mdl, Middleware: []middleware.Middleware{mw}})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+
+	if _, err := rt.Run(context.Background(), Request{Prompt: "hello", SessionID: "typed-session", RequestID: "run-42"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if sessionID != "typed-session" || runID != "run-42" {
+		t.Fatalf("expected typed context keys, got session=%q run=%q", sessionID, runID)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
+	"math/rand/v2"
 	"strings"
 	"sync"
 	"time"
@@ -21,6 +23,10 @@ type CompactConfig struct {
 	Threshold     float64 `json:"threshold"`      // trigger ratio (default 0.8)
 	PreserveCount int     `json:"preserve_count"` // keep latest N messages (default 5)
 	SummaryModel  string  `json:"summary_model"`  // model tier/name used for summary
+	// Extractive builds the summary locally from the first line of each
+	// compacted message instead of calling the model. It is deterministic
+	// and works offline; SummaryModel and the retry settings are ignored.
+	Extractive bool `json:"extractive"`
 
 	PreserveInitial  bool `json:"preserve_initial"`   // keep initial messages when compacting
 	InitialCount     int  `json:"initial_count"`      // keep first N messages from the compacted prefix
@@ -28,7 +34,11 @@ type CompactConfig struct {
 	UserTextTokens   int  `json:"user_text_tokens"`   // token budget for preserved user messages
 
 	MaxRetries    int           `json:"max_retries"`
-	RetryDelay    time.Duration `json:"retry_delay"`
+	RetryDelay    time.Duration `json:"retry_delay"`     // initial delay before the first retry
+	RetryBackoff  float64       `json:"retry_backoff"`   // delay multiplier per retry (default 1 = fixed delay)
+	RetryJitter   bool          `json:"retry_jitter"`    // randomise delays, never below RetryDelay
+	MaxRetryDelay time.Duration `json:"max_retry_delay"` // cap for a single delay (0 = uncapped)
+	RetryBudget   time.Duration `json:"retry_budget"`    // stop retrying once exceeded (0 = unlimited)
 	FallbackModel string        `json:"fallback_model"`
 
 	// RolloutDir enables compact event persistence when non-empty.
@@ -39,6 +49,7 @@ type CompactConfig struct {
 const (
 	defaultCompactThreshold   = 0.8
 	defaultCompactPreserve    = 5
+	defaultCompactBackoff     = 1.0
 	defaultClaudeContextLimit = 200000
 	summaryMaxTokens          = 1024
 )
@@ -72,6 +83,15 @@ func (c CompactConfig) withDefaults() CompactConfig {
 	if cfg.RetryDelay < 0 {
 		cfg.RetryDelay = 0
 	}
+	if cfg.RetryBackoff < 1 {
+		cfg.RetryBackoff = defaultCompactBackoff
+	}
+	if cfg.MaxRetryDelay < 0 {
+		cfg.MaxRetryDelay = 0
+	}
+	if cfg.RetryBudget < 0 {
+		cfg.RetryBudget = 0
+	}
 	cfg.FallbackModel = strings.TrimSpace(cfg.FallbackModel)
 	cfg.RolloutDir = strings.TrimSpace(cfg.RolloutDir)
 	return cfg
@@ -84,6 +104,10 @@ type compactor struct {
 	hooks   *corehooks.Executor
 	rollout *RolloutWriter
 	mu      sync.Mutex
+
+	// jitter randomises retry delays when RetryJitter is set; nil uses
+	// equalJitter.
+	jitter func(time.Duration) time.Duration
 }
 
 func newCompactor(projectRoot string, cfg CompactConfig, mdl model.Model, tokenLimit int, hooks *corehooks.Executor) *compactor {
@@ -125,6 +149,14 @@ type compactResult struct {
 	preservedMsgs int
 	tokensBefore  int
 	tokensAfter   int
+	attempts      int
+	retryTime     time.Duration
+}
+
+// summaryRetryStats records how much retrying a summary request needed.
+type summaryRetryStats struct {
+	attempts  int
+	retryTime time.Duration
 }
 
 func (c *compactor) maybeCompact(ctx context.Context, hist *message.History, sessionID string, recorder *hookRecorder) (compactResult, bool, error) {
@@ -228,33 +260,100 @@ func (c *compactor) record(recorder *hookRecorder, evt coreevents.Event) {
 }
 
 func (c *compactor) compact(ctx context.Context, hist *message.History, snapshot []message.Message, tokensBefore int) (compactResult, error) {
-	if c.model == nil {
+	if c.model == nil && !c.cfg.Extractive {
 		return compactResult{}, errors.New("api: summary model is nil")
 	}
+	if c.cfg.PreserveCount >= len(snapshot) {
+		return compactResult{}, nil
+	}
+	plan := c.plan(snapshot)
+	if len(plan.summarize) == 0 {
+		return compactResult{}, errNoCompaction
+	}
+	initial, userText, kept, summarize := plan.initial, plan.userText, plan.kept, plan.summarize
+
+	var (
+		summary string
+		stats   summaryRetryStats
+	)
+	if c.cfg.Extractive {
+		summary = extractiveSummary(summarize)
+	} else {
+		req := model.Request{
+			Messages:  convertMessages(summarize),
+			System:    summarySystemPrompt,
+			Model:     c.cfg.SummaryModel,
+			MaxTokens: summaryMaxTokens,
+		}
+		resp, retryStats, err := c.summarizeWithRetry(ctx, req)
+		if err != nil {
+			return compactResult{}, fmt.Errorf("api: compact summary: %w", err)
+		}
+		summary = strings.TrimSpace(resp.Message.Content)
+		stats = retryStats
+	}
+	if summary == "" {
+		summary = "对话摘要为空"
+	}
+
+	newMsgs := make([]message.Message, 0, len(initial)+1+len(userText)+len(kept))
+	newMsgs = append(newMsgs, message.CloneMessages(initial)...)
+	newMsgs = append(newMsgs, message.Message{
+		Role:    "system",
+		Content: fmt.Sprintf("对话摘要：\n%s", summary),
+	})
+	newMsgs = append(newMsgs, message.CloneMessages(userText)...)
+	newMsgs = append(newMsgs, message.CloneMessages(kept)...)
+	hist.Replace(newMsgs)
+
+	tokensAfter := hist.TokenCount()
+	preservedMsgs := len(initial) + len(userText) + len(kept)
+	return compactResult{
+		summary:       summary,
+		originalMsgs:  len(snapshot),
+		preservedMsgs: preservedMsgs,
+		tokensBefore:  tokensBefore,
+		tokensAfter:   tokensAfter,
+		attempts:      stats.attempts,
+		retryTime:     stats.retryTime,
+	}, nil
+}
+
+// compactPlan partitions a history snapshot into the messages compaction
+// keeps verbatim and the ones it folds into the summary.
+type compactPlan struct {
+	initial   []message.Message
+	userText  []message.Message
+	kept      []message.Message
+	summarize []message.Message
+	dropped   []int // snapshot indices of summarize
+}
+
+func (c *compactor) plan(snapshot []message.Message) compactPlan {
+	var p compactPlan
 	preserve := c.cfg.PreserveCount
 	if preserve >= len(snapshot) {
-		return compactResult{}, nil
+		p.kept = snapshot
+		return p
 	}
 	cut := len(snapshot) - preserve
 	older := snapshot[:cut]
-	kept := snapshot[cut:]
+	p.kept = snapshot[cut:]
 
 	preservedPrefix := make([]bool, len(older))
 
-	var initial []message.Message
 	if c.cfg.PreserveInitial && c.cfg.InitialCount > 0 {
 		n := c.cfg.InitialCount
 		if n > len(older) {
 			n = len(older)
 		}
-		initial = make([]message.Message, 0, n)
+		p.initial = make([]message.Message, 0, n)
 		for i := 0; i < n; i++ {
 			preservedPrefix[i] = true
-			initial = append(initial, message.CloneMessage(older[i]))
+			p.initial = append(p.initial, message.CloneMessage(older[i]))
 		}
 	}
 
-	var userText []message.Message
 	if c.cfg.PreserveUserText && c.cfg.UserTextTokens > 0 {
 		var counter message.NaiveCounter
 		total := 0
@@ -275,66 +374,39 @@ func (c *compactor) compact(ctx context.Context, hist *message.History, snapshot
 			}
 		}
 		if len(indices) > 0 {
-			userText = make([]message.Message, 0, len(indices))
+			p.userText = make([]message.Message, 0, len(indices))
 			for j := len(indices) - 1; j >= 0; j-- {
-				userText = append(userText, message.CloneMessage(older[indices[j]]))
+				p.userText = append(p.userText, message.CloneMessage(older[indices[j]]))
 			}
 		}
 	}
 
-	summarize := make([]message.Message, 0, len(older))
+	p.summarize = make([]message.Message, 0, len(older))
 	for i, msg := range older {
 		if preservedPrefix[i] {
 			continue
 		}
-		summarize = append(summarize, msg)
-	}
-	if len(summarize) == 0 {
-		return compactResult{}, errNoCompaction
+		p.summarize = append(p.summarize, msg)
+		p.dropped = append(p.dropped, i)
 	}
-
-	req := model.Request{
-		Messages:  convertMessages(summarize),
-		System:    summarySystemPrompt,
-		Model:     c.cfg.SummaryModel,
-		MaxTokens: summaryMaxTokens,
-	}
-	resp, err := c.completeSummary(ctx, req)
-	if err != nil {
-		return compactResult{}, fmt.Errorf("api: compact summary: %w", err)
-	}
-	summary := strings.TrimSpace(resp.Message.Content)
-	if summary == "" {
-		summary = "对话摘要为空"
-	}
-
-	newMsgs := make([]message.Message, 0, len(initial)+1+len(userText)+len(kept))
-	newMsgs = append(newMsgs, message.CloneMessages(initial)...)
-	newMsgs = append(newMsgs, message.Message{
-		Role:    "system",
-		Content: fmt.Sprintf("对话摘要：\n%s", summary),
-	})
-	newMsgs = append(newMsgs, message.CloneMessages(userText)...)
-	newMsgs = append(newMsgs, message.CloneMessages(kept)...)
-	hist.Replace(newMsgs)
-
-	tokensAfter := hist.TokenCount()
-	preservedMsgs := len(initial) + len(userText) + len(kept)
-	return compactResult{
-		summary:       summary,
-		originalMsgs:  len(snapshot),
-		preservedMsgs: preservedMsgs,
-		tokensBefore:  tokensBefore,
-		tokensAfter:   tokensAfter,
-	}, nil
+	return p
 }
 
 func (c *compactor) completeSummary(ctx context.Context, req model.Request) (*model.Response, error) {
+	resp, _, err := c.summarizeWithRetry(ctx, req)
+	return resp, err
+}
+
+// summarizeWithRetry requests a summary, retrying failures with exponential
+// backoff. Retries stop after MaxRetries or once the next wait would push the
+// time spent retrying past RetryBudget.
+func (c *compactor) summarizeWithRetry(ctx context.Context, req model.Request) (*model.Response, summaryRetryStats, error) {
+	var stats summaryRetryStats
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	if c == nil || c.model == nil {
-		return nil, errors.New("api: summary model is nil")
+		return nil, stats, errors.New("api: summary model is nil")
 	}
 	attempts := 1 + c.cfg.MaxRetries
 	if attempts < 1 {
@@ -342,14 +414,21 @@ func (c *compactor) completeSummary(ctx context.Context, req model.Request) (*mo
 	}
 
 	var lastErr error
+	var firstFailure time.Time
 	for attempt := 1; attempt <= attempts; attempt++ {
 		if attempt > 1 {
-			if delay := c.cfg.RetryDelay; delay > 0 {
+			delay := c.retryDelay(attempt - 1)
+			if budget := c.cfg.RetryBudget; budget > 0 && time.Since(firstFailure)+delay > budget {
+				log.Printf("api: compact summary retry budget %s exhausted after %d attempts", budget, stats.attempts)
+				break
+			}
+			if delay > 0 {
 				timer := time.NewTimer(delay)
 				select {
 				case <-ctx.Done():
 					timer.Stop()
-					return nil, ctx.Err()
+					stats.retryTime = time.Since(firstFailure)
+					return nil, stats, ctx.Err()
 				case <-timer.C:
 				}
 			}
@@ -357,6 +436,7 @@ func (c *compactor) completeSummary(ctx context.Context, req model.Request) (*mo
 				req.Model = fallback
 			}
 		}
+		stats.attempts = attempt
 		var resp *model.Response
 		err := c.model.CompleteStream(ctx, req, func(sr model.StreamResult) error {
 			if sr.Final && sr.Response != nil {
@@ -364,16 +444,60 @@ func (c *compactor) completeSummary(ctx context.Context, req model.Request) (*mo
 			}
 			return nil
 		})
+		if !firstFailure.IsZero() {
+			stats.retryTime = time.Since(firstFailure)
+		}
 		if err == nil && resp != nil {
-			return resp, nil
+			return resp, stats, nil
 		}
 		if err == nil && resp == nil {
 			err = errors.New("api: compact summary returned no final response")
 		}
 		lastErr = err
+		if firstFailure.IsZero() {
+			firstFailure = time.Now()
+		}
 		if attempts > 1 {
 			log.Printf("api: compact summary attempt %d/%d failed: %v", attempt, attempts, err)
 		}
 	}
-	return nil, lastErr
+	return nil, stats, lastErr
+}
+
+// retryDelay returns the wait before the given retry (1-based):
+// RetryDelay * RetryBackoff^(retry-1), capped at MaxRetryDelay. With
+// RetryJitter the wait is randomised but never drops below RetryDelay.
+func (c *compactor) retryDelay(retry int) time.Duration {
+	base := c.cfg.RetryDelay
+	if base <= 0 {
+		return 0
+	}
+	delay := float64(base) * math.Pow(c.cfg.RetryBackoff, float64(retry-1))
+	if limit := c.cfg.MaxRetryDelay; limit > 0 && delay > float64(limit) {
+		delay = float64(limit)
+	}
+	if delay > math.MaxInt64 {
+		delay = math.MaxInt64
+	}
+	if !c.cfg.RetryJitter {
+		return time.Duration(delay)
+	}
+	jitter := c.jitter
+	if jitter == nil {
+		jitter = equalJitter
+	}
+	if jittered := jitter(time.Duration(delay)); jittered > base {
+		return jittered
+	}
+	return base
+}
+
+// equalJitter keeps half of the delay fixed and randomises the other half so
+// concurrent sessions do not retry in lockstep.
+func equalJitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + rand.N(d-half)
 }
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"github.com/cexll/agentsdk-go/pkg/message"
+)
+
+// CompactEstimate describes what automatic compaction would do to a history
+// without calling the summary model.
+type CompactEstimate struct {
+	WouldCompact bool
+	TokenLimit   int
+	Ratio        float64 // TokensBefore / TokenLimit
+
+	TokensBefore int
+	// TokensAfter assumes the summary uses its full token allowance, so it
+	// is an upper bound on the post-compaction size.
+	TokensAfter int
+
+	OriginalMessages  int
+	PreservedMessages int
+	// Dropped lists the history indices that would be folded into the summary.
+	Dropped []int
+}
+
+// EstimateCompaction reports whether cfg would compact hist and how much it
+// would remove. cfg.Enabled is ignored so thresholds can be tuned offline
+// before turning compaction on; tokenLimit <= 0 uses the default context
+// window. PreCompact hooks are not consulted.
+func EstimateCompaction(hist *message.History, cfg CompactConfig, tokenLimit int) CompactEstimate {
+	cfg.Enabled = true
+	c := &compactor{cfg: cfg.withDefaults(), limit: tokenLimit}
+	if c.limit <= 0 {
+		c.limit = defaultClaudeContextLimit
+	}
+	return c.estimate(hist)
+}
+
+func (c *compactor) estimate(hist *message.History) CompactEstimate {
+	est := CompactEstimate{TokenLimit: c.limit}
+	if hist == nil {
+		return est
+	}
+	snapshot := hist.All()
+	est.OriginalMessages = len(snapshot)
+	est.PreservedMessages = len(snapshot)
+	est.TokensBefore = hist.TokenCount()
+	est.TokensAfter = est.TokensBefore
+	if c.limit > 0 {
+		est.Ratio = float64(est.TokensBefore) / float64(c.limit)
+	}
+	if !c.shouldCompact(len(snapshot), est.TokensBefore) {
+		return est
+	}
+	plan := c.plan(snapshot)
+	if len(plan.summarize) == 0 {
+		return est
+	}
+
+	var counter message.NaiveCounter
+	after := summaryMaxTokens
+	for _, group := range [][]message.Message{plan.initial, plan.userText, plan.kept} {
+		for _, msg := range group {
+			after += counter.Count(msg)
+		}
+	}
+	est.WouldCompact = true
+	est.TokensAfter = after
+	est.PreservedMessages = len(plan.initial) + len(plan.userText) + len(plan.kept)
+	est.Dropped = plan.dropped
+	return est
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/cexll/agentsdk-go/pkg/message"
+)
+
+const (
+	// extractiveLineRunes bounds each message line in an extractive summary.
+	extractiveLineRunes = 200
+	// extractiveMaxRunes bounds the whole extractive summary, roughly the
+	// summaryMaxTokens allowance a model summary gets.
+	extractiveMaxRunes = 4 * summaryMaxTokens
+)
+
+// extractiveSummary condenses msgs without a model: one line per message
+// with its role, the first line of its text and any tool names it called.
+// When the result would exceed extractiveMaxRunes the oldest lines are
+// dropped first, since later turns matter more to the preserved tail.
+func extractiveSummary(msgs []message.Message) string {
+	lines := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		text := firstLine(msg.Content)
+		if text == "" {
+			for _, block := range msg.ContentBlocks {
+				if text = firstLine(block.Text); text != "" {
+					break
+				}
+			}
+		}
+		var tools []string
+		for _, call := range msg.ToolCalls {
+			if name := strings.TrimSpace(call.Name); name != "" {
+				tools = append(tools, name)
+			}
+		}
+		if text == "" && len(tools) == 0 {
+			continue
+		}
+		line := "- " + strings.TrimSpace(msg.Role) + ": " + truncateRunes(text, extractiveLineRunes)
+		if len(tools) > 0 {
+			line = strings.TrimRight(line, " ") + " [tools: " + strings.Join(tools, ", ") + "]"
+		}
+		lines = append(lines, line)
+	}
+
+	total := 0
+	start := len(lines)
+	for start > 0 {
+		n := len([]rune(lines[start-1])) + 1
+		if total+n > extractiveMaxRunes {
+			break
+		}
+		total += n
+		start--
+	}
+	if start > 0 {
+		lines = append([]string{"- …"}, lines[start:]...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	return s
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "…"
+}
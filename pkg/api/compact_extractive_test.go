@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cexll/agentsdk-go/pkg/message"
+	"github.com/cexll/agentsdk-go/pkg/model"
+)
+
+type countingSummaryModel struct {
+	compactStubModel
+	calls int
+}
+
+func (m *countingSummaryModel) CompleteStream(ctx context.Context, req model.Request, cb model.StreamHandler) error {
+	m.calls++
+	return m.compactStubModel.CompleteStream(ctx, req, cb)
+}
+
+func TestCompactorExtractiveSkipsModel(t *testing.T) {
+	t.Parallel()
+
+	hist := message.NewHistory()
+	hist.Append(message.Message{Role: "user", Content: "Deploy the billing service\nto staging please"})
+	hist.Append(message.Message{Role: "assistant", ToolCalls: []message.ToolCall{{Name: "Bash"}, {Name: "Read"}}})
+	hist.Append(message.Message{Role: "assistant", Content: strings.Repeat("x", 300)})
+	hist.Append(message.Message{Role: "user", Content: "   "})
+	hist.Append(message.Message{Role: "user", Content: "now roll back"})
+
+	mdl := &countingSummaryModel{compactStubModel: compactStubModel{resp: "model summary"}}
+	comp := newCompactor("", CompactConfig{Enabled: true, PreserveCount: 1, Threshold: 0.01, Extractive: true}, mdl, 1, nil)
+	res, ok, err := comp.maybeCompact(context.Background(), hist, "sess", nil)
+	if err != nil || !ok {
+		t.Fatalf("unexpected compact result ok=%v err=%v", ok, err)
+	}
+	if mdl.calls != 0 || res.attempts != 0 {
+		t.Fatalf("extractive mode must not call the model, calls=%d attempts=%d", mdl.calls, res.attempts)
+	}
+	want := "- user: Deploy the billing service\n" +
+		"- assistant: [tools: Bash, Read]\n" +
+		"- assistant: " + strings.Repeat("x", extractiveLineRunes) + "…"
+	if res.summary != want {
+		t.Fatalf("unexpected summary:\n%s", res.summary)
+	}
+
+	msgs := hist.All()
+	if len(msgs) != 2 || msgs[0].Role != "system" || msgs[1].Content != "now roll back" {
+		t.Fatalf("unexpected compacted history %+v", msgs)
+	}
+	if !strings.HasSuffix(msgs[0].Content, want) {
+		t.Fatalf("summary message missing extractive text: %q", msgs[0].Content)
+	}
+
+	// No model at all is fine in extractive mode.
+	offline := newCompactor("", CompactConfig{Enabled: true, PreserveCount: 1, Threshold: 0.01, Extractive: true}, nil, 1, nil)
+	hist.Append(message.Message{Role: "user", Content: "again"})
+	if _, ok, err := offline.maybeCompact(context.Background(), hist, "sess", nil); err != nil || !ok {
+		t.Fatalf("expected offline compaction, ok=%v err=%v", ok, err)
+	}
+}
+
+func TestExtractiveSummaryKeepsNewestWithinBudget(t *testing.T) {
+	t.Parallel()
+
+	msgs := make([]message.Message, 0, 100)
+	for i := 0; i < 100; i++ {
+		msgs = append(msgs, message.Message{Role: "user", Content: strings.Repeat("y", 100)})
+	}
+	msgs[99].Content = "latest"
+	summary := extractiveSummary(msgs)
+	if len([]rune(summary)) > extractiveMaxRunes+len("- …\n") {
+		t.Fatalf("summary exceeds budget: %d runes", len([]rune(summary)))
+	}
+	if !strings.HasPrefix(summary, "- …\n") || !strings.HasSuffix(summary, "- user: latest") {
+		t.Fatalf("expected oldest lines elided, got prefix %q suffix %q", summary[:10], summary[len(summary)-20:])
+	}
+}
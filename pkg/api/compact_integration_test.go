@@ -3,11 +3,15 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	coreevents "github.com/cexll/agentsdk-go/pkg/core/events"
 	"github.com/cexll/agentsdk-go/pkg/message"
 )
 
@@ -87,3 +91,68 @@ func TestRuntimeRun_LongConversationCompactsAndPersists(t *testing.T) {
 		t.Fatalf("expected OriginalMessages>=100 for session %q, got %d", sessionID, maxOriginal)
 	}
 }
+
+func TestRuntimeCompactOnDemand(t *testing.T) {
+	auto := CompactConfig{Enabled: true, Threshold: 0.8, PreserveCount: 1}
+	rt := newTestRuntime(t, staticModel{content: "SUM"}, auto)
+
+	below := rt.histories.Get("sess-small")
+	below.Append(msgWithTokens("user", 5))
+	below.Append(msgWithTokens("assistant", 5))
+	evt, err := rt.Compact(context.Background(), "sess-small")
+	if !errors.Is(err, ErrNothingToCompact) || evt != nil {
+		t.Fatalf("expected ErrNothingToCompact below threshold, got %+v, %v", evt, err)
+	}
+	if below.Len() != 2 {
+		t.Fatalf("history should be untouched, got %d messages", below.Len())
+	}
+
+	above := rt.histories.Get("sess-big")
+	for i := 0; i < 6; i++ {
+		role := "assistant"
+		if i%2 == 0 {
+			role = "user"
+		}
+		above.Append(msgWithTokens(role, 10))
+	}
+	evt, err = rt.Compact(context.Background(), "sess-big")
+	if err != nil {
+		t.Fatalf("Compact above threshold: %v", err)
+	}
+	if evt == nil {
+		t.Fatalf("expected compaction event")
+	}
+	if evt.SessionID != "sess-big" || evt.OriginalMessages != 6 || evt.Summary != "SUM" {
+		t.Fatalf("unexpected event %+v", evt)
+	}
+	if above.Len() != evt.PreservedMessages+1 {
+		t.Fatalf("expected summary plus %d preserved messages, got %d", evt.PreservedMessages, above.Len())
+	}
+	var hookTypes []coreevents.EventType
+	for _, he := range evt.HookEvents {
+		hookTypes = append(hookTypes, he.Type)
+	}
+	if !slices.Contains(hookTypes, coreevents.PreCompact) || !slices.Contains(hookTypes, coreevents.ContextCompacted) {
+		t.Fatalf("expected PreCompact and ContextCompacted hook events, got %v", hookTypes)
+	}
+
+	// A busy session makes Compact wait; cancelling returns the ctx error.
+	if err := rt.sessionGate.Acquire(context.Background(), "sess-big"); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := rt.Compact(ctx, "sess-big"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error while the session is busy, got %v", err)
+	}
+	rt.sessionGate.Release("sess-big")
+
+	disabled := newTestRuntime(t, staticModel{content: "SUM"}, CompactConfig{})
+	if _, err := disabled.Compact(context.Background(), "sess"); !errors.Is(err, ErrCompactionDisabled) {
+		t.Fatalf("expected ErrCompactionDisabled, got %v", err)
+	}
+
+	if _, err := rt.Compact(context.Background(), " "); err == nil {
+		t.Fatalf("expected error for empty session id")
+	}
+}
@@ -287,3 +287,161 @@ func TestCompactor_CompleteSummaryContextCanceled(t *testing.T) {
 		t.Fatalf("expected context canceled error, got %v", err)
 	}
 }
+
+type flakyModel struct {
+	failures int
+	calls    []time.Time
+}
+
+func (m *flakyModel) Complete(context.Context, model.Request) (*model.Response, error) {
+	m.calls = append(m.calls, time.Now())
+	if len(m.calls) <= m.failures {
+		return nil, errors.New("overloaded")
+	}
+	return &model.Response{Message: model.Message{Content: "sum"}}, nil
+}
+
+func (m *flakyModel) CompleteStream(ctx context.Context, req model.Request, cb model.StreamHandler) error {
+	resp, err := m.Complete(ctx, req)
+	if err != nil {
+		return err
+	}
+	return cb(model.StreamResult{Final: true, Response: resp})
+}
+
+func TestCompactor_RetryBackoffGrows(t *testing.T) {
+	mdl := &flakyModel{failures: 3}
+	cfg := CompactConfig{Enabled: true, MaxRetries: 3, RetryDelay: 5 * time.Millisecond, RetryBackoff: 3}
+	comp := &compactor{cfg: cfg.withDefaults(), model: mdl, limit: 100, jitter: func(d time.Duration) time.Duration { return d }}
+
+	if got := comp.retryDelay(1); got != 5*time.Millisecond {
+		t.Fatalf("unexpected first delay %s", got)
+	}
+	if got := comp.retryDelay(3); got != 45*time.Millisecond {
+		t.Fatalf("unexpected third delay %s", got)
+	}
+
+	resp, stats, err := comp.summarizeWithRetry(context.Background(), model.Request{})
+	if err != nil || resp == nil {
+		t.Fatalf("expected eventual success, got %v", err)
+	}
+	if stats.attempts != 4 {
+		t.Fatalf("expected 4 attempts, got %d", stats.attempts)
+	}
+	if stats.retryTime < 65*time.Millisecond {
+		t.Fatalf("expected retry time to include backoff waits, got %s", stats.retryTime)
+	}
+	first := mdl.calls[1].Sub(mdl.calls[0])
+	last := mdl.calls[3].Sub(mdl.calls[2])
+	if last <= first {
+		t.Fatalf("expected backoff to grow, first=%s last=%s", first, last)
+	}
+}
+
+func TestCompactor_RetryBudgetCapsAttempts(t *testing.T) {
+	mdl := &flakyModel{failures: 100}
+	cfg := CompactConfig{Enabled: true, MaxRetries: 50, RetryDelay: 10 * time.Millisecond, RetryBackoff: 2, RetryBudget: 50 * time.Millisecond}
+	comp := &compactor{cfg: cfg.withDefaults(), model: mdl, limit: 100, jitter: func(d time.Duration) time.Duration { return d }}
+
+	_, stats, err := comp.summarizeWithRetry(context.Background(), model.Request{})
+	if err == nil {
+		t.Fatal("expected failure once budget exhausted")
+	}
+	// Waits of 10ms and 20ms fit in 50ms; the 40ms third wait does not.
+	if stats.attempts != 3 || len(mdl.calls) != 3 {
+		t.Fatalf("expected budget to cap attempts at 3, got %d (calls=%d)", stats.attempts, len(mdl.calls))
+	}
+	if stats.retryTime > cfg.RetryBudget {
+		t.Fatalf("retry time %s exceeded budget", stats.retryTime)
+	}
+}
+
+func TestCompactor_RetryDelayDefaultsToFixed(t *testing.T) {
+	cfg := CompactConfig{Enabled: true, MaxRetries: 3, RetryDelay: 20 * time.Millisecond}
+	comp := &compactor{cfg: cfg.withDefaults()}
+	for retry := 1; retry <= 3; retry++ {
+		if got := comp.retryDelay(retry); got != 20*time.Millisecond {
+			t.Fatalf("retry %d: expected fixed 20ms delay, got %s", retry, got)
+		}
+	}
+}
+
+func TestCompactor_RetryJitterNeverBelowRetryDelay(t *testing.T) {
+	cfg := CompactConfig{Enabled: true, MaxRetries: 3, RetryDelay: 20 * time.Millisecond, RetryBackoff: 2, RetryJitter: true}
+	comp := &compactor{cfg: cfg.withDefaults(), jitter: func(time.Duration) time.Duration { return time.Millisecond }}
+	if got := comp.retryDelay(1); got != 20*time.Millisecond {
+		t.Fatalf("expected jitter clamped to RetryDelay, got %s", got)
+	}
+	comp.jitter = nil
+	for i := 0; i < 100; i++ {
+		if got := comp.retryDelay(1); got < 20*time.Millisecond {
+			t.Fatalf("jittered delay %s below RetryDelay", got)
+		}
+	}
+}
+
+func TestCompactor_EqualJitterBounds(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		got := equalJitter(100 * time.Millisecond)
+		if got < 50*time.Millisecond || got >= 100*time.Millisecond {
+			t.Fatalf("jitter out of range: %s", got)
+		}
+	}
+}
+
+func TestEstimateCompactionMatchesCompact(t *testing.T) {
+	build := func() *message.History {
+		hist := message.NewHistory()
+		hist.Append(message.Message{Role: "system", Content: "init"})
+		hist.Append(msgWithTokens("user", 30))
+		hist.Append(msgWithTokens("assistant", 40))
+		hist.Append(msgWithTokens("user", 8))
+		hist.Append(msgWithTokens("assistant", 40))
+		hist.Append(msgWithTokens("user", 10))
+		hist.Append(msgWithTokens("assistant", 10))
+		return hist
+	}
+	cfg := CompactConfig{
+		Threshold:        0.5,
+		PreserveCount:    2,
+		PreserveInitial:  true,
+		PreserveUserText: true,
+		UserTextTokens:   6,
+	}
+
+	hist := build()
+	before := hist.TokenCount()
+	est := EstimateCompaction(hist, cfg, 200)
+	if !est.WouldCompact {
+		t.Fatalf("expected estimate to compact: %+v", est)
+	}
+	if hist.Len() != 7 || hist.TokenCount() != before {
+		t.Fatalf("estimate must not mutate history")
+	}
+	if want := []int{1, 2, 4}; len(est.Dropped) != len(want) || est.Dropped[0] != 1 || est.Dropped[1] != 2 || est.Dropped[2] != 4 {
+		t.Fatalf("unexpected dropped indices %v", est.Dropped)
+	}
+
+	enabled := cfg
+	enabled.Enabled = true
+	comp := newCompactor("", enabled, &summaryModel{content: "sum"}, 200, nil)
+	actual := build()
+	res, ok, err := comp.maybeCompact(context.Background(), actual, "sess", nil)
+	if err != nil || !ok {
+		t.Fatalf("expected compaction, ok=%v err=%v", ok, err)
+	}
+	if est.OriginalMessages != res.originalMsgs || est.PreservedMessages != res.preservedMsgs {
+		t.Fatalf("estimate %d/%d, actual %d/%d", est.OriginalMessages, est.PreservedMessages, res.originalMsgs, res.preservedMsgs)
+	}
+	if est.OriginalMessages-est.PreservedMessages != len(est.Dropped) {
+		t.Fatalf("dropped count mismatch: %+v", est)
+	}
+	if est.TokensBefore != res.tokensBefore || est.TokensAfter < res.tokensAfter {
+		t.Fatalf("estimate tokens %d->%d, actual %d->%d", est.TokensBefore, est.TokensAfter, res.tokensBefore, res.tokensAfter)
+	}
+
+	below := EstimateCompaction(build(), CompactConfig{Threshold: 0.99}, 1000)
+	if below.WouldCompact || len(below.Dropped) != 0 || below.PreservedMessages != 7 {
+		t.Fatalf("expected no compaction below threshold: %+v", below)
+	}
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/cexll/agentsdk-go/pkg/middleware"
+	"github.com/cexll/agentsdk-go/pkg/model"
+)
+
+// RuntimeInfo is a read-only snapshot of a runtime's effective configuration,
+// intended for debug endpoints. Secrets are masked with the same filter the
+// HTTP trace middleware applies to telemetry.
+type RuntimeInfo struct {
+	EntryPoint  EntryPoint    `json:"entrypoint"`
+	ProjectRoot string        `json:"project_root,omitempty"`
+	Model       ModelInfo     `json:"model"`
+	Fallbacks   []ModelInfo   `json:"fallbacks,omitempty"`
+	Sandbox     SandboxReport `json:"sandbox"`
+
+	Tools      []string `json:"tools,omitempty"`
+	Skills     []string `json:"skills,omitempty"`
+	Commands   []string `json:"commands,omitempty"`
+	Subagents  []string `json:"subagents,omitempty"`
+	Middleware []string `json:"middleware,omitempty"`
+
+	ActiveSessions int `json:"active_sessions"`
+	MaxSessions    int `json:"max_sessions,omitempty"`
+
+	MaxIterations int        `json:"max_iterations,omitempty"`
+	TokenLimit    int        `json:"token_limit,omitempty"`
+	OTEL          OTELConfig `json:"otel"`
+}
+
+// ModelInfo describes one configured model backend. Model, BaseURL and
+// APIKey are only known for the built-in providers; APIKey is masked.
+type ModelInfo struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Model   string `json:"model,omitempty"`
+	BaseURL string `json:"base_url,omitempty"`
+	APIKey  string `json:"api_key,omitempty"`
+}
+
+// Describe returns a snapshot of the runtime configuration. It only reads
+// in-memory state and never contacts the model.
+func (rt *Runtime) Describe() RuntimeInfo {
+	if rt == nil {
+		return RuntimeInfo{}
+	}
+	info := RuntimeInfo{
+		EntryPoint:     rt.mode.EntryPoint,
+		ProjectRoot:    rt.opts.ProjectRoot,
+		Sandbox:        rt.sandboxReport(),
+		ActiveSessions: rt.ActiveSessions(),
+		MaxSessions:    rt.opts.MaxSessions,
+		MaxIterations:  rt.opts.MaxIterations,
+		TokenLimit:     rt.opts.TokenLimit,
+		OTEL:           maskOTELConfig(rt.opts.OTEL),
+	}
+
+	// New stores the resolved model in opts.Model, so the factory (when
+	// given) is the better description of what was configured.
+	if rt.opts.ModelFactory != nil {
+		info.Model = describeModel("primary", rt.opts.ModelFactory)
+	} else {
+		info.Model = describeModel("primary", rt.opts.Model)
+	}
+	for i, fb := range rt.opts.ModelFallbacks {
+		name := strings.TrimSpace(fb.Name)
+		if name == "" {
+			name = fmt.Sprintf("fallback-%d", i+1)
+		}
+		info.Fallbacks = append(info.Fallbacks, describeModel(name, fb.Factory))
+	}
+
+	if rt.registry != nil {
+		for _, t := range rt.registry.List() {
+			info.Tools = append(info.Tools, t.Name())
+		}
+	}
+	if rt.skReg != nil {
+		for _, def := range rt.skReg.List() {
+			info.Skills = append(info.Skills, def.Name)
+		}
+	}
+	if rt.cmdExec != nil {
+		for _, def := range rt.cmdExec.List() {
+			info.Commands = append(info.Commands, def.Name)
+		}
+	}
+	if rt.subMgr != nil {
+		for _, def := range rt.subMgr.List() {
+			info.Subagents = append(info.Subagents, def.Name)
+		}
+	}
+	sort.Strings(info.Tools)
+	sort.Strings(info.Skills)
+	sort.Strings(info.Commands)
+	sort.Strings(info.Subagents)
+
+	// Middleware keeps chain order.
+	for _, mw := range rt.opts.Middleware {
+		if mw != nil {
+			info.Middleware = append(info.Middleware, mw.Name())
+		}
+	}
+	return info
+}
+
+func describeModel(name string, src any) ModelInfo {
+	info := ModelInfo{Name: name}
+	if src == nil {
+		return info
+	}
+	info.Type = fmt.Sprintf("%T", src)
+	switch p := src.(type) {
+	case *model.AnthropicProvider:
+		info.Model = p.ModelName
+		info.BaseURL = p.BaseURL
+		info.APIKey = middleware.MaskSecret(p.APIKey)
+	case *model.OpenAIProvider:
+		info.Model = p.ModelName
+		info.BaseURL = p.BaseURL
+		info.APIKey = middleware.MaskSecret(p.APIKey)
+	}
+	return info
+}
+
+func maskOTELConfig(cfg OTELConfig) OTELConfig {
+	if len(cfg.Headers) == 0 {
+		return cfg
+	}
+	cfg.Headers = maps.Clone(cfg.Headers)
+	for k, v := range cfg.Headers {
+		cfg.Headers[k] = middleware.MaskSensitiveHeader(k, v)
+	}
+	return cfg
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cexll/agentsdk-go/pkg/middleware"
+	"github.com/cexll/agentsdk-go/pkg/model"
+	"github.com/cexll/agentsdk-go/pkg/runtime/commands"
+	"github.com/cexll/agentsdk-go/pkg/runtime/skills"
+	"github.com/cexll/agentsdk-go/pkg/runtime/subagents"
+)
+
+func TestRuntimeDescribe(t *testing.T) {
+	root := newClaudeProject(t)
+	const apiKey = "sk-ant-0123456789abcdef"
+	opts := Options{
+		ProjectRoot:         root,
+		ModelFactory:        &model.AnthropicProvider{APIKey: apiKey, ModelName: "claude-describe"},
+		ModelFallbacks:      []FallbackModel{{Factory: &model.OpenAIProvider{APIKey: apiKey, ModelName: "gpt-describe"}}},
+		EnabledBuiltinTools: []string{"bash"},
+		Middleware:          []middleware.Middleware{middleware.Funcs{Identifier: "audit"}, middleware.Funcs{Identifier: "metrics"}},
+		Skills: []SkillRegistration{{
+			Definition: skills.Definition{Name: "tagger"},
+			Handler: skills.HandlerFunc(func(context.Context, skills.ActivationContext) (skills.Result, error) {
+				return skills.Result{}, nil
+			}),
+		}},
+		Commands: []CommandRegistration{{
+			Definition: commands.Definition{Name: "deploy"},
+			Handler: commands.HandlerFunc(func(context.Context, commands.Invocation) (commands.Result, error) {
+				return commands.Result{}, nil
+			}),
+		}},
+		Subagents: []SubagentRegistration{{
+			Definition: subagents.Definition{Name: "reviewer"},
+			Handler: subagents.HandlerFunc(func(context.Context, subagents.Context, subagents.Request) (subagents.Result, error) {
+				return subagents.Result{}, nil
+			}),
+		}},
+		OTEL: OTELConfig{Headers: map[string]string{"x-api-key": apiKey, "x-tenant": "acme"}},
+	}
+	rt, err := New(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+
+	info := rt.Describe()
+	if info.Model.Name != "primary" || info.Model.Model != "claude-describe" {
+		t.Fatalf("unexpected model info %+v", info.Model)
+	}
+	if len(info.Fallbacks) != 1 || info.Fallbacks[0].Name != "fallback-1" || info.Fallbacks[0].Model != "gpt-describe" {
+		t.Fatalf("unexpected fallbacks %+v", info.Fallbacks)
+	}
+	if len(info.Sandbox.Roots) != 1 || info.Sandbox.Roots[0] != rt.sbRoot {
+		t.Fatalf("unexpected sandbox roots %+v", info.Sandbox.Roots)
+	}
+	if !reflect.DeepEqual(info.Tools, []string{"Bash"}) {
+		t.Fatalf("unexpected tools %+v", info.Tools)
+	}
+	if !reflect.DeepEqual(info.Skills, []string{"tagger"}) || !reflect.DeepEqual(info.Commands, []string{"deploy"}) {
+		t.Fatalf("unexpected skills/commands %+v %+v", info.Skills, info.Commands)
+	}
+	if !reflect.DeepEqual(info.Subagents, []string{"reviewer"}) {
+		t.Fatalf("unexpected subagents %+v", info.Subagents)
+	}
+	if !reflect.DeepEqual(info.Middleware, []string{"audit", "metrics"}) {
+		t.Fatalf("unexpected middleware %+v", info.Middleware)
+	}
+	if info.OTEL.Headers["x-tenant"] != "acme" {
+		t.Fatalf("non-secret header altered: %+v", info.OTEL.Headers)
+	}
+	if opts.OTEL.Headers["x-api-key"] != apiKey {
+		t.Fatalf("describe mutated caller options")
+	}
+
+	raw, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if strings.Contains(string(raw), apiKey) {
+		t.Fatalf("secret leaked into snapshot: %s", raw)
+	}
+	if info.Model.APIKey != middleware.MaskSecret(apiKey) {
+		t.Fatalf("api key not masked: %q", info.Model.APIKey)
+	}
+}
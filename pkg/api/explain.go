@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/cexll/agentsdk-go/pkg/runtime/subagents"
+	"github.com/cexll/agentsdk-go/pkg/security"
+	"github.com/cexll/agentsdk-go/pkg/tool"
+)
+
+// Explanation is a dry-run report of how the runtime would handle a request:
+// which skills match, which subagent it would dispatch to and which tools the
+// sandbox would let the model call.
+type Explanation struct {
+	Skills   []SkillVerdict   `json:"skills,omitempty"`
+	Subagent *SubagentVerdict `json:"subagent,omitempty"`
+	Tools    []ToolVerdict    `json:"tools,omitempty"`
+}
+
+// SkillVerdict is one skill's entry in the match trace. Forced skills are
+// listed after the auto-activating ones.
+type SkillVerdict struct {
+	Name       string  `json:"name"`
+	Matched    bool    `json:"matched"`
+	Activated  bool    `json:"activated"`
+	Forced     bool    `json:"forced,omitempty"`
+	Score      float64 `json:"score,omitempty"`
+	Reason     string  `json:"reason,omitempty"`
+	Suppressed string  `json:"suppressed,omitempty"`
+}
+
+// SubagentVerdict reports which subagent a dispatch for the request would
+// resolve to. Name is empty and Error set when resolution fails.
+type SubagentVerdict struct {
+	Target string `json:"target,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ToolVerdict is the sandbox decision for calling a tool with empty
+// parameters. Permitted is false for deny and for ask, which would need a
+// PermissionResolver to approve it.
+type ToolVerdict struct {
+	Name      string                    `json:"name"`
+	Action    security.PermissionAction `json:"action"`
+	Rule      string                    `json:"rule,omitempty"`
+	Target    string                    `json:"target,omitempty"`
+	Permitted bool                      `json:"permitted"`
+	Error     string                    `json:"error,omitempty"`
+}
+
+// Explain reports how Run would treat req without executing anything:
+// skills are matched but not run, the subagent is resolved but not
+// dispatched, and tools are checked against the sandbox but not called.
+// Commands are not evaluated because parsing them is not separable from
+// running them. Explain records no history and leaves skill dedup and
+// cooldown state untouched.
+func (rt *Runtime) Explain(ctx context.Context, req Request) (*Explanation, error) {
+	if rt == nil {
+		return nil, errors.New("api: runtime is nil")
+	}
+	if ctx != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	normalized := req.normalized(rt.mode, defaultSessionID(rt.mode.EntryPoint))
+	prompt := strings.TrimSpace(normalized.Prompt)
+	if prompt == "" && len(normalized.ContentBlocks) == 0 {
+		return nil, errors.New("api: prompt is empty")
+	}
+	activation := normalized.activationContext(prompt)
+
+	exp := &Explanation{}
+	if rt.skReg != nil {
+		for _, m := range rt.skReg.Explain(activation) {
+			exp.Skills = append(exp.Skills, SkillVerdict{
+				Name:       m.Name,
+				Matched:    m.Matched,
+				Activated:  m.Activated,
+				Score:      m.Score,
+				Reason:     m.Reason,
+				Suppressed: m.Suppressed,
+			})
+		}
+		for _, forced := range orderedForcedSkills(rt.skReg, normalized.ForceSkills) {
+			exp.Skills = append(exp.Skills, SkillVerdict{
+				Name:      forced.Skill.Definition().Name,
+				Matched:   true,
+				Activated: true,
+				Forced:    true,
+			})
+		}
+	}
+
+	applySubagentTarget(&normalized)
+	if rt.subMgr != nil {
+		verdict := &SubagentVerdict{Target: normalized.TargetSubagent}
+		def, err := rt.subMgr.Resolve(subagents.Request{
+			Target:        normalized.TargetSubagent,
+			Instruction:   prompt,
+			Activation:    activation,
+			ToolWhitelist: cloneStrings(normalized.ToolWhitelist),
+		})
+		if err != nil {
+			verdict.Error = err.Error()
+		} else {
+			verdict.Name = def.Name
+		}
+		exp.Subagent = verdict
+	}
+
+	if rt.executor != nil && rt.registry != nil {
+		whitelist := combineToolWhitelists(normalized.ToolWhitelist, nil)
+		for _, impl := range rt.registry.List() {
+			name := impl.Name()
+			if whitelist != nil {
+				if _, ok := whitelist[strings.ToLower(name)]; !ok {
+					continue
+				}
+			}
+			exp.Tools = append(exp.Tools, explainTool(rt.executor, name))
+		}
+		sort.Slice(exp.Tools, func(i, j int) bool { return exp.Tools[i].Name < exp.Tools[j].Name })
+	}
+	return exp, nil
+}
+
+func explainTool(exec *tool.Executor, name string) ToolVerdict {
+	verdict := ToolVerdict{Name: name}
+	decision, err := exec.Decide(tool.Call{Name: name})
+	if err != nil {
+		verdict.Action = security.PermissionUnknown
+		verdict.Error = err.Error()
+		return verdict
+	}
+	verdict.Action = decision.Action
+	verdict.Rule = decision.Rule
+	verdict.Target = decision.Target
+	verdict.Permitted = decision.Action != security.PermissionDeny && decision.Action != security.PermissionAsk
+	return verdict
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cexll/agentsdk-go/pkg/runtime/skills"
+	"github.com/cexll/agentsdk-go/pkg/runtime/subagents"
+	"github.com/cexll/agentsdk-go/pkg/security"
+	"github.com/cexll/agentsdk-go/pkg/tool"
+)
+
+func TestRuntimeExplain(t *testing.T) {
+	root := newClaudeProjectWithSettings(t, `{"permissions":{"deny":["echo"]},"sandbox":{"enabled":true}}`)
+	var skillRuns, subRuns int
+	rt, err := New(context.Background(), Options{
+		ProjectRoot: root,
+		Model:       &stubModel{},
+		Tools:       []tool.Tool{&echoTool{}, &sleepyTool{}},
+		Skills: []SkillRegistration{
+			{
+				Definition: skills.Definition{Name: "reviewer", Matchers: []skills.Matcher{skills.KeywordMatcher{Any: []string{"review"}}}},
+				Handler: skills.HandlerFunc(func(context.Context, skills.ActivationContext) (skills.Result, error) {
+					skillRuns++
+					return skills.Result{Output: "ran"}, nil
+				}),
+			},
+			{
+				Definition: skills.Definition{Name: "deployer", Matchers: []skills.Matcher{skills.KeywordMatcher{Any: []string{"deploy"}}}},
+				Handler: skills.HandlerFunc(func(context.Context, skills.ActivationContext) (skills.Result, error) {
+					skillRuns++
+					return skills.Result{}, nil
+				}),
+			},
+		},
+		Subagents: []SubagentRegistration{{
+			Definition: subagents.Definition{Name: "code-review", Matchers: []skills.Matcher{skills.KeywordMatcher{Any: []string{"review"}}}},
+			Handler: subagents.HandlerFunc(func(context.Context, subagents.Context, subagents.Request) (subagents.Result, error) {
+				subRuns++
+				return subagents.Result{Output: "reviewed"}, nil
+			}),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+
+	exp, err := rt.Explain(context.Background(), Request{Prompt: "please review this diff", SessionID: "explain"})
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	if skillRuns != 0 || subRuns != 0 {
+		t.Fatalf("explain must not execute handlers, skills=%d subagents=%d", skillRuns, subRuns)
+	}
+
+	if len(exp.Skills) != 2 {
+		t.Fatalf("expected both skills in trace, got %+v", exp.Skills)
+	}
+	if got := exp.Skills[0]; got.Name != "reviewer" || !got.Matched || !got.Activated || got.Score <= 0 {
+		t.Fatalf("unexpected reviewer verdict %+v", got)
+	}
+	if got := exp.Skills[1]; got.Name != "deployer" || got.Matched || got.Score != 0 {
+		t.Fatalf("unexpected deployer verdict %+v", got)
+	}
+
+	if exp.Subagent == nil || exp.Subagent.Name != "code-review" || exp.Subagent.Error != "" {
+		t.Fatalf("unexpected subagent verdict %+v", exp.Subagent)
+	}
+
+	verdicts := map[string]ToolVerdict{}
+	for _, v := range exp.Tools {
+		verdicts[v.Name] = v
+	}
+	if echo := verdicts["echo"]; echo.Action != security.PermissionDeny || echo.Permitted {
+		t.Fatalf("expected echo denied, got %+v", echo)
+	}
+	if sleepy := verdicts["sleepy"]; !sleepy.Permitted {
+		t.Fatalf("expected sleepy permitted, got %+v", sleepy)
+	}
+
+	// A dry run leaves activation state alone, so Run still sees the skill.
+	if _, err := rt.Run(context.Background(), Request{Prompt: "please review this diff", SessionID: "explain"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if skillRuns != 1 {
+		t.Fatalf("expected run to execute skill once, got %d", skillRuns)
+	}
+}
+
+func TestRuntimeExplainUnknownTargetAndWhitelist(t *testing.T) {
+	root := newClaudeProject(t)
+	rt, err := New(context.Background(), Options{
+		ProjectRoot: root,
+		Model:       &stubModel{},
+		Tools:       []tool.Tool{&echoTool{}, &sleepyTool{}},
+		Subagents: []SubagentRegistration{{
+			Definition: subagents.Definition{Name: "code-review"},
+			Handler: subagents.HandlerFunc(func(context.Context, subagents.Context, subagents.Request) (subagents.Result, error) {
+				return subagents.Result{}, nil
+			}),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+
+	exp, err := rt.Explain(context.Background(), Request{Prompt: "hi", TargetSubagent: "missing", ToolWhitelist: []string{"sleepy"}})
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	if exp.Subagent == nil || exp.Subagent.Name != "" || exp.Subagent.Error != subagents.ErrUnknownSubagent.Error() {
+		t.Fatalf("expected unknown subagent error, got %+v", exp.Subagent)
+	}
+	if len(exp.Tools) != 1 || exp.Tools[0].Name != "sleepy" || !exp.Tools[0].Permitted {
+		t.Fatalf("expected only whitelisted tool, got %+v", exp.Tools)
+	}
+
+	if _, err := rt.Explain(context.Background(), Request{}); err == nil {
+		t.Fatal("expected empty prompt error")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := rt.Explain(ctx, Request{Prompt: "hi"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+}
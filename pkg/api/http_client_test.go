@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cexll/agentsdk-go/pkg/config"
+	"github.com/cexll/agentsdk-go/pkg/model"
+	"github.com/cexll/agentsdk-go/pkg/ratelimit"
+)
+
+type recordingTransport struct {
+	mu    sync.Mutex
+	hosts []string
+}
+
+func (r *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	r.hosts = append(r.hosts, req.URL.Host)
+	r.mu.Unlock()
+	body, contentType := "<html><body><p>docs</p></body></html>", "text/html"
+	if strings.HasSuffix(req.URL.Path, "/messages") {
+		contentType = "application/json"
+		body = `{"id":"msg_1","type":"message","role":"assistant","model":"claude-test",` +
+			`"content":[{"type":"text","text":"hi"}],"stop_reason":"end_turn",` +
+			`"usage":{"input_tokens":1,"output_tokens":1}}`
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{contentType}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestSharedHTTPClientThrottlesModelAndWebFetch(t *testing.T) {
+	upstream := &recordingTransport{}
+	client := &http.Client{Transport: ratelimit.NewTransport(upstream, 10, 1)}
+
+	provider := &model.AnthropicProvider{APIKey: "test-key", BaseURL: "https://api.example.test", MaxRetries: 1}
+	mdl, err := resolveModel(context.Background(), Options{ModelFactory: provider, HTTPClient: client})
+	if err != nil {
+		t.Fatalf("resolve model: %v", err)
+	}
+	if provider.HTTPClient != nil {
+		t.Fatalf("expected caller-owned provider to be left unmodified")
+	}
+
+	factories := builtinToolFactories(t.TempDir(), false, EntryPointCLI, &config.Settings{}, nil, nil, nil, client)
+	fetch := factories["web_fetch"]()
+
+	start := time.Now()
+	if _, err := mdl.Complete(context.Background(), model.Request{Messages: []model.Message{{Role: "user", Content: "hi"}}}); err != nil {
+		t.Fatalf("model complete: %v", err)
+	}
+	if _, err := fetch.Execute(context.Background(), map[string]any{"url": "https://docs.example.test/page", "prompt": "read"}); err != nil {
+		t.Fatalf("web fetch: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("expected the second call to wait on the shared limiter, took %s", elapsed)
+	}
+
+	upstream.mu.Lock()
+	defer upstream.mu.Unlock()
+	if len(upstream.hosts) != 2 || upstream.hosts[0] != "api.example.test" || upstream.hosts[1] != "docs.example.test" {
+		t.Fatalf("expected provider then fetch through the shared transport, got %v", upstream.hosts)
+	}
+}
+
+func TestResolveModelKeepsProviderHTTPClient(t *testing.T) {
+	own := &http.Client{}
+	provider := &model.AnthropicProvider{APIKey: "test-key", HTTPClient: own}
+	if _, err := resolveModel(context.Background(), Options{ModelFactory: provider, HTTPClient: &http.Client{}}); err != nil {
+		t.Fatalf("resolve model: %v", err)
+	}
+	if provider.HTTPClient != own {
+		t.Fatalf("expected explicitly configured client to be kept")
+	}
+}
+
+func TestResolveFailoverModelDoesNotMutateProviders(t *testing.T) {
+	client := &http.Client{}
+	primary := &model.AnthropicProvider{APIKey: "test-key"}
+	fallback := &model.OpenAIProvider{APIKey: "test-key"}
+	opts := Options{ModelFactory: primary, ModelFallbacks: []FallbackModel{{Name: "openai", Factory: fallback}}, HTTPClient: client}
+	for i := 0; i < 2; i++ {
+		if _, err := resolveModel(context.Background(), opts); err != nil {
+			t.Fatalf("resolve model: %v", err)
+		}
+	}
+	if primary.HTTPClient != nil || fallback.HTTPClient != nil {
+		t.Fatalf("expected providers to be left unmodified")
+	}
+	bound, ok := primary.WithHTTPClient(client).(*model.AnthropicProvider)
+	if !ok || bound == primary || bound.HTTPClient != client || bound.APIKey != "test-key" {
+		t.Fatalf("expected a bound copy, got %+v", bound)
+	}
+}
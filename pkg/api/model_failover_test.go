@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cexll/agentsdk-go/pkg/model"
+)
+
+type unavailableModel struct{ calls int }
+
+func (m *unavailableModel) Complete(context.Context, model.Request) (*model.Response, error) {
+	m.calls++
+	return nil, fmt.Errorf("503 from upstream: %w", model.ErrUnavailable)
+}
+
+func (m *unavailableModel) CompleteStream(ctx context.Context, req model.Request, _ model.StreamHandler) error {
+	_, err := m.Complete(ctx, req)
+	return err
+}
+
+func TestRuntimeModelFallbacksFailOver(t *testing.T) {
+	primary := &unavailableModel{}
+	rt, err := New(context.Background(), Options{
+		ProjectRoot:         t.TempDir(),
+		Model:               primary,
+		EnabledBuiltinTools: []string{},
+		ModelFallbacks: []FallbackModel{{
+			Name: "secondary",
+			Factory: ModelFactoryFunc(func(context.Context) (model.Model, error) {
+				return staticModel{content: "served by secondary"}, nil
+			}),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+
+	resp, err := rt.Run(context.Background(), Request{Prompt: "hi", SessionID: "failover"})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if primary.calls == 0 {
+		t.Fatalf("expected primary to be tried first")
+	}
+	if resp.Result == nil || resp.Result.Output != "served by secondary" {
+		t.Fatalf("unexpected result %+v", resp.Result)
+	}
+	if resp.Result.Provider != "secondary" {
+		t.Fatalf("expected serving provider to be reported, got %q", resp.Result.Provider)
+	}
+}
+
+func TestRuntimeSingleModelLeavesProviderEmpty(t *testing.T) {
+	rt := newTestRuntime(t, staticModel{content: "ok"}, CompactConfig{})
+	resp, err := rt.Run(context.Background(), Request{Prompt: "hi", SessionID: "single"})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if resp.Result == nil || resp.Result.Output != "ok" || resp.Result.Provider != "" {
+		t.Fatalf("unexpected result %+v", resp.Result)
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"io/fs"
 	"maps"
+	"net/http"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cexll/agentsdk-go/pkg/config"
@@ -33,8 +35,28 @@ var (
 	ErrRuntimeClosed           = errors.New("api: runtime is closed")
 	ErrToolUseDenied           = errors.New("api: tool use denied by hook")
 	ErrToolUseRequiresApproval = errors.New("api: tool use requires approval")
+	ErrPromptTooLarge          = errors.New("api: prompt too large")
+	ErrSubagentFailed          = errors.New("api: subagent failed")
+	ErrCompactionDisabled      = errors.New("api: auto compaction is disabled")
+	ErrNothingToCompact        = errors.New("api: nothing to compact")
 )
 
+// PromptTooLargeError reports a prompt rejected by MaxPromptBytes. It matches
+// ErrPromptTooLarge via errors.Is.
+type PromptTooLargeError struct {
+	Limit int
+	Size  int
+}
+
+func (e *PromptTooLargeError) Error() string {
+	return fmt.Sprintf("%v: %d bytes exceeds limit of %d", ErrPromptTooLarge, e.Size, e.Limit)
+}
+
+// Is reports whether target is ErrPromptTooLarge.
+func (e *PromptTooLargeError) Is(target error) bool {
+	return target == ErrPromptTooLarge
+}
+
 type EntryPoint string
 
 const (
@@ -97,6 +119,14 @@ type SandboxOptions struct {
 	AllowedPaths  []string
 	NetworkAllow  []string
 	ResourceLimit sandbox.ResourceLimits
+	// RestrictWebFetch makes the web_fetch tool refuse hosts (and redirect
+	// targets) outside NetworkAllow. It is off by default because the default
+	// NetworkAllow only covers local networks, which web_fetch blocks anyway.
+	RestrictWebFetch bool
+	// EnforceResourceLimits samples foreground bash commands while they run
+	// and kills them once they exceed ResourceLimit, instead of only reporting
+	// the limits.
+	EnforceResourceLimits bool
 }
 
 // PermissionRequest captures a permission prompt for sandbox "ask" matches.
@@ -131,11 +161,21 @@ type SubagentRegistration struct {
 	Handler    subagents.Handler
 }
 
+// RequestBuilder edits each outgoing model request after middleware has run
+// and immediately before the model is called. A non-nil error aborts the run.
+type RequestBuilder func(ctx context.Context, req *model.Request) error
+
 // ModelFactory allows callers to supply arbitrary model implementations.
 type ModelFactory interface {
 	Model(ctx context.Context) (model.Model, error)
 }
 
+// FallbackModel names a ModelFactory in Options.ModelFallbacks.
+type FallbackModel struct {
+	Name    string
+	Factory ModelFactory
+}
+
 // ModelFactoryFunc turns a function into a ModelFactory.
 type ModelFactoryFunc func(context.Context) (model.Model, error)
 
@@ -163,6 +203,21 @@ type Options struct {
 	Model        model.Model
 	ModelFactory ModelFactory
 
+	// HTTPClient is shared by outbound HTTP callers: the WebFetch and
+	// WebSearch built-ins, and a ModelFactory that accepts a client (such as
+	// model.AnthropicProvider) when it has none of its own; such a factory
+	// is bound to a copy and never modified. Build it with
+	// ratelimit.NewRetryingClient, or wrap its transport with
+	// ratelimit.NewTransport and ratelimit.NewRetryTransport, to throttle and
+	// retry all of them together.
+	HTTPClient *http.Client
+
+	// ModelFallbacks are tried in order when the primary model (Model or
+	// ModelFactory, reported as "primary") is unavailable: it cannot be
+	// built, or a call fails with a model.IsUnavailable error. Unlike retries
+	// these switch backends. Result.Provider reports which one served a run.
+	ModelFallbacks []FallbackModel
+
 	// ModelPool maps tiers to model instances for cost optimization.
 	// Use ModelTier constants (ModelTierLow, ModelTierMid, ModelTierHigh) as keys.
 	ModelPool map[ModelTier]model.Model
@@ -179,12 +234,25 @@ type Options struct {
 	SystemPrompt string
 	RulesEnabled *bool // nil = 默认启用，false = 禁用
 
+	// RequestBuilder, when set, may rewrite every model request, for example
+	// to inject a system message carrying the current time or feature flags.
+	// Unlike Middleware it sees and mutates the exact model.Request sent.
+	RequestBuilder RequestBuilder
+
 	Middleware        []middleware.Middleware
 	MiddlewareTimeout time.Duration
 	MaxIterations     int
 	Timeout           time.Duration
 	TokenLimit        int
 	MaxSessions       int
+	// MaxPromptBytes rejects prompts larger than this many bytes before any
+	// model work starts. Zero disables the check.
+	MaxPromptBytes int
+	// MaxConcurrentTools, when above one, runs the tool calls of a model turn
+	// concurrently with at most this many executing at once per run; excess
+	// calls queue and their wait is reported in ToolUsageStat.TotalWait. Zero
+	// or one keeps sequential execution.
+	MaxConcurrentTools int
 
 	Tools []tool.Tool
 
@@ -216,6 +284,9 @@ type Options struct {
 	Skills    []SkillRegistration
 	Commands  []CommandRegistration
 	Subagents []SubagentRegistration
+	// SkillPolicy limits skill auto-activation on every run. The zero value
+	// applies no limits.
+	SkillPolicy skills.ActivationPolicy
 
 	Sandbox SandboxOptions
 
@@ -240,6 +311,10 @@ type Options struct {
 	ApprovalWhitelistTTL time.Duration
 	// ApprovalWait blocks tool execution until a pending approval is resolved.
 	ApprovalWait bool
+	// ApprovalRequiredTools names tools that need approval on every call
+	// unless a permission rule explicitly allows or denies them. Tools can
+	// also opt in by implementing tool.ApprovalRequirer.
+	ApprovalRequiredTools []string
 
 	// AutoCompact enables automatic context compaction for long sessions.
 	AutoCompact CompactConfig
@@ -247,6 +322,8 @@ type Options struct {
 	// OTEL configures OpenTelemetry distributed tracing.
 	// Requires build tag 'otel' for actual instrumentation; otherwise no-op.
 	OTEL OTELConfig
+	// SessionMetrics optionally records the agent.sessions.active gauge.
+	SessionMetrics SessionMetrics
 
 	fsLayer *config.FS
 }
@@ -276,15 +353,21 @@ type Request struct {
 	TargetSubagent    string
 	ToolWhitelist     []string
 	ForceSkills       []string
+	// MaxPromptBytes optionally tightens Options.MaxPromptBytes for this
+	// request. It can never raise the runtime-wide limit.
+	MaxPromptBytes int
 }
 
 // Response aggregates the final agent result together with metadata emitted
 // by the unified runtime pipeline (skills/commands/hooks/etc.).
 type Response struct {
-	Mode           ModeContext
-	RequestID      string `json:"request_id,omitempty"` // UUID for distributed tracing
-	Result         *Result
-	SkillResults   []SkillExecution
+	Mode         ModeContext
+	RequestID    string `json:"request_id,omitempty"` // UUID for distributed tracing
+	Result       *Result
+	SkillResults []SkillExecution
+	// SkillTrace is the run's auto-activation pass: the skills that ran,
+	// those the ActivationPolicy suppressed and matchers that timed out.
+	SkillTrace     skills.MatchReport
 	CommandResults []CommandExecution
 	Subagent       *subagents.Result
 	HookEvents     []coreevents.Event
@@ -293,6 +376,9 @@ type Response struct {
 	Settings        *config.Settings
 	SandboxSnapshot SandboxReport
 	Tags            map[string]string
+	// ToolUsage aggregates tool executions for the run, keyed by tool name.
+	// Nil when no tool ran.
+	ToolUsage map[string]ToolUsageStat
 }
 
 // Result represents the agent execution result.
@@ -301,6 +387,9 @@ type Result struct {
 	StopReason string
 	Usage      model.Usage
 	ToolCalls  []model.ToolCall
+	// Provider names the model that produced the final response when
+	// Options.ModelFallbacks is in use.
+	Provider string
 }
 
 // SkillExecution records individual skill invocations.
@@ -319,10 +408,10 @@ type CommandExecution struct {
 
 // SandboxReport documents the sandbox configuration attached to the runtime.
 type SandboxReport struct {
-	Roots          []string
-	AllowedPaths   []string
-	AllowedDomains []string
-	ResourceLimits sandbox.ResourceLimits
+	Roots          []string               `json:"roots,omitempty"`
+	AllowedPaths   []string               `json:"allowed_paths,omitempty"`
+	AllowedDomains []string               `json:"allowed_domains,omitempty"`
+	ResourceLimits sandbox.ResourceLimits `json:"resource_limits"`
 }
 
 // WithMaxSessions caps how many parallel session histories are retained.
@@ -486,6 +575,9 @@ func (o Options) frozen() Options {
 	if len(o.ModelPool) > 0 {
 		o.ModelPool = maps.Clone(o.ModelPool)
 	}
+	if len(o.ModelFallbacks) > 0 {
+		o.ModelFallbacks = append([]FallbackModel(nil), o.ModelFallbacks...)
+	}
 	if len(o.SubagentModelMapping) > 0 {
 		o.SubagentModelMapping = maps.Clone(o.SubagentModelMapping)
 	}
@@ -644,8 +736,10 @@ type HookRecorder interface {
 	Drain() []coreevents.Event
 }
 
-// hookRecorder stores hook events for the response payload.
+// hookRecorder stores hook events for the response payload. It is safe for
+// concurrent use by parallel tool calls.
 type hookRecorder struct {
+	mu     sync.Mutex
 	events []coreevents.Event
 }
 
@@ -653,10 +747,14 @@ func (r *hookRecorder) Record(evt coreevents.Event) {
 	if evt.Timestamp.IsZero() {
 		evt.Timestamp = time.Now().UTC()
 	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.events = append(r.events, evt)
 }
 
 func (r *hookRecorder) Drain() []coreevents.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	defer func() { r.events = nil }()
 	if len(r.events) == 0 {
 		return nil
@@ -11,6 +11,7 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
@@ -90,6 +91,32 @@ func NewTracer(cfg OTELConfig) (Tracer, error) {
 	}, nil
 }
 
+// otelSessionMetrics records agent.sessions.active on a caller-supplied meter.
+type otelSessionMetrics struct {
+	sessions metric.Int64UpDownCounter
+}
+
+// NewSessionMetrics creates the agent.sessions.active up-down counter on
+// meter, for use as Options.SessionMetrics.
+func NewSessionMetrics(meter metric.Meter) (SessionMetrics, error) {
+	if meter == nil {
+		return nil, fmt.Errorf("otel: meter is nil")
+	}
+	sessions, err := meter.Int64UpDownCounter("agent.sessions.active",
+		metric.WithDescription("Sessions with a run currently in progress"),
+		metric.WithUnit("{session}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("otel: failed to create session gauge: %w", err)
+	}
+	return &otelSessionMetrics{sessions: sessions}, nil
+}
+
+// AddActiveSessions implements SessionMetrics.
+func (m *otelSessionMetrics) AddActiveSessions(delta int64) {
+	m.sessions.Add(context.Background(), delta)
+}
+
 func (t *otelTracer) StartAgentSpan(sessionID, requestID string, iteration int) SpanContext {
 	ctx, span := t.tracer.Start(context.Background(), "agent.run",
 		trace.WithAttributes(
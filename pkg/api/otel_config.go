@@ -50,6 +50,14 @@ type Tracer interface {
 	Shutdown() error
 }
 
+// SessionMetrics receives agent.sessions.active deltas. The runtime reports +1
+// when a run creates a session, -1 when MaxSessions evicts it, and releases
+// the remaining sessions on Close. With the 'otel' build tag,
+// NewSessionMetrics builds one from a caller-supplied meter.
+type SessionMetrics interface {
+	AddActiveSessions(delta int64)
+}
+
 // SpanContext carries span identification for propagation.
 type SpanContext interface {
 	// TraceID returns the trace identifier.
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cexll/agentsdk-go/pkg/model"
+)
+
+func TestRequestBuilderMutatesModelRequest(t *testing.T) {
+	mdl := &stubModel{responses: []*model.Response{{Message: model.Message{Role: "assistant", Content: "ok"}}}}
+	opts := Options{
+		ProjectRoot:         t.TempDir(),
+		Model:               mdl,
+		EnabledBuiltinTools: []string{},
+		RequestBuilder: func(_ context.Context, req *model.Request) error {
+			req.Messages = append(req.Messages, model.Message{Role: "system", Content: "flags: beta"})
+			return nil
+		},
+	}
+	rt, err := New(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+
+	if _, err := rt.Run(context.Background(), Request{Prompt: "hello"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(mdl.requests) != 1 {
+		t.Fatalf("expected one model request, got %d", len(mdl.requests))
+	}
+	msgs := mdl.requests[0].Messages
+	if len(msgs) < 2 || msgs[len(msgs)-1].Role != "system" || msgs[len(msgs)-1].Content != "flags: beta" {
+		t.Fatalf("builder message missing from request: %+v", msgs)
+	}
+}
+
+func TestRequestBuilderErrorAbortsRun(t *testing.T) {
+	mdl := &stubModel{responses: []*model.Response{{Message: model.Message{Role: "assistant", Content: "ok"}}}}
+	sentinel := errors.New("flags unavailable")
+	opts := Options{
+		ProjectRoot:         t.TempDir(),
+		Model:               mdl,
+		EnabledBuiltinTools: []string{},
+		RequestBuilder: func(context.Context, *model.Request) error {
+			return sentinel
+		},
+	}
+	rt, err := New(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+
+	_, err = rt.Run(context.Background(), Request{Prompt: "hello"})
+	if !errors.Is(err, sentinel) {
+		t.Fatalf("expected builder error, got %v", err)
+	}
+	if len(mdl.requests) != 0 {
+		t.Fatalf("model should not be called, got %d requests", len(mdl.requests))
+	}
+}
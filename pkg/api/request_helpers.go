@@ -10,6 +10,21 @@ import (
 	"github.com/cexll/agentsdk-go/pkg/runtime/subagents"
 )
 
+// checkPromptSize enforces the effective prompt byte limit. A per-request
+// limit applies only when it is tighter than the runtime-wide one.
+func checkPromptSize(limit int, req Request) error {
+	if req.MaxPromptBytes > 0 && (limit <= 0 || req.MaxPromptBytes < limit) {
+		limit = req.MaxPromptBytes
+	}
+	if limit <= 0 {
+		return nil
+	}
+	if size := len(req.Prompt); size > limit {
+		return &PromptTooLargeError{Limit: limit, Size: size}
+	}
+	return nil
+}
+
 func removeCommandLines(prompt string, invs []commands.Invocation) string {
 	if len(invs) == 0 {
 		return prompt
@@ -140,6 +155,18 @@ func canonicalToolName(name string) string {
 	return strings.ToLower(strings.TrimSpace(name))
 }
 
+// setKeys returns the members of set in unspecified order.
+func setKeys(set map[string]struct{}) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
 func toLowerSet(values []string) map[string]struct{} {
 	if len(values) == 0 {
 		return nil
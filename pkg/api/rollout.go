@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	coreevents "github.com/cexll/agentsdk-go/pkg/core/events"
 )
 
 type RolloutWriter struct {
@@ -21,6 +23,11 @@ type CompactEvent struct {
 	PreservedMessages     int       `json:"preserved_messages"`
 	EstimatedTokensBefore int       `json:"estimated_tokens_before"`
 	EstimatedTokensAfter  int       `json:"estimated_tokens_after"`
+	SummaryAttempts       int       `json:"summary_attempts,omitempty"`
+	RetryDurationMs       int64     `json:"retry_duration_ms,omitempty"`
+	// HookEvents holds the PreCompact and ContextCompacted hook results of
+	// a Runtime.Compact call, as Response.HookEvents does for a run.
+	HookEvents []coreevents.Event `json:"hook_events,omitempty"`
 }
 
 func newRolloutWriter(projectRoot, dir string) *RolloutWriter {
@@ -47,15 +54,7 @@ func (w *RolloutWriter) WriteCompactEvent(sessionID string, res compactResult) e
 	}
 
 	ts := time.Now().UTC()
-	event := CompactEvent{
-		SessionID:             sessionID,
-		Timestamp:             ts,
-		Summary:               res.summary,
-		OriginalMessages:      res.originalMsgs,
-		PreservedMessages:     res.preservedMsgs,
-		EstimatedTokensBefore: res.tokensBefore,
-		EstimatedTokensAfter:  res.tokensAfter,
-	}
+	event := newCompactEvent(sessionID, res, ts)
 	data, err := json.MarshalIndent(event, "", "  ")
 	if err != nil {
 		return fmt.Errorf("api: marshal compact event: %w", err)
@@ -70,6 +69,20 @@ func (w *RolloutWriter) WriteCompactEvent(sessionID string, res compactResult) e
 	return nil
 }
 
+func newCompactEvent(sessionID string, res compactResult, ts time.Time) CompactEvent {
+	return CompactEvent{
+		SessionID:             sessionID,
+		Timestamp:             ts,
+		Summary:               res.summary,
+		OriginalMessages:      res.originalMsgs,
+		PreservedMessages:     res.preservedMsgs,
+		EstimatedTokensBefore: res.tokensBefore,
+		EstimatedTokensAfter:  res.tokensAfter,
+		SummaryAttempts:       res.attempts,
+		RetryDurationMs:       res.retryTime.Milliseconds(),
+	}
+}
+
 func safeRolloutName(name string) string {
 	name = strings.TrimSpace(name)
 	if name == "" {
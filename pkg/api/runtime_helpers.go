@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -18,59 +17,8 @@ import (
 	"github.com/cexll/agentsdk-go/pkg/runtime/skills"
 	"github.com/cexll/agentsdk-go/pkg/runtime/subagents"
 	"github.com/cexll/agentsdk-go/pkg/sandbox"
-	"github.com/cexll/agentsdk-go/pkg/tool"
 )
 
-func availableTools(registry *tool.Registry, whitelist map[string]struct{}) []model.ToolDefinition {
-	if registry == nil {
-		return nil
-	}
-	tools := registry.List()
-	defs := make([]model.ToolDefinition, 0, len(tools))
-	for _, impl := range tools {
-		if impl == nil {
-			continue
-		}
-		name := strings.TrimSpace(impl.Name())
-		if name == "" {
-			continue
-		}
-		canon := canonicalToolName(name)
-		if canon == "" {
-			continue
-		}
-		if len(whitelist) > 0 {
-			if _, ok := whitelist[canon]; !ok {
-				continue
-			}
-		}
-		defs = append(defs, model.ToolDefinition{
-			Name:        name,
-			Description: strings.TrimSpace(impl.Description()),
-			Parameters:  schemaToMap(impl.Schema()),
-		})
-	}
-	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
-	return defs
-}
-
-func schemaToMap(schema *tool.JSONSchema) map[string]any {
-	if schema == nil {
-		return nil
-	}
-	payload := map[string]any{}
-	if schema.Type != "" {
-		payload["type"] = schema.Type
-	}
-	if len(schema.Properties) > 0 {
-		payload["properties"] = schema.Properties
-	}
-	if len(schema.Required) > 0 {
-		payload["required"] = append([]string(nil), schema.Required...)
-	}
-	return payload
-}
-
 func convertMessages(msgs []message.Message) []model.Message {
 	if len(msgs) == 0 {
 		return nil
@@ -270,7 +218,7 @@ func buildSkillsRegistry(opts Options) (*skills.Registry, []error) {
 
 	merged := mergeSkillRegistrations(fsRegs, opts.Skills, &errs)
 
-	reg := skills.NewRegistry()
+	reg := skills.NewRegistry().WithActivationPolicy(opts.SkillPolicy)
 	for _, entry := range merged {
 		if err := reg.Register(entry.Definition, entry.Handler); err != nil {
 			errs = append(errs, err)
@@ -372,6 +320,9 @@ type historyStore struct {
 	lastUsed map[string]time.Time
 	maxSize  int
 	onEvict  func(string)
+	// onActive receives +1 when a session is created and -1 when it is
+	// evicted.
+	onActive func(delta int64)
 	loader   func(string) ([]message.Message, error)
 }
 
@@ -401,12 +352,19 @@ func (s *historyStore) Get(id string) *message.History {
 	s.data[id] = hist
 	s.lastUsed[id] = now
 	onEvict := s.onEvict
+	onActive := s.onActive
 	loader := s.loader
 	evicted := ""
 	if len(s.data) > s.maxSize {
 		evicted = s.evictOldest()
 	}
 	s.mu.Unlock()
+	if onActive != nil {
+		onActive(1)
+		if evicted != "" {
+			onActive(-1)
+		}
+	}
 	if loader != nil {
 		if loaded, err := loader(id); err == nil && len(loaded) > 0 {
 			hist.Replace(loaded)
@@ -535,7 +493,8 @@ func (g *sessionGate) Acquire(ctx context.Context, sessionID string) error {
 		existing, loaded := g.gates.LoadOrStore(sessionID, gate)
 		if !loaded {
 			if err := ctx.Err(); err != nil {
-				g.Release(sessionID)
+				g.gates.Delete(sessionID)
+				close(gate)
 				return err
 			}
 			return nil
@@ -25,22 +25,6 @@ func (s *helperStubTool) Execute(context.Context, map[string]interface{}) (*tool
 	return &tool.ToolResult{Success: true}, nil
 }
 
-func TestAvailableToolsAndSchemaToMap(t *testing.T) {
-	t.Parallel()
-
-	reg := tool.NewRegistry()
-	if err := reg.Register(&helperStubTool{name: "Bash"}); err != nil {
-		t.Fatalf("register: %v", err)
-	}
-	defs := availableTools(reg, map[string]struct{}{"bash": {}})
-	if len(defs) != 1 || defs[0].Name != "Bash" {
-		t.Fatalf("unexpected tool defs %v", defs)
-	}
-	if schema := schemaToMap(&tool.JSONSchema{Type: "object", Properties: map[string]any{"a": "b"}}); schema["type"] != "object" {
-		t.Fatalf("unexpected schema map %v", schema)
-	}
-}
-
 func TestRegisterHelpers(t *testing.T) {
 	t.Parallel()
 
@@ -71,12 +55,6 @@ func TestConvertMessages(t *testing.T) {
 	if clone := cloneArguments(modelMsgs[0].ToolCalls[0].Arguments); clone["a"] != "b" {
 		t.Fatalf("unexpected cloned args")
 	}
-	if def := availableTools(nil, nil); def != nil {
-		t.Fatalf("expected nil defs")
-	}
-	if schema := schemaToMap(nil); schema != nil {
-		t.Fatalf("expected nil schema")
-	}
 	def := model.ToolDefinition{Name: "x"}
 	if def.Name == "" {
 		t.Fatalf("unexpected empty def")
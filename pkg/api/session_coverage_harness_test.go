@@ -28,10 +28,6 @@ func TestSessionCoverageHarness(t *testing.T) {
 		{"TestApplyPromptMetadataOverride", TestApplyPromptMetadataOverride},
 		{"TestAtomicWriteFileCreateTempError", TestAtomicWriteFileCreateTempError},
 		{"TestAtomicWriteFileRenameErrorCleansTemp", TestAtomicWriteFileRenameErrorCleansTemp},
-		{"TestAvailableToolsFiltersWhitelist", TestAvailableToolsFiltersWhitelist},
-		{"TestAvailableToolsNilRegistry", TestAvailableToolsNilRegistry},
-		{"TestAvailableToolsReturnsDefinitions", TestAvailableToolsReturnsDefinitions},
-		{"TestAvailableToolsWhitelistAllowsMatch", TestAvailableToolsWhitelistAllowsMatch},
 		{"TestBuildSandboxManager", TestBuildSandboxManager},
 		{"TestBuildSandboxManagerAppliesDefaultNetworkAllow", TestBuildSandboxManagerAppliesDefaultNetworkAllow},
 		{"TestBuildSandboxManagerEnabledByDefault", TestBuildSandboxManagerEnabledByDefault},
@@ -184,7 +180,6 @@ func TestSessionCoverageHarness(t *testing.T) {
 		{"TestRuntimeToolFlow", TestRuntimeToolFlow},
 		{"TestSafeRolloutName", TestSafeRolloutName},
 		{"TestSanitizePathComponent", TestSanitizePathComponent},
-		{"TestSchemaToMap", TestSchemaToMap},
 		{"TestSelectModelForSubagent", TestSelectModelForSubagent},
 		{"TestSelectModelForSubagentCaseInsensitive", TestSelectModelForSubagentCaseInsensitive},
 		{"TestSelectModelForSubagentConcurrent", TestSelectModelForSubagentConcurrent},
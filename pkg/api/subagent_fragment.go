@@ -0,0 +1,68 @@
+package api
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/cexll/agentsdk-go/pkg/runtime/subagents"
+)
+
+// subagentToolUsageKey is the Result.Metadata key under which a subagent
+// handler may report its own tool usage for aggregation.
+const subagentToolUsageKey = "tool_usage"
+
+// SubagentFragment is the part of a Response contributed by one subagent
+// dispatch, in the shape the runtime aggregates.
+type SubagentFragment struct {
+	Subagent  string
+	Output    string
+	ToolUsage map[string]ToolUsageStat
+	Metadata  map[string]any
+	// Err is a *SubagentError when Result.Error is set, nil otherwise.
+	Err error
+}
+
+// SubagentError reports a failure recorded in subagents.Result.Error. It
+// matches ErrSubagentFailed via errors.Is.
+type SubagentError struct {
+	Subagent string
+	Message  string
+}
+
+func (e *SubagentError) Error() string {
+	if e.Subagent == "" {
+		return fmt.Sprintf("%v: %s", ErrSubagentFailed, e.Message)
+	}
+	return fmt.Sprintf("%v: %s: %s", ErrSubagentFailed, e.Subagent, e.Message)
+}
+
+// Is reports whether target is ErrSubagentFailed.
+func (e *SubagentError) Is(target error) bool {
+	return target == ErrSubagentFailed
+}
+
+// NewSubagentFragment converts res into a SubagentFragment. A nil Output
+// becomes the empty string and other values are rendered with fmt.Sprint.
+// Tool usage is taken from Metadata["tool_usage"] when it holds a
+// map[string]ToolUsageStat; that key is not repeated in Metadata.
+func NewSubagentFragment(res subagents.Result) SubagentFragment {
+	frag := SubagentFragment{Subagent: res.Subagent}
+	if res.Output != nil {
+		frag.Output = strings.TrimSpace(fmt.Sprint(res.Output))
+	}
+	if len(res.Metadata) > 0 {
+		meta := maps.Clone(res.Metadata)
+		if usage, ok := meta[subagentToolUsageKey].(map[string]ToolUsageStat); ok {
+			frag.ToolUsage = maps.Clone(usage)
+			delete(meta, subagentToolUsageKey)
+		}
+		if len(meta) > 0 {
+			frag.Metadata = meta
+		}
+	}
+	if msg := strings.TrimSpace(res.Error); msg != "" {
+		frag.Err = &SubagentError{Subagent: res.Subagent, Message: msg}
+	}
+	return frag
+}
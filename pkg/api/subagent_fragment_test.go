@@ -0,0 +1,42 @@
+package api
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cexll/agentsdk-go/pkg/runtime/subagents"
+)
+
+func TestNewSubagentFragment(t *testing.T) {
+	usage := map[string]ToolUsageStat{"bash": {Count: 2, Errors: 1}}
+	ok := NewSubagentFragment(subagents.Result{
+		Subagent: "explore",
+		Output:   "  found it \n",
+		Metadata: map[string]any{"tool_usage": usage, "k": "v"},
+	})
+	if ok.Err != nil || ok.Subagent != "explore" || ok.Output != "found it" {
+		t.Fatalf("unexpected success fragment %+v", ok)
+	}
+	if ok.ToolUsage["bash"].Count != 2 || ok.ToolUsage["bash"].Errors != 1 {
+		t.Fatalf("unexpected tool usage %+v", ok.ToolUsage)
+	}
+	if len(ok.Metadata) != 1 || ok.Metadata["k"] != "v" {
+		t.Fatalf("expected tool_usage stripped from metadata, got %+v", ok.Metadata)
+	}
+
+	failed := NewSubagentFragment(subagents.Result{Subagent: "plan", Error: "model timed out"})
+	if failed.Output != "" || failed.Metadata != nil || failed.ToolUsage != nil {
+		t.Fatalf("unexpected error fragment %+v", failed)
+	}
+	if !errors.Is(failed.Err, ErrSubagentFailed) {
+		t.Fatalf("expected ErrSubagentFailed, got %v", failed.Err)
+	}
+	var subErr *SubagentError
+	if !errors.As(failed.Err, &subErr) || subErr.Message != "model timed out" || subErr.Subagent != "plan" {
+		t.Fatalf("unexpected error %#v", failed.Err)
+	}
+	if !strings.Contains(failed.Err.Error(), "plan: model timed out") {
+		t.Fatalf("error text not preserved: %q", failed.Err.Error())
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cexll/agentsdk-go/pkg/tool"
+)
+
+// ToolUsageStat aggregates the executions of one tool during a run.
+type ToolUsageStat struct {
+	Count         int           `json:"count"`
+	Errors        int           `json:"errors"`
+	TotalDuration time.Duration `json:"total_duration"`
+	// TotalWait is the time calls spent queued behind
+	// Options.MaxConcurrentTools.
+	TotalWait time.Duration `json:"total_wait"`
+}
+
+// toolUsage accumulates per-tool stats from executor CallResults. Calls
+// rejected before reaching the executor (whitelist, PreToolUse) are not
+// counted.
+type toolUsage struct {
+	mu    sync.Mutex
+	stats map[string]ToolUsageStat
+}
+
+func (u *toolUsage) record(name string, res *tool.CallResult, err error, wait time.Duration) {
+	if u == nil || name == "" {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.stats == nil {
+		u.stats = map[string]ToolUsageStat{}
+	}
+	stat := u.stats[name]
+	stat.Count++
+	if err != nil {
+		stat.Errors++
+	}
+	if res != nil {
+		stat.TotalDuration += res.Duration()
+	}
+	stat.TotalWait += wait
+	u.stats[name] = stat
+}
+
+func (u *toolUsage) snapshot() map[string]ToolUsageStat {
+	if u == nil {
+		return nil
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if len(u.stats) == 0 {
+		return nil
+	}
+	out := make(map[string]ToolUsageStat, len(u.stats))
+	for name, stat := range u.stats {
+		out[name] = stat
+	}
+	return out
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cexll/agentsdk-go/pkg/model"
+	"github.com/cexll/agentsdk-go/pkg/tool"
+)
+
+type sleepyTool struct{ delay time.Duration }
+
+func (s *sleepyTool) Name() string             { return "sleepy" }
+func (s *sleepyTool) Description() string      { return "sleeps" }
+func (s *sleepyTool) Schema() *tool.JSONSchema { return &tool.JSONSchema{Type: "object"} }
+func (s *sleepyTool) Execute(context.Context, map[string]interface{}) (*tool.ToolResult, error) {
+	time.Sleep(s.delay)
+	return &tool.ToolResult{Success: true, Output: "rested"}, nil
+}
+
+func TestRuntimeResponseToolUsage(t *testing.T) {
+	root := newClaudeProject(t)
+	mdl := &stubModel{responses: []*model.Response{
+		{Message: model.Message{Role: "assistant", ToolCalls: []model.ToolCall{
+			{ID: "1", Name: "sleepy", Arguments: map[string]any{"n": 1}},
+			{ID: "2", Name: "fail", Arguments: map[string]any{"n": 1}},
+		}}},
+		{Message: model.Message{Role: "assistant", ToolCalls: []model.ToolCall{
+			{ID: "3", Name: "sleepy", Arguments: map[string]any{"n": 2}},
+		}}},
+		{Message: model.Message{Role: "assistant", Content: "done"}},
+	}}
+	delay := 5 * time.Millisecond
+	opts := Options{
+		ProjectRoot: root,
+		Model:       mdl,
+		Tools:       []tool.Tool{&sleepyTool{delay: delay}, &failingTool{err: errors.New("boom")}},
+	}
+	rt, err := New(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+
+	resp, err := rt.Run(context.Background(), Request{Prompt: "use tools"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(resp.ToolUsage) != 2 {
+		t.Fatalf("expected stats for two tools, got %+v", resp.ToolUsage)
+	}
+	sleepy := resp.ToolUsage["sleepy"]
+	if sleepy.Count != 2 || sleepy.Errors != 0 || sleepy.TotalDuration < 2*delay {
+		t.Fatalf("unexpected sleepy stats %+v", sleepy)
+	}
+	fail := resp.ToolUsage["fail"]
+	if fail.Count != 1 || fail.Errors != 1 {
+		t.Fatalf("unexpected fail stats %+v", fail)
+	}
+
+	plain, err := rt.Run(context.Background(), Request{Prompt: "no tools", SessionID: "other"})
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if plain.ToolUsage != nil {
+		t.Fatalf("expected nil tool usage for a run without tools, got %+v", plain.ToolUsage)
+	}
+}
+
+type gaugeTool struct {
+	inflight, peak atomic.Int32
+	done           atomic.Int32
+}
+
+func (g *gaugeTool) Name() string             { return "gauge" }
+func (g *gaugeTool) Description() string      { return "tracks concurrency" }
+func (g *gaugeTool) Schema() *tool.JSONSchema { return &tool.JSONSchema{Type: "object"} }
+func (g *gaugeTool) Execute(_ context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
+	n := g.inflight.Add(1)
+	for {
+		peak := g.peak.Load()
+		if n <= peak || g.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	// Later calls finish sooner, so completion order differs from call order.
+	i, _ := params["i"].(int)
+	time.Sleep(time.Duration(20+5*(5-i)) * time.Millisecond)
+	g.inflight.Add(-1)
+	g.done.Add(1)
+	return &tool.ToolResult{Success: true, Output: fmt.Sprintf("ok %d", i)}, nil
+}
+
+func TestRuntimeMaxConcurrentTools(t *testing.T) {
+	root := newClaudeProject(t)
+	calls := make([]model.ToolCall, 6)
+	for i := range calls {
+		calls[i] = model.ToolCall{ID: fmt.Sprintf("c%d", i), Name: "gauge", Arguments: map[string]any{"i": i}}
+	}
+	mdl := &stubModel{responses: []*model.Response{
+		{Message: model.Message{Role: "assistant", ToolCalls: calls}},
+		{Message: model.Message{Role: "assistant", Content: "done"}},
+	}}
+	gauge := &gaugeTool{}
+	rt, err := New(context.Background(), Options{
+		ProjectRoot:        root,
+		Model:              mdl,
+		Tools:              []tool.Tool{gauge},
+		MaxConcurrentTools: 2,
+	})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+
+	resp, err := rt.Run(context.Background(), Request{Prompt: "fan out", SessionID: "fan"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got := gauge.done.Load(); got != 6 {
+		t.Fatalf("expected all six calls to complete, got %d", got)
+	}
+	if peak := gauge.peak.Load(); peak != 2 {
+		t.Fatalf("expected peak concurrency of 2, got %d", peak)
+	}
+	stat := resp.ToolUsage["gauge"]
+	if stat.Count != 6 || stat.TotalWait <= 0 {
+		t.Fatalf("expected queued wait recorded, got %+v", stat)
+	}
+	var ids []string
+	for _, msg := range rt.histories.Get("fan").All() {
+		for _, call := range msg.ToolCalls {
+			if msg.Role == "tool" {
+				ids = append(ids, call.ID)
+			}
+		}
+	}
+	if fmt.Sprint(ids) != "[c0 c1 c2 c3 c4 c5]" {
+		t.Fatalf("expected tool results in call order, got %v", ids)
+	}
+}
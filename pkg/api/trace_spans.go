@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/cexll/agentsdk-go/pkg/middleware"
+)
+
+// traceSpanPayloadLimit caps the JSON input/output copied onto a span.
+const traceSpanPayloadLimit = 4 << 10
+
+// NewTraceSpanExporter adapts tracer into a middleware.SpanExporter, so a
+// TraceMiddleware built with middleware.WithTelemetry also emits spans:
+// before/after_agent bracket an agent span per session and the model and tool
+// stages become its children. Each span carries the session id, stage,
+// iteration, duration and the already sanitized input and output; model spans
+// also carry model.provider when a failover chain served the call. Tool
+// spans are paired by tool call id. An after stage without a recorded before
+// stage yields a zero-length span. Model and tool spans still open when their
+// run ends are ended with an error, as are spans a session's previous run
+// left open when its next run starts.
+func NewTraceSpanExporter(tracer Tracer) middleware.SpanExporter {
+	return &traceSpanExporter{tracer: tracer, open: map[traceSpanKey][]SpanContext{}}
+}
+
+// errTraceSpanAbandoned ends spans whose after stage never arrived.
+var errTraceSpanAbandoned = errors.New("trace: span abandoned before its run ended")
+
+// traceSpanKey identifies the open spans of one kind in a session; id is the
+// tool call id for tool spans.
+type traceSpanKey struct {
+	kind, session, id string
+}
+
+type traceSpanExporter struct {
+	tracer Tracer
+
+	mu   sync.Mutex
+	open map[traceSpanKey][]SpanContext
+}
+
+func (e *traceSpanExporter) ExportEvent(_ context.Context, evt middleware.TraceEvent) {
+	if e == nil || e.tracer == nil {
+		return
+	}
+	agentKey := traceSpanKey{kind: "agent", session: evt.SessionID}
+	modelKey := traceSpanKey{kind: "model", session: evt.SessionID}
+	switch evt.Stage {
+	case "before_agent":
+		e.prune(evt.SessionID, traceSpanKey{})
+		e.push(agentKey, e.tracer.StartAgentSpan(evt.SessionID, "", evt.Iteration))
+	case "before_model":
+		e.push(modelKey, e.tracer.StartModelSpan(e.peek(agentKey), traceModelName(evt)))
+	case "before_tool":
+		e.push(traceToolKey(evt), e.tracer.StartToolSpan(e.peek(agentKey), traceToolName(evt)))
+	case "after_agent":
+		e.prune(evt.SessionID, agentKey)
+		e.finish(agentKey, evt, func() SpanContext { return e.tracer.StartAgentSpan(evt.SessionID, "", evt.Iteration) })
+	case "after_model":
+		e.finish(modelKey, evt, func() SpanContext {
+			return e.tracer.StartModelSpan(e.peek(agentKey), traceModelName(evt))
+		})
+	case "after_tool":
+		e.finish(traceToolKey(evt), evt, func() SpanContext {
+			return e.tracer.StartToolSpan(e.peek(agentKey), traceToolName(evt))
+		})
+	}
+}
+
+func (e *traceSpanExporter) push(key traceSpanKey, span SpanContext) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.open[key] = append(e.open[key], span)
+}
+
+func (e *traceSpanExporter) peek(key traceSpanKey) SpanContext {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if spans := e.open[key]; len(spans) > 0 {
+		return spans[len(spans)-1]
+	}
+	return nil
+}
+
+// finish ends the oldest open span under key, or a fresh one from start.
+func (e *traceSpanExporter) finish(key traceSpanKey, evt middleware.TraceEvent, start func() SpanContext) {
+	e.mu.Lock()
+	var span SpanContext
+	if spans := e.open[key]; len(spans) > 0 {
+		span = spans[0]
+		if len(spans) == 1 {
+			delete(e.open, key)
+		} else {
+			e.open[key] = spans[1:]
+		}
+	}
+	e.mu.Unlock()
+	if span == nil {
+		span = start()
+	}
+	var err error
+	if evt.Error != "" {
+		err = errors.New(evt.Error)
+	}
+	e.tracer.EndSpan(span, traceSpanAttrs(evt), err)
+}
+
+// prune ends every span still open for session except those under keep,
+// e.g. tool calls whose after stage was lost when the run failed.
+func (e *traceSpanExporter) prune(session string, keep traceSpanKey) {
+	e.mu.Lock()
+	var stale []SpanContext
+	for key, spans := range e.open {
+		if key.session == session && key != keep {
+			stale = append(stale, spans...)
+			delete(e.open, key)
+		}
+	}
+	e.mu.Unlock()
+	for _, span := range stale {
+		e.tracer.EndSpan(span, map[string]any{"agent.session_id": session}, errTraceSpanAbandoned)
+	}
+}
+
+func traceSpanAttrs(evt middleware.TraceEvent) map[string]any {
+	attrs := map[string]any{
+		"agent.session_id":  evt.SessionID,
+		"agent.iteration":   evt.Iteration,
+		"trace.stage":       evt.Stage,
+		"trace.duration_ms": evt.DurationMS,
+	}
+	if in := tracePayloadString(evt.Input); in != "" {
+		attrs["trace.input"] = in
+	}
+	if out := tracePayloadString(evt.Output); out != "" {
+		attrs["trace.output"] = out
+	}
+	// The failover chain records which candidate served the call.
+	if provider, ok := evt.ModelResponse["provider"].(string); ok && provider != "" {
+		attrs["model.provider"] = provider
+	}
+	return attrs
+}
+
+func tracePayloadString(v any) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	if len(data) > traceSpanPayloadLimit {
+		return strings.ToValidUTF8(string(data[:traceSpanPayloadLimit]), "") + "...(truncated)"
+	}
+	return string(data)
+}
+
+func traceModelName(evt middleware.TraceEvent) string {
+	if name, ok := evt.ModelRequest["model"].(string); ok {
+		return name
+	}
+	return ""
+}
+
+func traceToolName(evt middleware.TraceEvent) string {
+	if name, ok := evt.ToolCall["name"].(string); ok {
+		return name
+	}
+	return ""
+}
+
+// traceToolKey pairs tool stages by call id, falling back to the tool name
+// for calls without one.
+func traceToolKey(evt middleware.TraceEvent) traceSpanKey {
+	id, _ := evt.ToolCall["id"].(string)
+	if id == "" {
+		id = "name:" + traceToolName(evt)
+	}
+	return traceSpanKey{kind: "tool", session: evt.SessionID, id: id}
+}
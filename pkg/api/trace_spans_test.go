@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cexll/agentsdk-go/pkg/middleware"
+)
+
+type fakeSpan struct {
+	name   string
+	parent *fakeSpan
+	attrs  map[string]any
+	err    error
+	ended  bool
+}
+
+func (s *fakeSpan) TraceID() string   { return "" }
+func (s *fakeSpan) SpanID() string    { return s.name }
+func (s *fakeSpan) IsRecording() bool { return !s.ended }
+
+type fakeTracer struct{ spans []*fakeSpan }
+
+func (f *fakeTracer) start(name string, parent SpanContext) SpanContext {
+	span := &fakeSpan{name: name}
+	if p, ok := parent.(*fakeSpan); ok {
+		span.parent = p
+	}
+	f.spans = append(f.spans, span)
+	return span
+}
+
+func (f *fakeTracer) StartAgentSpan(sessionID, _ string, _ int) SpanContext {
+	return f.start("agent:"+sessionID, nil)
+}
+
+func (f *fakeTracer) StartModelSpan(parent SpanContext, modelName string) SpanContext {
+	return f.start("model:"+modelName, parent)
+}
+
+func (f *fakeTracer) StartToolSpan(parent SpanContext, toolName string) SpanContext {
+	return f.start("tool:"+toolName, parent)
+}
+
+func (f *fakeTracer) EndSpan(span SpanContext, attrs map[string]any, err error) {
+	s := span.(*fakeSpan)
+	s.attrs, s.err, s.ended = attrs, err, true
+}
+
+func (f *fakeTracer) Shutdown() error { return nil }
+
+func TestTraceSpanExporterPairsStages(t *testing.T) {
+	tracer := &fakeTracer{}
+	exp := NewTraceSpanExporter(tracer)
+	ctx := context.Background()
+	events := []middleware.TraceEvent{
+		{Stage: "before_agent", SessionID: "s1", Iteration: 0},
+		{Stage: "before_tool", SessionID: "s1", ToolCall: map[string]any{"name": "bash"}},
+		{Stage: "after_tool", SessionID: "s1", Iteration: 1, DurationMS: 12, ToolCall: map[string]any{"name": "bash"}, Output: strings.Repeat("é", traceSpanPayloadLimit), Error: "boom"},
+		{Stage: "after_model", SessionID: "s1", ModelRequest: map[string]any{"model": "m"}, ModelResponse: map[string]any{"provider": "fallback-1"}},
+		{Stage: "after_agent", SessionID: "s1"},
+	}
+	for _, evt := range events {
+		exp.ExportEvent(ctx, evt)
+	}
+
+	if len(tracer.spans) != 3 {
+		t.Fatalf("expected agent, tool and model spans, got %d", len(tracer.spans))
+	}
+	agent, tool, model := tracer.spans[0], tracer.spans[1], tracer.spans[2]
+	for _, span := range tracer.spans {
+		if !span.ended {
+			t.Fatalf("span %s not ended", span.name)
+		}
+	}
+	if tool.name != "tool:bash" || tool.parent != agent || model.parent != agent {
+		t.Fatalf("unexpected span tree: tool=%s parent=%v model parent=%v", tool.name, tool.parent, model.parent)
+	}
+	if tool.err == nil || tool.err.Error() != "boom" {
+		t.Fatalf("expected tool error, got %v", tool.err)
+	}
+	if tool.attrs["trace.stage"] != "after_tool" || tool.attrs["agent.session_id"] != "s1" ||
+		tool.attrs["agent.iteration"] != 1 || tool.attrs["trace.duration_ms"] != int64(12) {
+		t.Fatalf("unexpected tool attrs %v", tool.attrs)
+	}
+	out, _ := tool.attrs["trace.output"].(string)
+	if !strings.HasSuffix(out, "...(truncated)") || !strings.HasPrefix(out, `"é`) {
+		t.Fatalf("expected truncated output, got %q", out[:min(len(out), 20)])
+	}
+	if model.name != "model:m" || model.attrs["trace.stage"] != "after_model" || model.attrs["model.provider"] != "fallback-1" {
+		t.Fatalf("unexpected model span %s %v", model.name, model.attrs)
+	}
+}
+
+func TestTraceSpanExporterPairsToolsByCallIDAndPrunes(t *testing.T) {
+	tracer := &fakeTracer{}
+	exp := NewTraceSpanExporter(tracer)
+	ctx := context.Background()
+	events := []middleware.TraceEvent{
+		{Stage: "before_agent", SessionID: "s1"},
+		{Stage: "before_tool", SessionID: "s1", ToolCall: map[string]any{"id": "c1", "name": "bash"}},
+		{Stage: "before_tool", SessionID: "s1", ToolCall: map[string]any{"id": "c2", "name": "bash"}},
+		{Stage: "before_tool", SessionID: "s1", ToolCall: map[string]any{"id": "c3", "name": "bash"}},
+		{Stage: "after_tool", SessionID: "s1", ToolCall: map[string]any{"id": "c2", "name": "bash"}, Error: "second"},
+		{Stage: "after_tool", SessionID: "s1", ToolCall: map[string]any{"id": "c1", "name": "bash"}, Error: "first"},
+		{Stage: "before_agent", SessionID: "s2"},
+		{Stage: "before_agent", SessionID: "s1"},
+	}
+	for _, evt := range events {
+		exp.ExportEvent(ctx, evt)
+	}
+
+	if len(tracer.spans) != 6 {
+		t.Fatalf("expected 6 spans, got %d", len(tracer.spans))
+	}
+	agent1, c1, c2, c3, agent2, agent3 := tracer.spans[0], tracer.spans[1], tracer.spans[2], tracer.spans[3], tracer.spans[4], tracer.spans[5]
+	if c1.err == nil || c1.err.Error() != "first" || c2.err == nil || c2.err.Error() != "second" {
+		t.Fatalf("tool spans paired out of order: c1=%v c2=%v", c1.err, c2.err)
+	}
+	for _, span := range []*fakeSpan{agent1, c3} {
+		if !span.ended || !errors.Is(span.err, errTraceSpanAbandoned) {
+			t.Fatalf("expected %s pruned by the next run, ended=%v err=%v", span.name, span.ended, span.err)
+		}
+	}
+	if agent2.ended || agent3.ended {
+		t.Fatal("new runs must stay open")
+	}
+}
+
+func TestTraceSpanExporterEndsOpenSpansWithTheRun(t *testing.T) {
+	tracer := &fakeTracer{}
+	exp := NewTraceSpanExporter(tracer).(*traceSpanExporter)
+	ctx := context.Background()
+	for _, evt := range []middleware.TraceEvent{
+		{Stage: "before_agent", SessionID: "s1"},
+		{Stage: "before_model", SessionID: "s1", ModelRequest: map[string]any{"model": "m"}},
+		{Stage: "before_tool", SessionID: "s1", ToolCall: map[string]any{"id": "c1", "name": "bash"}},
+		{Stage: "after_agent", SessionID: "s1"},
+	} {
+		exp.ExportEvent(ctx, evt)
+	}
+
+	if len(tracer.spans) != 3 {
+		t.Fatalf("expected agent, model and tool spans, got %d", len(tracer.spans))
+	}
+	agent, model, tool := tracer.spans[0], tracer.spans[1], tracer.spans[2]
+	if !agent.ended || agent.err != nil {
+		t.Fatalf("expected agent span ended cleanly, ended=%v err=%v", agent.ended, agent.err)
+	}
+	for _, span := range []*fakeSpan{model, tool} {
+		if !span.ended || !errors.Is(span.err, errTraceSpanAbandoned) {
+			t.Fatalf("expected %s ended as abandoned, ended=%v err=%v", span.name, span.ended, span.err)
+		}
+	}
+	if len(exp.open) != 0 {
+		t.Fatalf("expected no open spans after the run, got %v", exp.open)
+	}
+}
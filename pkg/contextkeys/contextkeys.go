@@ -0,0 +1,61 @@
+// Package contextkeys defines the typed context keys shared by the runtime,
+// middleware and tool layers. Keys use a dedicated type so values can
+// never collide with bare string keys set by other packages.
+package contextkeys
+
+import (
+	"context"
+	"strings"
+)
+
+// Key identifies a value stored in a context.Context by agentsdk-go.
+type Key string
+
+const (
+	// SessionID stores the conversation session identifier.
+	SessionID Key = "agentsdk.session_id"
+	// RunID stores the identifier of a single Runtime.Run/RunStream call
+	// (the request id).
+	RunID Key = "agentsdk.run_id"
+)
+
+// WithSessionID returns a child context carrying the session id.
+func WithSessionID(ctx context.Context, id string) context.Context {
+	return with(ctx, SessionID, id)
+}
+
+// WithRunID returns a child context carrying the run id.
+func WithRunID(ctx context.Context, id string) context.Context {
+	return with(ctx, RunID, id)
+}
+
+// SessionIDFrom reports the session id stored in ctx, or "".
+func SessionIDFrom(ctx context.Context) string {
+	return String(ctx, SessionID)
+}
+
+// RunIDFrom reports the run id stored in ctx, or "".
+func RunIDFrom(ctx context.Context) string {
+	return String(ctx, RunID)
+}
+
+// String returns the trimmed string stored under key, or "" when the value is
+// missing or not a string.
+func String(ctx context.Context, key Key) string {
+	if ctx == nil {
+		return ""
+	}
+	value, _ := ctx.Value(key).(string)
+	return strings.TrimSpace(value)
+}
+
+func with(ctx context.Context, key Key, id string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, key, id)
+}
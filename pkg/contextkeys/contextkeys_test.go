@@ -0,0 +1,36 @@
+package contextkeys
+
+import (
+	"context"
+	"testing"
+)
+
+func TestKeysRoundTrip(t *testing.T) {
+	ctx := WithSessionID(context.Background(), " sess ")
+	ctx = WithRunID(ctx, "run-1")
+
+	if got := SessionIDFrom(ctx); got != "sess" {
+		t.Fatalf("session id = %q", got)
+	}
+	if got := RunIDFrom(ctx); got != "run-1" {
+		t.Fatalf("run id = %q", got)
+	}
+}
+
+func TestKeysDoNotCollideWithBareStrings(t *testing.T) {
+	//nolint:staticcheck // deliberately using a bare string key
+	ctx := context.WithValue(context.Background(), string(SessionID), "bare")
+	if got := SessionIDFrom(ctx); got != "" {
+		t.Fatalf("expected bare string key to be ignored, got %q", got)
+	}
+}
+
+func TestEmptyValuesAreNotStored(t *testing.T) {
+	base := context.Background()
+	if ctx := WithSessionID(base, "  "); ctx != base {
+		t.Fatal("expected blank id to leave context unchanged")
+	}
+	if got := SessionIDFrom(nil); got != "" { //nolint:staticcheck // nil ctx is tolerated
+		t.Fatalf("expected empty id for nil ctx, got %q", got)
+	}
+}
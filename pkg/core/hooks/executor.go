@@ -126,6 +126,17 @@ func (s Selector) Match(evt events.Event) bool {
 	return true
 }
 
+// Environment variables exported to every hook command alongside the JSON
+// stdin payload so simple scripts can branch without a JSON parser. Variables
+// whose value would be empty are unset, including any HOOK_* inherited from
+// the parent environment; ShellHook.Env takes precedence.
+const (
+	EnvHookEvent     = "HOOK_EVENT"       // event type, e.g. PreToolUse
+	EnvHookSessionID = "HOOK_SESSION_ID"  // session identifier
+	EnvHookToolName  = "HOOK_TOOL_NAME"   // tool events, PermissionRequest, ModelSelected
+	EnvHookToolUseID = "HOOK_TOOL_USE_ID" // PreToolUse/PostToolUse/PostToolUseFailure
+)
+
 // ShellHook describes a single shell command bound to an event type.
 type ShellHook struct {
 	Event         events.EventType
@@ -333,7 +344,7 @@ func (e *Executor) executeHook(ctx context.Context, hook ShellHook, payload []by
 	defer cancel()
 
 	cmd := newShellCommand(runCtx, cmdStr)
-	cmd.Env = mergeEnv(os.Environ(), hook.Env)
+	cmd.Env = mergeEnv(mergeEnv(stripHookEnv(os.Environ()), eventEnv(evt)), hook.Env)
 	if e.workDir != "" {
 		cmd.Dir = e.workDir
 	}
@@ -584,6 +595,45 @@ func buildPayload(evt events.Event) ([]byte, error) {
 	return data, nil
 }
 
+// eventEnv derives the HOOK_* environment variables for evt.
+func eventEnv(evt events.Event) map[string]string {
+	env := map[string]string{EnvHookEvent: string(evt.Type)}
+	if evt.SessionID != "" {
+		env[EnvHookSessionID] = evt.SessionID
+	}
+	var toolName, toolUseID string
+	switch p := evt.Payload.(type) {
+	case events.ToolUsePayload:
+		toolName, toolUseID = p.Name, p.ToolUseID
+	case events.ToolResultPayload:
+		toolName, toolUseID = p.Name, p.ToolUseID
+	case events.PermissionRequestPayload:
+		toolName = p.ToolName
+	case events.ModelSelectedPayload:
+		toolName = p.ToolName
+	}
+	if toolName != "" {
+		env[EnvHookToolName] = toolName
+	}
+	if toolUseID != "" {
+		env[EnvHookToolUseID] = toolUseID
+	}
+	return env
+}
+
+// stripHookEnv drops inherited HOOK_* variables so a hook never sees a stale
+// value from the parent process for a field the event leaves empty.
+func stripHookEnv(env []string) []string {
+	out := make([]string, 0, len(env))
+	for _, kv := range env {
+		if strings.HasPrefix(kv, "HOOK_") {
+			continue
+		}
+		out = append(out, kv)
+	}
+	return out
+}
+
 func mergeEnv(base []string, extra map[string]string) []string {
 	if len(extra) == 0 {
 		return base
@@ -332,6 +332,75 @@ func TestEnvIsMergedIntoCommand(t *testing.T) {
 	}
 }
 
+func TestHookReadsToolNameFromEnvAndStdin(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("script parses stdin with sed")
+	}
+	dir := t.TempDir()
+	script := writeScript(t, dir, "tool_name.sh",
+		"#!/bin/sh\n"+
+			"from_stdin=$(sed -n 's/.*\"tool_name\":\"\\([^\"]*\\)\".*/\\1/p')\n"+
+			"echo \"env=$HOOK_EVENT/$HOOK_TOOL_NAME/$HOOK_TOOL_USE_ID/$HOOK_SESSION_ID stdin=$from_stdin\" >&2\n"+
+			"[ \"$HOOK_TOOL_NAME\" = \"$from_stdin\" ] || exit 1\n")
+
+	exec := NewExecutor()
+	exec.Register(ShellHook{Event: events.PreToolUse, Command: script})
+
+	results, err := exec.Execute(context.Background(), events.Event{
+		Type:      events.PreToolUse,
+		SessionID: "sess-env",
+		Payload:   events.ToolUsePayload{Name: "Bash", ToolUseID: "call-1", Params: map[string]any{"command": "ls"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, DecisionAllow, results[0].Decision, results[0].Stderr)
+	require.Contains(t, results[0].Stderr, "env=PreToolUse/Bash/call-1/sess-env stdin=Bash")
+}
+
+func TestEventEnvPerPayload(t *testing.T) {
+	t.Parallel()
+	env := eventEnv(events.Event{Type: events.PermissionRequest, Payload: events.PermissionRequestPayload{ToolName: "Write"}})
+	require.Equal(t, map[string]string{EnvHookEvent: "PermissionRequest", EnvHookToolName: "Write"}, env)
+
+	env = eventEnv(events.Event{Type: events.Stop, SessionID: "s1", Payload: events.StopPayload{}})
+	require.Equal(t, map[string]string{EnvHookEvent: "Stop", EnvHookSessionID: "s1"}, env)
+}
+
+func TestHookEnvUnsetsInheritedHookVars(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("script uses sh parameter expansion")
+	}
+	t.Setenv(EnvHookToolName, "stale")
+	dir := t.TempDir()
+	script := writeScript(t, dir, "stale.sh", "#!/bin/sh\necho \"tool=${HOOK_TOOL_NAME-unset}\" >&2\n")
+
+	exec := NewExecutor()
+	exec.Register(ShellHook{Event: events.Stop, Command: script})
+
+	results, err := exec.Execute(context.Background(), events.Event{Type: events.Stop, Payload: events.StopPayload{}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Contains(t, results[0].Stderr, "tool=unset")
+}
+
+func TestHookEnvOverridesEventEnv(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	script := writeScript(t, dir, "override.sh", shScript(
+		"#!/bin/sh\necho $HOOK_EVENT >&2\n",
+		"@echo %HOOK_EVENT% >&2\r\n",
+	))
+
+	exec := NewExecutor()
+	exec.Register(ShellHook{Event: events.Notification, Command: script, Env: map[string]string{EnvHookEvent: "custom"}})
+
+	results, err := exec.Execute(context.Background(), events.Event{Type: events.Notification})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Contains(t, results[0].Stderr, "custom")
+}
+
 func TestBuildPayloadFlatFormat(t *testing.T) {
 	t.Parallel()
 	cases := []struct {
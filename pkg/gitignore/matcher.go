@@ -21,6 +21,9 @@ type pattern struct {
 	negate   bool
 	dirOnly  bool
 	baseName bool // true if pattern contains no slash (matches any directory level)
+	// scope is the directory of the nested .gitignore a baseName pattern
+	// came from; the pattern only matches paths below it.
+	scope string
 }
 
 // NewMatcher creates a Matcher by loading all .gitignore files from root up to
@@ -42,6 +45,18 @@ func NewMatcher(root string) (*Matcher, error) {
 	return m, nil
 }
 
+// Clone returns an independent copy of m, so callers can load nested
+// .gitignore files for one walk without affecting a shared matcher.
+func (m *Matcher) Clone() *Matcher {
+	if m == nil {
+		return nil
+	}
+	return &Matcher{
+		patterns: append([]pattern(nil), m.patterns...),
+		root:     m.root,
+	}
+}
+
 // addDefaultPatterns adds common directories that are typically ignored.
 func (m *Matcher) addDefaultPatterns() {
 	defaults := []string{
@@ -118,11 +133,12 @@ func parseLine(line, relDir string) (pattern, bool) {
 	// it matches at any directory level
 	if !strings.Contains(line, "/") {
 		p.baseName = true
+		p.scope = filepath.ToSlash(relDir)
 	} else {
 		// Leading slash anchors to root
 		line = strings.TrimPrefix(line, "/")
 		// Prepend relDir for nested .gitignore patterns
-		if relDir != "" && !strings.HasPrefix(line, relDir) {
+		if relDir != "" {
 			line = filepath.Join(relDir, line)
 		}
 	}
@@ -174,8 +190,15 @@ func matchPattern(p pattern, relPath string, isDir bool) bool {
 	target := relPath
 	patternStr := p.pattern
 
-	// For baseName patterns, match against any path component
+	// For baseName patterns, match against any path component below the
+	// pattern's .gitignore
 	if p.baseName {
+		if p.scope != "" {
+			if !strings.HasPrefix(relPath, p.scope+"/") {
+				return false
+			}
+			target = strings.TrimPrefix(relPath, p.scope+"/")
+		}
 		// Try matching against the full path first
 		if matchGlob(patternStr, target) {
 			return true
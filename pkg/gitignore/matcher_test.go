@@ -276,6 +276,10 @@ dist/
 
 		// Non-ignored
 		{"packages/app/src", "packages/app/src", true, false},
+
+		// Nested patterns do not leak into sibling directories
+		{"sibling dist", "packages/other/dist", true, false},
+		{"sibling dist file", "zzz/dist/x.js", false, false},
 	}
 
 	for _, tt := range tests {
@@ -530,3 +534,31 @@ func TestLoadGitignoreScanError(t *testing.T) {
 		t.Errorf("LoadNestedGitignore should not error for missing .gitignore: %v", err)
 	}
 }
+
+func TestMatcherCloneIsIndependent(t *testing.T) {
+	tmpDir := t.TempDir()
+	subDir := filepath.Join(tmpDir, "sub")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(subDir, ".gitignore"), []byte("build/\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewMatcher(tmpDir)
+	if err != nil {
+		t.Fatalf("NewMatcher failed: %v", err)
+	}
+	clone := m.Clone()
+	if err := clone.LoadNestedGitignore("sub"); err != nil {
+		t.Fatalf("LoadNestedGitignore failed: %v", err)
+	}
+	if !clone.Match("sub/build", true) {
+		t.Error("clone should match nested pattern")
+	}
+	if m.Match("sub/build", true) {
+		t.Error("original matcher should not see patterns loaded into the clone")
+	}
+	if (*Matcher)(nil).Clone() != nil {
+		t.Error("Clone of nil matcher should be nil")
+	}
+}
@@ -0,0 +1,31 @@
+// Package pathglob matches slash-separated paths against glob patterns whose
+// segments use path.Match syntax and where "**" spans zero or more segments.
+// It is shared by the sandbox path policy and the grep tool's file filters.
+package pathglob
+
+import "path"
+
+// MatchSegments matches path segments against pattern segments. "a/**"
+// matches "a" itself as well as everything below it.
+func MatchSegments(pattern, parts []string) (bool, error) {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				ok, err := MatchSegments(pattern[1:], parts[i:])
+				if err != nil || ok {
+					return ok, err
+				}
+			}
+			return false, nil
+		}
+		if len(parts) == 0 {
+			return false, nil
+		}
+		ok, err := path.Match(pattern[0], parts[0])
+		if err != nil || !ok {
+			return false, err
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0, nil
+}
@@ -0,0 +1,30 @@
+package pathglob
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchSegments(t *testing.T) {
+	cases := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"**", "a/b", true},
+		{"a/**", "a", true},
+		{"a/**/c", "a/c", true},
+		{"a/**/c", "a/x/y/c", true},
+		{"a/*/c", "a/x/y/c", false},
+		{"*.go", "a.go", true},
+		{"*.go", "a/b.go", false},
+	}
+	for _, tc := range cases {
+		got, err := MatchSegments(strings.Split(tc.pattern, "/"), strings.Split(tc.path, "/"))
+		if err != nil || got != tc.want {
+			t.Errorf("MatchSegments(%q, %q) = %v, %v, want %v", tc.pattern, tc.path, got, err, tc.want)
+		}
+	}
+	if _, err := MatchSegments([]string{"[bad"}, []string{"x"}); err == nil {
+		t.Error("expected invalid pattern error")
+	}
+}
@@ -0,0 +1,76 @@
+// Package pool provides a bounded worker pool for fan-out work that must keep
+// results in input order. It centralises the concurrency pattern shared by the
+// parallel APIs so each package does not reimplement it.
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Func processes the item at index i. Implementations should observe ctx.
+type Func[T any] func(ctx context.Context, i int) (T, error)
+
+// Result captures the outcome of a single item. Items skipped because the
+// context was cancelled carry the context error and a zero Value.
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// Map runs fn for every index in [0, n) using at most limit goroutines and
+// returns one Result per index in input order. A non-positive limit runs every
+// item concurrently. Once ctx is cancelled no further items are started.
+func Map[T any](ctx context.Context, limit, n int, fn Func[T]) []Result[T] {
+	if n <= 0 {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if limit <= 0 || limit > n {
+		limit = n
+	}
+
+	results := make([]Result[T], n)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(limit)
+	for w := 0; w < limit; w++ {
+		go func() {
+			defer wg.Done()
+			for idx := range next {
+				if err := ctx.Err(); err != nil {
+					results[idx] = Result[T]{Err: err}
+					continue
+				}
+				val, err := fn(ctx, idx)
+				results[idx] = Result[T]{Value: val, Err: err}
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// Run is Map with the per-item errors aggregated. Values are returned in input
+// order; the error joins every failure annotated with its index, or is nil
+// when all items succeed.
+func Run[T any](ctx context.Context, limit, n int, fn Func[T]) ([]T, error) {
+	results := Map(ctx, limit, n, fn)
+	values := make([]T, len(results))
+	var errs []error
+	for i, res := range results {
+		values[i] = res.Value
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("item %d: %w", i, res.Err))
+		}
+	}
+	return values, errors.Join(errs...)
+}
@@ -0,0 +1,94 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMapRespectsConcurrencyLimit(t *testing.T) {
+	var active, peak int32
+	_, err := Run(context.Background(), 3, 20, func(ctx context.Context, i int) (int, error) {
+		cur := atomic.AddInt32(&active, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if cur <= old || atomic.CompareAndSwapInt32(&peak, old, cur) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		return i, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&peak); got > 3 || got == 0 {
+		t.Fatalf("expected peak concurrency in (0,3], got %d", got)
+	}
+}
+
+func TestRunPreservesOrder(t *testing.T) {
+	values, err := Run(context.Background(), 4, 10, func(ctx context.Context, i int) (int, error) {
+		time.Sleep(time.Duration(10-i) * time.Millisecond)
+		return i * i, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, v := range values {
+		if v != i*i {
+			t.Fatalf("index %d: expected %d, got %d", i, i*i, v)
+		}
+	}
+}
+
+func TestMapStopsStartingItemsAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var started int32
+	results := Map(ctx, 1, 5, func(ctx context.Context, i int) (int, error) {
+		atomic.AddInt32(&started, 1)
+		if i == 1 {
+			cancel()
+		}
+		return i, nil
+	})
+	if got := atomic.LoadInt32(&started); got != 2 {
+		t.Fatalf("expected 2 items started before cancel, got %d", got)
+	}
+	for i := 2; i < len(results); i++ {
+		if !errors.Is(results[i].Err, context.Canceled) {
+			t.Fatalf("item %d: expected context.Canceled, got %v", i, results[i].Err)
+		}
+	}
+	if results[0].Err != nil || results[0].Value != 0 {
+		t.Fatalf("unexpected first result: %+v", results[0])
+	}
+}
+
+func TestRunAggregatesErrors(t *testing.T) {
+	errOdd := errors.New("odd")
+	values, err := Run(context.Background(), 2, 4, func(ctx context.Context, i int) (string, error) {
+		if i%2 == 1 {
+			return "", errOdd
+		}
+		return "ok", nil
+	})
+	if !errors.Is(err, errOdd) {
+		t.Fatalf("expected joined error to wrap errOdd, got %v", err)
+	}
+	if msg := err.Error(); msg != "item 1: odd\nitem 3: odd" {
+		t.Fatalf("unexpected error message %q", msg)
+	}
+	if values[0] != "ok" || values[2] != "ok" {
+		t.Fatalf("successful values lost: %v", values)
+	}
+}
+
+func TestMapEmptyInput(t *testing.T) {
+	if res := Map(context.Background(), 2, 0, func(context.Context, int) (int, error) { return 0, nil }); res != nil {
+		t.Fatalf("expected nil results, got %v", res)
+	}
+}
@@ -62,6 +62,43 @@ func (h *History) Last() (Message, bool) {
 	return CloneMessage(h.messages[len(h.messages)-1]), true
 }
 
+// LastAssistant returns the newest assistant message when present.
+func (h *History) LastAssistant() (Message, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for i := len(h.messages) - 1; i >= 0; i-- {
+		if h.messages[i].Role == "assistant" {
+			return CloneMessage(h.messages[i]), true
+		}
+	}
+	return Message{}, false
+}
+
+// RecentToolCalls returns up to n of the newest tool calls across all
+// messages, ordered from oldest to newest. Non-positive n returns nil.
+func (h *History) RecentToolCalls(n int) []ToolCall {
+	if n <= 0 {
+		return nil
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var calls []ToolCall
+	for i := len(h.messages) - 1; i >= 0 && len(calls) < n; i-- {
+		tc := h.messages[i].ToolCalls
+		for j := len(tc) - 1; j >= 0 && len(calls) < n; j-- {
+			calls = append(calls, tc[j])
+		}
+	}
+	if len(calls) == 0 {
+		return nil
+	}
+	calls = cloneToolCalls(calls)
+	for l, r := 0, len(calls)-1; l < r; l, r = l+1, r-1 {
+		calls[l], calls[r] = calls[r], calls[l]
+	}
+	return calls
+}
+
 // Len reports the number of stored messages.
 func (h *History) Len() int {
 	h.mu.RLock()
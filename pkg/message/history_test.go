@@ -108,3 +108,38 @@ func TestHistoryTokenCountTracksAppendReplaceAndReset(t *testing.T) {
 		t.Fatalf("TokenCount=%d after Reset, want 0", got)
 	}
 }
+
+func TestHistoryRecentToolCallsAndLastAssistant(t *testing.T) {
+	h := NewHistory()
+	if _, ok := h.LastAssistant(); ok {
+		t.Fatal("expected no assistant message in empty history")
+	}
+	h.Append(Message{Role: "user", Content: "start"})
+	h.Append(Message{Role: "assistant", Content: "first", ToolCalls: []ToolCall{{ID: "a"}, {ID: "b"}}})
+	h.Append(Message{Role: "tool", ToolCalls: []ToolCall{{ID: "b", Result: "ok"}}})
+	h.Append(Message{Role: "assistant", Content: "second", ToolCalls: []ToolCall{{ID: "c", Arguments: map[string]any{"k": "v"}}}})
+	h.Append(Message{Role: "user", Content: "thanks"})
+
+	last, ok := h.LastAssistant()
+	if !ok || last.Content != "second" {
+		t.Fatalf("unexpected last assistant: %+v ok=%v", last, ok)
+	}
+
+	calls := h.RecentToolCalls(3)
+	if len(calls) != 3 || calls[0].ID != "b" || calls[1].ID != "b" || calls[2].ID != "c" {
+		t.Fatalf("unexpected recent calls: %+v", calls)
+	}
+	if calls[1].Result != "ok" {
+		t.Fatalf("expected tool result preserved, got %+v", calls[1])
+	}
+	calls[2].Arguments["k"] = "mutated"
+	if again := h.RecentToolCalls(1); again[0].Arguments["k"] != "v" {
+		t.Fatal("expected RecentToolCalls to return clones")
+	}
+	if all := h.RecentToolCalls(10); len(all) != 4 || all[0].ID != "a" {
+		t.Fatalf("expected all calls oldest first, got %+v", all)
+	}
+	if h.RecentToolCalls(0) != nil {
+		t.Fatal("expected nil for non-positive n")
+	}
+}
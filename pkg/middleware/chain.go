@@ -52,7 +52,9 @@ func (c *Chain) Use(m Middleware) {
 }
 
 // Execute runs the requested stage on all middleware in order. It stops on
-// the first error and returns it.
+// the first error and returns it. Cancellation of ctx is checked before each
+// middleware, so once ctx is done no further middleware runs and ctx.Err()
+// is returned unwrapped.
 func (c *Chain) Execute(ctx context.Context, stage Stage, st *State) error {
 	c.mu.RLock()
 	mws := make([]Middleware, len(c.middlewares))
@@ -60,6 +62,9 @@ func (c *Chain) Execute(ctx context.Context, stage Stage, st *State) error {
 	c.mu.RUnlock()
 
 	for _, mw := range mws {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		var err error
 		exec := func(ctx context.Context) error {
 			switch stage {
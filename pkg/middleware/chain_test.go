@@ -179,6 +179,32 @@ func TestExecuteCancelledContext(t *testing.T) {
 	}
 }
 
+func TestChainStopsWhenCancelledMidChain(t *testing.T) {
+	for _, timeout := range []time.Duration{0, time.Second} {
+		ctx, cancel := context.WithCancel(context.Background())
+		var ran []string
+		chain := NewChain([]Middleware{
+			Funcs{Identifier: "first", OnBeforeModel: func(context.Context, *State) error {
+				ran = append(ran, "first")
+				cancel()
+				return nil
+			}},
+			// A middleware that ignores cancellation must not be reached.
+			Funcs{Identifier: "downstream", OnBeforeModel: func(context.Context, *State) error {
+				ran = append(ran, "downstream")
+				return nil
+			}},
+		}, WithTimeout(timeout))
+		err := chain.Execute(ctx, StageBeforeModel, &State{})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("timeout %s: expected context.Canceled, got %v", timeout, err)
+		}
+		if len(ran) != 1 {
+			t.Fatalf("timeout %s: downstream middleware ran after cancel: %v", timeout, ran)
+		}
+	}
+}
+
 func TestMiddlewareNameVariants(t *testing.T) {
 	if got := middlewareName(nil); got != "<nil>" {
 		t.Fatalf("unexpected nil name: %s", got)
@@ -252,7 +252,7 @@ func cloneTraceHeaders(h http.Header) map[string]string {
 			continue
 		}
 		name := strings.ToLower(k)
-		cloned[name] = maskSensitiveHeader(name, strings.Join(v, ","))
+		cloned[name] = MaskSensitiveHeader(name, strings.Join(v, ","))
 	}
 	if len(cloned) == 0 {
 		return nil
@@ -260,25 +260,19 @@ func cloneTraceHeaders(h http.Header) map[string]string {
 	return cloned
 }
 
-func maskSensitiveHeader(name, value string) string {
+// MaskSensitiveHeader masks API key and Authorization header values with
+// MaskSecret and returns other values unchanged.
+func MaskSensitiveHeader(name, value string) string {
 	if value == "" {
 		return ""
 	}
 	lower := strings.ToLower(name)
 	if strings.Contains(lower, "api-key") || lower == "authorization" {
-		return maskToken(value)
+		return MaskSecret(value)
 	}
 	return value
 }
 
-func maskToken(v string) string {
-	runes := []rune(v)
-	if len(runes) <= 10 {
-		return strings.Repeat("*", len(runes))
-	}
-	return string(runes[:5]) + "..." + string(runes[len(runes)-5:])
-}
-
 func readAndReplaceBody(r *http.Request, limit int64) ([]byte, bool, error) {
 	if r == nil || r.Body == nil || limit == 0 {
 		return nil, false, nil
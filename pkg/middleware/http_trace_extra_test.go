@@ -177,7 +177,7 @@ func TestCloneTraceHeadersAndMasking(t *testing.T) {
 		t.Fatalf("authorization header not masked: %s", cloned["authorization"])
 	}
 
-	if got := maskToken("short"); got != "*****" {
+	if got := MaskSecret("short"); got != "*****" {
 		t.Fatalf("short token masking failed: %s", got)
 	}
 }
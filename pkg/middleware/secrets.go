@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SecretPatterns match common credential shapes: sk-, ghp_/gho_/ghs_, xox*-
+// and AKIA tokens, bearer credentials and Authorization: Basic/Token headers,
+// and the values of NAME=value or "name": value pairs whose name mentions a
+// key, token, secret or password. Patterns with a capturing group mark only
+// that group as the secret. It is a best-effort filter, not a guarantee.
+var SecretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{16,}`),
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{20,}`),
+	regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),
+	regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`(?i)\b(?:bearer|authorization:\s*(?:basic|token))\s+([A-Za-z0-9._~+/=-]{8,})`),
+	regexp.MustCompile(`(?i)\b[A-Z0-9_-]*(?:api[_-]?key|token|secret|password|passwd)[A-Z0-9_]*["']?\s*[=:]\s*("[^"]*"|'[^']*'|[^\s"',;&]+)`),
+}
+
+// RedactSecrets replaces every SecretPatterns match in s with mask(match).
+func RedactSecrets(s string, mask func(string) string) string {
+	return RedactPatterns(s, SecretPatterns, mask)
+}
+
+// RedactPatterns replaces every match of patterns in s with mask(match).
+// When a pattern has a capturing group only the first group is masked, so
+// the key in `api_key=(\S+)` stays readable.
+func RedactPatterns(s string, patterns []*regexp.Regexp, mask func(string) string) string {
+	for _, re := range patterns {
+		if re.NumSubexp() == 0 {
+			s = re.ReplaceAllStringFunc(s, mask)
+			continue
+		}
+		s = replaceFirstGroup(re, s, mask)
+	}
+	return s
+}
+
+func replaceFirstGroup(re *regexp.Regexp, s string, mask func(string) string) string {
+	matches := re.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+	out := make([]byte, 0, len(s))
+	last := 0
+	for _, m := range matches {
+		start, end := m[2], m[3]
+		if start < 0 {
+			continue
+		}
+		out = append(out, s[last:start]...)
+		out = append(out, mask(s[start:end])...)
+		last = end
+	}
+	return string(out) + s[last:]
+}
+
+// MaskSecret hides a secret while keeping enough of its ends to tell values
+// apart: short values are fully starred, longer ones keep five runes per side.
+func MaskSecret(v string) string {
+	runes := []rune(v)
+	if len(runes) <= 10 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:5]) + "..." + string(runes[len(runes)-5:])
+}
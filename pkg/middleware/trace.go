@@ -9,11 +9,13 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/cexll/agentsdk-go/pkg/contextkeys"
 	"github.com/cexll/agentsdk-go/pkg/runtime/skills"
 )
 
@@ -26,6 +28,15 @@ type TraceMiddleware struct {
 	mu          sync.Mutex
 	clock       func() time.Time
 	traceSkills bool
+	sanitizer   PayloadSanitizer
+	// disabled holds stages WithStagesEnabled excluded; empty records all.
+	disabled StageSet
+	// pathTemplate is set by WithPathTemplate; the zero value means log-{id}.
+	pathTemplate PathTemplate
+	// exporter mirrors recorded events into an external tracing system.
+	exporter SpanExporter
+	// redactPatterns overrides DefaultTraceRedactPatterns when non-nil.
+	redactPatterns []*regexp.Regexp
 }
 
 type traceSession struct {
@@ -45,8 +56,12 @@ type TraceContextKey string
 
 const (
 	// TraceSessionIDContextKey stores the trace-specific session identifier.
+	//
+	// Deprecated: use contextkeys.SessionID.
 	TraceSessionIDContextKey TraceContextKey = "trace.session_id"
 	// SessionIDContextKey stores the generic session identifier fallback.
+	//
+	// Deprecated: use contextkeys.SessionID.
 	SessionIDContextKey TraceContextKey = "session_id"
 
 	traceSkillBeforeKey = "trace.skills.before"
@@ -65,6 +80,44 @@ func WithSkillTracing(enabled bool) TraceOption {
 	}
 }
 
+// PayloadSanitizer rewrites a stage's input or output before the default
+// sanitization runs, e.g. to replace a large binary body with a size marker.
+// Returning the value unchanged keeps the default rendering.
+type PayloadSanitizer func(stage Stage, value any) any
+
+// WithPayloadSanitizer installs a hook applied to the agent, model and tool
+// payloads of every recorded event.
+func WithPayloadSanitizer(fn PayloadSanitizer) TraceOption {
+	return func(tm *TraceMiddleware) {
+		tm.sanitizer = fn
+	}
+}
+
+// WithStagesEnabled limits recording to the given stages, e.g.
+// StageAfterModel and StageAfterTool in production. Disabled stages write no
+// JSONL line and trigger no HTML re-render, though durations reported by the
+// enabled After* stages are still measured. All stages are recorded by default.
+func WithStagesEnabled(stages ...Stage) TraceOption {
+	return func(tm *TraceMiddleware) {
+		tm.disabled = AllStages &^ StagesOf(stages...)
+	}
+}
+
+// SpanExporter receives every recorded TraceEvent after sanitization, e.g. to
+// mirror it as OpenTelemetry spans. It is called synchronously from the
+// middleware hook, so implementations should not block.
+type SpanExporter interface {
+	ExportEvent(ctx context.Context, evt TraceEvent)
+}
+
+// WithTelemetry forwards recorded events to exp in addition to the JSONL and
+// HTML output. Stages excluded by WithStagesEnabled are not exported either.
+func WithTelemetry(exp SpanExporter) TraceOption {
+	return func(tm *TraceMiddleware) {
+		tm.exporter = exp
+	}
+}
+
 // NewTraceMiddleware builds a TraceMiddleware that writes to outputDir
 // (defaults to .trace when empty).
 func NewTraceMiddleware(outputDir string, opts ...TraceOption) *TraceMiddleware {
@@ -151,23 +204,32 @@ func (m *TraceMiddleware) record(ctx context.Context, stage Stage, st *State) {
 		return
 	}
 	ensureStateValues(st)
-	sessionID := m.resolveSessionID(ctx, st)
 	now := m.now()
+	if m.disabled.Has(stage) {
+		m.trackDuration(stage, st, now)
+		return
+	}
+	sessionID := m.resolveSessionID(ctx, st)
 	evt := TraceEvent{
 		Timestamp: now,
 		Stage:     stageName(stage),
 		Iteration: st.Iteration,
 		SessionID: sessionID,
 	}
-	evt.Input, evt.Output = stageIO(stage, st)
+	view := m.sanitizedView(stage, st)
+	evt.Input, evt.Output = stageIO(stage, view)
 	evt.Input = sanitizePayload(evt.Input)
 	evt.Output = sanitizePayload(evt.Output)
-	evt.ModelRequest = captureModelRequest(stage, st)
-	evt.ModelResponse = captureModelResponse(stage, st)
-	evt.ToolCall = captureToolCall(stage, st)
-	evt.ToolResult = captureToolResult(stage, st, evt.ToolCall)
+	evt.ModelRequest = captureModelRequest(stage, view)
+	evt.ModelResponse = captureModelResponse(stage, view)
+	evt.ToolCall = captureToolCall(stage, view)
+	evt.ToolResult = captureToolResult(stage, view, evt.ToolCall)
 	evt.Error = captureTraceError(stage, st, evt.ToolResult)
 	evt.DurationMS = m.trackDuration(stage, st, now)
+	m.redactEvent(&evt)
+	if m.exporter != nil {
+		m.exporter.ExportEvent(ctx, evt)
+	}
 
 	sess := m.sessionFor(sessionID)
 	if sess == nil {
@@ -176,6 +238,29 @@ func (m *TraceMiddleware) record(ctx context.Context, stage Stage, st *State) {
 	sess.append(evt, m)
 }
 
+// sanitizedView returns a shallow copy of st whose payload fields have been
+// passed through the configured PayloadSanitizer. The original state is
+// never modified so downstream middleware observes the real values.
+func (m *TraceMiddleware) sanitizedView(stage Stage, st *State) *State {
+	if m.sanitizer == nil {
+		return st
+	}
+	view := *st
+	view.Agent = m.customSanitize(stage, st.Agent)
+	view.ModelInput = m.customSanitize(stage, st.ModelInput)
+	view.ModelOutput = m.customSanitize(stage, st.ModelOutput)
+	view.ToolCall = m.customSanitize(stage, st.ToolCall)
+	view.ToolResult = m.customSanitize(stage, st.ToolResult)
+	return &view
+}
+
+func (m *TraceMiddleware) customSanitize(stage Stage, value any) any {
+	if value == nil {
+		return nil
+	}
+	return m.sanitizer(stage, value)
+}
+
 func (m *TraceMiddleware) sessionFor(id string) *traceSession {
 	if id == "" {
 		id = "session"
@@ -197,19 +282,21 @@ func (m *TraceMiddleware) sessionFor(id string) *traceSession {
 }
 
 func (m *TraceMiddleware) newSessionLocked(id string) (*traceSession, error) {
-	if err := os.MkdirAll(m.outputDir, 0o755); err != nil {
+	now := m.now()
+	timestamp := now.UTC().Format(time.RFC3339)
+	base, err := m.sessionBasePath(id, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(base), 0o755); err != nil {
 		return nil, err
 	}
-	timestamp := m.now().UTC().Format(time.RFC3339)
-	safeID := sanitizeSessionComponent(id)
-	base := fmt.Sprintf("log-%s", safeID)
-	jsonPath := filepath.Join(m.outputDir, base+".jsonl")
-	htmlPath := filepath.Join(m.outputDir, base+".html")
+	jsonPath := base + ".jsonl"
+	htmlPath := base + ".html"
 	file, err := os.OpenFile(jsonPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
 	if err != nil {
 		return nil, err
 	}
-	now := m.now()
 	return &traceSession{
 		id:        id,
 		timestamp: timestamp,
@@ -362,12 +449,17 @@ func (m *TraceMiddleware) resolveSessionID(ctx context.Context, st *State) strin
 			return id
 		}
 	}
+	if id := contextkeys.SessionIDFrom(ctx); id != "" {
+		return id
+	}
 	if id := contextString(ctx, TraceSessionIDContextKey); id != "" {
 		return id
 	}
 	if id := contextString(ctx, SessionIDContextKey); id != "" {
 		return id
 	}
+	// Deprecated: bare string keys collide across packages and are only
+	// honoured for callers that predate contextkeys.
 	if id := contextString(ctx, "trace.session_id"); id != "" {
 		return id
 	}
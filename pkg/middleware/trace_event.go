@@ -298,9 +298,15 @@ func snapshotModelResponse(resp *model.Response) map[string]any {
 		payload["tool_calls"] = resp.Message.ToolCalls
 	}
 	payload["usage"] = usageToMap(resp.Usage)
-	if strings.TrimSpace(resp.StopReason) != "" {
+	if resp.StopReason != "" {
 		payload["stop_reason"] = resp.StopReason
 	}
+	if strings.TrimSpace(resp.RawStopReason) != "" {
+		payload["raw_stop_reason"] = resp.RawStopReason
+	}
+	if resp.Provider != "" {
+		payload["provider"] = resp.Provider
+	}
 	return payload
 }
 
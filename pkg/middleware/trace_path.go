@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultTracePathTemplate reproduces the flat log-<id> layout.
+const defaultTracePathTemplate = "log-{id}"
+
+// PathTemplate is a validated trace file layout; build one with
+// ParsePathTemplate. The zero value is the flat log-{id} layout.
+type PathTemplate struct {
+	tmpl string
+}
+
+// ParsePathTemplate validates tmpl, a slash-separated path without extension
+// relative to the trace output directory. The placeholders {id} (the
+// sanitized session ID), {year}, {month} and {day} are expanded, the date
+// being the UTC time the session's files are first opened; ".jsonl" and
+// ".html" are appended. For example "{year}/{month}/{day}/{id}" yields
+// 2025/01/02/<id>.jsonl.
+//
+// tmpl must contain {id} and must stay inside the output directory: absolute
+// paths and empty, "." or ".." components are rejected.
+func ParsePathTemplate(tmpl string) (PathTemplate, error) {
+	if err := validatePathTemplate(tmpl); err != nil {
+		return PathTemplate{}, fmt.Errorf("trace path template %q: %w", tmpl, err)
+	}
+	return PathTemplate{tmpl: tmpl}, nil
+}
+
+// String returns the template text.
+func (t PathTemplate) String() string {
+	if t.tmpl == "" {
+		return defaultTracePathTemplate
+	}
+	return t.tmpl
+}
+
+// WithPathTemplate lays trace files out under the output directory using
+// tmpl. A session keeps the files it was created with for the middleware's
+// lifetime, even across midnight.
+func WithPathTemplate(tmpl PathTemplate) TraceOption {
+	return func(tm *TraceMiddleware) {
+		tm.pathTemplate = tmpl
+	}
+}
+
+func validatePathTemplate(tmpl string) error {
+	if !strings.Contains(tmpl, "{id}") {
+		return fmt.Errorf("missing {id}")
+	}
+	if strings.HasPrefix(tmpl, "/") || filepath.IsAbs(tmpl) {
+		return fmt.Errorf("must be relative")
+	}
+	for _, part := range strings.Split(tmpl, "/") {
+		if part == "" || part == "." || part == ".." || strings.ContainsRune(part, '\\') {
+			return fmt.Errorf("invalid path component %q", part)
+		}
+	}
+	return nil
+}
+
+// sessionBasePath expands the path template for one session, returning the
+// path without extension.
+func (m *TraceMiddleware) sessionBasePath(id string, at time.Time) (string, error) {
+	tmpl := m.pathTemplate.String()
+	at = at.UTC()
+	rel := strings.NewReplacer(
+		"{id}", sanitizeSessionComponent(id),
+		"{year}", fmt.Sprintf("%04d", at.Year()),
+		"{month}", fmt.Sprintf("%02d", int(at.Month())),
+		"{day}", fmt.Sprintf("%02d", at.Day()),
+	).Replace(tmpl)
+	base := filepath.Join(m.outputDir, filepath.FromSlash(rel))
+	// Placeholders expand to safe components, but check the result anyway.
+	if r, err := filepath.Rel(m.outputDir, base); err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("trace path %q escapes %s", rel, m.outputDir)
+	}
+	return base, nil
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"encoding/json"
+	"regexp"
+)
+
+// DefaultTraceRedactPatterns are the patterns traced payloads are redacted
+// with unless WithRedactPatterns overrides them; see SecretPatterns.
+var DefaultTraceRedactPatterns = SecretPatterns
+
+// sensitiveTraceKey marks object fields whose string values are masked
+// whole whenever redaction is enabled, e.g. {"api_key": "..."}.
+var sensitiveTraceKey = regexp.MustCompile(`(?i)api_?key|token|secret|password|passwd|authorization`)
+
+// WithRedactPatterns replaces DefaultTraceRedactPatterns. Every match in a
+// recorded payload is masked with MaskSecret before it reaches the JSONL log,
+// the HTML viewer or a SpanExporter; when a pattern has a capturing group only
+// the first group is masked, so the key in `api_key=(\S+)` stays readable.
+// String fields whose name looks like a credential (api_key, token, secret,
+// password, authorization) are masked whole. Calling it with no patterns
+// disables redaction.
+func WithRedactPatterns(patterns ...*regexp.Regexp) TraceOption {
+	return func(tm *TraceMiddleware) {
+		tm.redactPatterns = append([]*regexp.Regexp{}, patterns...)
+	}
+}
+
+// redactEvent masks secrets in every payload field of evt.
+func (m *TraceMiddleware) redactEvent(evt *TraceEvent) {
+	patterns := m.redactPatterns
+	if patterns == nil {
+		patterns = DefaultTraceRedactPatterns
+	}
+	if len(patterns) == 0 {
+		return
+	}
+	redact := func(s string) string { return redactString(s, patterns) }
+	evt.Input = redactPayload(evt.Input, patterns, redact)
+	evt.Output = redactPayload(evt.Output, patterns, redact)
+	evt.ModelRequest = redactMap(evt.ModelRequest, patterns, redact)
+	evt.ModelResponse = redactMap(evt.ModelResponse, patterns, redact)
+	evt.ToolCall = redactMap(evt.ToolCall, patterns, redact)
+	evt.ToolResult = redactMap(evt.ToolResult, patterns, redact)
+	evt.Error = redact(evt.Error)
+}
+
+func redactString(s string, patterns []*regexp.Regexp) string {
+	return RedactPatterns(s, patterns, MaskSecret)
+}
+
+// redactPayload masks secrets in an arbitrary payload. Values without a
+// match are returned as is; otherwise the payload is re-encoded through JSON
+// into plain maps, slices and strings so typed structs can be walked too.
+func redactPayload(v any, patterns []*regexp.Regexp, redact func(string) string) any {
+	if v == nil {
+		return nil
+	}
+	if s, ok := v.(string); ok {
+		return redact(s)
+	}
+	data, err := json.Marshal(v)
+	if err != nil || (!anyMatch(string(data), patterns) && !sensitiveTraceKey.Match(data)) {
+		return v
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return v
+	}
+	return redactGeneric(generic, redact)
+}
+
+func redactMap(m map[string]any, patterns []*regexp.Regexp, redact func(string) string) map[string]any {
+	if m == nil {
+		return nil
+	}
+	if out, ok := redactPayload(m, patterns, redact).(map[string]any); ok {
+		return out
+	}
+	return m
+}
+
+func redactGeneric(v any, redact func(string) string) any {
+	switch val := v.(type) {
+	case string:
+		return redact(val)
+	case map[string]any:
+		for k, item := range val {
+			if str, ok := item.(string); ok && sensitiveTraceKey.MatchString(k) {
+				val[k] = MaskSecret(str)
+				continue
+			}
+			val[k] = redactGeneric(item, redact)
+		}
+		return val
+	case []any:
+		for i, item := range val {
+			val[i] = redactGeneric(item, redact)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+func anyMatch(s string, patterns []*regexp.Regexp) bool {
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
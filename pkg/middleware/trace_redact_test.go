@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/cexll/agentsdk-go/pkg/contextkeys"
+	"github.com/cexll/agentsdk-go/pkg/model"
+	"github.com/cexll/agentsdk-go/pkg/tool"
+)
+
+const traceTestSecret = "sk-abcdefghijklmnopqrstuvwxyz123456"
+
+func TestTraceMiddlewareRedactsSecrets(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTraceMiddleware(t.TempDir())
+	defer tm.Close()
+	ctx := contextkeys.WithSessionID(context.Background(), "redact")
+	st := &State{
+		Iteration: 1,
+		Values:    map[string]any{},
+		ModelInput: &model.Request{Messages: []model.Message{
+			{Role: "user", Content: "use key " + traceTestSecret + " please"},
+		}},
+		ToolCall: tool.Call{Name: "http", Params: map[string]any{
+			"api_key": "plain-value-1234567",
+			"header":  "Authorization: Bearer abcdefghijklmnop",
+			"query":   "token=qwertyuiopasdfgh&x=1",
+		}},
+	}
+	if err := tm.BeforeModel(ctx, st); err != nil {
+		t.Fatalf("before model: %v", err)
+	}
+	if err := tm.BeforeTool(ctx, st); err != nil {
+		t.Fatalf("before tool: %v", err)
+	}
+
+	sess := tm.sessions["redact"]
+	raw, err := os.ReadFile(sess.jsonPath)
+	if err != nil {
+		t.Fatalf("read jsonl: %v", err)
+	}
+	for _, secret := range []string{traceTestSecret, "plain-value-1234567", "abcdefghijklmnop", "qwertyuiopasdfgh"} {
+		if strings.Contains(string(raw), secret) {
+			t.Fatalf("secret %q leaked into jsonl: %s", secret, raw)
+		}
+	}
+	for _, kept := range []string{"use key sk-ab...23456 please", "Authorization: Bearer abcde...lmnop", "token=qwert...sdfgh"} {
+		if !strings.Contains(string(raw), kept) {
+			t.Fatalf("expected %q in jsonl: %s", kept, raw)
+		}
+	}
+	if st.ModelInput.(*model.Request).Messages[0].Content != "use key "+traceTestSecret+" please" {
+		t.Fatal("state payload must not be modified")
+	}
+}
+
+func TestTraceMiddlewareRedactPatternsOption(t *testing.T) {
+	t.Parallel()
+
+	custom := NewTraceMiddleware(t.TempDir(), WithRedactPatterns(regexp.MustCompile(`internal-id-(\d+)`)))
+	defer custom.Close()
+	evt := TraceEvent{Input: "internal-id-1234567890123 " + traceTestSecret, Error: "internal-id-42"}
+	custom.redactEvent(&evt)
+	if evt.Input != "internal-id-12345...90123 "+traceTestSecret {
+		t.Fatalf("unexpected custom redaction %q", evt.Input)
+	}
+	if evt.Error != "internal-id-**" {
+		t.Fatalf("unexpected error redaction %q", evt.Error)
+	}
+
+	disabled := NewTraceMiddleware(t.TempDir(), WithRedactPatterns())
+	defer disabled.Close()
+	evt = TraceEvent{Input: traceTestSecret, ToolCall: map[string]any{"api_key": "plain-value-1234567"}}
+	disabled.redactEvent(&evt)
+	if evt.Input != traceTestSecret || evt.ToolCall["api_key"] != "plain-value-1234567" {
+		t.Fatalf("expected redaction disabled, got %+v", evt)
+	}
+}
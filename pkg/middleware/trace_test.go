@@ -1,10 +1,16 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/cexll/agentsdk-go/pkg/contextkeys"
 )
 
 func TestTraceMiddlewareRecords(t *testing.T) {
@@ -39,3 +45,198 @@ func TestTraceMiddlewareRecords(t *testing.T) {
 		t.Fatalf("expected jsonl trace output")
 	}
 }
+
+type recordingExporter struct{ events []TraceEvent }
+
+func (r *recordingExporter) ExportEvent(_ context.Context, evt TraceEvent) {
+	r.events = append(r.events, evt)
+}
+
+func TestTraceMiddlewareTelemetryExporter(t *testing.T) {
+	t.Parallel()
+
+	exp := &recordingExporter{}
+	tm := NewTraceMiddleware(t.TempDir(),
+		WithTelemetry(exp),
+		WithStagesEnabled(StageBeforeTool, StageAfterTool),
+		WithPayloadSanitizer(func(_ Stage, value any) any { return "masked" }),
+	)
+	defer tm.Close()
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "sess")
+	st := &State{Iteration: 2, Values: map[string]any{}, ToolCall: map[string]any{"name": "bash", "secret": "x"}}
+	for _, hook := range []func(context.Context, *State) error{tm.BeforeAgent, tm.BeforeTool, tm.AfterTool, tm.AfterAgent} {
+		if err := hook(ctx, st); err != nil {
+			t.Fatalf("hook failed: %v", err)
+		}
+	}
+
+	if len(exp.events) != 2 {
+		t.Fatalf("expected only enabled stages exported, got %d", len(exp.events))
+	}
+	for i, stage := range []string{"before_tool", "after_tool"} {
+		evt := exp.events[i]
+		if evt.Stage != stage || evt.SessionID != "sess" || evt.Iteration != 2 {
+			t.Fatalf("unexpected event %d: %+v", i, evt)
+		}
+		if evt.Input != "masked" {
+			t.Fatalf("expected sanitized input, got %v", evt.Input)
+		}
+	}
+}
+
+func TestTraceMiddlewareResolvesTypedSessionKey(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTraceMiddleware(t.TempDir())
+	defer tm.Close()
+	ctx := contextkeys.WithSessionID(context.Background(), "typed-sess")
+	if got := tm.resolveSessionID(ctx, nil); got != "typed-sess" {
+		t.Fatalf("expected typed session id, got %q", got)
+	}
+}
+
+func TestTraceMiddlewarePayloadSanitizer(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	var stages []Stage
+	tm := NewTraceMiddleware(dir, WithPayloadSanitizer(func(stage Stage, value any) any {
+		stages = append(stages, stage)
+		if b, ok := value.([]byte); ok && len(b) > 1024 {
+			return fmt.Sprintf("[binary %d bytes]", len(b))
+		}
+		return value
+	}))
+	defer tm.Close()
+
+	ctx := contextkeys.WithSessionID(context.Background(), "sanitize")
+	st := &State{
+		Iteration:  1,
+		ToolCall:   map[string]any{"name": "download"},
+		ToolResult: bytes.Repeat([]byte{0xff}, 4096),
+		Values:     map[string]any{},
+	}
+	if err := tm.AfterTool(ctx, st); err != nil {
+		t.Fatalf("after tool failed: %v", err)
+	}
+
+	sess := tm.sessions["sanitize"]
+	if sess == nil || len(sess.events) != 1 {
+		t.Fatalf("expected one recorded event, got %+v", sess)
+	}
+	evt := sess.events[0]
+	if evt.Output != "[binary 4096 bytes]" {
+		t.Fatalf("expected placeholder output, got %v", evt.Output)
+	}
+	if evt.Input == nil {
+		t.Fatalf("expected unchanged input to keep default rendering")
+	}
+	if evt.ToolResult["raw"] != "[binary 4096 bytes]" {
+		t.Fatalf("expected placeholder in tool result, got %v", evt.ToolResult)
+	}
+	if len(stages) != 2 || stages[0] != StageAfterTool {
+		t.Fatalf("unexpected sanitizer stages: %v", stages)
+	}
+	if b, ok := st.ToolResult.([]byte); !ok || len(b) != 4096 {
+		t.Fatalf("state payload must not be modified")
+	}
+	raw, err := os.ReadFile(sess.jsonPath)
+	if err != nil {
+		t.Fatalf("read jsonl: %v", err)
+	}
+	if !strings.Contains(string(raw), "[binary 4096 bytes]") {
+		t.Fatalf("placeholder missing from jsonl: %s", raw)
+	}
+}
+
+func TestTraceMiddlewareStagesEnabled(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	tm := NewTraceMiddleware(dir, WithStagesEnabled(StageAfterModel, StageAfterTool))
+	defer tm.Close()
+
+	ctx := contextkeys.WithSessionID(context.Background(), "subset")
+	st := &State{Iteration: 1, Values: map[string]any{}}
+	calls := []func(context.Context, *State) error{
+		tm.BeforeAgent, tm.BeforeModel, tm.AfterModel, tm.BeforeTool, tm.AfterTool, tm.AfterAgent,
+	}
+	for _, call := range calls {
+		if err := call(ctx, st); err != nil {
+			t.Fatalf("stage failed: %v", err)
+		}
+	}
+
+	sess := tm.sessions["subset"]
+	if sess == nil || len(sess.events) != 2 {
+		t.Fatalf("expected two recorded events, got %+v", sess)
+	}
+	if sess.events[0].Stage != stageName(StageAfterModel) || sess.events[1].Stage != stageName(StageAfterTool) {
+		t.Fatalf("unexpected stages recorded: %s, %s", sess.events[0].Stage, sess.events[1].Stage)
+	}
+	raw, err := os.ReadFile(sess.jsonPath)
+	if err != nil {
+		t.Fatalf("read jsonl: %v", err)
+	}
+	if lines := strings.Count(strings.TrimSpace(string(raw)), "\n") + 1; lines != 2 {
+		t.Fatalf("expected two jsonl lines, got %d", lines)
+	}
+
+	none := NewTraceMiddleware(t.TempDir(), WithStagesEnabled())
+	defer none.Close()
+	if err := none.AfterModel(ctx, &State{Values: map[string]any{}}); err != nil {
+		t.Fatalf("after model: %v", err)
+	}
+	if len(none.sessions) != 0 {
+		t.Fatalf("expected no sessions when every stage is disabled")
+	}
+}
+
+func TestTraceMiddlewarePathTemplate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	tmpl, err := ParsePathTemplate("{year}/{month}/{day}/{id}")
+	if err != nil {
+		t.Fatalf("parse template: %v", err)
+	}
+	tm := NewTraceMiddleware(dir, WithPathTemplate(tmpl))
+	defer tm.Close()
+	day := time.Date(2025, 1, 2, 23, 59, 0, 0, time.UTC)
+	tm.clock = func() time.Time { return day }
+
+	ctx := contextkeys.WithSessionID(context.Background(), "../../etc/passwd")
+	st := &State{Iteration: 1, Values: map[string]any{}}
+	if err := tm.BeforeAgent(ctx, st); err != nil {
+		t.Fatalf("before agent: %v", err)
+	}
+	// Later writes after midnight stay in the session's original files.
+	day = day.Add(time.Hour)
+	if err := tm.AfterAgent(ctx, st); err != nil {
+		t.Fatalf("after agent: %v", err)
+	}
+
+	base := filepath.Join(dir, "2025", "01", "02", "etc-passwd")
+	raw, err := os.ReadFile(base + ".jsonl")
+	if err != nil {
+		t.Fatalf("expected dated jsonl: %v", err)
+	}
+	if lines := strings.Count(strings.TrimSpace(string(raw)), "\n") + 1; lines != 2 {
+		t.Fatalf("expected both events in one file, got %d lines", lines)
+	}
+	if _, err := os.Stat(base + ".html"); err != nil {
+		t.Fatalf("expected dated html: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2025", "01", "03")); !os.IsNotExist(err) {
+		t.Fatalf("expected no directory for the second day, got %v", err)
+	}
+
+	for _, bad := range []string{"{year}/../{id}", "/abs/{id}", "{year}/{month}", "a//{id}", "../{id}"} {
+		if _, err := ParsePathTemplate(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+	if got := (PathTemplate{}).String(); got != "log-{id}" {
+		t.Fatalf("expected zero template to be the flat layout, got %q", got)
+	}
+}
@@ -14,6 +14,28 @@ const (
 	StageAfterAgent
 )
 
+// StageSet is a bitset of stages.
+type StageSet uint8
+
+// AllStages contains every Stage.
+const AllStages StageSet = 1<<(StageAfterAgent+1) - 1
+
+// StagesOf builds a StageSet from the given stages.
+func StagesOf(stages ...Stage) StageSet {
+	var set StageSet
+	for _, stage := range stages {
+		if stage >= StageBeforeAgent && stage <= StageAfterAgent {
+			set |= 1 << stage
+		}
+	}
+	return set
+}
+
+// Has reports whether stage is in the set.
+func (s StageSet) Has(stage Stage) bool {
+	return stage >= StageBeforeAgent && stage <= StageAfterAgent && s&(1<<stage) != 0
+}
+
 // State carries mutable execution data shared across middleware invocations.
 // The concrete types stored in these fields are left to callers; middleware
 // should type-assert to what it expects.
@@ -29,6 +51,10 @@ type State struct {
 
 // Middleware defines all six interception points. Implementations may
 // no-op individual methods when the hook is not needed.
+//
+// Hooks must honor cancellation: check ctx.Err() before expensive work and
+// return the context error (wrapped with %w if at all) instead of swallowing
+// it. The Chain stops at the first error and runs no later middleware.
 type Middleware interface {
 	Name() string
 	BeforeAgent(ctx context.Context, st *State) error
@@ -28,6 +28,9 @@ type AnthropicConfig struct {
 	System      string
 	Temperature *float64
 	HTTPClient  *http.Client
+	// StopReasons overrides how raw stop_reason values map to canonical
+	// StopReason values, e.g. for proxies that emit non-standard strings.
+	StopReasons map[string]StopReason
 }
 
 type anthropicMessages interface {
@@ -44,6 +47,7 @@ type anthropicModel struct {
 	system           string
 	temperature      *float64
 	configuredAPIKey string
+	stopReasons      map[string]StopReason
 }
 
 var anthropicPredefinedHeaders = map[string]string{
@@ -164,6 +168,7 @@ func NewAnthropic(cfg AnthropicConfig) (Model, error) {
 		system:           strings.TrimSpace(cfg.System),
 		temperature:      cfg.Temperature,
 		configuredAPIKey: apiKey,
+		stopReasons:      copyStopReasons(cfg.StopReasons),
 	}, nil
 }
 
@@ -185,9 +190,10 @@ func (m *anthropicModel) Complete(ctx context.Context, req Request) (*Response,
 
 		usage := convertUsage(msg.Usage)
 		resp = &Response{
-			Message:    convertResponseMessage(*msg),
-			Usage:      usage,
-			StopReason: string(msg.StopReason),
+			Message:       convertResponseMessage(*msg),
+			Usage:         usage,
+			StopReason:    string(normalizeStopReason(string(msg.StopReason), anthropicStopReasons, m.stopReasons)),
+			RawStopReason: string(msg.StopReason),
 		}
 		recordModelResponse(ctx, resp)
 		return nil
@@ -259,9 +265,10 @@ func (m *anthropicModel) CompleteStream(ctx context.Context, req Request, cb Str
 		}
 
 		resp := &Response{
-			Message:    convertResponseMessage(final),
-			Usage:      usageFromFallback(final.Usage, usage),
-			StopReason: string(final.StopReason),
+			Message:       convertResponseMessage(final),
+			Usage:         usageFromFallback(final.Usage, usage),
+			StopReason:    string(normalizeStopReason(string(final.StopReason), anthropicStopReasons, m.stopReasons)),
+			RawStopReason: string(final.StopReason),
 		}
 		recordModelResponse(ctx, resp)
 		return cb(StreamResult{Final: true, Response: resp})
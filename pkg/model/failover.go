@@ -0,0 +1,130 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	anthropicsdk "github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go"
+)
+
+var (
+	// ErrUnavailable may be wrapped by custom models to mark an error as an
+	// availability failure that a failover chain should route around.
+	ErrUnavailable = errors.New("model: provider unavailable")
+	// ErrNoFailoverCandidates is returned by NewFailover for an empty chain.
+	ErrNoFailoverCandidates = errors.New("model: failover chain has no providers")
+)
+
+// FailoverCandidate is one named provider in a failover chain.
+type FailoverCandidate struct {
+	Name     string
+	Provider Provider
+}
+
+// NewFailover builds a Model that sends each request to the first candidate
+// and moves to the next when the provider cannot be built or the call fails
+// with an availability error (see IsUnavailable). Other errors are returned
+// as-is. A stream is only failed over if nothing was delivered to the
+// handler yet. The serving candidate's name is stored in Response.Provider.
+func NewFailover(candidates ...FailoverCandidate) (Model, error) {
+	chain := make([]FailoverCandidate, 0, len(candidates))
+	for i, c := range candidates {
+		if c.Provider == nil {
+			continue
+		}
+		if strings.TrimSpace(c.Name) == "" {
+			c.Name = fmt.Sprintf("provider-%d", i)
+		}
+		chain = append(chain, c)
+	}
+	if len(chain) == 0 {
+		return nil, ErrNoFailoverCandidates
+	}
+	return &failoverModel{candidates: chain}, nil
+}
+
+type failoverModel struct {
+	candidates []FailoverCandidate
+}
+
+func (f *failoverModel) Complete(ctx context.Context, req Request) (*Response, error) {
+	var errs []error
+	for _, c := range f.candidates {
+		mdl, err := c.Provider.Model(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.Name, err))
+			continue
+		}
+		resp, err := mdl.Complete(ctx, req)
+		if err == nil {
+			if resp != nil {
+				resp.Provider = c.Name
+			}
+			return resp, nil
+		}
+		if ctx.Err() != nil || !IsUnavailable(err) {
+			return nil, err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", c.Name, err))
+	}
+	return nil, fmt.Errorf("model: all providers failed: %w", errors.Join(errs...))
+}
+
+func (f *failoverModel) CompleteStream(ctx context.Context, req Request, cb StreamHandler) error {
+	var errs []error
+	for _, c := range f.candidates {
+		mdl, err := c.Provider.Model(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.Name, err))
+			continue
+		}
+		delivered := false
+		err = mdl.CompleteStream(ctx, req, func(sr StreamResult) error {
+			delivered = true
+			if sr.Response != nil {
+				sr.Response.Provider = c.Name
+			}
+			return cb(sr)
+		})
+		if err == nil {
+			return nil
+		}
+		if delivered || ctx.Err() != nil || !IsUnavailable(err) {
+			return err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", c.Name, err))
+	}
+	return fmt.Errorf("model: all providers failed: %w", errors.Join(errs...))
+}
+
+// IsUnavailable reports whether err means the provider could not serve the
+// request (timeouts, rate limits, 5xx, transport failures) rather than that
+// the request itself was rejected. Caller cancellation never counts.
+func IsUnavailable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrUnavailable) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var anthropicErr *anthropicsdk.Error
+	if errors.As(err, &anthropicErr) {
+		return unavailableStatus(anthropicErr.StatusCode)
+	}
+	var openaiErr *openai.Error
+	if errors.As(err, &openaiErr) {
+		return unavailableStatus(openaiErr.StatusCode)
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func unavailableStatus(code int) bool {
+	return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
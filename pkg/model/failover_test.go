@@ -0,0 +1,141 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	anthropicsdk "github.com/anthropics/anthropic-sdk-go"
+)
+
+type failoverStub struct {
+	name  string
+	err   error
+	calls int
+	// partial emits a delta before failing.
+	partial bool
+}
+
+func (s *failoverStub) Complete(context.Context, Request) (*Response, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &Response{Message: Message{Role: "assistant", Content: s.name}}, nil
+}
+
+func (s *failoverStub) CompleteStream(ctx context.Context, req Request, cb StreamHandler) error {
+	if s.partial {
+		if err := cb(StreamResult{Delta: "partial"}); err != nil {
+			return err
+		}
+	}
+	resp, err := s.Complete(ctx, req)
+	if err != nil {
+		return err
+	}
+	return cb(StreamResult{Final: true, Response: resp})
+}
+
+func fixedProvider(m Model) Provider {
+	return ProviderFunc(func(context.Context) (Model, error) { return m, nil })
+}
+
+func TestFailoverSwitchesOnUnavailable(t *testing.T) {
+	primary := &failoverStub{name: "primary", err: fmt.Errorf("overloaded: %w", ErrUnavailable)}
+	secondary := &failoverStub{name: "secondary"}
+	mdl, err := NewFailover(
+		FailoverCandidate{Name: "a", Provider: fixedProvider(primary)},
+		FailoverCandidate{Name: "b", Provider: fixedProvider(secondary)},
+	)
+	if err != nil {
+		t.Fatalf("NewFailover: %v", err)
+	}
+
+	resp, err := mdl.Complete(context.Background(), Request{})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if resp.Provider != "b" || resp.Message.Content != "secondary" {
+		t.Fatalf("expected secondary to serve, got %+v", resp)
+	}
+
+	var final *Response
+	if err := mdl.CompleteStream(context.Background(), Request{}, func(sr StreamResult) error {
+		if sr.Final {
+			final = sr.Response
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("CompleteStream: %v", err)
+	}
+	if final == nil || final.Provider != "b" {
+		t.Fatalf("expected streamed response from b, got %+v", final)
+	}
+}
+
+func TestFailoverKeepsRequestErrors(t *testing.T) {
+	bad := errors.New("invalid request")
+	primary := &failoverStub{err: bad}
+	secondary := &failoverStub{name: "secondary"}
+	mdl, _ := NewFailover(
+		FailoverCandidate{Provider: fixedProvider(primary)},
+		FailoverCandidate{Provider: fixedProvider(secondary)},
+	)
+	if _, err := mdl.Complete(context.Background(), Request{}); !errors.Is(err, bad) {
+		t.Fatalf("expected request error to surface, got %v", err)
+	}
+	if secondary.calls != 0 {
+		t.Fatalf("secondary should not be tried for request errors")
+	}
+}
+
+func TestFailoverStreamDoesNotSwitchAfterDelivery(t *testing.T) {
+	primary := &failoverStub{err: ErrUnavailable, partial: true}
+	secondary := &failoverStub{name: "secondary"}
+	mdl, _ := NewFailover(
+		FailoverCandidate{Provider: fixedProvider(primary)},
+		FailoverCandidate{Provider: fixedProvider(secondary)},
+	)
+	err := mdl.CompleteStream(context.Background(), Request{}, func(StreamResult) error { return nil })
+	if !errors.Is(err, ErrUnavailable) || secondary.calls != 0 {
+		t.Fatalf("expected partial stream error without failover, err=%v calls=%d", err, secondary.calls)
+	}
+}
+
+func TestFailoverProviderBuildErrorAndExhaustion(t *testing.T) {
+	broken := ProviderFunc(func(context.Context) (Model, error) { return nil, errors.New("no api key") })
+	down := &failoverStub{err: ErrUnavailable}
+	mdl, _ := NewFailover(FailoverCandidate{Name: "x", Provider: broken}, FailoverCandidate{Name: "y", Provider: fixedProvider(down)})
+	_, err := mdl.Complete(context.Background(), Request{})
+	if err == nil || !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected joined availability error, got %v", err)
+	}
+
+	if _, err := NewFailover(FailoverCandidate{Name: "nil"}); !errors.Is(err, ErrNoFailoverCandidates) {
+		t.Fatalf("expected ErrNoFailoverCandidates, got %v", err)
+	}
+}
+
+func TestIsUnavailable(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{context.Canceled, false},
+		{errors.New("bad input"), false},
+		{io.ErrUnexpectedEOF, true},
+		{&anthropicsdk.Error{StatusCode: http.StatusTooManyRequests}, true},
+		{&anthropicsdk.Error{StatusCode: 529}, true},
+		{&anthropicsdk.Error{StatusCode: http.StatusBadRequest}, false},
+	}
+	for _, tc := range cases {
+		if got := IsUnavailable(tc.err); got != tc.want {
+			t.Errorf("IsUnavailable(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
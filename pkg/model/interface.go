@@ -95,9 +95,16 @@ type Usage struct {
 
 // Response wraps the final assistant message and accounting.
 type Response struct {
-	Message    Message
-	Usage      Usage
+	Message Message
+	Usage   Usage
+	// StopReason is the canonical reason the completion ended, one of the
+	// StopReason* constants; see Reason for the typed value.
 	StopReason string
+	// RawStopReason preserves the provider's original value.
+	RawStopReason string
+	// Provider names the failover candidate that served the response; it is
+	// empty when the model is not a failover chain.
+	Provider string
 }
 
 // StreamResult delivers incremental updates during streaming calls.
@@ -50,5 +50,8 @@ func recordModelResponse(ctx context.Context, resp *Response) {
 		if trimmed := strings.TrimSpace(resp.StopReason); trimmed != "" {
 			state.SetValue("model.stop_reason", trimmed)
 		}
+		if trimmed := strings.TrimSpace(resp.RawStopReason); trimmed != "" {
+			state.SetValue("model.raw_stop_reason", trimmed)
+		}
 	}
 }
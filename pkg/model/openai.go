@@ -27,6 +27,9 @@ type OpenAIConfig struct {
 	Temperature  *float64
 	HTTPClient   *http.Client
 	UseResponses bool // true = /responses API, false = /chat/completions
+	// StopReasons overrides how raw finish_reason/status values map to
+	// canonical StopReason values.
+	StopReasons map[string]StopReason
 }
 
 type openaiChatCompletions interface {
@@ -41,6 +44,7 @@ type openaiModel struct {
 	maxRetries  int
 	system      string
 	temperature *float64
+	stopReasons map[string]StopReason
 }
 
 const (
@@ -88,6 +92,7 @@ func NewOpenAI(cfg OpenAIConfig) (Model, error) {
 		maxRetries:  retries,
 		system:      strings.TrimSpace(cfg.System),
 		temperature: cfg.Temperature,
+		stopReasons: copyStopReasons(cfg.StopReasons),
 	}, nil
 }
 
@@ -107,6 +112,7 @@ func (m *openaiModel) Complete(ctx context.Context, req Request) (*Response, err
 		}
 
 		resp = convertOpenAIResponse(completion)
+		resp.StopReason = string(normalizeStopReason(resp.RawStopReason, openaiStopReasons, m.stopReasons))
 		recordModelResponse(ctx, resp)
 		return nil
 	})
@@ -232,8 +238,9 @@ func (m *openaiModel) CompleteStream(ctx context.Context, req Request, cb Stream
 				ToolCalls:        toolCalls,
 				ReasoningContent: accumulatedReasoning.String(),
 			},
-			Usage:      finalUsage,
-			StopReason: finishReason,
+			Usage:         finalUsage,
+			StopReason:    string(normalizeStopReason(finishReason, openaiStopReasons, m.stopReasons)),
+			RawStopReason: finishReason,
 		}
 		recordModelResponse(ctx, resp)
 		return cb(StreamResult{Final: true, Response: resp})
@@ -598,8 +605,9 @@ func convertOpenAIResponse(completion *openai.ChatCompletion) *Response {
 			ToolCalls:        toolCalls,
 			ReasoningContent: reasoningContent,
 		},
-		Usage:      convertOpenAIUsage(completion.Usage),
-		StopReason: choice.FinishReason,
+		Usage:         convertOpenAIUsage(completion.Usage),
+		StopReason:    string(normalizeStopReason(choice.FinishReason, openaiStopReasons, nil)),
+		RawStopReason: choice.FinishReason,
 	}
 }
 
@@ -22,6 +22,7 @@ type openaiResponsesModel struct {
 	maxRetries  int
 	system      string
 	temperature *float64
+	stopReasons map[string]StopReason
 }
 
 type openaiResponsesService interface {
@@ -68,6 +69,7 @@ func NewOpenAIResponses(cfg OpenAIConfig) (Model, error) {
 		maxRetries:  retries,
 		system:      strings.TrimSpace(cfg.System),
 		temperature: cfg.Temperature,
+		stopReasons: copyStopReasons(cfg.StopReasons),
 	}, nil
 }
 
@@ -84,6 +86,7 @@ func (m *openaiResponsesModel) Complete(ctx context.Context, req Request) (*Resp
 		}
 
 		resp = convertResponsesAPIResponse(response)
+		resp.StopReason = string(normalizeStopReason(resp.RawStopReason, openaiStopReasons, m.stopReasons))
 		recordModelResponse(ctx, resp)
 		return nil
 	})
@@ -204,8 +207,9 @@ func (m *openaiResponsesModel) CompleteStream(ctx context.Context, req Request,
 				Content:   accumulatedContent.String(),
 				ToolCalls: toolCalls,
 			},
-			Usage:      finalUsage,
-			StopReason: stopReason,
+			Usage:         finalUsage,
+			StopReason:    string(normalizeStopReason(stopReason, openaiStopReasons, m.stopReasons)),
+			RawStopReason: stopReason,
 		}
 		recordModelResponse(ctx, resp)
 		return cb(StreamResult{Final: true, Response: resp})
@@ -503,8 +507,9 @@ func convertResponsesAPIResponse(resp *responses.Response) *Response {
 			Content:   content.String(),
 			ToolCalls: toolCalls,
 		},
-		Usage:      convertResponsesUsage(resp.Usage),
-		StopReason: stopReason,
+		Usage:         convertResponsesUsage(resp.Usage),
+		StopReason:    string(normalizeStopReason(stopReason, openaiStopReasons, nil)),
+		RawStopReason: stopReason,
 	}
 }
 
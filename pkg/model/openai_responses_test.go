@@ -626,7 +626,7 @@ func TestConvertResponsesAPIResponse(t *testing.T) {
 			assert.Equal(t, tt.wantRole, resp.Message.Role)
 			assert.Equal(t, tt.wantContent, resp.Message.Content)
 			assert.Len(t, resp.Message.ToolCalls, tt.wantToolCalls)
-			assert.Equal(t, tt.wantStopReason, resp.StopReason)
+			assert.Equal(t, tt.wantStopReason, resp.RawStopReason)
 
 			// Verify tool call details for function_call tests
 			if tt.wantToolCalls > 0 {
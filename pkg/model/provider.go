@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 	"sync"
@@ -36,6 +37,8 @@ type AnthropicProvider struct {
 	System      string
 	Temperature *float64
 	CacheTTL    time.Duration
+	// HTTPClient is used for API calls; nil uses the SDK default.
+	HTTPClient *http.Client
 
 	mu      sync.RWMutex
 	cached  Model
@@ -66,6 +69,7 @@ func (p *AnthropicProvider) Model(ctx context.Context) (Model, error) {
 		MaxRetries:  p.MaxRetries,
 		System:      p.System,
 		Temperature: p.Temperature,
+		HTTPClient:  p.HTTPClient,
 	})
 	if err != nil {
 		return nil, err
@@ -92,6 +96,26 @@ func (p *AnthropicProvider) resolveAPIKey() string {
 	return ""
 }
 
+// WithHTTPClient returns a copy of p that uses c for API calls. p is
+// returned unchanged when c is nil or p already has its own client, so a
+// provider shared between runtimes is never mutated.
+func (p *AnthropicProvider) WithHTTPClient(c *http.Client) Provider {
+	if p == nil || c == nil || p.HTTPClient != nil {
+		return p
+	}
+	return &AnthropicProvider{
+		APIKey:      p.APIKey,
+		BaseURL:     p.BaseURL,
+		ModelName:   p.ModelName,
+		MaxTokens:   p.MaxTokens,
+		MaxRetries:  p.MaxRetries,
+		System:      p.System,
+		Temperature: p.Temperature,
+		CacheTTL:    p.CacheTTL,
+		HTTPClient:  c,
+	}
+}
+
 func (p *AnthropicProvider) cachedModel() Model {
 	if p.CacheTTL <= 0 {
 		return nil
@@ -124,6 +148,8 @@ type OpenAIProvider struct {
 	System      string
 	Temperature *float64
 	CacheTTL    time.Duration
+	// HTTPClient is used for API calls; nil uses the SDK default.
+	HTTPClient *http.Client
 
 	mu      sync.RWMutex
 	cached  Model
@@ -154,6 +180,7 @@ func (p *OpenAIProvider) Model(ctx context.Context) (Model, error) {
 		MaxRetries:  p.MaxRetries,
 		System:      p.System,
 		Temperature: p.Temperature,
+		HTTPClient:  p.HTTPClient,
 	})
 	if err != nil {
 		return nil, err
@@ -177,6 +204,26 @@ func (p *OpenAIProvider) resolveAPIKey() string {
 	return ""
 }
 
+// WithHTTPClient returns a copy of p that uses c for API calls. p is
+// returned unchanged when c is nil or p already has its own client, so a
+// provider shared between runtimes is never mutated.
+func (p *OpenAIProvider) WithHTTPClient(c *http.Client) Provider {
+	if p == nil || c == nil || p.HTTPClient != nil {
+		return p
+	}
+	return &OpenAIProvider{
+		APIKey:      p.APIKey,
+		BaseURL:     p.BaseURL,
+		ModelName:   p.ModelName,
+		MaxTokens:   p.MaxTokens,
+		MaxRetries:  p.MaxRetries,
+		System:      p.System,
+		Temperature: p.Temperature,
+		CacheTTL:    p.CacheTTL,
+		HTTPClient:  c,
+	}
+}
+
 func (p *OpenAIProvider) cachedModel() Model {
 	if p.CacheTTL <= 0 {
 		return nil
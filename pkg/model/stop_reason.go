@@ -0,0 +1,72 @@
+package model
+
+import "strings"
+
+// StopReason is the provider-agnostic reason a completion ended.
+type StopReason string
+
+const (
+	StopReasonEndTurn      StopReason = "end_turn"
+	StopReasonToolUse      StopReason = "tool_use"
+	StopReasonMaxTokens    StopReason = "max_tokens"
+	StopReasonStopSequence StopReason = "stop_sequence"
+)
+
+// Reason returns the canonical stop reason as a typed value.
+func (r *Response) Reason() StopReason {
+	if r == nil {
+		return ""
+	}
+	return StopReason(r.StopReason)
+}
+
+// anthropicStopReasons maps Messages API stop_reason values.
+var anthropicStopReasons = map[string]StopReason{
+	"end_turn":      StopReasonEndTurn,
+	"tool_use":      StopReasonToolUse,
+	"max_tokens":    StopReasonMaxTokens,
+	"stop_sequence": StopReasonStopSequence,
+	"pause_turn":    StopReasonEndTurn,
+	"refusal":       StopReasonEndTurn,
+}
+
+// openaiStopReasons maps Chat Completions finish_reason values as well as the
+// Responses API status strings.
+var openaiStopReasons = map[string]StopReason{
+	"stop":           StopReasonEndTurn,
+	"length":         StopReasonMaxTokens,
+	"tool_calls":     StopReasonToolUse,
+	"function_call":  StopReasonToolUse,
+	"content_filter": StopReasonEndTurn,
+	"completed":      StopReasonEndTurn,
+	"incomplete":     StopReasonMaxTokens,
+}
+
+// normalizeStopReason resolves raw against overrides first and then the
+// provider's built-in table. Unrecognised non-empty values are treated as
+// EndTurn so the agent loop terminates instead of spinning on them.
+func normalizeStopReason(raw string, table, overrides map[string]StopReason) StopReason {
+	key := strings.ToLower(strings.TrimSpace(raw))
+	if key == "" {
+		return ""
+	}
+	if reason, ok := overrides[key]; ok {
+		return reason
+	}
+	if reason, ok := table[key]; ok {
+		return reason
+	}
+	return StopReasonEndTurn
+}
+
+// copyStopReasons lower-cases override keys so lookups are case-insensitive.
+func copyStopReasons(in map[string]StopReason) map[string]StopReason {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(map[string]StopReason, len(in))
+	for raw, reason := range in {
+		out[strings.ToLower(strings.TrimSpace(raw))] = reason
+	}
+	return out
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	anthropicsdk "github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/responses"
+)
+
+func TestAnthropicStopReasonNormalization(t *testing.T) {
+	cases := map[string]StopReason{
+		"end_turn":      StopReasonEndTurn,
+		"tool_use":      StopReasonToolUse,
+		"max_tokens":    StopReasonMaxTokens,
+		"stop_sequence": StopReasonStopSequence,
+		"refusal":       StopReasonEndTurn,
+	}
+	for raw, want := range cases {
+		msg := anthropicsdk.Message{StopReason: anthropicsdk.StopReason(raw)}
+		m := &anthropicModel{msgs: &fakeMessages{newMsg: &msg}, model: mapModelName(""), maxTokens: 16, configuredAPIKey: "key"}
+		resp, err := m.Complete(context.Background(), Request{Messages: []Message{{Role: "user", Content: "hi"}}})
+		if err != nil {
+			t.Fatalf("%s: complete: %v", raw, err)
+		}
+		if resp.Reason() != want || resp.RawStopReason != raw {
+			t.Fatalf("%s: got canonical %q raw %q, want %q", raw, resp.StopReason, resp.RawStopReason, want)
+		}
+	}
+}
+
+func TestOpenAIStopReasonNormalization(t *testing.T) {
+	cases := map[string]StopReason{
+		"stop":           StopReasonEndTurn,
+		"length":         StopReasonMaxTokens,
+		"tool_calls":     StopReasonToolUse,
+		"function_call":  StopReasonToolUse,
+		"content_filter": StopReasonEndTurn,
+	}
+	for raw, want := range cases {
+		resp := convertOpenAIResponse(&openai.ChatCompletion{
+			Choices: []openai.ChatCompletionChoice{{FinishReason: raw}},
+		})
+		if resp.Reason() != want || resp.RawStopReason != raw {
+			t.Fatalf("%s: got canonical %q raw %q, want %q", raw, resp.StopReason, resp.RawStopReason, want)
+		}
+	}
+}
+
+func TestOpenAIResponsesStopReasonNormalization(t *testing.T) {
+	cases := map[responses.ResponseStatus]StopReason{
+		"completed":  StopReasonEndTurn,
+		"incomplete": StopReasonMaxTokens,
+	}
+	for status, want := range cases {
+		resp := convertResponsesAPIResponse(&responses.Response{Status: status})
+		if resp.Reason() != want || resp.RawStopReason != string(status) {
+			t.Fatalf("%s: got canonical %q raw %q, want %q", status, resp.StopReason, resp.RawStopReason, want)
+		}
+	}
+
+	withTools := convertResponsesAPIResponse(&responses.Response{
+		Status: "completed",
+		Output: []responses.ResponseOutputItemUnion{{Type: "function_call", CallID: "c1", Name: "calc", Arguments: "{}"}},
+	})
+	if withTools.Reason() != StopReasonToolUse {
+		t.Fatalf("expected tool_use for function calls, got %q", withTools.StopReason)
+	}
+}
+
+func TestStopReasonOverridesAndFallback(t *testing.T) {
+	overrides := copyStopReasons(map[string]StopReason{" EOS ": StopReasonStopSequence})
+	if got := normalizeStopReason("eos", openaiStopReasons, overrides); got != StopReasonStopSequence {
+		t.Fatalf("expected override to apply, got %q", got)
+	}
+	if got := normalizeStopReason("mystery", anthropicStopReasons, nil); got != StopReasonEndTurn {
+		t.Fatalf("expected unknown reasons to end the turn, got %q", got)
+	}
+	if got := normalizeStopReason("  ", anthropicStopReasons, nil); got != "" {
+		t.Fatalf("expected empty reason to stay empty, got %q", got)
+	}
+}
+
+func TestResponseStopReasonStaysPlainString(t *testing.T) {
+	resp := &Response{StopReason: "tool_use"}
+	if resp.StopReason != "tool_use" || resp.Reason() != StopReasonToolUse {
+		t.Fatalf("unexpected stop reason %q / %q", resp.StopReason, resp.Reason())
+	}
+	var nilResp *Response
+	if nilResp.Reason() != "" {
+		t.Fatal("expected empty reason for nil response")
+	}
+}
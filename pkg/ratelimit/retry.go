@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultRetryDelay is the initial backoff used by RetryTransport when no
+// delay is given.
+const DefaultRetryDelay = 500 * time.Millisecond
+
+// RetryTransport retries requests that fail with a transport error or a
+// 429, 502, 503 or 504 response, doubling the delay after each attempt and
+// honouring a Retry-After header given in seconds. Requests whose body cannot
+// be replayed (no GetBody) are sent once. Wrap a Transport with it so every
+// attempt also waits on the shared rate limit.
+type RetryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	delay      time.Duration
+}
+
+// NewRetryTransport wraps base (http.DefaultTransport when nil) with up to
+// maxRetries retries. A non-positive delay selects DefaultRetryDelay.
+func NewRetryTransport(base http.RoundTripper, maxRetries int, delay time.Duration) *RetryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if delay <= 0 {
+		delay = DefaultRetryDelay
+	}
+	return &RetryTransport{base: base, maxRetries: max(maxRetries, 0), delay: delay}
+}
+
+// NewRetryingClient returns an *http.Client that is throttled like NewClient
+// and retries failed requests like NewRetryTransport.
+func NewRetryingClient(rate float64, burst, maxRetries int, delay time.Duration) *http.Client {
+	return &http.Client{Transport: NewRetryTransport(NewTransport(nil, rate, burst), maxRetries, delay)}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	delay := t.delay
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+		resp, err := t.base.RoundTrip(attemptReq)
+		if attempt >= t.maxRetries || !replayable || !retryable(resp, err) {
+			return resp, err
+		}
+		wait := delay
+		if after := retryAfter(resp); after > 0 {
+			wait = after
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+			_ = resp.Body.Close()
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// CloseIdleConnections forwards to the base transport when supported.
+func (t *RetryTransport) CloseIdleConnections() {
+	type closeIdler interface{ CloseIdleConnections() }
+	if c, ok := t.base.(closeIdler); ok {
+		c.CloseIdleConnections()
+	}
+}
+
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	secs, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type scriptedTransport struct {
+	statuses []int
+	bodies   []string
+}
+
+func (s *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		data, _ := io.ReadAll(req.Body)
+		s.bodies = append(s.bodies, string(data))
+	}
+	status := s.statuses[0]
+	if len(s.statuses) > 1 {
+		s.statuses = s.statuses[1:]
+	}
+	if status == 0 {
+		return nil, errors.New("connection reset")
+	}
+	return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+}
+
+func TestRetryTransportRetriesTransientFailures(t *testing.T) {
+	base := &scriptedTransport{statuses: []int{0, http.StatusServiceUnavailable, http.StatusOK}}
+	client := &http.Client{Transport: NewRetryTransport(base, 3, time.Millisecond)}
+
+	resp, err := client.Post("http://example.test/", "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected eventual success, got %d", resp.StatusCode)
+	}
+	if len(base.bodies) != 3 || base.bodies[2] != "payload" {
+		t.Fatalf("expected the body replayed on each attempt, got %q", base.bodies)
+	}
+}
+
+func TestRetryTransportStopsAtLimitAndOnClientErrors(t *testing.T) {
+	base := &scriptedTransport{statuses: []int{http.StatusTooManyRequests}}
+	tr := NewRetryTransport(base, 2, time.Millisecond)
+	req, _ := http.NewRequest(http.MethodGet, "http://example.test/", nil)
+	resp, err := tr.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests || len(base.bodies) != 0 {
+		t.Fatalf("expected last 429 returned, got %v %v", resp, err)
+	}
+
+	notFound := &scriptedTransport{statuses: []int{http.StatusNotFound, http.StatusOK}}
+	resp, err = NewRetryTransport(notFound, 2, time.Millisecond).RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 without retry, got %v %v", resp, err)
+	}
+}
+
+func TestRetryTransportHonoursContext(t *testing.T) {
+	base := &scriptedTransport{statuses: []int{http.StatusBadGateway}}
+	tr := NewRetryTransport(base, 5, time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.test/", nil)
+	if _, err := tr.RoundTrip(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+}
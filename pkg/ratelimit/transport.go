@@ -0,0 +1,105 @@
+// Package ratelimit provides http.RoundTrippers that throttle outbound
+// requests with a token bucket and retry transient failures. Sharing one
+// client between the model provider and network tools keeps all traffic
+// under a single quota and retry policy.
+package ratelimit
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Transport delays requests so that at most Rate requests per second are
+// sent on average, allowing bursts of up to Burst requests. It is safe for
+// concurrent use.
+type Transport struct {
+	base  http.RoundTripper
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewTransport wraps base (http.DefaultTransport when nil). A non-positive
+// rate disables throttling; burst is raised to at least 1.
+func NewTransport(base http.RoundTripper, rate float64, burst int) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &Transport{
+		base:   base,
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// NewClient returns an *http.Client whose transport is throttled by a new
+// Transport. Pass the client to every component that should share the limit.
+func NewClient(rate float64, burst int) *http.Client {
+	return &http.Client{Transport: NewTransport(nil, rate, burst)}
+}
+
+// RoundTrip implements http.RoundTripper. It waits for a token, honouring
+// the request context, before delegating to the base transport.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if wait := t.reserve(); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			t.cancel()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// reserve takes a token, possibly driving the bucket negative, and returns
+// how long the caller must wait for that token to become available.
+func (t *Transport) reserve() time.Duration {
+	if t.rate <= 0 {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	if !t.last.IsZero() {
+		t.tokens += now.Sub(t.last).Seconds() * t.rate
+		if t.tokens > t.burst {
+			t.tokens = t.burst
+		}
+	}
+	t.last = now
+	t.tokens--
+	if t.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-t.tokens / t.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token when the request is abandoned.
+func (t *Transport) cancel() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tokens < t.burst {
+		t.tokens++
+	}
+}
+
+// CloseIdleConnections forwards to the base transport when supported.
+func (t *Transport) CloseIdleConnections() {
+	type closeIdler interface{ CloseIdleConnections() }
+	if c, ok := t.base.(closeIdler); ok {
+		c.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingTransport struct{ calls atomic.Int32 }
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.calls.Add(1)
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("ok")), Request: req}, nil
+}
+
+func TestTransportReserveRefillsAtRate(t *testing.T) {
+	tr := NewTransport(&countingTransport{}, 10, 2)
+	now := time.Unix(0, 0)
+	tr.now = func() time.Time { return now }
+
+	if tr.reserve() != 0 || tr.reserve() != 0 {
+		t.Fatalf("expected burst of two to pass without waiting")
+	}
+	if wait := tr.reserve(); wait != 100*time.Millisecond {
+		t.Fatalf("expected 100ms wait, got %s", wait)
+	}
+	now = now.Add(time.Second)
+	if wait := tr.reserve(); wait != 0 {
+		t.Fatalf("expected refilled bucket, got wait %s", wait)
+	}
+}
+
+func TestTransportThrottlesAndHonoursContext(t *testing.T) {
+	base := &countingTransport{}
+	client := &http.Client{Transport: NewTransport(base, 20, 1)}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get("http://example.invalid/")
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		resp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("expected throttling, three requests took %s", elapsed)
+	}
+	if base.calls.Load() != 3 {
+		t.Fatalf("expected 3 upstream calls, got %d", base.calls.Load())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.invalid/", nil)
+	if _, err := client.Do(req); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context cancellation, got %v", err)
+	}
+	if base.calls.Load() != 3 {
+		t.Fatalf("cancelled request must not reach upstream")
+	}
+}
+
+func TestTransportZeroRateDisablesThrottling(t *testing.T) {
+	tr := NewTransport(nil, 0, 0)
+	for i := 0; i < 5; i++ {
+		if wait := tr.reserve(); wait != 0 {
+			t.Fatalf("expected no wait, got %s", wait)
+		}
+	}
+}
@@ -2,9 +2,11 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"maps"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -21,6 +23,11 @@ type Definition struct {
 	Description string
 	Priority    int
 	MutexKey    string
+	// Flags declares typed flags validated before the handler runs.
+	Flags []FlagSpec
+	// Aliases are alternative names, e.g. "q" for "query". Invocations
+	// through an alias run the command and report its canonical Name.
+	Aliases []string
 }
 
 // Validate ensures the definition is sound.
@@ -32,6 +39,22 @@ func (d Definition) Validate() error {
 	if !validName(strings.ToLower(name)) {
 		return fmt.Errorf("commands: invalid name %q", d.Name)
 	}
+	seen := make(map[string]struct{}, len(d.Flags))
+	for _, spec := range d.Flags {
+		if err := spec.validate(); err != nil {
+			return err
+		}
+		key := strings.ToLower(strings.TrimSpace(spec.Name))
+		if _, dup := seen[key]; dup {
+			return fmt.Errorf("commands: duplicate flag %q", spec.Name)
+		}
+		seen[key] = struct{}{}
+	}
+	for _, alias := range d.Aliases {
+		if !validName(strings.ToLower(strings.TrimSpace(alias))) {
+			return fmt.Errorf("commands: invalid alias %q", alias)
+		}
+	}
 	return nil
 }
 
@@ -53,10 +76,21 @@ func (fn HandlerFunc) Handle(ctx context.Context, inv Invocation) (Result, error
 
 // Result captures handler output.
 type Result struct {
-	Command  string
-	Output   any
-	Metadata map[string]any
-	Error    string
+	Command  string         `json:"command"`
+	Output   any            `json:"output"`
+	Metadata map[string]any `json:"metadata"`
+	Error    string         `json:"error"`
+}
+
+// MarshalJSON encodes the result with every field present so consumers see a
+// fixed shape: metadata is {} rather than null and error is "" on success.
+// Metadata keys are emitted in sorted order.
+func (r Result) MarshalJSON() ([]byte, error) {
+	type plain Result
+	if r.Metadata == nil {
+		r.Metadata = map[string]any{}
+	}
+	return json.Marshal(plain(r))
 }
 
 func (r Result) clone() Result {
@@ -70,14 +104,21 @@ func (r Result) clone() Result {
 type Executor struct {
 	mu       sync.RWMutex
 	commands map[string]*registeredCommand
+	// aliases maps each alias to its command's canonical name.
+	aliases    map[string]string
+	middleware []CommandMiddleware
+	// continueOnError keeps Execute going after a handler error.
+	continueOnError bool
 }
 
 // NewExecutor creates a new command executor.
 func NewExecutor() *Executor {
-	return &Executor{commands: map[string]*registeredCommand{}}
+	return &Executor{commands: map[string]*registeredCommand{}, aliases: map[string]string{}}
 }
 
-// Register adds a command definition + handler pair.
+// Register adds a command definition + handler pair. It fails with
+// ErrDuplicateCommand when the name or any alias is already taken by another
+// command's name or alias.
 func (e *Executor) Register(def Definition, handler Handler) error {
 	if err := def.Validate(); err != nil {
 		return err
@@ -91,6 +132,8 @@ func (e *Executor) Register(def Definition, handler Handler) error {
 			Description: strings.TrimSpace(def.Description),
 			Priority:    max(def.Priority, 0),
 			MutexKey:    strings.ToLower(strings.TrimSpace(def.MutexKey)),
+			Flags:       normalizeFlagSpecs(def.Flags),
+			Aliases:     normalizeAliases(def.Aliases),
 		},
 		handler: handler,
 	}
@@ -98,13 +141,55 @@ func (e *Executor) Register(def Definition, handler Handler) error {
 
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	if _, exists := e.commands[key]; exists {
+	if e.nameTakenLocked(key) {
 		return ErrDuplicateCommand
 	}
+	for _, alias := range normalized.definition.Aliases {
+		if alias == key || e.nameTakenLocked(alias) {
+			return fmt.Errorf("%w: alias %q of %q", ErrDuplicateCommand, alias, key)
+		}
+	}
 	e.commands[key] = &normalized
+	for _, alias := range normalized.definition.Aliases {
+		e.aliases[alias] = key
+	}
 	return nil
 }
 
+func (e *Executor) nameTakenLocked(name string) bool {
+	if _, ok := e.commands[name]; ok {
+		return true
+	}
+	_, ok := e.aliases[name]
+	return ok
+}
+
+// lookupLocked resolves name, which may be an alias, to its command.
+func (e *Executor) lookupLocked(name string) (*registeredCommand, bool) {
+	if cmd, ok := e.commands[name]; ok {
+		return cmd, true
+	}
+	if canonical, ok := e.aliases[name]; ok {
+		cmd, ok := e.commands[canonical]
+		return cmd, ok
+	}
+	return nil, false
+}
+
+func normalizeAliases(aliases []string) []string {
+	var out []string
+	seen := map[string]struct{}{}
+	for _, alias := range aliases {
+		alias = strings.ToLower(strings.TrimSpace(alias))
+		if _, dup := seen[alias]; dup {
+			continue
+		}
+		seen[alias] = struct{}{}
+		out = append(out, alias)
+	}
+	return out
+}
+
 // Run parses text and executes slash commands sequentially.
 func (e *Executor) Run(ctx context.Context, text string) ([]Result, error) {
 	invocations, err := Parse(text)
@@ -114,7 +199,39 @@ func (e *Executor) Run(ctx context.Context, text string) ([]Result, error) {
 	return e.Execute(ctx, invocations)
 }
 
-// Execute runs already parsed invocations.
+// WithContinueOnError makes Execute run every invocation even after a
+// handler fails. Each Result carries its own Error and the batch returns a
+// *BatchError counting the failures. Unknown commands, flag errors and
+// cancellation still stop the batch as usual.
+func (e *Executor) WithContinueOnError(enabled bool) *Executor {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.continueOnError = enabled
+	return e
+}
+
+// BatchError reports the handler failures of a batch run with
+// WithContinueOnError. Errs holds each failure in invocation order; errors.Is
+// and errors.As see through to them.
+type BatchError struct {
+	Failed int
+	Total  int
+	Errs   []error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("commands: %d of %d commands failed: %v", e.Failed, e.Total, errors.Join(e.Errs...))
+}
+
+func (e *BatchError) Unwrap() []error { return e.Errs }
+
+// Execute runs already parsed invocations in order. Every invocation is first
+// checked against its command's FlagSpecs; an unknown command or a *FlagError
+// fails the batch before any handler runs. The context is checked
+// before each invocation; once it is cancelled the remaining invocations are
+// skipped and the results gathered so far are returned with ctx.Err(). A
+// handler error likewise stops the batch and is returned with the partial
+// results, the failing one included, unless WithContinueOnError is set.
 func (e *Executor) Execute(ctx context.Context, invocations []Invocation) ([]Result, error) {
 	if ctx == nil {
 		ctx = context.Background()
@@ -126,41 +243,79 @@ func (e *Executor) Execute(ctx context.Context, invocations []Invocation) ([]Res
 
 	e.mu.RLock()
 	for idx, inv := range invocations {
-		cmd, ok := e.commands[inv.Name]
+		cmd, ok := e.lookupLocked(inv.Name)
 		if !ok {
 			e.mu.RUnlock()
 			return nil, ErrUnknownCommand
 		}
+		// Handlers always see the canonical name; Raw keeps the alias.
+		inv.Name = cmd.definition.Name
+		bound, err := bindFlags(inv, cmd.definition.Flags)
+		if err != nil {
+			e.mu.RUnlock()
+			return nil, err
+		}
 		pending = append(pending, plannedExecution{
 			order:      idx,
-			invocation: inv,
+			invocation: bound,
 			command:    cmd,
 		})
 	}
+	middleware := e.middleware
+	continueOnError := e.continueOnError
 	e.mu.RUnlock()
 
 	filtered := applyMutex(pending)
 	results := make([]Result, 0, len(filtered))
+	var failures []error
 	for _, exec := range filtered {
-		res, err := exec.command.handler.Handle(ctx, exec.invocation)
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		res, err := chainMiddleware(middleware, exec.command.handler)(ctx, exec.invocation)
 		res.Command = exec.command.definition.Name
 		res = res.clone()
 		if err != nil {
 			res.Error = err.Error()
 			results = append(results, res)
-			return results, err
+			if !continueOnError {
+				return results, err
+			}
+			failures = append(failures, fmt.Errorf("/%s: %w", res.Command, err))
+			continue
 		}
 		results = append(results, res)
 	}
+	if len(failures) > 0 {
+		return results, &BatchError{Failed: len(failures), Total: len(filtered), Errs: failures}
+	}
 	return results, nil
 }
 
+// ExecuteJSON runs invocations like Execute and encodes the results as a JSON
+// array. On a handler error or cancellation the partial results are still
+// encoded and returned alongside the error.
+func (e *Executor) ExecuteJSON(ctx context.Context, invocations []Invocation) ([]byte, error) {
+	results, execErr := e.Execute(ctx, invocations)
+	if results == nil {
+		results = []Result{}
+	}
+	data, err := json.Marshal(results)
+	if err != nil {
+		return nil, fmt.Errorf("commands: encode results: %w", err)
+	}
+	return data, execErr
+}
+
 // List returns registered command definitions sorted by priority + name.
 func (e *Executor) List() []Definition {
 	e.mu.RLock()
 	defs := make([]Definition, 0, len(e.commands))
 	for _, cmd := range e.commands {
-		defs = append(defs, cmd.definition)
+		def := cmd.definition
+		def.Flags = slices.Clone(def.Flags)
+		def.Aliases = slices.Clone(def.Aliases)
+		defs = append(defs, def)
 	}
 	e.mu.RUnlock()
 	sort.Slice(defs, func(i, j int) bool {
@@ -2,7 +2,10 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -53,6 +56,35 @@ func TestExecutorErrorPropagation(t *testing.T) {
 	}
 }
 
+func TestExecutorCancellationReturnsPartialResults(t *testing.T) {
+	exec := NewExecutor()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var ran []string
+	for _, name := range []string{"first", "second", "third"} {
+		if err := exec.Register(Definition{Name: name}, HandlerFunc(func(ctx context.Context, inv Invocation) (Result, error) {
+			ran = append(ran, inv.Name)
+			if inv.Name == "first" {
+				cancel()
+			}
+			return Result{Output: inv.Name}, nil
+		})); err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+	}
+
+	results, err := exec.Execute(ctx, []Invocation{{Name: "first"}, {Name: "second"}, {Name: "third"}})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(results) != 1 || results[0].Command != "first" || results[0].Output != "first" {
+		t.Fatalf("expected only the first result, got %+v", results)
+	}
+	if len(ran) != 1 {
+		t.Fatalf("expected later invocations to be skipped, ran %v", ran)
+	}
+}
+
 func TestExecutorUnknownCommand(t *testing.T) {
 	exec := NewExecutor()
 	if _, err := exec.Execute(context.Background(), []Invocation{{Name: "missing"}}); !errors.Is(err, ErrUnknownCommand) {
@@ -107,3 +139,138 @@ func TestExecutorListAndValidation(t *testing.T) {
 		t.Fatalf("metadata clone failed")
 	}
 }
+
+func TestExecutorExecuteJSON(t *testing.T) {
+	exec := NewExecutor()
+	if err := exec.Register(Definition{Name: "deploy"}, HandlerFunc(func(ctx context.Context, inv Invocation) (Result, error) {
+		return Result{Output: "shipped " + inv.Args[0], Metadata: map[string]any{"zone": "eu", "attempt": 1}}, nil
+	})); err != nil {
+		t.Fatalf("register deploy: %v", err)
+	}
+	if err := exec.Register(Definition{Name: "rollback"}, HandlerFunc(func(context.Context, Invocation) (Result, error) {
+		return Result{}, errors.New("no previous release")
+	})); err != nil {
+		t.Fatalf("register rollback: %v", err)
+	}
+
+	invs, err := Parse("/deploy prod\n/rollback")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	data, err := exec.ExecuteJSON(context.Background(), invs)
+	if err == nil || err.Error() != "no previous release" {
+		t.Fatalf("expected handler error alongside JSON, got %v", err)
+	}
+	want := `[{"command":"deploy","output":"shipped prod","metadata":{"attempt":1,"zone":"eu"},"error":""},` +
+		`{"command":"rollback","output":null,"metadata":{},"error":"no previous release"}]`
+	if string(data) != want {
+		t.Fatalf("unexpected JSON\n got: %s\nwant: %s", data, want)
+	}
+
+	var decoded []Result
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	again, err := json.Marshal(decoded)
+	if err != nil || string(again) != want {
+		t.Fatalf("round trip mismatch: %s (%v)", again, err)
+	}
+	if !reflect.DeepEqual(decoded[0].Metadata, map[string]any{"zone": "eu", "attempt": float64(1)}) {
+		t.Fatalf("unexpected decoded metadata %+v", decoded[0].Metadata)
+	}
+
+	empty, err := exec.ExecuteJSON(context.Background(), nil)
+	if err != nil || string(empty) != "[]" {
+		t.Fatalf("expected empty array, got %s (%v)", empty, err)
+	}
+}
+
+func TestExecutorAliases(t *testing.T) {
+	exec := NewExecutor()
+	var seen []Invocation
+	handler := HandlerFunc(func(_ context.Context, inv Invocation) (Result, error) {
+		seen = append(seen, inv)
+		return Result{}, nil
+	})
+	if err := exec.Register(Definition{Name: "query", Aliases: []string{"Q", "find", "q"}, Flags: []FlagSpec{{Name: "limit", Type: FlagInt}}}, handler); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	results, err := exec.Run(context.Background(), "/q logs --limit 2\n/FIND x\n/query y")
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	for i, res := range results {
+		if res.Command != "query" || seen[i].Name != "query" {
+			t.Fatalf("result %d not resolved to canonical name: %+v / %+v", i, res, seen[i])
+		}
+	}
+	if len(results) != 3 || seen[0].Int("limit") != 2 || seen[0].Raw != "/q logs --limit 2" {
+		t.Fatalf("unexpected alias invocation %+v", seen)
+	}
+	if _, err := exec.Run(context.Background(), "/q --limit=lots"); err == nil || !strings.Contains(err.Error(), "/query --limit") {
+		t.Fatalf("flag error should name the canonical command, got %v", err)
+	}
+
+	collisions := []Definition{
+		{Name: "q"},
+		{Name: "quick", Aliases: []string{"query"}},
+		{Name: "search", Aliases: []string{"find"}},
+		{Name: "self", Aliases: []string{"self"}},
+	}
+	for _, def := range collisions {
+		if err := exec.Register(def, handler); !errors.Is(err, ErrDuplicateCommand) {
+			t.Fatalf("%+v: expected ErrDuplicateCommand, got %v", def, err)
+		}
+	}
+	if err := exec.Register(Definition{Name: "search", Aliases: []string{"bad alias"}}, handler); err == nil {
+		t.Fatal("expected invalid alias error")
+	}
+	if err := exec.Register(Definition{Name: "search", Aliases: []string{"s"}}, handler); err != nil {
+		t.Fatalf("failed registrations must not reserve names: %v", err)
+	}
+	defs := exec.List()
+	if len(defs) != 2 || !reflect.DeepEqual(defs[0].Aliases, []string{"q", "find"}) {
+		t.Fatalf("unexpected definitions %+v", defs)
+	}
+}
+
+func TestExecutorContinueOnError(t *testing.T) {
+	errBoom := errors.New("boom")
+	exec := NewExecutor().WithContinueOnError(true)
+	var ran []string
+	for _, name := range []string{"ok", "fail", "also-fail"} {
+		if err := exec.Register(Definition{Name: name}, HandlerFunc(func(_ context.Context, inv Invocation) (Result, error) {
+			ran = append(ran, inv.Name)
+			if strings.Contains(inv.Name, "fail") {
+				return Result{Output: "partial"}, errBoom
+			}
+			return Result{Output: "done"}, nil
+		})); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+
+	results, err := exec.Run(context.Background(), "/fail\n/ok\n/also-fail\n/ok")
+	var batch *BatchError
+	if !errors.As(err, &batch) || !errors.Is(err, errBoom) {
+		t.Fatalf("expected BatchError wrapping handler errors, got %v", err)
+	}
+	if batch.Failed != 2 || batch.Total != 4 || !strings.Contains(err.Error(), "2 of 4 commands failed") || !strings.Contains(err.Error(), "/also-fail: boom") {
+		t.Fatalf("unexpected batch error %+v: %v", batch, err)
+	}
+	if len(ran) != 4 || len(results) != 4 {
+		t.Fatalf("every invocation should run, ran %v results %d", ran, len(results))
+	}
+	if results[0].Error != "boom" || results[0].Output != "partial" || results[1].Error != "" || results[2].Error != "boom" {
+		t.Fatalf("unexpected per-result errors %+v", results)
+	}
+
+	if results, err := exec.Run(context.Background(), "/ok"); err != nil || len(results) != 1 {
+		t.Fatalf("clean batch should not error: %v", err)
+	}
+	exec.WithContinueOnError(false)
+	if results, err := exec.Run(context.Background(), "/fail\n/ok"); !errors.Is(err, errBoom) || len(results) != 1 {
+		t.Fatalf("default mode should stop at the first error: %v %+v", err, results)
+	}
+}
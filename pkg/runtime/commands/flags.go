@@ -0,0 +1,257 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidFlag = errors.New("commands: invalid flag")
+
+// FlagType names the value type a FlagSpec coerces to.
+type FlagType string
+
+const (
+	FlagString   FlagType = "string"
+	FlagInt      FlagType = "int"
+	FlagBool     FlagType = "bool"
+	FlagDuration FlagType = "duration"
+)
+
+// FlagSpec declares one flag a command accepts. Executor.Execute coerces the
+// flag to Type before the handler runs, fills in Default when the flag is
+// absent and rejects the invocation when a Required flag is missing. Flags
+// without a spec are passed through as strings.
+type FlagSpec struct {
+	Name string
+	// Type defaults to FlagString.
+	Type FlagType
+	// Default is written in command syntax, e.g. "10" or "30s", and must parse
+	// as Type. It is ignored for required flags.
+	Default  string
+	Required bool
+}
+
+// FlagError reports an invocation whose flags do not satisfy the command's
+// FlagSpecs. It matches ErrInvalidFlag with errors.Is.
+type FlagError struct {
+	Command string
+	Flag    string
+	Reason  string
+}
+
+func (e *FlagError) Error() string {
+	return fmt.Sprintf("commands: /%s --%s: %s", e.Command, e.Flag, e.Reason)
+}
+
+func (e *FlagError) Unwrap() error { return ErrInvalidFlag }
+
+func (s FlagSpec) validate() error {
+	name := strings.ToLower(strings.TrimSpace(s.Name))
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsFunc(name, func(r rune) bool { return r == '=' || r == ' ' }) {
+		return fmt.Errorf("commands: invalid flag name %q", s.Name)
+	}
+	switch s.Type {
+	case "", FlagString, FlagInt, FlagBool, FlagDuration:
+	default:
+		return fmt.Errorf("commands: flag %q has unknown type %q", s.Name, s.Type)
+	}
+	if s.Default != "" && !s.Required {
+		if _, err := coerceFlag(s.Type, s.Default); err != nil {
+			return fmt.Errorf("commands: flag %q default: %w", s.Name, err)
+		}
+	}
+	return nil
+}
+
+func normalizeFlagSpecs(specs []FlagSpec) []FlagSpec {
+	if len(specs) == 0 {
+		return nil
+	}
+	out := make([]FlagSpec, len(specs))
+	for i, spec := range specs {
+		spec.Name = strings.ToLower(strings.TrimSpace(spec.Name))
+		if spec.Type == "" {
+			spec.Type = FlagString
+		}
+		if spec.Required {
+			spec.Default = ""
+		}
+		out[i] = spec
+	}
+	return out
+}
+
+// bindFlags checks inv against specs and returns a copy carrying the coerced
+// values. Defaults are also written to Flags so Flag reports them.
+func bindFlags(inv Invocation, specs []FlagSpec) (Invocation, error) {
+	if len(specs) == 0 {
+		return inv, nil
+	}
+	inv = reclaimBoolArgs(inv, specs)
+	flags := make(map[string]string, len(inv.Flags)+len(specs))
+	for k, v := range inv.Flags {
+		flags[k] = v
+	}
+	typed := make(map[string]any, len(specs))
+	for _, spec := range specs {
+		raw, ok := flags[spec.Name]
+		if !ok {
+			if spec.Required {
+				return inv, &FlagError{Command: inv.Name, Flag: spec.Name, Reason: "required flag is missing"}
+			}
+			if spec.Default == "" {
+				continue
+			}
+			raw = spec.Default
+			flags[spec.Name] = raw
+		}
+		// Every value of a repeated flag must coerce; the typed value is the
+		// last one, matching Flag.
+		values := inv.FlagValues(spec.Name)
+		if values == nil {
+			values = []string{raw}
+		}
+		var val any
+		for _, v := range values {
+			var err error
+			if val, err = coerceFlag(spec.Type, v); err != nil {
+				return inv, &FlagError{Command: inv.Name, Flag: spec.Name, Reason: err.Error()}
+			}
+		}
+		typed[spec.Name] = val
+	}
+	inv.Flags = flags
+	inv.typed = typed
+	return inv, nil
+}
+
+// reclaimBoolArgs makes a bool flag bare when the value the parser took from
+// the following token is not a bool, and returns that token to Args, so
+// /cmd --verbose file.txt binds verbose=true with Args [file.txt].
+func reclaimBoolArgs(inv Invocation, specs []FlagSpec) Invocation {
+	if len(inv.borrowed) == 0 {
+		return inv
+	}
+	bools := map[string]bool{}
+	for _, spec := range specs {
+		if spec.Type == FlagBool {
+			bools[spec.Name] = true
+		}
+	}
+	var reclaimed []borrowedValue
+	for _, b := range inv.borrowed {
+		if !bools[b.flag] {
+			continue
+		}
+		if _, ok := parseFlagBool(b.text); ok {
+			continue
+		}
+		if values := inv.FlagValues(b.flag); b.occurrence < len(values) && values[b.occurrence] == b.text {
+			reclaimed = append(reclaimed, b)
+		}
+	}
+	if len(reclaimed) == 0 {
+		return inv
+	}
+	flags := maps.Clone(inv.Flags)
+	repeated := make(map[string][]string, len(inv.repeated))
+	for k, v := range inv.repeated {
+		repeated[k] = slices.Clone(v)
+	}
+	args := slices.Clone(inv.Args)
+	for n, b := range reclaimed {
+		vals := repeated[b.flag]
+		if len(vals) > 1 {
+			vals[b.occurrence] = "true"
+		}
+		if len(vals) <= 1 || b.occurrence == len(vals)-1 {
+			flags[b.flag] = "true"
+		}
+		args = slices.Insert(args, b.arg+n, b.text)
+	}
+	inv.Flags = flags
+	inv.repeated = repeated
+	inv.Args = args
+	inv.borrowed = nil
+	return inv
+}
+
+func coerceFlag(typ FlagType, raw string) (any, error) {
+	switch typ {
+	case FlagInt:
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid int value %q", raw)
+		}
+		return n, nil
+	case FlagBool:
+		b, ok := parseFlagBool(raw)
+		if !ok {
+			return nil, fmt.Errorf("invalid bool value %q", raw)
+		}
+		return b, nil
+	case FlagDuration:
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration value %q", raw)
+		}
+		return d, nil
+	default:
+		return raw, nil
+	}
+}
+
+func parseFlagBool(raw string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "1", "true", "yes", "y", "on":
+		return true, true
+	case "0", "false", "no", "n", "off":
+		return false, true
+	}
+	return false, false
+}
+
+// Int returns flag name as an int. Flags with a FlagSpec were coerced by
+// Executor.Execute; others are parsed on demand. Absent or malformed flags
+// yield 0.
+func (i Invocation) Int(name string) int {
+	n, _ := typedFlag[int](i, name, FlagInt)
+	return n
+}
+
+// Bool returns flag name as a bool, accepting true/false, yes/no, on/off and
+// 1/0. A bare --flag is true; absent or malformed flags yield false.
+func (i Invocation) Bool(name string) bool {
+	b, _ := typedFlag[bool](i, name, FlagBool)
+	return b
+}
+
+// Duration returns flag name parsed with time.ParseDuration. Absent or
+// malformed flags yield 0.
+func (i Invocation) Duration(name string) time.Duration {
+	d, _ := typedFlag[time.Duration](i, name, FlagDuration)
+	return d
+}
+
+func typedFlag[T any](inv Invocation, name string, typ FlagType) (T, bool) {
+	var zero T
+	name = strings.ToLower(name)
+	if v, ok := inv.typed[name].(T); ok {
+		return v, true
+	}
+	raw, ok := inv.Flag(name)
+	if !ok {
+		return zero, false
+	}
+	val, err := coerceFlag(typ, raw)
+	if err != nil {
+		return zero, false
+	}
+	v, ok := val.(T)
+	return v, ok
+}
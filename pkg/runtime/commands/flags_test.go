@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecuteCoercesTypedFlags(t *testing.T) {
+	exec := NewExecutor()
+	var got Invocation
+	def := Definition{Name: "fetch", Flags: []FlagSpec{
+		{Name: "URL", Required: true},
+		{Name: "limit", Type: FlagInt, Default: "10"},
+		{Name: "force", Type: FlagBool},
+		{Name: "timeout", Type: FlagDuration, Default: "30s"},
+	}}
+	if err := exec.Register(def, HandlerFunc(func(_ context.Context, inv Invocation) (Result, error) {
+		got = inv
+		return Result{}, nil
+	})); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	invs, err := Parse(`/fetch --url https://example.com --force --timeout=2m`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if _, err := exec.Execute(context.Background(), invs); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if url, _ := got.Flag("url"); url != "https://example.com" {
+		t.Fatalf("unexpected url %q", url)
+	}
+	if got.Int("limit") != 10 || !got.Bool("force") || got.Duration("timeout") != 2*time.Minute {
+		t.Fatalf("unexpected typed values limit=%d force=%t timeout=%s", got.Int("limit"), got.Bool("force"), got.Duration("timeout"))
+	}
+	if limit, ok := got.Flag("limit"); !ok || limit != "10" {
+		t.Fatalf("default should be visible through Flag, got %q %t", limit, ok)
+	}
+	if _, ok := invs[0].Flags["limit"]; ok {
+		t.Fatal("Execute must not mutate the caller's invocation")
+	}
+}
+
+func TestExecuteBoolFlagLeavesPositionalArgs(t *testing.T) {
+	exec := NewExecutor()
+	var got Invocation
+	def := Definition{Name: "lint", Flags: []FlagSpec{
+		{Name: "verbose", Type: FlagBool},
+		{Name: "tag", Type: FlagBool},
+		{Name: "level"},
+	}}
+	if err := exec.Register(def, HandlerFunc(func(_ context.Context, inv Invocation) (Result, error) {
+		got = inv
+		return Result{}, nil
+	})); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	cases := []struct {
+		line    string
+		args    string
+		verbose bool
+		tags    string
+		level   string
+	}{
+		{line: "/lint --verbose file.txt", args: "file.txt", verbose: true},
+		{line: "/lint --verbose false file.txt", args: "file.txt"},
+		{line: "/lint a --verbose b c --level high d", args: "a,b,c,d", verbose: true, level: "high"},
+		{line: "/lint --tag x --tag no y", args: "x,y", tags: "true,no"},
+	}
+	for _, tc := range cases {
+		invs, err := Parse(tc.line)
+		if err != nil {
+			t.Fatalf("%s: parse: %v", tc.line, err)
+		}
+		if _, err := exec.Execute(context.Background(), invs); err != nil {
+			t.Fatalf("%s: execute: %v", tc.line, err)
+		}
+		if args := strings.Join(got.Args, ","); args != tc.args {
+			t.Fatalf("%s: args = %q, want %q", tc.line, args, tc.args)
+		}
+		if got.Bool("verbose") != tc.verbose {
+			t.Fatalf("%s: verbose = %t", tc.line, got.Bool("verbose"))
+		}
+		if level, _ := got.Flag("level"); level != tc.level {
+			t.Fatalf("%s: level = %q, want %q", tc.line, level, tc.level)
+		}
+		if tc.tags != "" {
+			if tags := strings.Join(got.FlagValues("tag"), ","); tags != tc.tags {
+				t.Fatalf("%s: tag values = %q, want %q", tc.line, tags, tc.tags)
+			}
+		}
+	}
+}
+
+func TestExecuteFlagErrors(t *testing.T) {
+	exec := NewExecutor()
+	ran := 0
+	handler := HandlerFunc(func(context.Context, Invocation) (Result, error) {
+		ran++
+		return Result{}, nil
+	})
+	if err := exec.Register(Definition{Name: "ok"}, handler); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := exec.Register(Definition{Name: "fetch", Flags: []FlagSpec{
+		{Name: "url", Required: true},
+		{Name: "limit", Type: FlagInt},
+		{Name: "force", Type: FlagBool},
+	}}, handler); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	cases := []struct {
+		line string
+		flag string
+		want string
+	}{
+		{line: "/ok\n/fetch --limit 3", flag: "url", want: "required flag is missing"},
+		{line: "/fetch --url x --limit=many", flag: "limit", want: `invalid int value "many"`},
+		{line: "/fetch --url x --force=maybe", flag: "force", want: `invalid bool value "maybe"`},
+		{line: "/fetch --url x --limit=oops --limit 2", flag: "limit", want: `invalid int value "oops"`},
+	}
+	for _, tc := range cases {
+		invs, err := Parse(tc.line)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tc.line, err)
+		}
+		results, err := exec.Execute(context.Background(), invs)
+		var flagErr *FlagError
+		if !errors.As(err, &flagErr) || !errors.Is(err, ErrInvalidFlag) {
+			t.Fatalf("%q: expected FlagError, got %v", tc.line, err)
+		}
+		if flagErr.Command != "fetch" || flagErr.Flag != tc.flag || flagErr.Reason != tc.want {
+			t.Fatalf("%q: unexpected error %+v", tc.line, flagErr)
+		}
+		if !strings.Contains(err.Error(), "/fetch --"+tc.flag) || results != nil {
+			t.Fatalf("%q: unexpected message %q or results %+v", tc.line, err, results)
+		}
+	}
+	if ran != 0 {
+		t.Fatalf("no handler should run when flags are invalid, ran %d", ran)
+	}
+}
+
+func TestDefinitionValidateFlags(t *testing.T) {
+	cases := []struct {
+		spec []FlagSpec
+		want string
+	}{
+		{spec: []FlagSpec{{Name: " "}}, want: "invalid flag name"},
+		{spec: []FlagSpec{{Name: "--x"}}, want: "invalid flag name"},
+		{spec: []FlagSpec{{Name: "n", Type: "float"}}, want: "unknown type"},
+		{spec: []FlagSpec{{Name: "n", Type: FlagInt, Default: "ten"}}, want: "default"},
+		{spec: []FlagSpec{{Name: "n"}, {Name: "N"}}, want: "duplicate flag"},
+	}
+	for _, tc := range cases {
+		err := Definition{Name: "cmd", Flags: tc.spec}.Validate()
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%+v: expected %q error, got %v", tc.spec, tc.want, err)
+		}
+	}
+	if err := (Definition{Name: "cmd", Flags: []FlagSpec{{Name: "n", Type: FlagInt, Default: "ten", Required: true}}}).Validate(); err != nil {
+		t.Fatalf("required flags ignore their default: %v", err)
+	}
+}
+
+func TestInvocationTypedAccessorsWithoutSpec(t *testing.T) {
+	inv := Invocation{Flags: map[string]string{"limit": "5", "dry-run": "true", "wait": "1s", "bad": "x"}}
+	if inv.Int("LIMIT") != 5 || !inv.Bool("dry-run") || inv.Duration("wait") != time.Second {
+		t.Fatalf("unexpected on-demand parsing")
+	}
+	if inv.Int("bad") != 0 || inv.Bool("missing") || inv.Duration("bad") != 0 {
+		t.Fatalf("malformed or absent flags should yield zero values")
+	}
+}
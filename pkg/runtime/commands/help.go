@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Help renders every registered command as HelpFor does, sorted by name and
+// separated by blank lines. The output is deterministic, so it can back a
+// /help command or a snapshot test. It is empty when nothing is registered.
+func (e *Executor) Help() string {
+	defs := e.List()
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	blocks := make([]string, 0, len(defs))
+	for _, def := range defs {
+		blocks = append(blocks, renderHelp(def))
+	}
+	return strings.Join(blocks, "\n")
+}
+
+// HelpFor renders one command: a "/name - description" line, its aliases and
+// its flags sorted by name with type, default and required-ness. name may be
+// an alias. Unknown names return ErrUnknownCommand.
+func (e *Executor) HelpFor(name string) (string, error) {
+	key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(name), "/")))
+	e.mu.RLock()
+	cmd, ok := e.lookupLocked(key)
+	e.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownCommand, name)
+	}
+	return renderHelp(cmd.definition), nil
+}
+
+func renderHelp(def Definition) string {
+	var b strings.Builder
+	b.WriteString("/")
+	b.WriteString(def.Name)
+	if def.Description != "" {
+		b.WriteString(" - ")
+		b.WriteString(def.Description)
+	}
+	b.WriteByte('\n')
+	if len(def.Aliases) > 0 {
+		b.WriteString("  aliases: /")
+		b.WriteString(strings.Join(def.Aliases, ", /"))
+		b.WriteByte('\n')
+	}
+	if len(def.Flags) == 0 {
+		return b.String()
+	}
+	specs := append([]FlagSpec(nil), def.Flags...)
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	width := 0
+	for _, spec := range specs {
+		width = max(width, len(spec.Name))
+	}
+	b.WriteString("  flags:\n")
+	for _, spec := range specs {
+		fmt.Fprintf(&b, "    --%-*s  %s", width, spec.Name, spec.Type)
+		switch {
+		case spec.Required:
+			b.WriteString(" (required)")
+		case spec.Default != "":
+			fmt.Fprintf(&b, " (default %q)", spec.Default)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
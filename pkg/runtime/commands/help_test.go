@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestExecutorHelp(t *testing.T) {
+	exec := NewExecutor()
+	if got := exec.Help(); got != "" {
+		t.Fatalf("expected empty help, got %q", got)
+	}
+	noop := HandlerFunc(func(context.Context, Invocation) (Result, error) { return Result{}, nil })
+	defs := []Definition{
+		{Name: "query", Description: "run read-only queries", Priority: 5, Aliases: []string{"q", "find"}, Flags: []FlagSpec{
+			{Name: "since", Default: "1h"},
+			{Name: "limit", Type: FlagInt},
+			{Name: "table", Required: true},
+		}},
+		{Name: "backup", Description: "ship logs somewhere", Flags: []FlagSpec{{Name: "compress", Type: FlagBool}}},
+		{Name: "clear"},
+	}
+	for _, def := range defs {
+		if err := exec.Register(def, noop); err != nil {
+			t.Fatalf("register %s: %v", def.Name, err)
+		}
+	}
+
+	want := `/backup - ship logs somewhere
+  flags:
+    --compress  bool
+
+/clear
+
+/query - run read-only queries
+  aliases: /q, /find
+  flags:
+    --limit  int
+    --since  string (default "1h")
+    --table  string (required)
+`
+	if got := exec.Help(); got != want {
+		t.Fatalf("help mismatch:\n%s\nwant:\n%s", got, want)
+	}
+
+	byAlias, err := exec.HelpFor("/Q")
+	if err != nil {
+		t.Fatalf("help for alias: %v", err)
+	}
+	byName, _ := exec.HelpFor("query")
+	if byAlias != byName || byName[:len("/query -")] != "/query -" {
+		t.Fatalf("alias help should render the canonical command, got %q", byAlias)
+	}
+	if _, err := exec.HelpFor("missing"); !errors.Is(err, ErrUnknownCommand) {
+		t.Fatalf("expected ErrUnknownCommand, got %v", err)
+	}
+}
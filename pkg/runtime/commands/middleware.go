@@ -0,0 +1,35 @@
+package commands
+
+import "context"
+
+// CommandMiddleware wraps the execution of one invocation. It may inspect or
+// replace inv before calling next, inspect the Result afterwards, or veto the
+// command by returning without calling next. An error stops the batch just
+// like a handler error. inv carries the canonical command name and flags
+// already checked against the command's FlagSpecs.
+type CommandMiddleware func(ctx context.Context, inv Invocation, next HandlerFunc) (Result, error)
+
+// Use appends middleware to the executor. Middleware runs in registration
+// order, the first registered outermost, around every handler invoked by
+// later Execute and Run calls.
+func (e *Executor) Use(mw ...CommandMiddleware) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, m := range mw {
+		if m != nil {
+			// Copy on write so an in-flight Execute keeps its snapshot.
+			e.middleware = append(e.middleware[:len(e.middleware):len(e.middleware)], m)
+		}
+	}
+}
+
+func chainMiddleware(mw []CommandMiddleware, handler Handler) HandlerFunc {
+	next := handler.Handle
+	for i := len(mw) - 1; i >= 0; i-- {
+		m, inner := mw[i], next
+		next = func(ctx context.Context, inv Invocation) (Result, error) {
+			return m(ctx, inv, inner)
+		}
+	}
+	return next
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExecutorMiddleware(t *testing.T) {
+	exec := NewExecutor()
+	var ran []string
+	handler := HandlerFunc(func(_ context.Context, inv Invocation) (Result, error) {
+		ran = append(ran, inv.Name)
+		return Result{Output: inv.Name}, nil
+	})
+	for _, def := range []Definition{{Name: "status"}, {Name: "deploy", Aliases: []string{"ship"}}} {
+		if err := exec.Register(def, handler); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+
+	var trace []string
+	audit := func(ctx context.Context, inv Invocation, next HandlerFunc) (Result, error) {
+		trace = append(trace, "audit>"+inv.Name)
+		res, err := next(ctx, inv)
+		trace = append(trace, "audit<"+inv.Name)
+		return res, err
+	}
+	errReadOnly := errors.New("read-only mode")
+	readOnly := func(ctx context.Context, inv Invocation, next HandlerFunc) (Result, error) {
+		trace = append(trace, "guard:"+inv.Name)
+		if inv.Name == "deploy" {
+			return Result{}, errReadOnly
+		}
+		res, err := next(ctx, inv)
+		res.Metadata = map[string]any{"checked": true}
+		return res, err
+	}
+	exec.Use(audit, nil, readOnly)
+
+	results, err := exec.Run(context.Background(), "/status\n/ship prod")
+	if !errors.Is(err, errReadOnly) {
+		t.Fatalf("expected veto error, got %v", err)
+	}
+	if !reflect.DeepEqual(ran, []string{"status"}) {
+		t.Fatalf("vetoed handler ran: %v", ran)
+	}
+	wantTrace := []string{"audit>status", "guard:status", "audit<status", "audit>deploy", "guard:deploy", "audit<deploy"}
+	if !reflect.DeepEqual(trace, wantTrace) {
+		t.Fatalf("middleware order %v, want %v", trace, wantTrace)
+	}
+	if len(results) != 2 || results[0].Metadata["checked"] != true {
+		t.Fatalf("middleware result changes lost: %+v", results)
+	}
+	if results[1].Command != "deploy" || results[1].Error != errReadOnly.Error() {
+		t.Fatalf("vetoed result should carry the error: %+v", results[1])
+	}
+
+	short := NewExecutor()
+	if err := short.Register(Definition{Name: "ping"}, handler); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	short.Use(func(context.Context, Invocation, HandlerFunc) (Result, error) {
+		return Result{Output: "cached"}, nil
+	})
+	ran = nil
+	res, err := short.Run(context.Background(), "/ping")
+	if err != nil || len(res) != 1 || res[0].Output != "cached" || res[0].Command != "ping" || ran != nil {
+		t.Fatalf("short-circuit failed: %+v %v ran=%v", res, err, ran)
+	}
+}
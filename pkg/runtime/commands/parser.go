@@ -3,6 +3,7 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"unicode"
 )
@@ -19,9 +20,27 @@ type Invocation struct {
 	Flags    map[string]string
 	Raw      string
 	Position int
+	// typed holds flag values coerced against the command's FlagSpecs.
+	typed map[string]any
+	// repeated holds every value, in order, of flags given more than once.
+	repeated map[string][]string
+	// borrowed lists the flag values taken from the token after a bare
+	// --flag, so bindFlags can return them to Args for bool flags.
+	borrowed []borrowedValue
 }
 
-// Flag retrieves a flag value.
+// borrowedValue is a flag value read from the token following a bare --flag.
+// occurrence indexes the flag's values and arg counts the Args before it.
+type borrowedValue struct {
+	flag       string
+	text       string
+	occurrence int
+	arg        int
+}
+
+// Flag retrieves a flag value. When the flag was given more than once, as in
+// --tag a --tag b, Flag and Flags report the last value; use FlagValues to
+// read them all.
 func (i Invocation) Flag(name string) (string, bool) {
 	if i.Flags == nil {
 		return "", false
@@ -30,8 +49,77 @@ func (i Invocation) Flag(name string) (string, bool) {
 	return val, ok
 }
 
+// FlagValues returns every value given for flag name in command-line order,
+// or nil when the flag is absent. A flag given once yields a single value.
+// If Flags was changed after parsing, its current value wins.
+func (i Invocation) FlagValues(name string) []string {
+	key := strings.ToLower(name)
+	val, ok := i.Flag(key)
+	if !ok {
+		return nil
+	}
+	if vals := i.repeated[key]; len(vals) > 1 && vals[len(vals)-1] == val {
+		return append([]string(nil), vals...)
+	}
+	return []string{val}
+}
+
+// String re-serializes the invocation as a single command line that Parse
+// reads back to the same Name, Args and Flags. Args come first, then flags in
+// key order, repeated flags once per value; "true" flags are written bare and
+// the rest as --key=value.
+// Empty strings and newlines cannot be expressed in the command syntax and do
+// not round-trip.
+func (i Invocation) String() string {
+	var b strings.Builder
+	b.WriteString("/")
+	b.WriteString(i.Name)
+	for _, arg := range i.Args {
+		b.WriteByte(' ')
+		b.WriteString(quoteToken(arg))
+	}
+	keys := make([]string, 0, len(i.Flags))
+	for key := range i.Flags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, val := range i.FlagValues(key) {
+			b.WriteString(" --")
+			b.WriteString(quoteToken(key))
+			if val != "true" {
+				b.WriteByte('=')
+				b.WriteString(quoteToken(val))
+			}
+		}
+	}
+	return b.String()
+}
+
+// quoteToken double-quotes s when lex would otherwise split or unescape it,
+// or read it as a flag.
+func quoteToken(s string) string {
+	if s != "" && !strings.HasPrefix(s, "-") && !strings.ContainsFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '"' || r == '\'' || r == '\\'
+	}) {
+		return s
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		if r == '"' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
 // Parse extracts slash commands from the input text. Each line beginning with
-// '/' is treated as a command. Quoted arguments and --flag syntax are supported.
+// '/' is treated as a command. Quoted arguments and --flag syntax are supported;
+// a quoted or escaped word is never read as a flag, so --pattern "--help" sets
+// pattern to --help.
 func Parse(input string) ([]Invocation, error) {
 	lines := strings.Split(input, "\n")
 	var invocations []Invocation
@@ -54,6 +142,50 @@ func Parse(input string) ([]Invocation, error) {
 	return invocations, nil
 }
 
+// UnknownCommandError lists parsed invocations whose names were not in the
+// allowlist passed to ParseKnown. Position holds each one's line number. It
+// matches ErrUnknownCommand with errors.Is.
+type UnknownCommandError struct {
+	Invocations []Invocation
+}
+
+func (e *UnknownCommandError) Error() string {
+	parts := make([]string, 0, len(e.Invocations))
+	for _, inv := range e.Invocations {
+		parts = append(parts, fmt.Sprintf("/%s (line %d)", inv.Name, inv.Position))
+	}
+	return "commands: unknown command " + strings.Join(parts, ", ")
+}
+
+func (e *UnknownCommandError) Unwrap() error { return ErrUnknownCommand }
+
+// ParseKnown parses input like Parse but keeps only invocations whose names
+// appear in known (case-insensitive). When some are unknown it returns the
+// known invocations together with an *UnknownCommandError, so callers can
+// either drop the unknown ones by ignoring the error or report them.
+func ParseKnown(input string, known []string) ([]Invocation, error) {
+	invocations, err := Parse(input)
+	if err != nil {
+		return nil, err
+	}
+	allowed := make(map[string]struct{}, len(known))
+	for _, name := range known {
+		allowed[strings.ToLower(strings.TrimSpace(name))] = struct{}{}
+	}
+	var kept, unknown []Invocation
+	for _, inv := range invocations {
+		if _, ok := allowed[inv.Name]; ok {
+			kept = append(kept, inv)
+		} else {
+			unknown = append(unknown, inv)
+		}
+	}
+	if len(unknown) > 0 {
+		return kept, &UnknownCommandError{Invocations: unknown}
+	}
+	return kept, nil
+}
+
 func parseLine(line string) (Invocation, error) {
 	tokens, err := lex(line)
 	if err != nil {
@@ -62,7 +194,7 @@ func parseLine(line string) (Invocation, error) {
 	if len(tokens) == 0 {
 		return Invocation{}, ErrInvalidCommand
 	}
-	name := tokens[0]
+	name := tokens[0].text
 	if !strings.HasPrefix(name, "/") {
 		return Invocation{}, ErrInvalidCommand
 	}
@@ -73,23 +205,37 @@ func parseLine(line string) (Invocation, error) {
 	inv := Invocation{Name: normalized, Flags: map[string]string{}}
 	for i := 1; i < len(tokens); i++ {
 		token := tokens[i]
-		if strings.HasPrefix(token, "--") {
-			key, value, consumed := parseFlag(token)
+		if token.isFlag() {
+			key, value, consumed := parseFlag(token.text)
 			key = strings.ToLower(key)
 			if key == "" {
-				return Invocation{}, fmt.Errorf("commands: invalid flag %q", token)
+				return Invocation{}, fmt.Errorf("commands: invalid flag %q", token.text)
 			}
-			if !consumed && i+1 < len(tokens) && !strings.HasPrefix(tokens[i+1], "-") {
-				value = tokens[i+1]
+			occurrence := 0
+			if _, seen := inv.Flags[key]; seen {
+				occurrence = max(len(inv.repeated[key]), 1)
+			}
+			if !consumed && i+1 < len(tokens) && !tokens[i+1].isOption() {
+				value = tokens[i+1].text
+				inv.borrowed = append(inv.borrowed, borrowedValue{flag: key, text: value, occurrence: occurrence, arg: len(inv.Args)})
 				i++
 			}
 			if value == "" {
 				value = "true"
 			}
+			if prev, seen := inv.Flags[key]; seen {
+				if inv.repeated == nil {
+					inv.repeated = map[string][]string{}
+				}
+				if len(inv.repeated[key]) == 0 {
+					inv.repeated[key] = []string{prev}
+				}
+				inv.repeated[key] = append(inv.repeated[key], value)
+			}
 			inv.Flags[key] = value
 			continue
 		}
-		inv.Args = append(inv.Args, token)
+		inv.Args = append(inv.Args, token.text)
 	}
 	if len(inv.Flags) == 0 {
 		inv.Flags = nil
@@ -107,21 +253,46 @@ func parseFlag(token string) (key, value string, hasValue bool) {
 	return strings.TrimSpace(trimmed), "", false
 }
 
-func lex(line string) ([]string, error) {
-	var tokens []string
+// token is one lexed word. literal marks a word whose first character was
+// quoted or escaped, so a leading "-" is data rather than a flag marker.
+type token struct {
+	text    string
+	literal bool
+}
+
+func (t token) isFlag() bool {
+	return !t.literal && strings.HasPrefix(t.text, "--")
+}
+
+func (t token) isOption() bool {
+	return !t.literal && strings.HasPrefix(t.text, "-")
+}
+
+// lex splits line into words. Single and double quotes group words; a
+// backslash makes the next character literal both inside and outside quotes,
+// so "say \"hi\"" yields say "hi".
+func lex(line string) ([]token, error) {
+	var tokens []token
 	var buf strings.Builder
 	var quote rune
 	escaped := false
+	literal := false
+	write := func(r rune, quoted bool) {
+		if buf.Len() == 0 {
+			literal = quoted
+		}
+		buf.WriteRune(r)
+	}
 	emit := func() {
 		if buf.Len() > 0 {
-			tokens = append(tokens, buf.String())
+			tokens = append(tokens, token{text: buf.String(), literal: literal})
 			buf.Reset()
 		}
 	}
 	for _, r := range line {
 		switch {
 		case escaped:
-			buf.WriteRune(r)
+			write(r, true)
 			escaped = false
 		case r == '\\':
 			escaped = true
@@ -130,13 +301,13 @@ func lex(line string) ([]string, error) {
 				quote = 0
 				continue
 			}
-			buf.WriteRune(r)
+			write(r, true)
 		case r == '\'' || r == '"':
 			quote = r
 		case unicode.IsSpace(r):
 			emit()
 		default:
-			buf.WriteRune(r)
+			write(r, false)
 		}
 	}
 	if escaped {
@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"errors"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -46,8 +48,146 @@ func TestParseHandlesEdgeCases(t *testing.T) {
 	}
 }
 
+func TestParseQuotingAndEscapes(t *testing.T) {
+	cases := []struct {
+		name  string
+		line  string
+		args  []string
+		flags map[string]string
+		err   string
+	}{
+		{name: "escaped quotes in double quotes", line: `/say --msg "say \"hi\""`, flags: map[string]string{"msg": `say "hi"`}},
+		{name: "escaped quote in single quotes", line: `/say --msg 'it\'s'`, flags: map[string]string{"msg": "it's"}},
+		{name: "double quotes inside single", line: `/say '"quoted"'`, args: []string{`"quoted"`}},
+		{name: "single quotes inside double", line: `/say "it's fine"`, args: []string{"it's fine"}},
+		{name: "mixed quotes join one word", line: `/say "a b"'c d'e`, args: []string{"a bc de"}},
+		{name: "equals value looks like flag", line: `/grep --flag=--looks-like-flag`, flags: map[string]string{"flag": "--looks-like-flag"}},
+		{name: "quoted value looks like flag", line: `/grep --pattern "--help" file`, args: []string{"file"}, flags: map[string]string{"pattern": "--help"}},
+		{name: "single quoted dash value", line: `/grep --offset '-5'`, flags: map[string]string{"offset": "-5"}},
+		{name: "escaped dashes are an argument", line: `/grep \--help`, args: []string{"--help"}},
+		{name: "bare flag before flag", line: `/grep --force --dry-run`, flags: map[string]string{"force": "true", "dry-run": "true"}},
+		{name: "escaped backslash", line: `/path "C:\\dir\\"`, args: []string{`C:\dir\`}},
+		{name: "escaped space", line: `/open my\ file`, args: []string{"my file"}},
+		{name: "trailing backslash", line: `/open dir\`, err: "dangling escape"},
+		{name: "trailing backslash in quotes", line: `/say "oops\"`, err: "unclosed quote"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			inv, err := Parse(tc.line)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected %q error, got %v (%+v)", tc.err, err, inv)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if !reflect.DeepEqual(inv[0].Args, tc.args) || !reflect.DeepEqual(inv[0].Flags, tc.flags) {
+				t.Fatalf("got args %q flags %q, want args %q flags %q", inv[0].Args, inv[0].Flags, tc.args, tc.flags)
+			}
+		})
+	}
+}
+
+func TestParseRepeatedFlags(t *testing.T) {
+	inv, err := Parse(`/note add "body" --tag ops --tag="on call" --TAG release --private`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	got := inv[0]
+	if tags := got.FlagValues("tag"); !reflect.DeepEqual(tags, []string{"ops", "on call", "release"}) {
+		t.Fatalf("unexpected tags %q", tags)
+	}
+	if last, _ := got.Flag("tag"); last != "release" || got.Flags["tag"] != "release" {
+		t.Fatalf("Flag should report the last value, got %q", last)
+	}
+	if vals := got.FlagValues("private"); !reflect.DeepEqual(vals, []string{"true"}) {
+		t.Fatalf("single flag should yield one value, got %q", vals)
+	}
+	if got.FlagValues("missing") != nil {
+		t.Fatal("absent flag should yield nil")
+	}
+
+	again, err := Parse(got.String())
+	if err != nil || !reflect.DeepEqual(again[0].FlagValues("tag"), got.FlagValues("tag")) {
+		t.Fatalf("repeated flags should round-trip through String: %q %v", got.String(), err)
+	}
+
+	got.Flags["tag"] = "override"
+	if vals := got.FlagValues("tag"); !reflect.DeepEqual(vals, []string{"override"}) {
+		t.Fatalf("edited Flags should win, got %q", vals)
+	}
+}
+
 func TestInvocationFlagNilMap(t *testing.T) {
 	if _, ok := (Invocation{}).Flag("any"); ok {
 		t.Fatalf("flag lookup on nil map should be false")
 	}
 }
+
+func TestInvocationStringRoundTrip(t *testing.T) {
+	cases := []Invocation{
+		{Name: "deploy"},
+		{Name: "note", Args: []string{"add", "release checklist", "/tmp/release plan.md"}},
+		{Name: "say", Args: []string{`she said "hi"`, "it's", `C:\path`, "-n", "--verbose"}},
+		{Name: "backup", Args: []string{"run"}, Flags: map[string]string{
+			"compress": "true",
+			"dest":     "./tmp/log backup",
+			"path":     "/var/log/app",
+			"quote":    `a "b" 'c'`,
+			"offset":   "-5",
+			"pattern":  "--*",
+		}},
+		{Name: "flags-only", Flags: map[string]string{"force": "true", "dry-run": "true"}},
+	}
+	for _, want := range cases {
+		line := want.String()
+		got, err := Parse(line)
+		if err != nil {
+			t.Fatalf("parse %q: %v", line, err)
+		}
+		if len(got) != 1 {
+			t.Fatalf("parse %q: expected one invocation, got %d", line, len(got))
+		}
+		if got[0].Name != want.Name || !reflect.DeepEqual(got[0].Args, want.Args) || !reflect.DeepEqual(got[0].Flags, want.Flags) {
+			t.Fatalf("round trip of %q mismatch:\nwant %+v\ngot  %+v", line, want, got[0])
+		}
+	}
+
+	inv := Invocation{Name: "deploy", Args: []string{"staging"}, Flags: map[string]string{"force": "true", "region": "us east"}}
+	if got := inv.String(); got != `/deploy staging --force --region="us east"` {
+		t.Fatalf("unexpected canonical form %q", got)
+	}
+}
+
+func TestParseKnownFlagsUnknownCommands(t *testing.T) {
+	script := "/deploy prod\nplain text\n/foo bar\n/NOTE add\n/bar"
+	known, err := ParseKnown(script, []string{"deploy", "Note"})
+	if !errors.Is(err, ErrUnknownCommand) {
+		t.Fatalf("expected ErrUnknownCommand, got %v", err)
+	}
+	var unknownErr *UnknownCommandError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("expected *UnknownCommandError, got %T", err)
+	}
+	if len(unknownErr.Invocations) != 2 ||
+		unknownErr.Invocations[0].Name != "foo" || unknownErr.Invocations[0].Position != 3 ||
+		unknownErr.Invocations[1].Name != "bar" || unknownErr.Invocations[1].Position != 5 {
+		t.Fatalf("unexpected unknown invocations %+v", unknownErr.Invocations)
+	}
+	if !strings.Contains(err.Error(), "/foo (line 3)") {
+		t.Fatalf("error should name the command and line: %v", err)
+	}
+	if len(known) != 2 || known[0].Name != "deploy" || known[1].Name != "note" || known[1].Position != 4 {
+		t.Fatalf("unexpected known invocations %+v", known)
+	}
+
+	all, err := ParseKnown("/deploy\n/note", []string{"deploy", "note"})
+	if err != nil || len(all) != 2 {
+		t.Fatalf("expected all known, got %v %+v", err, all)
+	}
+	if _, err := ParseKnown("no commands", nil); !errors.Is(err, ErrNoCommand) {
+		t.Fatalf("expected ErrNoCommand, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package skills
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// activationFingerprint hashes the activation context deterministically:
+// channels, traits and tags are sorted, and metadata is JSON-encoded with
+// sorted keys, so equal contexts always produce the same digest.
+func activationFingerprint(ac ActivationContext) string {
+	h := sha256.New()
+	write := func(field string, values ...string) {
+		h.Write([]byte(field))
+		for _, v := range values {
+			h.Write([]byte{0})
+			h.Write([]byte(v))
+		}
+		h.Write([]byte{'\n'})
+	}
+	sorted := func(values []string) []string {
+		out := append([]string(nil), values...)
+		sort.Strings(out)
+		return out
+	}
+
+	write("prompt", strings.TrimSpace(ac.Prompt))
+	write("channels", sorted(ac.Channels)...)
+	write("traits", sorted(ac.Traits)...)
+	tags := make([]string, 0, len(ac.Tags))
+	for k, v := range ac.Tags {
+		tags = append(tags, k+"="+v)
+	}
+	write("tags", sorted(tags)...)
+	if len(ac.Metadata) > 0 {
+		if raw, err := json.Marshal(ac.Metadata); err == nil {
+			write("metadata", string(raw))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// dedupKey identifies one skill activated for one context.
+func dedupKey(skill, fingerprint string) string {
+	return skill + "\x00" + fingerprint
+}
+
+// pruneRecentLocked drops activations older than window. Caller holds r.mu.
+func (r *Registry) pruneRecentLocked(now time.Time, window time.Duration) {
+	for key, at := range r.recent {
+		if now.Sub(at) >= window {
+			delete(r.recent, key)
+		}
+	}
+}
+
+// forgetLocked drops the activation history of one skill. Caller holds r.mu.
+func (r *Registry) forgetLocked(name string) {
+	prefix := dedupKey(name, "")
+	for key := range r.recent {
+		if strings.HasPrefix(key, prefix) {
+			delete(r.recent, key)
+		}
+	}
+	delete(r.lastActivated, name)
+}
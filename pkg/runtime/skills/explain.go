@@ -0,0 +1,49 @@
+package skills
+
+import "context"
+
+// MatchExplanation describes how one auto-activating skill fared in a match
+// pass.
+type MatchExplanation struct {
+	Name    string
+	Matched bool
+	// Activated reports whether Match would return the skill.
+	Activated bool
+	// Score and Reason come from the winning matcher; both are zero when the
+	// skill did not match.
+	Score  float64
+	Reason string
+	// Suppressed is the SuppressedBy* cause when the skill matched but was
+	// dropped, e.g. SuppressedByMutex for a MutexKey conflict.
+	Suppressed string
+}
+
+// Explain runs a match pass without side effects and reports every
+// auto-activating skill: matches in ranking order, then misses by name.
+// Dedup and cooldown are checked against past activations but nothing is
+// recorded, so Explain never changes what the next Match returns.
+func (r *Registry) Explain(ac ActivationContext) []MatchExplanation {
+	matches, misses, ranked := r.rank(context.Background(), ac)
+	report := r.applyPolicy(matches, ranked, ac, matchDryRun)
+
+	causes := make(map[*Skill]string, len(report.Suppressed))
+	for _, s := range report.Suppressed {
+		causes[s.Skill] = s.Cause
+	}
+	out := make([]MatchExplanation, 0, len(matches)+len(misses))
+	for _, activation := range matches {
+		cause := causes[activation.Skill]
+		out = append(out, MatchExplanation{
+			Name:       activation.Skill.definition.Name,
+			Matched:    true,
+			Activated:  cause == "",
+			Score:      activation.Score,
+			Reason:     activation.Reason,
+			Suppressed: cause,
+		})
+	}
+	for _, skill := range misses {
+		out = append(out, MatchExplanation{Name: skill.definition.Name})
+	}
+	return out
+}
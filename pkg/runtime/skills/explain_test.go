@@ -0,0 +1,47 @@
+package skills
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRegistryExplain(t *testing.T) {
+	r := NewRegistry().WithDedupWindow(time.Minute)
+	handler := HandlerFunc(func(context.Context, ActivationContext) (Result, error) { return Result{}, nil })
+	deploy := KeywordMatcher{Any: []string{"deploy"}}
+	defs := []Definition{
+		{Name: "deploy-prod", Priority: 2, MutexKey: "deploy", Matchers: []Matcher{deploy}},
+		{Name: "deploy-stage", Priority: 1, MutexKey: "deploy", Matchers: []Matcher{deploy}},
+		{Name: "docs", Matchers: []Matcher{KeywordMatcher{Any: []string{"readme"}}}},
+		{Name: "manual", DisableAutoActivation: true},
+	}
+	for _, def := range defs {
+		if err := r.Register(def, handler); err != nil {
+			t.Fatalf("register %s: %v", def.Name, err)
+		}
+	}
+
+	ac := ActivationContext{Prompt: "deploy the service"}
+	got := r.Explain(ac)
+	if len(got) != 3 {
+		t.Fatalf("expected three explanations, got %+v", got)
+	}
+	if e := got[0]; e.Name != "deploy-prod" || !e.Matched || !e.Activated || e.Score == 0 || e.Reason == "" {
+		t.Fatalf("unexpected winner %+v", e)
+	}
+	if e := got[1]; e.Name != "deploy-stage" || !e.Matched || e.Activated || e.Suppressed != SuppressedByMutex {
+		t.Fatalf("expected mutex suppression, got %+v", e)
+	}
+	if e := got[2]; e.Name != "docs" || e.Matched || e.Activated || e.Score != 0 || e.Suppressed != "" {
+		t.Fatalf("unexpected miss %+v", e)
+	}
+
+	// Explain records nothing, so the real pass is not deduplicated.
+	if matches := r.Match(ac); len(matches) != 1 || matches[0].Skill.Definition().Name != "deploy-prod" {
+		t.Fatalf("explain must not affect match, got %+v", matches)
+	}
+	if e := r.Explain(ac)[0]; e.Activated || e.Suppressed != SuppressedByDedup {
+		t.Fatalf("expected dedup after a real match, got %+v", e)
+	}
+}
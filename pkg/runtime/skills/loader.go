@@ -57,6 +57,10 @@ func statFileOverrideOrOS(path string) (fs.FileInfo, error) {
 // LoaderOptions controls how skills are discovered from the filesystem.
 type LoaderOptions struct {
 	ProjectRoot string
+	// ExtraRoots are further project roots whose .claude/skills directories
+	// are scanned after ProjectRoot, in order. When names collide the skill
+	// from the earlier root wins and the rest are reported as duplicates.
+	ExtraRoots []string
 	// Deprecated: user-level scanning has been removed; this field is ignored.
 	UserHome string
 	// Deprecated: user-level scanning has been removed; this flag is ignored.
@@ -64,6 +68,15 @@ type LoaderOptions struct {
 	// FS is the filesystem abstraction layer for loading skills.
 	// If nil, falls back to os.* functions for backward compatibility.
 	FS *config.FS
+	// MaxSupportFileBytes flags any single scripts/references/assets file
+	// larger than this many bytes with a LoaderErrorOversize entry in the
+	// errors LoadFromFS returns. Sizes come from stat; the file stays indexed
+	// by name. Zero disables the check.
+	MaxSupportFileBytes int64
+	// MaxSupportTotalBytes flags support files once a skill's combined
+	// support size exceeds this many bytes, reported like
+	// MaxSupportFileBytes. Zero disables the check.
+	MaxSupportTotalBytes int64
 }
 
 // SkillFile captures an on-disk SKILL.md entry.
@@ -71,7 +84,22 @@ type SkillFile struct {
 	Name     string
 	Path     string
 	Metadata SkillMetadata
-	fs       *config.FS
+	// Root is the project root the skill was discovered under.
+	Root   string
+	fs     *config.FS
+	limits supportLimits
+	order  int // index of Root in the scan order
+}
+
+// supportLimits bounds support file sizes. Sizes come from stat, so
+// oversized files are still indexed by name but never read.
+type supportLimits struct {
+	perFile int64
+	total   int64
+}
+
+func (l supportLimits) enabled() bool {
+	return l.perFile > 0 || l.total > 0
 }
 
 // readFile is swappable in tests to track filesystem IO.
@@ -135,6 +163,20 @@ type SkillMetadata struct {
 	Compatibility string            `yaml:"compatibility,omitempty"`
 	Metadata      map[string]string `yaml:"metadata,omitempty"`
 	AllowedTools  ToolList          `yaml:"allowed-tools,omitempty"`
+	// Priority orders auto-activation like Definition.Priority. Negative
+	// values fail validation.
+	Priority int `yaml:"priority,omitempty"`
+
+	// Keywords, Tags and Channels become matchers on the loaded Definition,
+	// each activating the skill on its own:
+	//   - keywords: KeywordMatcher{Any: ...}, score 0.7 when a keyword appears
+	//   - tags: TagMatcher{Require: ...}, score 0.9 when every tag matches
+	//     (an empty value only requires the key)
+	//   - channels: ChannelMatcher, score 0.6 when a channel is present
+	// Without them the Definition has no matchers, as before.
+	Keywords []string          `yaml:"keywords,omitempty"`
+	Tags     map[string]string `yaml:"tags,omitempty"`
+	Channels []string          `yaml:"channels,omitempty"`
 }
 
 // SkillRegistration wires a definition to its handler.
@@ -143,6 +185,50 @@ type SkillRegistration struct {
 	Handler    Handler
 }
 
+// LoaderErrorKind classifies a LoaderError.
+type LoaderErrorKind string
+
+const (
+	// LoaderErrorIO reports a skills directory that could not be read.
+	LoaderErrorIO LoaderErrorKind = "io"
+	// LoaderErrorParse reports an unreadable SKILL.md or malformed frontmatter.
+	LoaderErrorParse LoaderErrorKind = "parse"
+	// LoaderErrorValidate reports frontmatter that parsed but is invalid.
+	LoaderErrorValidate LoaderErrorKind = "validate"
+	// LoaderErrorDuplicate reports a skill skipped because its name was
+	// already loaded. It is a warning: the first definition still loads.
+	LoaderErrorDuplicate LoaderErrorKind = "duplicate"
+	// LoaderErrorSupport reports a failure listing scripts/references/assets.
+	LoaderErrorSupport LoaderErrorKind = "support"
+	// LoaderErrorOversize reports a support file beyond the configured size
+	// caps. It is a warning: the file stays in the index by name.
+	LoaderErrorOversize LoaderErrorKind = "oversize"
+)
+
+// LoaderError describes one problem encountered while loading skills. The
+// loader returns these inside its []error so callers can group them with
+// errors.As by Kind, Path or Name.
+type LoaderError struct {
+	Path string
+	Name string
+	Kind LoaderErrorKind
+	Err  error
+}
+
+func (e *LoaderError) Error() string {
+	if e == nil || e.Err == nil {
+		return "skills: loader error"
+	}
+	return e.Err.Error()
+}
+
+func (e *LoaderError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
+
 // Skill names must be 1-64 characters, lowercase alphanumeric plus hyphens, and
 // cannot start or end with a hyphen.
 var skillNameRegexp = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]{0,62}[a-z0-9])?$`)
@@ -168,34 +254,34 @@ func LoadFromFS(opts LoaderOptions) ([]SkillRegistration, []error) {
 
 	ops := resolveFileOps(opts.FS)
 
-	projectDir := filepath.Join(opts.ProjectRoot, ".claude", "skills")
-	files, loadErrs := loadSkillDir(projectDir, fsLayer)
-	errs = append(errs, loadErrs...)
-	allFiles = append(allFiles, files...)
+	for i, root := range skillRoots(opts) {
+		files, loadErrs := loadSkillDir(filepath.Join(root, ".claude", "skills"), fsLayer)
+		errs = append(errs, loadErrs...)
+		for _, file := range files {
+			file.Root = root
+			file.order = i
+			allFiles = append(allFiles, file)
+		}
+	}
 
 	if len(allFiles) == 0 {
 		return nil, errs
 	}
 
-	sort.Slice(allFiles, func(i, j int) bool {
-		if allFiles[i].Metadata.Name != allFiles[j].Metadata.Name {
-			return allFiles[i].Metadata.Name < allFiles[j].Metadata.Name
+	unique, dupErrs := dedupeSkillFiles(allFiles)
+	errs = append(errs, dupErrs...)
+	limits := supportLimits{perFile: opts.MaxSupportFileBytes, total: opts.MaxSupportTotalBytes}
+	for _, file := range unique {
+		file.limits = limits
+		if limits.enabled() {
+			errs = append(errs, oversizeSupportErrors(file)...)
 		}
-		return allFiles[i].Path < allFiles[j].Path
-	})
-
-	seen := map[string]string{}
-	for _, file := range allFiles {
-		if prev, ok := seen[file.Metadata.Name]; ok {
-			errs = append(errs, fmt.Errorf("skills: duplicate skill %q at %s (already from %s)", file.Metadata.Name, file.Path, prev))
-			continue
-		}
-		seen[file.Metadata.Name] = file.Path
-
 		def := Definition{
 			Name:        file.Metadata.Name,
 			Description: file.Metadata.Description,
+			Priority:    file.Metadata.Priority,
 			Metadata:    buildDefinitionMetadata(file),
+			Matchers:    buildMatchers(file.Metadata),
 		}
 		reg := SkillRegistration{
 			Definition: def,
@@ -207,6 +293,59 @@ func LoadFromFS(opts LoaderOptions) ([]SkillRegistration, []error) {
 	return registrations, errs
 }
 
+// skillRoots returns ProjectRoot followed by the distinct ExtraRoots.
+func skillRoots(opts LoaderOptions) []string {
+	roots := []string{opts.ProjectRoot}
+	seen := map[string]struct{}{filepath.Clean(opts.ProjectRoot): {}}
+	for _, root := range opts.ExtraRoots {
+		if strings.TrimSpace(root) == "" {
+			continue
+		}
+		clean := filepath.Clean(root)
+		if _, ok := seen[clean]; ok {
+			continue
+		}
+		seen[clean] = struct{}{}
+		roots = append(roots, root)
+	}
+	return roots
+}
+
+// dedupeSkillFiles sorts files by name, root order, then path and keeps the
+// first file for each name, reporting the rest as LoaderErrorDuplicate
+// warnings.
+func dedupeSkillFiles(files []SkillFile) ([]SkillFile, []error) {
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Metadata.Name != files[j].Metadata.Name {
+			return files[i].Metadata.Name < files[j].Metadata.Name
+		}
+		if files[i].order != files[j].order {
+			return files[i].order < files[j].order
+		}
+		return files[i].Path < files[j].Path
+	})
+
+	var (
+		unique []SkillFile
+		errs   []error
+	)
+	seen := map[string]string{}
+	for _, file := range files {
+		if prev, ok := seen[file.Metadata.Name]; ok {
+			errs = append(errs, &LoaderError{
+				Path: file.Path,
+				Name: file.Metadata.Name,
+				Kind: LoaderErrorDuplicate,
+				Err:  fmt.Errorf("skills: duplicate skill %q at %s (already from %s)", file.Metadata.Name, file.Path, prev),
+			})
+			continue
+		}
+		seen[file.Metadata.Name] = file.Path
+		unique = append(unique, file)
+	}
+	return unique, errs
+}
+
 func loadSkillDir(root string, fsLayer *config.FS) ([]SkillFile, []error) {
 	var (
 		results []SkillFile
@@ -222,15 +361,15 @@ func loadSkillDir(root string, fsLayer *config.FS) ([]SkillFile, []error) {
 		if errors.Is(err, fs.ErrNotExist) {
 			return nil, nil
 		}
-		return nil, []error{fmt.Errorf("skills: stat %s: %w", root, err)}
+		return nil, []error{&LoaderError{Path: root, Kind: LoaderErrorIO, Err: fmt.Errorf("skills: stat %s: %w", root, err)}}
 	}
 	if !info.IsDir() {
-		return nil, []error{fmt.Errorf("skills: path %s is not a directory", root)}
+		return nil, []error{&LoaderError{Path: root, Kind: LoaderErrorIO, Err: fmt.Errorf("skills: path %s is not a directory", root)}}
 	}
 
 	entries, err := fsLayer.ReadDir(root)
 	if err != nil {
-		return nil, []error{fmt.Errorf("skills: read dir %s: %w", root, err)}
+		return nil, []error{&LoaderError{Path: root, Kind: LoaderErrorIO, Err: fmt.Errorf("skills: read dir %s: %w", root, err)}}
 	}
 
 	for _, entry := range entries {
@@ -257,13 +396,17 @@ func loadSkillDir(root string, fsLayer *config.FS) ([]SkillFile, []error) {
 func parseSkillFile(path, dirName string, fsLayer *config.FS) (SkillFile, error) {
 	meta, err := readFrontMatter(path, fsLayer)
 	if err != nil {
-		return SkillFile{}, fmt.Errorf("skills: read %s: %w", path, err)
+		return SkillFile{}, &LoaderError{Path: path, Name: dirName, Kind: LoaderErrorParse, Err: fmt.Errorf("skills: read %s: %w", path, err)}
 	}
 	if meta.Name != "" && dirName != "" && meta.Name != dirName {
-		return SkillFile{}, fmt.Errorf("skills: name %q does not match directory %q in %s", meta.Name, dirName, path)
+		return SkillFile{}, &LoaderError{Path: path, Name: meta.Name, Kind: LoaderErrorValidate, Err: fmt.Errorf("skills: name %q does not match directory %q in %s", meta.Name, dirName, path)}
 	}
 	if err := validateMetadata(meta); err != nil {
-		return SkillFile{}, fmt.Errorf("skills: validate %s: %w", path, err)
+		name := meta.Name
+		if name == "" {
+			name = dirName
+		}
+		return SkillFile{}, &LoaderError{Path: path, Name: name, Kind: LoaderErrorValidate, Err: fmt.Errorf("skills: validate %s: %w", path, err)}
 	}
 
 	return SkillFile{
@@ -374,16 +517,22 @@ func validateMetadata(meta SkillMetadata) error {
 	if len(compat) > 500 {
 		return errors.New("compatibility exceeds 500 characters")
 	}
+	if meta.Priority < 0 {
+		return fmt.Errorf("priority %d must not be negative", meta.Priority)
+	}
 	return nil
 }
 
 func loadSupportFiles(dir string) (map[string][]string, []error) {
-	return loadSupportFilesWithFS(dir, nil)
+	return loadSupportFilesWithFS(dir, nil, supportLimits{})
 }
 
-func loadSupportFilesWithFS(dir string, fsLayer *config.FS) (map[string][]string, []error) {
+func loadSupportFilesWithFS(dir string, fsLayer *config.FS, limits supportLimits) (map[string][]string, []error) {
 	out := map[string][]string{}
-	var errs []error
+	var (
+		errs  []error
+		total int64
+	)
 
 	if fsLayer == nil {
 		fsLayer = config.NewFS("", nil)
@@ -394,19 +543,19 @@ func loadSupportFilesWithFS(dir string, fsLayer *config.FS) (map[string][]string
 		info, err := fsLayer.Stat(root)
 		if err != nil {
 			if !errors.Is(err, fs.ErrNotExist) {
-				errs = append(errs, fmt.Errorf("skills: stat %s: %w", root, err))
+				errs = append(errs, &LoaderError{Path: root, Kind: LoaderErrorSupport, Err: fmt.Errorf("skills: stat %s: %w", root, err)})
 			}
 			continue
 		}
 		if !info.IsDir() {
-			errs = append(errs, fmt.Errorf("skills: %s is not a directory", root))
+			errs = append(errs, &LoaderError{Path: root, Kind: LoaderErrorSupport, Err: fmt.Errorf("skills: %s is not a directory", root)})
 			continue
 		}
 
 		var files []string
 		if walkErr := fsLayer.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
 			if walkErr != nil {
-				errs = append(errs, fmt.Errorf("skills: walk %s: %w", path, walkErr))
+				errs = append(errs, &LoaderError{Path: path, Kind: LoaderErrorSupport, Err: fmt.Errorf("skills: walk %s: %w", path, walkErr)})
 				return nil
 			}
 			if d.IsDir() {
@@ -418,9 +567,14 @@ func loadSupportFilesWithFS(dir string, fsLayer *config.FS) (map[string][]string
 				rel = d.Name()
 			}
 			files = append(files, filepath.ToSlash(rel))
+			if limits.enabled() {
+				if err := checkSupportSize(path, d, limits, &total); err != nil {
+					errs = append(errs, err)
+				}
+			}
 			return nil
 		}); walkErr != nil {
-			errs = append(errs, fmt.Errorf("skills: walk %s: %w", root, walkErr))
+			errs = append(errs, &LoaderError{Path: root, Kind: LoaderErrorSupport, Err: fmt.Errorf("skills: walk %s: %w", root, walkErr)})
 			continue
 		}
 
@@ -436,6 +590,40 @@ func loadSupportFilesWithFS(dir string, fsLayer *config.FS) (map[string][]string
 	return out, errs
 }
 
+// oversizeSupportErrors stats file's support files against its size caps
+// and returns the LoaderErrorOversize warnings, named after the skill. Other
+// support failures are left to execution, where the index is built.
+func oversizeSupportErrors(file SkillFile) []error {
+	_, supportErrs := loadSupportFilesWithFS(filepath.Dir(file.Path), file.fs, file.limits)
+	var errs []error
+	for _, err := range supportErrs {
+		var le *LoaderError
+		if errors.As(err, &le) && le.Kind == LoaderErrorOversize {
+			le.Name = file.Metadata.Name
+			errs = append(errs, le)
+		}
+	}
+	return errs
+}
+
+// checkSupportSize stats a support file and reports it when it breaks the
+// per-file cap or pushes the running total past the total cap.
+func checkSupportSize(path string, d fs.DirEntry, limits supportLimits, total *int64) error {
+	info, err := d.Info()
+	if err != nil {
+		return &LoaderError{Path: path, Kind: LoaderErrorSupport, Err: fmt.Errorf("skills: stat %s: %w", path, err)}
+	}
+	size := info.Size()
+	*total += size
+	switch {
+	case limits.perFile > 0 && size > limits.perFile:
+		return &LoaderError{Path: path, Kind: LoaderErrorOversize, Err: fmt.Errorf("skills: support file %s is %d bytes, exceeds per-file limit %d", path, size, limits.perFile)}
+	case limits.total > 0 && *total > limits.total:
+		return &LoaderError{Path: path, Kind: LoaderErrorOversize, Err: fmt.Errorf("skills: support file %s brings total support size to %d bytes, exceeds limit %d", path, *total, limits.total)}
+	}
+	return nil
+}
+
 func buildDefinitionMetadata(file SkillFile) map[string]string {
 	var meta map[string]string
 	if len(file.Metadata.Metadata) > 0 {
@@ -473,9 +661,28 @@ func buildDefinitionMetadata(file SkillFile) map[string]string {
 		meta["source"] = file.Path
 	}
 
+	if file.Root != "" {
+		meta["source_root"] = file.Root
+	}
+
 	return meta
 }
 
+// buildMatchers translates the frontmatter activation keys into matchers.
+func buildMatchers(meta SkillMetadata) []Matcher {
+	var matchers []Matcher
+	if keywords := normalizeTokens(meta.Keywords); len(keywords) > 0 {
+		matchers = append(matchers, KeywordMatcher{Any: keywords})
+	}
+	if tags := normalizeTagMap(meta.Tags); len(tags) > 0 {
+		matchers = append(matchers, TagMatcher{Require: tags})
+	}
+	if channels := normalizeTokens(meta.Channels); len(channels) > 0 {
+		matchers = append(matchers, ChannelMatcher{Channels: channels})
+	}
+	return matchers
+}
+
 func resolveFileOps(fsLayer *config.FS) fileOps {
 	if fsLayer != nil {
 		return fileOps{
@@ -505,8 +712,25 @@ func loadSkillContent(file SkillFile) (Result, error) {
 		return Result{}, err
 	}
 
-	support, supportErrs := loadSupportFilesWithFS(filepath.Dir(file.Path), file.fs)
-	if err := errors.Join(supportErrs...); err != nil {
+	support, supportErrs := loadSupportFilesWithFS(filepath.Dir(file.Path), file.fs, file.limits)
+	var (
+		failures []error
+		oversize []string
+	)
+	for _, err := range supportErrs {
+		var le *LoaderError
+		if errors.As(err, &le) {
+			if le.Name == "" {
+				le.Name = file.Metadata.Name
+			}
+			if le.Kind == LoaderErrorOversize {
+				oversize = append(oversize, le.Path)
+				continue
+			}
+		}
+		failures = append(failures, err)
+	}
+	if err := errors.Join(failures...); err != nil {
 		return Result{}, err
 	}
 
@@ -526,6 +750,9 @@ func loadSkillContent(file SkillFile) (Result, error) {
 		}
 		meta["support-file-count"] = count
 	}
+	if len(oversize) > 0 {
+		meta["support-files-oversize"] = oversize
+	}
 
 	if len(meta) == 0 {
 		meta = nil
@@ -2,9 +2,11 @@ package skills
 
 import (
 	"context"
+	"errors"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -277,3 +279,116 @@ func (m *mockFileInfo) Mode() fs.FileMode  { return 0o644 }
 func (m *mockFileInfo) ModTime() time.Time { return m.modTime }
 func (m *mockFileInfo) IsDir() bool        { return false }
 func (m *mockFileInfo) Sys() any           { return nil }
+
+func TestLoaderErrorKinds(t *testing.T) {
+	root := t.TempDir()
+	skillsDir := filepath.Join(root, ".claude", "skills")
+	parsePath := filepath.Join(skillsDir, "broken", "SKILL.md")
+	mustWrite(t, parsePath, "no frontmatter here")
+	validatePath := filepath.Join(skillsDir, "mismatch", "SKILL.md")
+	writeSkill(t, validatePath, "other-name", "body")
+	writeSkill(t, filepath.Join(skillsDir, "good", "SKILL.md"), "good", "body")
+
+	regs, errs := LoadFromFS(LoaderOptions{ProjectRoot: root})
+	if len(regs) != 1 || regs[0].Definition.Name != "good" {
+		t.Fatalf("expected only the valid skill to load, got %+v", regs)
+	}
+	byKind := map[LoaderErrorKind]*LoaderError{}
+	for _, err := range errs {
+		var le *LoaderError
+		if !errors.As(err, &le) {
+			t.Fatalf("expected LoaderError, got %T: %v", err, err)
+		}
+		byKind[le.Kind] = le
+	}
+	if le := byKind[LoaderErrorParse]; le == nil || le.Path != parsePath || le.Name != "broken" {
+		t.Fatalf("unexpected parse error: %+v", le)
+	}
+	if le := byKind[LoaderErrorValidate]; le == nil || le.Path != validatePath || le.Name != "other-name" {
+		t.Fatalf("unexpected validate error: %+v", le)
+	}
+
+	ioRoot := t.TempDir()
+	mustWrite(t, filepath.Join(ioRoot, ".claude", "skills"), "not a dir")
+	_, errs = LoadFromFS(LoaderOptions{ProjectRoot: ioRoot})
+	var ioErr *LoaderError
+	if len(errs) != 1 || !errors.As(errs[0], &ioErr) || ioErr.Kind != LoaderErrorIO || ioErr.Path != filepath.Join(ioRoot, ".claude", "skills") {
+		t.Fatalf("unexpected io errors: %v", errs)
+	}
+
+	files := []SkillFile{
+		{Path: "/b/dup/SKILL.md", Metadata: SkillMetadata{Name: "dup"}},
+		{Path: "/a/dup/SKILL.md", Metadata: SkillMetadata{Name: "dup"}},
+	}
+	unique, dupErrs := dedupeSkillFiles(files)
+	var dupErr *LoaderError
+	if len(unique) != 1 || len(dupErrs) != 1 || !errors.As(dupErrs[0], &dupErr) {
+		t.Fatalf("unexpected dedupe result %v %v", unique, dupErrs)
+	}
+	if dupErr.Kind != LoaderErrorDuplicate || dupErr.Path != "/b/dup/SKILL.md" || dupErr.Name != "dup" {
+		t.Fatalf("unexpected duplicate error: %+v", dupErr)
+	}
+
+	supportDir := t.TempDir()
+	mustWrite(t, filepath.Join(supportDir, "scripts"), "not a dir")
+	_, supportErrs := loadSupportFiles(supportDir)
+	var supportErr *LoaderError
+	if len(supportErrs) != 1 || !errors.As(supportErrs[0], &supportErr) || supportErr.Kind != LoaderErrorSupport || supportErr.Path != filepath.Join(supportDir, "scripts") {
+		t.Fatalf("unexpected support errors: %v", supportErrs)
+	}
+}
+
+func TestSupportFileSizeCaps(t *testing.T) {
+	root := t.TempDir()
+	skillDir := filepath.Join(root, ".claude", "skills", "big")
+	writeSkill(t, filepath.Join(skillDir, "SKILL.md"), "big", "body")
+	mustWrite(t, filepath.Join(skillDir, "scripts", "run.sh"), "echo hi")
+	hugePath := filepath.Join(skillDir, "assets", "huge.bin")
+	if err := os.MkdirAll(filepath.Dir(hugePath), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	// A sparse file: its size comes from stat, so the cap must not need a read.
+	huge, err := os.Create(hugePath)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := huge.Truncate(64 << 20); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	_ = huge.Close()
+
+	regs, errs := LoadFromFS(LoaderOptions{ProjectRoot: root, MaxSupportFileBytes: 1 << 20})
+	if len(errs) != 1 || len(regs) != 1 {
+		t.Fatalf("unexpected load result: regs=%d errs=%v", len(regs), errs)
+	}
+	var loadErr *LoaderError
+	if !errors.As(errs[0], &loadErr) || loadErr.Kind != LoaderErrorOversize || loadErr.Path != hugePath || loadErr.Name != "big" {
+		t.Fatalf("expected oversize loader error for %s, got %v", hugePath, errs[0])
+	}
+	if _, errs := LoadFromFS(LoaderOptions{ProjectRoot: root}); len(errs) != 0 {
+		t.Fatalf("expected no errors without caps, got %v", errs)
+	}
+	if _, errs := LoadFromFS(LoaderOptions{ProjectRoot: root, MaxSupportTotalBytes: 4}); len(errs) != 2 {
+		t.Fatalf("expected total cap violations in load errors, got %v", errs)
+	}
+	res, err := regs[0].Handler.Execute(context.Background(), ActivationContext{})
+	if err != nil {
+		t.Fatalf("oversize support file should not fail execution: %v", err)
+	}
+	support := res.Output.(map[string]any)["support_files"].(map[string][]string)
+	if len(support["assets"]) != 1 || support["assets"][0] != "huge.bin" {
+		t.Fatalf("expected oversized file indexed by name, got %v", support)
+	}
+	if got := res.Metadata["support-files-oversize"]; !reflect.DeepEqual(got, []string{hugePath}) {
+		t.Fatalf("expected oversize metadata, got %v", got)
+	}
+
+	_, supportErrs := loadSupportFilesWithFS(skillDir, nil, supportLimits{total: 4})
+	if len(supportErrs) != 2 {
+		t.Fatalf("expected both files to exceed the total cap, got %v", supportErrs)
+	}
+	var le *LoaderError
+	if !errors.As(supportErrs[0], &le) || le.Kind != LoaderErrorOversize || !strings.Contains(le.Error(), "total support size") {
+		t.Fatalf("unexpected cap error: %v", supportErrs[0])
+	}
+}
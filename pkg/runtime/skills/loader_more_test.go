@@ -1,7 +1,9 @@
 package skills
 
 import (
+	"errors"
 	"io/fs"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
@@ -81,3 +83,116 @@ func TestResolveFileOps(t *testing.T) {
 		t.Fatalf("expected override read, got %q err=%v", data, err)
 	}
 }
+
+func TestLoadFromFSExtraRoots(t *testing.T) {
+	project := t.TempDir()
+	service := t.TempDir()
+	shared := t.TempDir()
+	writeSkill(t, filepath.Join(project, ".claude", "skills", "deploy", "SKILL.md"), "deploy", "project")
+	writeSkill(t, filepath.Join(service, ".claude", "skills", "deploy", "SKILL.md"), "deploy", "service")
+	writeSkill(t, filepath.Join(service, ".claude", "skills", "lint", "SKILL.md"), "lint", "service")
+	writeSkill(t, filepath.Join(shared, ".claude", "skills", "lint", "SKILL.md"), "lint", "shared")
+	writeSkill(t, filepath.Join(shared, ".claude", "skills", "docs", "SKILL.md"), "docs", "shared")
+
+	regs, errs := LoadFromFS(LoaderOptions{ProjectRoot: project, ExtraRoots: []string{service, "", shared, project}})
+	roots := map[string]string{}
+	for _, reg := range regs {
+		roots[reg.Definition.Name] = reg.Definition.Metadata["source_root"]
+	}
+	if len(regs) != 3 || roots["deploy"] != project || roots["lint"] != service || roots["docs"] != shared {
+		t.Fatalf("expected earlier roots to win, got %v", roots)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected two duplicate warnings, got %v", errs)
+	}
+	for _, err := range errs {
+		var le *LoaderError
+		if !errors.As(err, &le) || le.Kind != LoaderErrorDuplicate {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if le.Name == "deploy" && !strings.HasPrefix(le.Path, service) {
+			t.Fatalf("expected service deploy reported as duplicate, got %s", le.Path)
+		}
+		if le.Name == "lint" && !strings.HasPrefix(le.Path, shared) {
+			t.Fatalf("expected shared lint reported as duplicate, got %s", le.Path)
+		}
+	}
+}
+
+func TestLoadFromFSPriorityFrontmatter(t *testing.T) {
+	root := t.TempDir()
+	skillsDir := filepath.Join(root, ".claude", "skills")
+	mustWrite(t, filepath.Join(skillsDir, "urgent", "SKILL.md"), "---\nname: urgent\ndescription: test\npriority: 7\n---\nbody")
+	writeSkill(t, filepath.Join(skillsDir, "plain", "SKILL.md"), "plain", "body")
+	mustWrite(t, filepath.Join(skillsDir, "negative", "SKILL.md"), "---\nname: negative\ndescription: test\npriority: -1\n---\nbody")
+
+	regs, errs := LoadFromFS(LoaderOptions{ProjectRoot: root})
+	priorities := map[string]int{}
+	for _, reg := range regs {
+		priorities[reg.Definition.Name] = reg.Definition.Priority
+	}
+	if len(regs) != 2 || priorities["urgent"] != 7 || priorities["plain"] != 0 {
+		t.Fatalf("unexpected priorities %v", priorities)
+	}
+	var le *LoaderError
+	if len(errs) != 1 || !errors.As(errs[0], &le) || le.Kind != LoaderErrorValidate || le.Name != "negative" || !strings.Contains(le.Error(), "priority") {
+		t.Fatalf("expected validation error for negative priority, got %v", errs)
+	}
+}
+
+func TestLoadFromFSFrontmatterMatchers(t *testing.T) {
+	root := t.TempDir()
+	skillsDir := filepath.Join(root, ".claude", "skills")
+	mustWrite(t, filepath.Join(skillsDir, "incident", "SKILL.md"), strings.Join([]string{
+		"---",
+		"name: incident",
+		"description: test",
+		"keywords: [Outage, incident]",
+		"tags:",
+		"  env: prod",
+		"channels:",
+		"  - pager",
+		"---",
+		"body",
+	}, "\n"))
+	writeSkill(t, filepath.Join(skillsDir, "manual", "SKILL.md"), "manual", "body")
+
+	regs, errs := LoadFromFS(LoaderOptions{ProjectRoot: root})
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	r := NewRegistry()
+	for _, reg := range regs {
+		if reg.Definition.Name == "manual" && len(reg.Definition.Matchers) != 0 {
+			t.Fatalf("skill without activation keys should have no matchers")
+		}
+		if err := r.Register(reg.Definition, reg.Handler); err != nil {
+			t.Fatalf("register %s: %v", reg.Definition.Name, err)
+		}
+	}
+
+	scoreOf := func(ac ActivationContext) (float64, string) {
+		for _, act := range r.Match(ac) {
+			if act.Skill.Definition().Name == "incident" {
+				return act.Score, act.Reason
+			}
+		}
+		return 0, ""
+	}
+	cases := []struct {
+		ac     ActivationContext
+		score  float64
+		reason string
+	}{
+		{ActivationContext{Prompt: "major OUTAGE in eu"}, 0.7, "keywords|hit=outage"},
+		{ActivationContext{Prompt: "status", Tags: map[string]string{"env": "prod"}}, 0.9, "tags|require=1"},
+		{ActivationContext{Prompt: "status", Channels: []string{"Pager"}}, 0.6, "channels|hit=pager"},
+		{ActivationContext{Prompt: "status", Tags: map[string]string{"env": "dev"}, Channels: []string{"cli"}}, 0, ""},
+	}
+	for _, tc := range cases {
+		score, reason := scoreOf(tc.ac)
+		if math.Abs(score-tc.score) > 1e-9 || reason != tc.reason {
+			t.Fatalf("context %+v: got %.2f %q, want %.2f %q", tc.ac, score, reason, tc.score, tc.reason)
+		}
+	}
+}
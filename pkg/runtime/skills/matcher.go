@@ -1,10 +1,15 @@
 package skills
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"maps"
+	"regexp"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // ActivationContext captures conversational state used for auto-activation.
@@ -79,6 +84,30 @@ func (fn MatcherFunc) Match(ctx ActivationContext) MatchResult {
 	return fn(ctx)
 }
 
+// ContextMatcher is implemented by matchers that perform I/O or other
+// expensive work and can honour cancellation. The registry prefers
+// MatchContext over Match and bounds it with the configured match timeout.
+type ContextMatcher interface {
+	Matcher
+	MatchContext(context.Context, ActivationContext) MatchResult
+}
+
+// ContextMatcherFunc adapts a context-aware function to ContextMatcher.
+type ContextMatcherFunc func(context.Context, ActivationContext) MatchResult
+
+// Match implements Matcher using a background context.
+func (fn ContextMatcherFunc) Match(ac ActivationContext) MatchResult {
+	return fn.MatchContext(context.Background(), ac)
+}
+
+// MatchContext implements ContextMatcher.
+func (fn ContextMatcherFunc) MatchContext(ctx context.Context, ac ActivationContext) MatchResult {
+	if fn == nil {
+		return MatchResult{}
+	}
+	return fn(ctx, ac)
+}
+
 // KeywordMatcher inspects prompt text for keywords.
 type KeywordMatcher struct {
 	All []string
@@ -202,6 +231,223 @@ func (m TraitMatcher) Match(ctx ActivationContext) MatchResult {
 	return MatchResult{Matched: true, Score: score, Reason: reason}
 }
 
+// ChannelMatcher matches when the activation context arrives on one of the
+// listed channels.
+type ChannelMatcher struct {
+	Channels []string
+}
+
+// Match implements Matcher.
+func (m ChannelMatcher) Match(ctx ActivationContext) MatchResult {
+	want := normalizeTokens(m.Channels)
+	if len(want) == 0 {
+		return MatchResult{}
+	}
+	have := tokenSet(ctx.Channels)
+	for _, channel := range want {
+		if _, ok := have[channel]; ok {
+			return MatchResult{Matched: true, Score: 0.6, Reason: "channels|hit=" + channel}
+		}
+	}
+	return MatchResult{}
+}
+
+// RegexMatcher matches when the prompt matches a regular expression. Regexp
+// takes precedence over Pattern; Pattern is compiled on first use. Score
+// defaults to 0.7. Use a pointer so the compiled pattern is cached.
+type RegexMatcher struct {
+	Pattern string
+	Regexp  *regexp.Regexp
+	Score   float64
+
+	once sync.Once
+	re   *regexp.Regexp
+	err  error
+}
+
+// Validate reports whether the pattern compiles. Definition.Validate calls
+// it so Register rejects invalid patterns up front.
+func (m *RegexMatcher) Validate() error {
+	_, err := m.compiled()
+	return err
+}
+
+// Match implements Matcher. An invalid pattern never matches. Reason lists
+// the captured groups, by name when the group is named.
+func (m *RegexMatcher) Match(ctx ActivationContext) MatchResult {
+	re, err := m.compiled()
+	if err != nil || re == nil {
+		return MatchResult{}
+	}
+	groups := re.FindStringSubmatch(ctx.Prompt)
+	if groups == nil {
+		return MatchResult{}
+	}
+	score := m.Score
+	if score <= 0 {
+		score = 0.7
+	}
+	reasonParts := []string{"regex"}
+	names := re.SubexpNames()
+	for i := 1; i < len(groups); i++ {
+		label := strconv.Itoa(i)
+		if names[i] != "" {
+			label = names[i]
+		}
+		reasonParts = append(reasonParts, label+"="+groups[i])
+	}
+	return MatchResult{Matched: true, Score: clampScore(score), Reason: strings.Join(reasonParts, "|")}
+}
+
+func (m *RegexMatcher) compiled() (*regexp.Regexp, error) {
+	if m == nil {
+		return nil, errors.New("skills: regex matcher is nil")
+	}
+	if m.Regexp != nil {
+		return m.Regexp, nil
+	}
+	m.once.Do(func() {
+		if strings.TrimSpace(m.Pattern) == "" {
+			m.err = errors.New("skills: regex matcher pattern is empty")
+			return
+		}
+		m.re, m.err = regexp.Compile(m.Pattern)
+		if m.err != nil {
+			m.err = fmt.Errorf("skills: invalid regex %q: %w", m.Pattern, m.err)
+		}
+	})
+	return m.re, m.err
+}
+
+// AllOf matches only when every child matches. The score is the lowest
+// child score. An AllOf without children never matches.
+func AllOf(matchers ...Matcher) Matcher {
+	return allOfMatcher(compactMatchers(matchers))
+}
+
+// AnyOf matches when at least one child matches. The score is the highest
+// child score.
+func AnyOf(matchers ...Matcher) Matcher {
+	return anyOfMatcher(compactMatchers(matchers))
+}
+
+// Not matches when its child does not. The score is the complement of the
+// child score, so a plain miss yields a near-maximal score that does not
+// lower an enclosing AllOf.
+func Not(matcher Matcher) Matcher {
+	return notMatcher{inner: matcher}
+}
+
+type allOfMatcher []Matcher
+
+// Match implements Matcher.
+func (m allOfMatcher) Match(ac ActivationContext) MatchResult {
+	return m.MatchContext(context.Background(), ac)
+}
+
+// MatchContext implements ContextMatcher, forwarding ctx to children.
+func (m allOfMatcher) MatchContext(ctx context.Context, ac ActivationContext) MatchResult {
+	if len(m) == 0 {
+		return MatchResult{}
+	}
+	score := 1.0
+	reasons := make([]string, 0, len(m))
+	for _, child := range m {
+		res := matchChild(ctx, child, ac)
+		if !res.Matched {
+			return MatchResult{}
+		}
+		score = min(score, res.Score)
+		reasons = append(reasons, res.Reason)
+	}
+	return MatchResult{Matched: true, Score: clampScore(score), Reason: "all(" + strings.Join(reasons, ";") + ")"}
+}
+
+// Validate checks every child that supports validation.
+func (m allOfMatcher) Validate() error { return validateMatchers(m) }
+
+type anyOfMatcher []Matcher
+
+// Match implements Matcher.
+func (m anyOfMatcher) Match(ac ActivationContext) MatchResult {
+	return m.MatchContext(context.Background(), ac)
+}
+
+// MatchContext implements ContextMatcher, forwarding ctx to children.
+func (m anyOfMatcher) MatchContext(ctx context.Context, ac ActivationContext) MatchResult {
+	var best MatchResult
+	for _, child := range m {
+		res := matchChild(ctx, child, ac)
+		if res.Matched && (!best.Matched || res.BetterThan(best)) {
+			best = res
+		}
+	}
+	if !best.Matched {
+		return MatchResult{}
+	}
+	return MatchResult{Matched: true, Score: best.Score, Reason: "any(" + best.Reason + ")"}
+}
+
+// Validate checks every child that supports validation.
+func (m anyOfMatcher) Validate() error { return validateMatchers(m) }
+
+type notMatcher struct {
+	inner Matcher
+}
+
+// Match implements Matcher.
+func (m notMatcher) Match(ac ActivationContext) MatchResult {
+	return m.MatchContext(context.Background(), ac)
+}
+
+// MatchContext implements ContextMatcher, forwarding ctx to the child.
+func (m notMatcher) MatchContext(ctx context.Context, ac ActivationContext) MatchResult {
+	if m.inner == nil {
+		return MatchResult{}
+	}
+	res := matchChild(ctx, m.inner, ac)
+	if res.Matched {
+		return MatchResult{}
+	}
+	return MatchResult{Matched: true, Score: clampScore(1 - res.Score), Reason: "not"}
+}
+
+// Validate checks the child when it supports validation.
+func (m notMatcher) Validate() error {
+	if m.inner == nil {
+		return errors.New("skills: not matcher has no child")
+	}
+	return validateMatchers([]Matcher{m.inner})
+}
+
+func matchChild(ctx context.Context, matcher Matcher, ac ActivationContext) MatchResult {
+	if cm, ok := matcher.(ContextMatcher); ok {
+		return cm.MatchContext(ctx, ac)
+	}
+	return matcher.Match(ac)
+}
+
+func validateMatchers(matchers []Matcher) error {
+	for _, matcher := range matchers {
+		if v, ok := matcher.(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func compactMatchers(matchers []Matcher) []Matcher {
+	out := make([]Matcher, 0, len(matchers))
+	for _, matcher := range matchers {
+		if matcher != nil {
+			out = append(out, matcher)
+		}
+	}
+	return out
+}
+
 func normalizeTokens(values []string) []string {
 	if len(values) == 0 {
 		return nil
@@ -1,6 +1,10 @@
 package skills
 
-import "testing"
+import (
+	"context"
+	"regexp"
+	"testing"
+)
 
 func TestActivationContextCloneIsolation(t *testing.T) {
 	ctx := ActivationContext{
@@ -148,3 +152,107 @@ func TestHelpersAndEdgeCases(t *testing.T) {
 		t.Fatalf("tokenSet should dedupe and ignore empty")
 	}
 }
+
+func TestRegexMatcher(t *testing.T) {
+	m := &RegexMatcher{Pattern: `deploy (?P<env>\w+) v(\d+)`}
+	res := m.Match(ActivationContext{Prompt: "please deploy staging v42 now"})
+	if !res.Matched || res.Score != 0.7 {
+		t.Fatalf("expected match with default score, got %+v", res)
+	}
+	if res.Reason != "regex|env=staging|2=42" {
+		t.Fatalf("unexpected reason %q", res.Reason)
+	}
+	if m.Match(ActivationContext{Prompt: "deploy"}).Matched {
+		t.Fatal("expected no match")
+	}
+
+	pre := &RegexMatcher{Regexp: regexp.MustCompile(`(?i)^hotfix`), Score: 0.9}
+	if res := pre.Match(ActivationContext{Prompt: "HOTFIX the build"}); !res.Matched || res.Score != 0.9 || res.Reason != "regex" {
+		t.Fatalf("unexpected precompiled result %+v", res)
+	}
+
+	bad := &RegexMatcher{Pattern: "deploy ("}
+	if bad.Match(ActivationContext{Prompt: "deploy ("}).Matched {
+		t.Fatal("invalid pattern must not match")
+	}
+	if err := bad.Validate(); err == nil {
+		t.Fatal("expected validation error")
+	}
+	reg := NewRegistry()
+	handler := HandlerFunc(func(context.Context, ActivationContext) (Result, error) { return Result{}, nil })
+	if err := reg.Register(Definition{Name: "broken", Matchers: []Matcher{bad}}, handler); err == nil {
+		t.Fatal("expected Register to reject invalid regex")
+	}
+	if err := reg.Register(Definition{Name: "ok", Matchers: []Matcher{m}}, handler); err != nil {
+		t.Fatalf("register valid regex: %v", err)
+	}
+}
+
+func TestCompositeMatchers(t *testing.T) {
+	fixed := func(score float64, reason string) Matcher {
+		return MatcherFunc(func(ActivationContext) MatchResult {
+			return MatchResult{Matched: true, Score: score, Reason: reason}
+		})
+	}
+	miss := MatcherFunc(func(ActivationContext) MatchResult { return MatchResult{} })
+
+	if res := AllOf(fixed(0.9, "a"), fixed(0.6, "b")).Match(ActivationContext{}); !res.Matched || res.Score != 0.6 || res.Reason != "all(a;b)" {
+		t.Fatalf("unexpected AllOf result %+v", res)
+	}
+	if AllOf(fixed(0.9, "a"), miss).Match(ActivationContext{}).Matched {
+		t.Fatal("AllOf must fail when a child misses")
+	}
+	if AllOf().Match(ActivationContext{}).Matched {
+		t.Fatal("empty AllOf must not match")
+	}
+	if res := AnyOf(miss, fixed(0.6, "b"), fixed(0.8, "c")).Match(ActivationContext{}); !res.Matched || res.Score != 0.8 || res.Reason != "any(c)" {
+		t.Fatalf("unexpected AnyOf result %+v", res)
+	}
+	if res := Not(miss).Match(ActivationContext{}); !res.Matched || res.Score != 0.99 {
+		t.Fatalf("unexpected Not result %+v", res)
+	}
+	if Not(fixed(0.5, "a")).Match(ActivationContext{}).Matched {
+		t.Fatal("Not must invert a match")
+	}
+
+	// prod AND (high OR critical severity) AND NOT (keyword drill)
+	incident := AllOf(
+		TagMatcher{Require: map[string]string{"env": "prod"}},
+		AnyOf(
+			TagMatcher{Require: map[string]string{"severity": "high"}},
+			AllOf(TagMatcher{Require: map[string]string{"severity": "critical"}}, KeywordMatcher{Any: []string{"incident"}}),
+		),
+		Not(KeywordMatcher{Any: []string{"drill"}}),
+	)
+	cases := []struct {
+		prompt string
+		tags   map[string]string
+		want   bool
+	}{
+		{"incident in checkout", map[string]string{"env": "prod", "severity": "high"}, true},
+		{"incident in checkout", map[string]string{"env": "prod", "severity": "critical"}, true},
+		{"latency report", map[string]string{"env": "prod", "severity": "critical"}, false},
+		{"incident drill", map[string]string{"env": "prod", "severity": "high"}, false},
+		{"incident in checkout", map[string]string{"env": "staging", "severity": "high"}, false},
+	}
+	for _, tc := range cases {
+		res := incident.Match(ActivationContext{Prompt: tc.prompt, Tags: tc.tags})
+		if res.Matched != tc.want {
+			t.Fatalf("prompt %q tags %v: got %+v, want matched=%v", tc.prompt, tc.tags, res, tc.want)
+		}
+	}
+
+	reg := NewRegistry()
+	handler := HandlerFunc(func(context.Context, ActivationContext) (Result, error) { return Result{}, nil })
+	nested := AllOf(AnyOf(Not(&RegexMatcher{Pattern: "("})))
+	if err := reg.Register(Definition{Name: "nested", Matchers: []Matcher{nested}}, handler); err == nil {
+		t.Fatal("expected Register to reject invalid regex nested in composite matchers")
+	}
+	if err := reg.Register(Definition{Name: "incident", Matchers: []Matcher{incident}}, handler); err != nil {
+		t.Fatalf("register composite: %v", err)
+	}
+	matches := reg.Match(ActivationContext{Prompt: "incident now", Tags: map[string]string{"env": "prod", "severity": "high"}})
+	if len(matches) != 1 || matches[0].Skill.Definition().Name != "incident" {
+		t.Fatalf("expected registry to activate composite skill, got %+v", matches)
+	}
+}
@@ -0,0 +1,33 @@
+package skills
+
+// MutexCandidate is one member of a potential mutex group.
+type MutexCandidate struct {
+	Key      string
+	Priority int
+	Name     string
+}
+
+// MutexWinners returns, for each non-empty Key, the index of the candidate
+// allowed to run: the highest Priority, with ties going to the smallest Name.
+// Score deliberately plays no part so the winner does not depend on matcher
+// heuristics. Skills and subagents both resolve MutexKey groups with it.
+func MutexWinners(cands []MutexCandidate) map[string]int {
+	winners := map[string]int{}
+	for i, cand := range cands {
+		if cand.Key == "" {
+			continue
+		}
+		j, ok := winners[cand.Key]
+		if !ok || mutexBeats(cand, cands[j]) {
+			winners[cand.Key] = i
+		}
+	}
+	return winners
+}
+
+func mutexBeats(a, b MutexCandidate) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	return a.Name < b.Name
+}
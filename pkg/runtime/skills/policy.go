@@ -0,0 +1,143 @@
+package skills
+
+import (
+	"context"
+	"time"
+)
+
+// ActivationPolicy gathers the knobs that limit auto-activation. Every limit
+// is disabled by its zero value. Each suppressed skill is reported in the
+// MatchReport with the SuppressedBy* cause of the first limit it hit. Mutex
+// groups are resolved first, then the limits in field order.
+type ActivationPolicy struct {
+	// MinScore drops matches scoring below it.
+	MinScore float64
+	// DedupWindow suppresses a skill already activated for an identical
+	// ActivationContext within the window.
+	DedupWindow time.Duration
+	// Cooldown suppresses a skill activated for any context within the
+	// cooldown.
+	Cooldown time.Duration
+	// MaxPerActivation caps the activations returned by one match pass.
+	MaxPerActivation int
+	// MaxConcurrent bounds the skills ExecuteMatched runs at once across all
+	// callers. Match does not execute and ignores it.
+	MaxConcurrent int
+}
+
+// WithActivationPolicy replaces the registry's activation policy. Negative
+// values are treated as zero. Disabling DedupWindow or Cooldown forgets the
+// activations recorded for them.
+func (r *Registry) WithActivationPolicy(p ActivationPolicy) *Registry {
+	p.MinScore = max(p.MinScore, 0)
+	p.DedupWindow = max(p.DedupWindow, 0)
+	p.Cooldown = max(p.Cooldown, 0)
+	p.MaxPerActivation = max(p.MaxPerActivation, 0)
+	p.MaxConcurrent = max(p.MaxConcurrent, 0)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policy = p
+	if p.DedupWindow == 0 {
+		r.recent = nil
+	}
+	if p.Cooldown == 0 {
+		r.lastActivated = nil
+	}
+	return r
+}
+
+// Policy returns the activation policy currently applied by the registry.
+func (r *Registry) Policy() ActivationPolicy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.policy
+}
+
+// MatchedExecution is the outcome of running one activation.
+type MatchedExecution struct {
+	Activation
+	Result Result
+	Err    error
+}
+
+// ExecuteMatched matches ac like MatchTrace and runs the resulting
+// activations in ranking order. An activation that would exceed
+// MaxConcurrent when it is about to start is suppressed with
+// SuppressedByConcurrency rather than queued. A handler error is recorded on
+// its execution and does not stop the others.
+func (r *Registry) ExecuteMatched(ctx context.Context, ac ActivationContext) ([]MatchedExecution, MatchReport) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var execs []MatchedExecution
+	report, _ := r.ExecuteMatchedFunc(ctx, ac, func(activation Activation) error {
+		res, err := activation.Skill.Execute(ctx, ac)
+		execs = append(execs, MatchedExecution{Activation: activation, Result: res, Err: err})
+		return nil
+	})
+	return execs, report
+}
+
+// ExecuteMatchedFunc is ExecuteMatched with the execution left to run, which
+// is called for each activation while it holds a concurrency slot. A non-nil
+// error from run stops the pass and is returned. The report lists the
+// activations that ran; skills suppressed for concurrency are appended to
+// Suppressed after those dropped by the match pass.
+func (r *Registry) ExecuteMatchedFunc(ctx context.Context, ac ActivationContext, run func(Activation) error) (MatchReport, error) {
+	report := r.matchTrace(ctx, ac, matchExecute)
+	matched := report.Activations
+	report.Activations = nil
+	for _, activation := range matched {
+		started, err := r.runActivation(activation, ac, run)
+		if !started {
+			report.Suppressed = append(report.Suppressed, Suppression{Activation: activation, Cause: SuppressedByConcurrency})
+			continue
+		}
+		report.Activations = append(report.Activations, activation)
+		if err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// runActivation calls run for activation if a concurrency slot is free,
+// holding the slot only for the duration of the call.
+func (r *Registry) runActivation(activation Activation, ac ActivationContext, run func(Activation) error) (bool, error) {
+	if !r.acquire(activation.Skill.definition.Name, ac) {
+		return false, nil
+	}
+	defer r.release()
+	return true, run(activation)
+}
+
+// acquire takes a concurrency slot for name and records the activation for
+// the dedup window and cooldown. It reports false when MaxConcurrent slots
+// are already held.
+func (r *Registry) acquire(name string, ac ActivationContext) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	policy := r.policy
+	if policy.MaxConcurrent > 0 && r.inflight >= policy.MaxConcurrent {
+		return false
+	}
+	r.inflight++
+	var fingerprint string
+	if policy.DedupWindow > 0 {
+		fingerprint = activationFingerprint(ac)
+	}
+	now := time.Now()
+	if r.now != nil {
+		now = r.now()
+	}
+	r.recordLocked(name, fingerprint, now, policy)
+	return true
+}
+
+func (r *Registry) release() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.inflight > 0 {
+		r.inflight--
+	}
+}
@@ -0,0 +1,148 @@
+package skills
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestActivationPolicySuppressionCauses(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	r := NewRegistry().WithActivationPolicy(ActivationPolicy{
+		MinScore:         0.5,
+		DedupWindow:      time.Minute,
+		Cooldown:         10 * time.Second,
+		MaxPerActivation: 2,
+		MaxConcurrent:    1,
+	})
+	r.now = func() time.Time { return now }
+
+	var ran []string
+	register := func(name string, priority int, mutex string, score float64) {
+		t.Helper()
+		matcher := MatcherFunc(func(ActivationContext) MatchResult {
+			return MatchResult{Matched: true, Score: score, Reason: name}
+		})
+		handler := HandlerFunc(func(context.Context, ActivationContext) (Result, error) {
+			ran = append(ran, name)
+			if name == "alpha" {
+				return Result{}, errors.New("alpha failed")
+			}
+			return Result{Output: name}, nil
+		})
+		if err := r.Register(Definition{Name: name, Priority: priority, MutexKey: mutex, Matchers: []Matcher{matcher}}, handler); err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+	}
+	register("alpha", 3, "deploy", 0.9)
+	register("beta", 2, "deploy", 0.9)
+	register("gamma", 1, "", 0.9)
+	register("delta", 0, "", 0.9)
+	register("low", 5, "", 0.3)
+
+	causes := func(report MatchReport) map[string]string {
+		out := map[string]string{}
+		for _, a := range report.Activations {
+			out[a.Skill.Definition().Name] = "active"
+		}
+		for _, s := range report.Suppressed {
+			out[s.Skill.Definition().Name] = s.Cause
+		}
+		return out
+	}
+	expect := func(label string, got, want map[string]string) {
+		t.Helper()
+		for name, cause := range want {
+			if got[name] != cause {
+				t.Fatalf("%s: %s got %q, want %q (all: %v)", label, name, got[name], cause, got)
+			}
+		}
+	}
+
+	ctxA := ActivationContext{Prompt: "ship it"}
+	expect("first pass", causes(r.MatchTrace(context.Background(), ctxA)), map[string]string{
+		"low":   SuppressedByMinScore,
+		"alpha": "active",
+		"beta":  SuppressedByMutex,
+		"gamma": "active",
+		"delta": SuppressedByCap,
+	})
+
+	now = now.Add(time.Second)
+	expect("repeat", causes(r.MatchTrace(context.Background(), ctxA)), map[string]string{
+		"alpha": SuppressedByDedup,
+		"gamma": SuppressedByDedup,
+	})
+
+	now = now.Add(time.Second)
+	expect("other context", causes(r.MatchTrace(context.Background(), ActivationContext{Prompt: "roll back"})), map[string]string{
+		"low":   SuppressedByMinScore,
+		"alpha": SuppressedByCooldown,
+		"gamma": SuppressedByCooldown,
+	})
+
+	now = now.Add(2 * time.Minute)
+	execs, report := r.ExecuteMatched(context.Background(), ActivationContext{Prompt: "later"})
+	// Skills run one after another, so a single slot serves the whole pass.
+	expect("execute", causes(report), map[string]string{
+		"alpha": "active",
+		"beta":  SuppressedByMutex,
+		"gamma": "active",
+		"delta": SuppressedByCap,
+	})
+	if len(execs) != 2 || execs[0].Skill.Definition().Name != "alpha" || execs[0].Err == nil || execs[1].Result.Output != "gamma" {
+		t.Fatalf("expected alpha then gamma to run, alpha reporting its error, got %+v", execs)
+	}
+	if len(ran) != 2 || r.inflight != 0 {
+		t.Fatalf("expected two runs and released slots, ran=%v inflight=%d", ran, r.inflight)
+	}
+
+	if got := r.Policy(); got.MaxConcurrent != 1 || got.Cooldown != 10*time.Second {
+		t.Fatalf("unexpected policy %+v", got)
+	}
+	r.WithActivationPolicy(ActivationPolicy{MaxPerActivation: -1})
+	if got := r.Match(ctxA); len(got) != 4 {
+		t.Fatalf("expected empty policy to leave only mutex filtering, got %d", len(got))
+	}
+}
+
+func TestExecuteMatchedHoldsSlotOnlyWhileRunning(t *testing.T) {
+	r := NewRegistry().WithActivationPolicy(ActivationPolicy{MaxConcurrent: 1})
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	handler := HandlerFunc(func(_ context.Context, ac ActivationContext) (Result, error) {
+		if ac.Prompt == "slow" {
+			close(started)
+			<-unblock
+		}
+		return Result{Output: "ok"}, nil
+	})
+	if err := r.Register(Definition{Name: "solo"}, handler); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	done := make(chan MatchReport)
+	go func() {
+		_, report := r.ExecuteMatched(context.Background(), ActivationContext{Prompt: "slow"})
+		done <- report
+	}()
+	<-started
+	execs, report := r.ExecuteMatched(context.Background(), ActivationContext{Prompt: "fast"})
+	if len(execs) != 0 || len(report.Suppressed) != 1 || report.Suppressed[0].Cause != SuppressedByConcurrency {
+		t.Fatalf("expected the busy slot to suppress the second caller, got %+v", report)
+	}
+	close(unblock)
+	if first := <-done; len(first.Activations) != 1 {
+		t.Fatalf("expected the first caller to run, got %+v", first)
+	}
+	if r.inflight != 0 {
+		t.Fatalf("expected slot released, inflight=%d", r.inflight)
+	}
+
+	stop := errors.New("stop")
+	report, err := r.ExecuteMatchedFunc(context.Background(), ActivationContext{Prompt: "again"}, func(Activation) error { return stop })
+	if !errors.Is(err, stop) || len(report.Activations) != 1 || r.inflight != 0 {
+		t.Fatalf("expected run error to be returned with the slot released, got %+v err=%v inflight=%d", report, err, r.inflight)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 var (
@@ -15,6 +16,8 @@ var (
 	ErrDuplicateSkill = errors.New("skills: duplicate registration")
 	// ErrUnknownSkill is returned by Execute/Get when a skill is missing.
 	ErrUnknownSkill = errors.New("skills: unknown skill")
+	// ErrSkillTimeout is returned when a handler exceeds Definition.Timeout.
+	ErrSkillTimeout = errors.New("skills: execution timed out")
 )
 
 // Definition describes a declarative skill registration entry.
@@ -28,6 +31,14 @@ type Definition struct {
 	DisableAutoActivation bool
 	Metadata              map[string]string
 	Matchers              []Matcher
+	// StopOnScore short-circuits matcher evaluation: once a matcher reports a
+	// score at or above this threshold the remaining matchers are skipped.
+	// Matchers run in slice order, so list cheap matchers first. Zero disables.
+	StopOnScore float64
+	// Timeout bounds each handler execution. On expiry Execute returns
+	// ErrSkillTimeout without waiting for handlers that ignore cancellation.
+	// Zero disables the bound.
+	Timeout time.Duration
 }
 
 // Validate performs cheap sanity checks before accepting a definition.
@@ -39,6 +50,13 @@ func (d Definition) Validate() error {
 	if !isValidSkillName(name) {
 		return fmt.Errorf("skills: invalid name %q (must be 1-64 chars, lowercase alphanumeric + hyphens, cannot start/end with hyphen)", d.Name)
 	}
+	for i, matcher := range d.Matchers {
+		if v, ok := matcher.(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return fmt.Errorf("skills: matcher %d of %q: %w", i, d.Name, err)
+			}
+		}
+	}
 	return nil
 }
 
@@ -97,7 +115,7 @@ func (s *Skill) Execute(ctx context.Context, ac ActivationContext) (Result, erro
 	if s == nil || s.handler == nil {
 		return Result{}, errors.New("skills: skill is nil")
 	}
-	res, err := s.handler.Execute(ctx, ac)
+	res, err := s.run(ctx, ac)
 	if err != nil {
 		return Result{}, err
 	}
@@ -107,6 +125,41 @@ func (s *Skill) Execute(ctx context.Context, ac ActivationContext) (Result, erro
 	return res.clone(), nil
 }
 
+// run invokes the handler, bounding it by Definition.Timeout when set. Like
+// runMatcher, a handler that overruns is abandoned on its goroutine.
+func (s *Skill) run(ctx context.Context, ac ActivationContext) (Result, error) {
+	timeout := s.definition.Timeout
+	if timeout <= 0 {
+		return s.handler.Execute(ctx, ac)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	hctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	type outcome struct {
+		res Result
+		err error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		res, err := s.handler.Execute(hctx, ac)
+		done <- outcome{res, err}
+	}()
+	select {
+	case out := <-done:
+		if out.err != nil && errors.Is(hctx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return Result{}, fmt.Errorf("%w: %s after %s", ErrSkillTimeout, s.definition.Name, timeout)
+		}
+		return out.res, out.err
+	case <-hctx.Done():
+		if ctx.Err() != nil {
+			return Result{}, ctx.Err()
+		}
+		return Result{}, fmt.Errorf("%w: %s after %s", ErrSkillTimeout, s.definition.Name, timeout)
+	}
+}
+
 // Handler exposes the underlying skill handler for observability and testing.
 func (s *Skill) Handler() Handler {
 	if s == nil {
@@ -117,13 +170,22 @@ func (s *Skill) Handler() Handler {
 
 // Registry coordinates skill registration and activation.
 type Registry struct {
-	mu     sync.RWMutex
-	skills map[string]*Skill
+	mu           sync.RWMutex
+	skills       map[string]*Skill
+	matchTimeout time.Duration
+	maxMatchers  int
+	policy       ActivationPolicy
+	stats        MatcherStats // cumulative across match passes
+
+	recent        map[string]time.Time // dedupKey -> last activation
+	lastActivated map[string]time.Time // skill name -> last activation, for Cooldown
+	inflight      int                  // activations running under ExecuteMatched
+	now           func() time.Time
 }
 
 // NewRegistry builds an empty registry.
 func NewRegistry() *Registry {
-	return &Registry{skills: map[string]*Skill{}}
+	return &Registry{skills: map[string]*Skill{}, now: time.Now}
 }
 
 // Register adds a skill definition + handler pair.
@@ -146,6 +208,106 @@ func (r *Registry) Register(def Definition, handler Handler) error {
 	return nil
 }
 
+// Unregister removes the named skill, reporting whether it was registered.
+// Its dedup and cooldown history is forgotten.
+func (r *Registry) Unregister(name string) bool {
+	key := strings.ToLower(strings.TrimSpace(name))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.skills[key]; !ok {
+		return false
+	}
+	delete(r.skills, key)
+	r.forgetLocked(key)
+	return true
+}
+
+// Replace swaps the definition and handler of an already registered skill.
+// It returns ErrUnknownSkill when no skill has def's name.
+func (r *Registry) Replace(def Definition, handler Handler) error {
+	if err := def.Validate(); err != nil {
+		return err
+	}
+	if handler == nil {
+		return errors.New("skills: handler is nil")
+	}
+	normalized := normalizeDefinition(def)
+	key := normalized.Name
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.skills[key]; !exists {
+		return ErrUnknownSkill
+	}
+	r.skills[key] = &Skill{definition: normalized, handler: handler}
+	return nil
+}
+
+// WithMatchTimeout bounds each ContextMatcher evaluation during Match. A
+// matcher that exceeds the timeout is treated as non-matching so one
+// degraded matcher cannot stall routing; MatchTrace reports it in
+// MatchReport.TimedOut. Zero disables the bound.
+func (r *Registry) WithMatchTimeout(d time.Duration) *Registry {
+	if d < 0 {
+		d = 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.matchTimeout = d
+	return r
+}
+
+// WithMaxMatchers caps how many matchers are evaluated per skill in one match
+// pass; the remainder are skipped and counted in MatcherStats.SkippedCap.
+// Zero (the default) or a negative n disables the cap.
+func (r *Registry) WithMaxMatchers(n int) *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxMatchers = max(n, 0)
+	return r
+}
+
+// MatcherStats returns the matcher counters accumulated over every match pass
+// since the registry was built, for export to metrics backends.
+func (r *Registry) MatcherStats() MatcherStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.stats
+}
+
+// WithMaxActivations caps Match at the n highest-ranked activations after
+// mutex filtering; the remainder are reported by MatchTrace as suppressed by
+// the cap. Zero (the default) or a negative n disables the cap. It sets
+// ActivationPolicy.MaxPerActivation.
+func (r *Registry) WithMaxActivations(n int) *Registry {
+	if n < 0 {
+		n = 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policy.MaxPerActivation = n
+	return r
+}
+
+// WithDedupWindow suppresses a skill that was already activated for an
+// identical ActivationContext within the last d, so repeated fragments of a
+// streaming conversation do not re-run it. Each returned activation refreshes
+// the window; suppressed skills appear in MatchTrace as SuppressedByDedup.
+// Zero (the default) disables deduplication and forgets past activations. It
+// sets ActivationPolicy.DedupWindow.
+func (r *Registry) WithDedupWindow(d time.Duration) *Registry {
+	if d < 0 {
+		d = 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policy.DedupWindow = d
+	if d == 0 {
+		r.recent = nil
+	}
+	return r
+}
+
 // Get fetches a skill by name.
 func (r *Registry) Get(name string) (*Skill, bool) {
 	key := strings.ToLower(strings.TrimSpace(name))
@@ -179,25 +341,135 @@ func (a Activation) Definition() Definition {
 	return a.Skill.Definition()
 }
 
+// Suppression causes reported by MatchTrace and ExecuteMatched.
+const (
+	SuppressedByMutex       = "mutex"
+	SuppressedByCap         = "max_activations"
+	SuppressedByDedup       = "dedup"
+	SuppressedByMinScore    = "min_score"
+	SuppressedByCooldown    = "cooldown"
+	SuppressedByConcurrency = "max_concurrent"
+)
+
+// Suppression is a skill that matched but was dropped from the result.
+type Suppression struct {
+	Activation
+	Cause string // one of the SuppressedBy* constants
+}
+
+// MatchReport is the outcome of a match pass: the activations Match returns
+// plus every matching skill that was suppressed, in ranking order.
+type MatchReport struct {
+	Activations []Activation
+	Suppressed  []Suppression
+	Matchers    MatcherStats
+	// TimedOut lists the matchers abandoned at the match timeout or on
+	// cancellation, in evaluation order.
+	TimedOut []MatcherTimeout
+}
+
+// MatcherTimeout records a matcher that was abandoned during a match pass.
+type MatcherTimeout struct {
+	Skill  string
+	Reason string
+}
+
+// MatcherStats counts the matchers of auto-activating skills considered in a
+// match pass. Every non-nil matcher lands in exactly one bucket.
+type MatcherStats struct {
+	Evaluated int
+	// SkippedShortCircuit counts matchers left unevaluated because an earlier
+	// one reached Definition.StopOnScore.
+	SkippedShortCircuit int
+	// SkippedTimeout counts matchers abandoned at the match timeout or on
+	// cancellation.
+	SkippedTimeout int
+	// SkippedCap counts matchers beyond the WithMaxMatchers cap.
+	SkippedCap int
+}
+
+// Skipped returns the number of matchers that did not produce a result.
+func (s MatcherStats) Skipped() int {
+	return s.SkippedShortCircuit + s.SkippedTimeout + s.SkippedCap
+}
+
+func (s *MatcherStats) add(o MatcherStats) {
+	s.Evaluated += o.Evaluated
+	s.SkippedShortCircuit += o.SkippedShortCircuit
+	s.SkippedTimeout += o.SkippedTimeout
+	s.SkippedCap += o.SkippedCap
+}
+
 // Match evaluates all auto-activating skills against the provided context while
 // enforcing priority ordering and mutex groups.
 func (r *Registry) Match(ctx ActivationContext) []Activation {
+	return r.MatchContext(context.Background(), ctx)
+}
+
+// MatchContext is like Match but passes ctx to context-aware matchers, each
+// bounded by the registry's match timeout.
+func (r *Registry) MatchContext(ctx context.Context, ac ActivationContext) []Activation {
+	return r.MatchTrace(ctx, ac).Activations
+}
+
+// MatchTrace runs the same pass as MatchContext, including recording
+// activations for the dedup window and cooldown, and also reports the
+// matching skills that the ActivationPolicy suppressed.
+func (r *Registry) MatchTrace(ctx context.Context, ac ActivationContext) MatchReport {
+	return r.matchTrace(ctx, ac, matchRecord)
+}
+
+// matchMode selects the side effects of a match pass.
+type matchMode int
+
+const (
+	// matchRecord records activations for the dedup window and cooldown.
+	matchRecord matchMode = iota
+	// matchExecute defers recording to acquire, which runs as each
+	// activation starts executing.
+	matchExecute
+	// matchDryRun leaves the registry untouched.
+	matchDryRun
+)
+
+// matchTrace ranks the matching skills and applies the policy in a fixed
+// order: mutex, min score, dedup, cooldown and cap. Concurrency is checked
+// later, by acquire, as each activation of an execute pass starts.
+func (r *Registry) matchTrace(ctx context.Context, ac ActivationContext, mode matchMode) MatchReport {
+	matches, _, report := r.rank(ctx, ac)
+	return r.applyPolicy(matches, report, ac, mode)
+}
+
+// rank evaluates every auto-activating skill, returning the matches in
+// ranking order, the misses sorted by name and a report carrying the matcher
+// stats and timeouts of the pass.
+func (r *Registry) rank(ctx context.Context, ac ActivationContext) ([]Activation, []*Skill, MatchReport) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	snapshot := r.snapshot()
-	var matches []Activation
+	r.mu.RLock()
+	timeout := r.matchTimeout
+	maxMatchers := r.maxMatchers
+	r.mu.RUnlock()
+	var (
+		matches []Activation
+		misses  []*Skill
+		report  MatchReport
+	)
 	for _, skill := range snapshot {
 		def := skill.definition
 		if def.DisableAutoActivation {
 			continue
 		}
-		result, ok := evaluate(skill, ctx)
+		result, ok := evaluate(ctx, skill, ac, timeout, maxMatchers, &report)
 		if !ok {
+			misses = append(misses, skill)
 			continue
 		}
 		matches = append(matches, Activation{Skill: skill, Score: result.Score, Reason: result.Reason})
 	}
-	if len(matches) == 0 {
-		return nil
-	}
+	sort.Slice(misses, func(i, j int) bool { return misses[i].definition.Name < misses[j].definition.Name })
 	sort.SliceStable(matches, func(i, j int) bool {
 		di := matches[i].Skill.definition
 		dj := matches[j].Skill.definition
@@ -209,22 +481,93 @@ func (r *Registry) Match(ctx ActivationContext) []Activation {
 		}
 		return di.Name < dj.Name
 	})
+	return matches, misses, report
+}
 
-	selected := matches[:0]
-	seen := map[string]struct{}{}
-	for _, activation := range matches {
-		key := activation.Skill.definition.MutexKey
-		if key == "" {
-			selected = append(selected, activation)
+// applyPolicy filters ranked matches through the activation policy, filling
+// in the activations and suppressions of report.
+func (r *Registry) applyPolicy(matches []Activation, report MatchReport, ac ActivationContext, mode matchMode) MatchReport {
+	var fingerprint string
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if mode != matchDryRun {
+		r.stats.add(report.Matchers)
+	}
+	if len(matches) == 0 {
+		return report
+	}
+	policy := r.policy
+	now := time.Now()
+	if r.now != nil {
+		now = r.now()
+	}
+	if policy.DedupWindow > 0 {
+		fingerprint = activationFingerprint(ac)
+		r.pruneRecentLocked(now, policy.DedupWindow)
+	}
+	suppress := func(activation Activation, cause string) {
+		report.Suppressed = append(report.Suppressed, Suppression{Activation: activation, Cause: cause})
+	}
+	// Mutex groups are resolved over every match up front, so a winner
+	// suppressed by another limit never lets a rival through.
+	cands := make([]MutexCandidate, len(matches))
+	for i, activation := range matches {
+		def := activation.Skill.definition
+		cands[i] = MutexCandidate{Key: def.MutexKey, Priority: def.Priority, Name: def.Name}
+	}
+	winners := MutexWinners(cands)
+	for i, activation := range matches {
+		name := activation.Skill.definition.Name
+		if key := activation.Skill.definition.MutexKey; key != "" && winners[key] != i {
+			suppress(activation, SuppressedByMutex)
+			continue
+		}
+		if policy.MinScore > 0 && activation.Score < policy.MinScore {
+			suppress(activation, SuppressedByMinScore)
 			continue
 		}
-		if _, ok := seen[key]; ok {
+		if policy.DedupWindow > 0 {
+			if _, ok := r.recent[dedupKey(name, fingerprint)]; ok {
+				suppress(activation, SuppressedByDedup)
+				continue
+			}
+		}
+		if policy.Cooldown > 0 {
+			if at, ok := r.lastActivated[name]; ok && now.Sub(at) < policy.Cooldown {
+				suppress(activation, SuppressedByCooldown)
+				continue
+			}
+		}
+		if policy.MaxPerActivation > 0 && len(report.Activations) >= policy.MaxPerActivation {
+			suppress(activation, SuppressedByCap)
 			continue
 		}
-		seen[key] = struct{}{}
-		selected = append(selected, activation)
+		report.Activations = append(report.Activations, activation)
+	}
+	if mode != matchRecord {
+		return report
+	}
+	for _, activation := range report.Activations {
+		r.recordLocked(activation.Skill.definition.Name, fingerprint, now, policy)
+	}
+	return report
+}
+
+// recordLocked notes an activation of name for the dedup window and
+// cooldown. Caller holds r.mu.
+func (r *Registry) recordLocked(name, fingerprint string, now time.Time, policy ActivationPolicy) {
+	if policy.DedupWindow > 0 {
+		if r.recent == nil {
+			r.recent = map[string]time.Time{}
+		}
+		r.recent[dedupKey(name, fingerprint)] = now
+	}
+	if policy.Cooldown > 0 {
+		if r.lastActivated == nil {
+			r.lastActivated = map[string]time.Time{}
+		}
+		r.lastActivated[name] = now
 	}
-	return selected
 }
 
 // List returns the registered skill definitions sorted by priority + name.
@@ -253,17 +596,33 @@ func (r *Registry) snapshot() []*Skill {
 	return out
 }
 
-func evaluate(skill *Skill, ctx ActivationContext) (MatchResult, bool) {
+// evaluate runs skill's matchers in order, evaluating at most maxMatchers of
+// them when positive, and adds each matcher's fate to report.
+func evaluate(ctx context.Context, skill *Skill, ac ActivationContext, timeout time.Duration, maxMatchers int, report *MatchReport) (MatchResult, bool) {
+	stats := &report.Matchers
 	if len(skill.definition.Matchers) == 0 {
 		return MatchResult{Matched: true, Score: 0.5, Reason: "always"}, true
 	}
 	var best MatchResult
 	matched := false
-	for _, matcher := range skill.definition.Matchers {
+	ran := 0
+	matchers := skill.definition.Matchers
+	for i, matcher := range matchers {
 		if matcher == nil {
 			continue
 		}
-		res := matcher.Match(ctx)
+		if maxMatchers > 0 && ran >= maxMatchers {
+			stats.SkippedCap += countMatchers(matchers[i:])
+			break
+		}
+		ran++
+		res, timedOut := runMatcher(ctx, matcher, ac, timeout)
+		if timedOut {
+			stats.SkippedTimeout++
+			report.TimedOut = append(report.TimedOut, MatcherTimeout{Skill: skill.definition.Name, Reason: res.Reason})
+			continue
+		}
+		stats.Evaluated++
 		if !res.Matched {
 			continue
 		}
@@ -271,10 +630,52 @@ func evaluate(skill *Skill, ctx ActivationContext) (MatchResult, bool) {
 			best = res
 			matched = true
 		}
+		if stop := skill.definition.StopOnScore; stop > 0 && res.Score >= stop {
+			stats.SkippedShortCircuit += countMatchers(matchers[i+1:])
+			break
+		}
 	}
 	return best, matched
 }
 
+func countMatchers(matchers []Matcher) int {
+	n := 0
+	for _, m := range matchers {
+		if m != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// runMatcher evaluates a single matcher. Context-aware matchers run on their
+// own goroutine so a matcher that ignores cancellation is abandoned rather
+// than awaited once the timeout elapses. It reports whether the matcher was
+// abandoned, with the cause in the result's Reason.
+func runMatcher(ctx context.Context, matcher Matcher, ac ActivationContext, timeout time.Duration) (MatchResult, bool) {
+	cm, ok := matcher.(ContextMatcher)
+	if !ok {
+		return matcher.Match(ac), false
+	}
+	if timeout <= 0 {
+		return cm.MatchContext(ctx, ac), false
+	}
+	mctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan MatchResult, 1)
+	go func() { done <- cm.MatchContext(mctx, ac) }()
+	select {
+	case res := <-done:
+		return res, false
+	case <-mctx.Done():
+		reason := fmt.Sprintf("matcher cancelled: %v", mctx.Err())
+		if ctx.Err() == nil {
+			reason = fmt.Sprintf("matcher timed out after %s", timeout)
+		}
+		return MatchResult{Reason: reason}, true
+	}
+}
+
 func normalizeDefinition(def Definition) Definition {
 	normalized := Definition{
 		Name:                  strings.ToLower(strings.TrimSpace(def.Name)),
@@ -282,6 +683,8 @@ func normalizeDefinition(def Definition) Definition {
 		Priority:              def.Priority,
 		MutexKey:              strings.ToLower(strings.TrimSpace(def.MutexKey)),
 		DisableAutoActivation: def.DisableAutoActivation,
+		StopOnScore:           def.StopOnScore,
+		Timeout:               max(def.Timeout, 0),
 	}
 	if normalized.Name == "" {
 		normalized.Name = strings.TrimSpace(def.Name)
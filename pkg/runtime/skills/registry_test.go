@@ -3,7 +3,9 @@ package skills
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestRegistryRegisterAndExecute(t *testing.T) {
@@ -77,6 +79,81 @@ func TestRegistryMatchOrderingAndMutex(t *testing.T) {
 	}
 }
 
+func TestRegistryMaxActivationsCapsMatches(t *testing.T) {
+	r := NewRegistry().WithMaxActivations(2)
+	handler := HandlerFunc(func(context.Context, ActivationContext) (Result, error) { return Result{}, nil })
+	for i, name := range []string{"env-b", "env-a", "low", "high", "mid", "top"} {
+		def := Definition{Name: name, Priority: i, Matchers: []Matcher{KeywordMatcher{Any: []string{"go"}}}}
+		if strings.HasPrefix(name, "env") {
+			def.MutexKey = "env"
+			def.Priority = 0
+		}
+		if err := r.Register(def, handler); err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+	}
+
+	ac := ActivationContext{Prompt: "go"}
+	matches := r.Match(ac)
+	if len(matches) != 2 || matches[0].Definition().Name != "top" || matches[1].Definition().Name != "mid" {
+		t.Fatalf("expected top-2 by priority, got %+v", matches)
+	}
+
+	report := r.MatchTrace(context.Background(), ac)
+	if len(report.Activations) != 2 {
+		t.Fatalf("trace activations mismatch: %+v", report.Activations)
+	}
+	var got []string
+	for _, sup := range report.Suppressed {
+		got = append(got, sup.Definition().Name+":"+sup.Cause)
+	}
+	want := []string{"high:max_activations", "low:max_activations", "env-a:max_activations", "env-b:mutex"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("suppressed = %v, want %v", got, want)
+	}
+
+	r.WithMaxActivations(0)
+	if all := r.Match(ac); len(all) != 5 {
+		t.Fatalf("expected cap removal to return all non-mutex-suppressed matches, got %d", len(all))
+	}
+}
+
+func TestRegistryDedupWindowSuppressesIdenticalRepeats(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	r := NewRegistry().WithDedupWindow(time.Minute)
+	r.now = func() time.Time { return now }
+	handler := HandlerFunc(func(context.Context, ActivationContext) (Result, error) { return Result{}, nil })
+	if err := r.Register(Definition{Name: "deploy", Matchers: []Matcher{KeywordMatcher{Any: []string{"deploy"}}}}, handler); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	first := ActivationContext{Prompt: "deploy now", Tags: map[string]string{"a": "1", "b": "2"}, Channels: []string{"x", "y"}}
+	if got := r.Match(first); len(got) != 1 {
+		t.Fatalf("expected first activation, got %+v", got)
+	}
+
+	// Same context with map/slice order shuffled hashes identically.
+	repeat := ActivationContext{Prompt: "deploy now", Tags: map[string]string{"b": "2", "a": "1"}, Channels: []string{"y", "x"}}
+	report := r.MatchTrace(context.Background(), repeat)
+	if len(report.Activations) != 0 || len(report.Suppressed) != 1 || report.Suppressed[0].Cause != SuppressedByDedup {
+		t.Fatalf("expected identical repeat to be deduped, got %+v", report)
+	}
+
+	if got := r.Match(ActivationContext{Prompt: "deploy later"}); len(got) != 1 {
+		t.Fatalf("expected differing context to activate, got %+v", got)
+	}
+
+	now = now.Add(time.Minute)
+	if got := r.Match(repeat); len(got) != 1 {
+		t.Fatalf("expected activation after the window expired, got %+v", got)
+	}
+
+	r.WithDedupWindow(0)
+	if got := r.Match(repeat); len(got) != 1 {
+		t.Fatalf("expected dedup disabled, got %+v", got)
+	}
+}
+
 func TestRegistryListSorted(t *testing.T) {
 	r := NewRegistry()
 	if err := r.Register(Definition{Name: "b", Priority: 1}, HandlerFunc(func(ctx context.Context, ac ActivationContext) (Result, error) { return Result{}, nil })); err != nil {
@@ -160,3 +237,283 @@ func TestRegistryDefaultsAndValidation(t *testing.T) {
 		}
 	}
 }
+
+func TestRegistryMatchStopOnScoreSkipsRemainingMatchers(t *testing.T) {
+	r := NewRegistry()
+	expensiveCalls := 0
+	cheap := MatcherFunc(func(ActivationContext) MatchResult {
+		return MatchResult{Matched: true, Score: 0.9, Reason: "cheap"}
+	})
+	expensive := MatcherFunc(func(ActivationContext) MatchResult {
+		expensiveCalls++
+		return MatchResult{Matched: true, Score: 0.95, Reason: "expensive"}
+	})
+	handler := HandlerFunc(func(context.Context, ActivationContext) (Result, error) { return Result{}, nil })
+	if err := r.Register(Definition{Name: "fast", StopOnScore: 0.8, Matchers: []Matcher{cheap, expensive}}, handler); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	matches := r.Match(ActivationContext{Prompt: "anything"})
+	if len(matches) != 1 || matches[0].Reason != "cheap" {
+		t.Fatalf("expected cheap matcher to decide activation, got %+v", matches)
+	}
+	if expensiveCalls != 0 {
+		t.Fatalf("expected expensive matcher to be skipped, called %d times", expensiveCalls)
+	}
+
+	if err := r.Register(Definition{Name: "slow", StopOnScore: 0.95, Matchers: []Matcher{cheap, expensive}}, handler); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	r.Match(ActivationContext{Prompt: "anything"})
+	if expensiveCalls != 1 {
+		t.Fatalf("expected expensive matcher to run below stop score, called %d times", expensiveCalls)
+	}
+}
+
+func TestRegistryMatchTimeoutSkipsSlowMatcher(t *testing.T) {
+	r := NewRegistry().WithMatchTimeout(20 * time.Millisecond)
+	handler := HandlerFunc(func(context.Context, ActivationContext) (Result, error) { return Result{}, nil })
+
+	slow := ContextMatcherFunc(func(ctx context.Context, _ ActivationContext) MatchResult {
+		select {
+		case <-time.After(time.Second):
+			return MatchResult{Matched: true, Score: 1, Reason: "slow"}
+		case <-ctx.Done():
+			return MatchResult{}
+		}
+	})
+	fast := ContextMatcherFunc(func(context.Context, ActivationContext) MatchResult {
+		return MatchResult{Matched: true, Score: 0.4, Reason: "fast"}
+	})
+	if err := r.Register(Definition{Name: "flaky", Matchers: []Matcher{slow}}, handler); err != nil {
+		t.Fatalf("register flaky: %v", err)
+	}
+	if err := r.Register(Definition{Name: "mixed", Matchers: []Matcher{slow, fast}}, handler); err != nil {
+		t.Fatalf("register mixed: %v", err)
+	}
+	if err := r.Register(Definition{Name: "plain", Matchers: []Matcher{KeywordMatcher{Any: []string{"deploy"}}}}, handler); err != nil {
+		t.Fatalf("register plain: %v", err)
+	}
+
+	start := time.Now()
+	report := r.MatchTrace(context.Background(), ActivationContext{Prompt: "deploy now"})
+	matches := report.Activations
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("match pass stalled for %s", elapsed)
+	}
+	names := map[string]string{}
+	for _, m := range matches {
+		names[m.Skill.Definition().Name] = m.Reason
+	}
+	if _, ok := names["flaky"]; ok {
+		t.Fatalf("expected timed-out matcher to be skipped, got %v", names)
+	}
+	if names["mixed"] != "fast" {
+		t.Fatalf("expected remaining matcher to contribute, got %v", names)
+	}
+	if _, ok := names["plain"]; !ok {
+		t.Fatalf("expected plain matcher to match, got %v", names)
+	}
+
+	timedOut := map[string]string{}
+	for _, to := range report.TimedOut {
+		timedOut[to.Skill] = to.Reason
+	}
+	if len(timedOut) != 2 || !strings.Contains(timedOut["flaky"], "timed out") || timedOut["mixed"] == "" {
+		t.Fatalf("expected both slow matchers reported with a reason, got %+v", report.TimedOut)
+	}
+
+	res, abandoned := runMatcher(context.Background(), slow, ActivationContext{}, 10*time.Millisecond)
+	if !abandoned || res.Matched || res.Reason == "" {
+		t.Fatalf("expected timeout reason, got %+v", res)
+	}
+}
+
+func TestRegistryExecuteTimeout(t *testing.T) {
+	r := NewRegistry()
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	stubborn := HandlerFunc(func(context.Context, ActivationContext) (Result, error) {
+		<-release
+		return Result{Output: "late"}, nil
+	})
+	polite := HandlerFunc(func(ctx context.Context, _ ActivationContext) (Result, error) {
+		<-ctx.Done()
+		return Result{}, ctx.Err()
+	})
+	failing := HandlerFunc(func(context.Context, ActivationContext) (Result, error) {
+		return Result{}, errors.New("boom")
+	})
+	for name, h := range map[string]Handler{"stubborn": stubborn, "polite": polite, "failing": failing} {
+		if err := r.Register(Definition{Name: name, Timeout: 10 * time.Millisecond}, h); err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+	}
+	if err := r.Register(Definition{Name: "unbounded"}, HandlerFunc(func(ctx context.Context, _ ActivationContext) (Result, error) {
+		if _, ok := ctx.Deadline(); ok {
+			return Result{}, errors.New("unexpected deadline")
+		}
+		return Result{Output: "ok"}, nil
+	})); err != nil {
+		t.Fatalf("register unbounded: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := r.Execute(context.Background(), "stubborn", ActivationContext{}); !errors.Is(err, ErrSkillTimeout) {
+		t.Fatalf("expected timeout for stubborn handler, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("execute waited %s for a handler ignoring cancellation", elapsed)
+	}
+	_, err := r.Execute(context.Background(), "polite", ActivationContext{})
+	if !errors.Is(err, ErrSkillTimeout) || !strings.Contains(err.Error(), "polite") {
+		t.Fatalf("expected named timeout for polite handler, got %v", err)
+	}
+	if _, err := r.Execute(context.Background(), "failing", ActivationContext{}); err == nil || errors.Is(err, ErrSkillTimeout) {
+		t.Fatalf("handler error must stay distinct from timeout, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.Execute(ctx, "polite", ActivationContext{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("caller cancellation should not be reported as timeout, got %v", err)
+	}
+	if res, err := r.Execute(context.Background(), "unbounded", ActivationContext{}); err != nil || res.Output != "ok" {
+		t.Fatalf("zero timeout should keep current behaviour: %+v %v", res, err)
+	}
+}
+
+func TestRegistryUnregisterAndReplace(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	r := NewRegistry().WithActivationPolicy(ActivationPolicy{Cooldown: time.Hour})
+	r.now = func() time.Time { return now }
+	output := func(out string) Handler {
+		return HandlerFunc(func(context.Context, ActivationContext) (Result, error) { return Result{Output: out}, nil })
+	}
+	if err := r.Register(Definition{Name: "deploy"}, output("v1")); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if got := r.Match(ActivationContext{Prompt: "go"}); len(got) != 1 {
+		t.Fatalf("expected activation, got %+v", got)
+	}
+
+	if err := r.Replace(Definition{Name: "deploy", Description: "swapped"}, output("v2")); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	res, err := r.Execute(context.Background(), "deploy", ActivationContext{})
+	if err != nil || res.Output != "v2" {
+		t.Fatalf("expected replaced handler, got %+v %v", res, err)
+	}
+	if skill, _ := r.Get("deploy"); skill.Definition().Description != "swapped" {
+		t.Fatalf("expected replaced definition, got %+v", skill.Definition())
+	}
+	if err := r.Replace(Definition{Name: "missing"}, output("x")); !errors.Is(err, ErrUnknownSkill) {
+		t.Fatalf("expected unknown skill error, got %v", err)
+	}
+	if err := r.Replace(Definition{Name: "deploy"}, nil); err == nil {
+		t.Fatal("expected nil handler error")
+	}
+
+	if !r.Unregister(" DEPLOY ") {
+		t.Fatal("expected unregister to report removal")
+	}
+	if r.Unregister("deploy") {
+		t.Fatal("second unregister should report false")
+	}
+	if _, err := r.Execute(context.Background(), "deploy", ActivationContext{}); !errors.Is(err, ErrUnknownSkill) {
+		t.Fatalf("expected unknown skill after unregister, got %v", err)
+	}
+
+	// Re-registering starts without the old cooldown.
+	if err := r.Register(Definition{Name: "deploy"}, output("v3")); err != nil {
+		t.Fatalf("re-register: %v", err)
+	}
+	if got := r.Match(ActivationContext{Prompt: "go"}); len(got) != 1 {
+		t.Fatalf("expected cooldown history cleared, got %+v", got)
+	}
+}
+
+func TestRegistryMatcherStats(t *testing.T) {
+	r := NewRegistry().WithMaxMatchers(2).WithMatchTimeout(20 * time.Millisecond)
+	handler := HandlerFunc(func(context.Context, ActivationContext) (Result, error) { return Result{}, nil })
+
+	cheap := KeywordMatcher{Any: []string{"deploy"}}
+	expensive := ContextMatcherFunc(func(ctx context.Context, _ ActivationContext) MatchResult {
+		select {
+		case <-time.After(time.Second):
+			return MatchResult{Matched: true, Score: 1, Reason: "slow"}
+		case <-ctx.Done():
+			return MatchResult{}
+		}
+	})
+	defs := []Definition{
+		// cheap hits the stop score, so expensive never runs.
+		{Name: "short", StopOnScore: 0.5, Matchers: []Matcher{cheap, expensive}},
+		// expensive times out, then cheap is evaluated.
+		{Name: "slow", Matchers: []Matcher{expensive, cheap}},
+		// only the first two of four run under the cap.
+		{Name: "capped", Matchers: []Matcher{cheap, cheap, nil, cheap, cheap}},
+	}
+	for _, def := range defs {
+		if err := r.Register(def, handler); err != nil {
+			t.Fatalf("register %s: %v", def.Name, err)
+		}
+	}
+
+	report := r.MatchTrace(context.Background(), ActivationContext{Prompt: "deploy"})
+	want := MatcherStats{Evaluated: 4, SkippedShortCircuit: 1, SkippedTimeout: 1, SkippedCap: 2}
+	if report.Matchers != want {
+		t.Fatalf("expected %+v, got %+v", want, report.Matchers)
+	}
+	if report.Matchers.Skipped() != 4 || len(report.Activations) != 3 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	// Without a hit nothing short-circuits, so short's expensive matcher runs.
+	_ = r.MatchTrace(context.Background(), ActivationContext{Prompt: "other"})
+	want = MatcherStats{Evaluated: 8, SkippedShortCircuit: 1, SkippedTimeout: 3, SkippedCap: 4}
+	if total := r.MatcherStats(); total != want {
+		t.Fatalf("unexpected cumulative stats %+v", total)
+	}
+}
+
+func TestRegistryMatchMutexKeyOneWinner(t *testing.T) {
+	r := NewRegistry().WithDedupWindow(time.Minute)
+	handler := HandlerFunc(func(context.Context, ActivationContext) (Result, error) { return Result{}, nil })
+	// Equal priority: the name decides even though incident-b scores higher.
+	defs := []Definition{
+		{Name: "incident-b", MutexKey: "Incident", Matchers: []Matcher{KeywordMatcher{Any: []string{"outage"}, All: []string{"pager"}}}},
+		{Name: "incident-a", MutexKey: "incident", Matchers: []Matcher{KeywordMatcher{Any: []string{"outage"}}}},
+	}
+	for _, def := range defs {
+		if err := r.Register(def, handler); err != nil {
+			t.Fatalf("register %s: %v", def.Name, err)
+		}
+	}
+
+	ac := ActivationContext{Prompt: "outage pager"}
+	for i := 0; i < 3; i++ {
+		// Later passes dedup incident-a; incident-b must still stay out.
+		report := r.MatchTrace(context.Background(), ac)
+		incident := 0
+		for _, a := range report.Activations {
+			if a.Skill.Definition().MutexKey == "incident" {
+				incident++
+			}
+		}
+		if incident > 1 {
+			t.Fatalf("pass %d: both incident skills activated: %+v", i, report.Activations)
+		}
+		if i == 0 && (incident != 1 || report.Activations[0].Skill.Definition().Name != "incident-a") {
+			t.Fatalf("expected incident-a to win by name, got %+v", report.Activations)
+		}
+	}
+
+	if winners := MutexWinners([]MutexCandidate{
+		{Key: "k", Priority: 1, Name: "z"},
+		{Key: "k", Priority: 2, Name: "y"},
+		{Key: "k", Priority: 2, Name: "x"},
+		{Name: "free"},
+	}); len(winners) != 1 || winners["k"] != 2 {
+		t.Fatalf("unexpected winners %v", winners)
+	}
+}
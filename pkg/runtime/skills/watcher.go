@@ -0,0 +1,281 @@
+package skills
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// defaultWatchDebounce coalesces the burst of events editors emit per save.
+const defaultWatchDebounce = 200 * time.Millisecond
+
+// ReloadEvent describes how the skill set changed after a rescan. Errors is
+// the full aggregated error set of that rescan, not just new errors.
+type ReloadEvent struct {
+	Added   []SkillRegistration
+	Changed []SkillRegistration
+	Removed []string
+	Errors  []error
+}
+
+// Watcher keeps the skills under each root's .claude/skills in sync with
+// disk. Each rescan is applied as a whole: a SKILL.md that fails to parse
+// keeps its previous registration until it becomes valid again or is
+// deleted, so a half-written file never drops a skill. Overrides in
+// LoaderOptions.FS are loaded but not watched.
+type Watcher struct {
+	opts     LoaderOptions
+	debounce time.Duration
+	events   chan ReloadEvent
+	fsw      *fsnotify.Watcher
+
+	mu      sync.RWMutex
+	current map[string]watchedSkill
+	errs    []error
+
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+type watchedSkill struct {
+	reg         SkillRegistration
+	path        string
+	fingerprint string
+}
+
+// NewWatcher loads the skills once and starts watching the skills directory
+// of every root. A non-positive debounce uses 200ms. Roots without a skills
+// directory are not watched; when none has one, the initial load is kept and
+// no reload events are produced.
+func NewWatcher(opts LoaderOptions, debounce time.Duration) (*Watcher, error) {
+	if debounce <= 0 {
+		debounce = defaultWatchDebounce
+	}
+	w := &Watcher{
+		opts:     opts,
+		debounce: debounce,
+		events:   make(chan ReloadEvent, 1),
+		current:  map[string]watchedSkill{},
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	w.reload()
+
+	var dirs []string
+	for _, root := range skillRoots(opts) {
+		dir := filepath.Join(root, ".claude", "skills")
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) == 0 {
+		close(w.stopped)
+		return w, nil
+	}
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if err := watchSkillDir(fsw, dir); err != nil {
+			_ = fsw.Close()
+			return nil, err
+		}
+	}
+	w.fsw = fsw
+	go w.loop()
+	return w, nil
+}
+
+// watchSkillDir watches dir and each skill directory directly under it.
+func watchSkillDir(fsw *fsnotify.Watcher, dir string) error {
+	if err := fsw.Add(dir); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			_ = fsw.Add(filepath.Join(dir, entry.Name())) //nolint:errcheck // best-effort: rescans still see the skill
+		}
+	}
+	return nil
+}
+
+// Events delivers one ReloadEvent per rescan that changed the skill set.
+// The channel is closed by Close.
+func (w *Watcher) Events() <-chan ReloadEvent {
+	return w.events
+}
+
+// Registrations returns the current skill set sorted by name.
+func (w *Watcher) Registrations() []SkillRegistration {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	regs := make([]SkillRegistration, 0, len(w.current))
+	for _, skill := range w.current {
+		regs = append(regs, skill.reg)
+	}
+	sort.Slice(regs, func(i, j int) bool { return regs[i].Definition.Name < regs[j].Definition.Name })
+	return regs
+}
+
+// Errors returns the aggregated loader errors from the latest rescan.
+func (w *Watcher) Errors() []error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return append([]error(nil), w.errs...)
+}
+
+// Close stops watching and closes the Events channel.
+func (w *Watcher) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.done)
+		if w.fsw != nil {
+			err = w.fsw.Close()
+		}
+		<-w.stopped
+		close(w.events)
+	})
+	return err
+}
+
+func (w *Watcher) loop() {
+	defer close(w.stopped)
+	var (
+		timer  *time.Timer
+		timerC <-chan time.Time
+	)
+	for {
+		select {
+		case <-w.done:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					_ = w.fsw.Add(event.Name) //nolint:errcheck // best-effort: rescans still see the skill
+				}
+			}
+			if event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Remove|fsnotify.Rename) == 0 {
+				continue
+			}
+			if timer == nil {
+				timer = time.NewTimer(w.debounce)
+			} else {
+				timer.Reset(w.debounce)
+			}
+			timerC = timer.C
+		case <-timerC:
+			timerC = nil
+			evt, changed := w.reload()
+			if !changed {
+				continue
+			}
+			select {
+			case w.events <- evt:
+			case <-w.done:
+				return
+			}
+		case _, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// reload rescans disk and swaps in the new skill set, reporting whether
+// anything other than the error set changed.
+func (w *Watcher) reload() (ReloadEvent, bool) {
+	regs, errs := LoadFromFS(w.opts)
+
+	// Files (or the directory) that failed this pass keep whatever
+	// registration they had.
+	var failed []string
+	for _, err := range errs {
+		var le *LoaderError
+		if errors.As(err, &le) && le.Kind != LoaderErrorDuplicate && le.Path != "" {
+			failed = append(failed, le.Path)
+		}
+	}
+	covered := func(path string) bool {
+		for _, f := range failed {
+			if path == f || strings.HasPrefix(path, f+string(filepath.Separator)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	next := make(map[string]watchedSkill, len(regs))
+	for _, reg := range regs {
+		path := reg.Definition.Metadata["source"]
+		next[reg.Definition.Name] = watchedSkill{reg: reg, path: path, fingerprint: fingerprintFile(path)}
+	}
+	for name, prev := range w.current {
+		if _, ok := next[name]; !ok && covered(prev.path) {
+			next[name] = prev
+		}
+	}
+
+	evt := ReloadEvent{Errors: errs}
+	for name, skill := range next {
+		prev, ok := w.current[name]
+		switch {
+		case !ok:
+			evt.Added = append(evt.Added, skill.reg)
+		case prev.fingerprint != skill.fingerprint || prev.path != skill.path:
+			evt.Changed = append(evt.Changed, skill.reg)
+		default:
+			next[name] = prev
+		}
+	}
+	for name := range w.current {
+		if _, ok := next[name]; !ok {
+			evt.Removed = append(evt.Removed, name)
+		}
+	}
+	byName := func(regs []SkillRegistration) {
+		sort.Slice(regs, func(i, j int) bool { return regs[i].Definition.Name < regs[j].Definition.Name })
+	}
+	byName(evt.Added)
+	byName(evt.Changed)
+	sort.Strings(evt.Removed)
+
+	w.current = next
+	w.errs = errs
+	changed := len(evt.Added) > 0 || len(evt.Changed) > 0 || len(evt.Removed) > 0
+	return evt, changed
+}
+
+func fingerprintFile(path string) string {
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
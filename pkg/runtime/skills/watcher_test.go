@@ -0,0 +1,94 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcherReloadsSkills(t *testing.T) {
+	root := t.TempDir()
+	skillsDir := filepath.Join(root, ".claude", "skills")
+	writeSkill(t, filepath.Join(skillsDir, "alpha", "SKILL.md"), "alpha", "v1")
+	writeSkill(t, filepath.Join(skillsDir, "beta", "SKILL.md"), "beta", "v1")
+
+	w, err := NewWatcher(LoaderOptions{ProjectRoot: root}, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("watcher: %v", err)
+	}
+	t.Cleanup(func() { _ = w.Close() })
+	if regs := w.Registrations(); len(regs) != 2 || regs[0].Definition.Name != "alpha" {
+		t.Fatalf("unexpected initial registrations %+v", regs)
+	}
+
+	next := func() ReloadEvent {
+		t.Helper()
+		select {
+		case evt := <-w.Events():
+			return evt
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for reload event")
+			return ReloadEvent{}
+		}
+	}
+
+	// A burst of edits is debounced into one event.
+	for _, body := range []string{"v2", "v3", "v4"} {
+		writeSkill(t, filepath.Join(skillsDir, "alpha", "SKILL.md"), "alpha", body)
+	}
+	evt := next()
+	if len(evt.Changed) != 1 || evt.Changed[0].Definition.Name != "alpha" || len(evt.Added)+len(evt.Removed) != 0 {
+		t.Fatalf("expected alpha changed, got %+v", evt)
+	}
+
+	writeSkill(t, filepath.Join(skillsDir, "gamma", "SKILL.md"), "gamma", "v1")
+	evt = next()
+	if len(evt.Added) != 1 || evt.Added[0].Definition.Name != "gamma" {
+		t.Fatalf("expected gamma added, got %+v", evt)
+	}
+
+	// A malformed mid-edit file keeps the previous registration.
+	mustWrite(t, filepath.Join(skillsDir, "beta", "SKILL.md"), "---\nname: beta\n")
+	mustWrite(t, filepath.Join(skillsDir, "gamma", "SKILL.md"), "---\nname: gamma\n")
+	if err := os.RemoveAll(filepath.Join(skillsDir, "alpha")); err != nil {
+		t.Fatalf("remove alpha: %v", err)
+	}
+	evt = next()
+	if len(evt.Removed) != 1 || evt.Removed[0] != "alpha" || len(evt.Changed) != 0 {
+		t.Fatalf("expected only alpha removed, got %+v", evt)
+	}
+	if len(evt.Errors) != 2 || len(w.Errors()) != 2 {
+		t.Fatalf("expected two parse errors, got %v", evt.Errors)
+	}
+	regs := w.Registrations()
+	if len(regs) != 2 || regs[0].Definition.Name != "beta" || regs[1].Definition.Name != "gamma" {
+		t.Fatalf("malformed files must keep previous registrations, got %+v", regs)
+	}
+
+	writeSkill(t, filepath.Join(skillsDir, "beta", "SKILL.md"), "beta", "fixed")
+	evt = next()
+	if len(evt.Changed) != 1 || evt.Changed[0].Definition.Name != "beta" || len(evt.Errors) != 1 {
+		t.Fatalf("expected beta changed with gamma still broken, got %+v", evt)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, ok := <-w.Events(); ok {
+		t.Fatal("expected events channel closed")
+	}
+}
+
+func TestWatcherMissingDirectory(t *testing.T) {
+	w, err := NewWatcher(LoaderOptions{ProjectRoot: t.TempDir()}, 0)
+	if err != nil {
+		t.Fatalf("watcher: %v", err)
+	}
+	if len(w.Registrations()) != 0 || len(w.Errors()) != 0 {
+		t.Fatalf("expected empty watcher")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package subagents
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// BudgetUsedMetadataKey is the Result.Metadata key holding the Usage charged
+// during a budgeted dispatch.
+const BudgetUsedMetadataKey = "subagent.budget_used"
+
+// Unlimited is reported by RemainingBudget for a limit that is not set.
+const Unlimited = -1
+
+// Budget caps the spend of a single subagent dispatch. Zero fields are
+// unlimited, so the zero Budget imposes no cap.
+type Budget struct {
+	MaxTokens    int
+	MaxToolCalls int
+}
+
+// IsZero reports whether the budget sets no limits.
+func (b Budget) IsZero() bool {
+	return b.MaxTokens <= 0 && b.MaxToolCalls <= 0
+}
+
+// Usage is spend a handler reports through Manager.Charge.
+type Usage struct {
+	Tokens    int
+	ToolCalls int
+}
+
+// Remaining is the unspent part of a Budget. Fields are Unlimited when the
+// matching limit is unset and never below zero otherwise.
+type Remaining struct {
+	Tokens    int
+	ToolCalls int
+}
+
+type budgetKey struct{}
+
+// budgetMeter tracks spend against one dispatch's budget. Nested dispatches
+// chain to the parent meter so a child can never outspend its parent.
+type budgetMeter struct {
+	mu     sync.Mutex
+	limit  Budget
+	used   Usage
+	parent *budgetMeter
+}
+
+func newBudgetMeter(limit Budget, parent *budgetMeter) *budgetMeter {
+	return &budgetMeter{limit: limit, parent: parent}
+}
+
+func budgetMeterFromContext(ctx context.Context) *budgetMeter {
+	if ctx == nil {
+		return nil
+	}
+	meter, _ := ctx.Value(budgetKey{}).(*budgetMeter)
+	return meter
+}
+
+func (b *budgetMeter) charge(u Usage) error {
+	var firstErr error
+	for meter := b; meter != nil; meter = meter.parent {
+		meter.mu.Lock()
+		meter.used.Tokens += max(u.Tokens, 0)
+		meter.used.ToolCalls += max(u.ToolCalls, 0)
+		err := meter.overLocked()
+		meter.mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (b *budgetMeter) snapshot() (Usage, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used, b.overLocked()
+}
+
+func (b *budgetMeter) overLocked() error {
+	switch {
+	case b.limit.MaxTokens > 0 && b.used.Tokens > b.limit.MaxTokens:
+		return fmt.Errorf("%w: %d tokens used, max %d", ErrBudgetExhausted, b.used.Tokens, b.limit.MaxTokens)
+	case b.limit.MaxToolCalls > 0 && b.used.ToolCalls > b.limit.MaxToolCalls:
+		return fmt.Errorf("%w: %d tool calls used, max %d", ErrBudgetExhausted, b.used.ToolCalls, b.limit.MaxToolCalls)
+	}
+	return nil
+}
+
+func (b *budgetMeter) remaining() Remaining {
+	rem := Remaining{Tokens: Unlimited, ToolCalls: Unlimited}
+	for meter := b; meter != nil; meter = meter.parent {
+		meter.mu.Lock()
+		if meter.limit.MaxTokens > 0 {
+			rem.Tokens = tighter(rem.Tokens, max(meter.limit.MaxTokens-meter.used.Tokens, 0))
+		}
+		if meter.limit.MaxToolCalls > 0 {
+			rem.ToolCalls = tighter(rem.ToolCalls, max(meter.limit.MaxToolCalls-meter.used.ToolCalls, 0))
+		}
+		meter.mu.Unlock()
+	}
+	return rem
+}
+
+func tighter(current, next int) int {
+	if current == Unlimited {
+		return next
+	}
+	return min(current, next)
+}
+
+// Charge records usage against the budget of the dispatch running in ctx and
+// of every budgeted dispatch above it. It returns an error wrapping
+// ErrBudgetExhausted once any of those budgets is exceeded; the usage is
+// recorded regardless. Charge is a no-op outside a budgeted dispatch.
+//
+// Dispatch also fails with ErrBudgetExhausted when the handler returns
+// successfully after overspending, so handlers may keep going after an error
+// from Charge only to clean up.
+func (m *Manager) Charge(ctx context.Context, usage Usage) error {
+	meter := budgetMeterFromContext(ctx)
+	if meter == nil {
+		return nil
+	}
+	return meter.charge(usage)
+}
+
+// RemainingBudget reports the unspent budget visible to the handler running
+// in ctx, taking the tightest limit across nested dispatches. The second
+// result is false outside a budgeted dispatch.
+func RemainingBudget(ctx context.Context) (Remaining, bool) {
+	meter := budgetMeterFromContext(ctx)
+	if meter == nil {
+		return Remaining{}, false
+	}
+	return meter.remaining(), true
+}
@@ -0,0 +1,118 @@
+package subagents
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestManagerBudgetCharge(t *testing.T) {
+	m := NewManager()
+	var remaining []Remaining
+	spend := func(usage ...Usage) Handler {
+		return HandlerFunc(func(ctx context.Context, _ Context, _ Request) (Result, error) {
+			for _, u := range usage {
+				if err := m.Charge(ctx, u); err != nil {
+					return Result{}, err
+				}
+				rem, _ := RemainingBudget(ctx)
+				remaining = append(remaining, rem)
+			}
+			return Result{Output: "ok"}, nil
+		})
+	}
+	budget := Budget{MaxTokens: 100, MaxToolCalls: 2}
+	if err := m.Register(Definition{Name: "frugal", BaseContext: Context{Budget: budget}}, spend(Usage{Tokens: 40, ToolCalls: 1}, Usage{Tokens: 60})); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := m.Register(Definition{Name: "spender", BaseContext: Context{Budget: budget}}, spend(Usage{Tokens: 90}, Usage{Tokens: 20})); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := m.Register(Definition{Name: "free"}, spend(Usage{Tokens: 1000})); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	res, err := m.Dispatch(taskDispatchCtx(), Request{Target: "frugal", Instruction: "go"})
+	if err != nil {
+		t.Fatalf("frugal: %v", err)
+	}
+	if len(remaining) != 2 || remaining[0] != (Remaining{Tokens: 60, ToolCalls: 1}) || remaining[1] != (Remaining{Tokens: 0, ToolCalls: 1}) {
+		t.Fatalf("unexpected remaining %+v", remaining)
+	}
+	if used := res.Metadata[BudgetUsedMetadataKey]; used != (Usage{Tokens: 100, ToolCalls: 1}) {
+		t.Fatalf("unexpected usage metadata %+v", used)
+	}
+
+	res, err = m.Dispatch(taskDispatchCtx(), Request{Target: "spender", Instruction: "go"})
+	if !errors.Is(err, ErrBudgetExhausted) || res.Error == "" {
+		t.Fatalf("expected budget exhausted, got %v (%+v)", err, res)
+	}
+
+	remaining = nil
+	if _, err := m.Dispatch(taskDispatchCtx(), Request{Target: "free", Instruction: "go"}); err != nil {
+		t.Fatalf("unbudgeted dispatch: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0] != (Remaining{}) {
+		t.Fatalf("expected no budget outside budgeted dispatch, got %+v", remaining)
+	}
+}
+
+func TestManagerBudgetIgnoredChargeErrorFailsDispatch(t *testing.T) {
+	m := NewManager()
+	handler := HandlerFunc(func(ctx context.Context, _ Context, _ Request) (Result, error) {
+		_ = m.Charge(ctx, Usage{ToolCalls: 3})
+		return Result{Output: "ignored"}, nil
+	})
+	if err := m.Register(Definition{Name: "sloppy", BaseContext: Context{Budget: Budget{MaxToolCalls: 2}}}, handler); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	res, err := m.Dispatch(taskDispatchCtx(), Request{Target: "sloppy", Instruction: "go"})
+	if !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("expected budget exhausted, got %v", err)
+	}
+	if res.Output != "ignored" || res.Metadata[BudgetUsedMetadataKey] != (Usage{ToolCalls: 3}) {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
+
+func TestManagerBudgetNestedChargesParent(t *testing.T) {
+	m := NewManager()
+	var childRemaining Remaining
+	child := HandlerFunc(func(ctx context.Context, _ Context, _ Request) (Result, error) {
+		childRemaining, _ = RemainingBudget(ctx)
+		return Result{}, m.Charge(ctx, Usage{Tokens: 30})
+	})
+	parent := HandlerFunc(func(ctx context.Context, _ Context, _ Request) (Result, error) {
+		if err := m.Charge(ctx, Usage{Tokens: 20}); err != nil {
+			return Result{}, err
+		}
+		return m.Dispatch(ctx, Request{Target: "child", Instruction: "nested"})
+	})
+	if err := m.Register(Definition{Name: "parent", BaseContext: Context{Budget: Budget{MaxTokens: 40}}}, parent); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := m.Register(Definition{Name: "child", BaseContext: Context{Budget: Budget{MaxTokens: 100, MaxToolCalls: 5}}}, child); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	_, err := m.Dispatch(taskDispatchCtx(), Request{Target: "parent", Instruction: "go"})
+	if !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("expected parent budget exhausted by child spend, got %v", err)
+	}
+	if childRemaining != (Remaining{Tokens: 20, ToolCalls: 5}) {
+		t.Fatalf("child should see the tighter parent limit, got %+v", childRemaining)
+	}
+}
+
+func TestContextCloneKeepsBudget(t *testing.T) {
+	ctx := Context{Budget: Budget{MaxTokens: 5}}
+	if ctx.Clone().Budget != ctx.Budget {
+		t.Fatalf("clone dropped budget")
+	}
+	if !(Budget{}).IsZero() || ctx.Budget.IsZero() {
+		t.Fatalf("unexpected IsZero")
+	}
+	if err := NewManager().Charge(context.Background(), Usage{Tokens: 1}); err != nil {
+		t.Fatalf("charge outside dispatch should be a no-op: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package subagents
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// defaultMaxChainLength bounds DispatchChain when WithMaxChainLength is unset.
+const defaultMaxChainLength = 8
+
+// Metadata keys DispatchChain sets on every step after the first.
+const (
+	ChainStepKey           = "chain.step"
+	ChainPreviousOutputKey = "chain.previous_output"
+)
+
+// WithMaxChainLength caps how many steps DispatchChain accepts. A
+// non-positive n restores the default of 8.
+func (m *Manager) WithMaxChainLength(n int) *Manager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxChain = max(n, 0)
+	return m
+}
+
+// DispatchChain dispatches reqs in order as a pipeline. Each step's Metadata
+// starts from the metadata accumulated from earlier results, overlaid with
+// the step's own Metadata, and carries the previous Output under
+// ChainPreviousOutputKey; a step with an empty Instruction receives the
+// previous Output as its instruction. Steps run as siblings, not nested
+// dispatches, so the same subagent may appear more than once.
+//
+// The first failing step stops the chain: the results so far, including the
+// failed step's, are returned with its error. Chains longer than the maximum
+// length are rejected with ErrChainTooLong before any step runs.
+func (m *Manager) DispatchChain(ctx context.Context, reqs []Request) ([]Result, error) {
+	m.mu.RLock()
+	limit := m.maxChain
+	m.mu.RUnlock()
+	if limit <= 0 {
+		limit = defaultMaxChainLength
+	}
+	if len(reqs) > limit {
+		return nil, fmt.Errorf("%w: %d steps, max %d", ErrChainTooLong, len(reqs), limit)
+	}
+
+	results := make([]Result, 0, len(reqs))
+	acc := map[string]any{}
+	for i, req := range reqs {
+		meta := maps.Clone(acc)
+		if i > 0 {
+			prev := results[i-1].Output
+			meta[ChainStepKey] = i
+			meta[ChainPreviousOutputKey] = prev
+			if strings.TrimSpace(req.Instruction) == "" && prev != nil {
+				req.Instruction = fmt.Sprint(prev)
+			}
+		}
+		maps.Copy(meta, req.Metadata)
+		if len(meta) > 0 {
+			req.Metadata = meta
+		}
+
+		res, err := m.Dispatch(ctx, req)
+		results = append(results, res)
+		if err != nil {
+			return results, fmt.Errorf("subagents: chain step %d: %w", i, err)
+		}
+		maps.Copy(acc, res.Metadata)
+		delete(acc, DepthMetadataKey) // describes the step, not the pipeline
+	}
+	return results, nil
+}
@@ -0,0 +1,69 @@
+package subagents
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestManagerDispatchChain(t *testing.T) {
+	m := NewManager()
+	var seen []Request
+	handle := func(out string, meta map[string]any, err error) Handler {
+		return HandlerFunc(func(_ context.Context, _ Context, req Request) (Result, error) {
+			seen = append(seen, req)
+			return Result{Output: out, Metadata: meta}, err
+		})
+	}
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	must(m.Register(Definition{Name: "plan"}, handle("1. build 2. test", map[string]any{"plan": "v1", "owner": "plan"}, nil)))
+	must(m.Register(Definition{Name: "execute"}, handle("done", map[string]any{"owner": "execute"}, nil)))
+	must(m.Register(Definition{Name: "broken"}, handle("", nil, errors.New("boom"))))
+
+	results, err := m.DispatchChain(taskDispatchCtx(), []Request{
+		{Target: "plan", Instruction: "ship it", Metadata: map[string]any{"session_id": "s1"}},
+		{Target: "execute", Metadata: map[string]any{"owner": "caller"}},
+		{Target: "plan", Instruction: "review"},
+	})
+	if err != nil {
+		t.Fatalf("chain: %v", err)
+	}
+	if len(results) != 3 || results[1].Subagent != "execute" || results[1].Output != "done" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	step := seen[1]
+	if step.Instruction != "1. build 2. test" || step.Metadata[ChainPreviousOutputKey] != "1. build 2. test" || step.Metadata[ChainStepKey] != 1 {
+		t.Fatalf("previous output not fed forward: %+v", step)
+	}
+	if step.Metadata["plan"] != "v1" || step.Metadata["owner"] != "caller" || step.Metadata["session_id"] != nil {
+		t.Fatalf("unexpected step metadata %+v", step.Metadata)
+	}
+	if last := seen[2]; last.Instruction != "review" || last.Metadata["owner"] != "execute" || last.Metadata[ChainPreviousOutputKey] != "done" {
+		t.Fatalf("metadata not accumulated: %+v", last)
+	}
+
+	seen = nil
+	results, err = m.DispatchChain(taskDispatchCtx(), []Request{
+		{Target: "plan", Instruction: "go"},
+		{Target: "broken"},
+		{Target: "execute"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "chain step 1: boom") {
+		t.Fatalf("expected step 1 failure, got %v", err)
+	}
+	if len(results) != 2 || results[1].Error != "boom" || len(seen) != 2 {
+		t.Fatalf("expected short-circuit after failing step, got %+v", results)
+	}
+
+	m.WithMaxChainLength(2)
+	long := []Request{{Target: "plan", Instruction: "a"}, {Target: "plan"}, {Target: "plan"}}
+	if results, err := m.DispatchChain(taskDispatchCtx(), long); !errors.Is(err, ErrChainTooLong) || results != nil {
+		t.Fatalf("expected ErrChainTooLong, got %v %v", results, err)
+	}
+}
@@ -15,11 +15,13 @@ type Context struct {
 	Metadata      map[string]any
 	ToolWhitelist []string
 	Model         string
+	// Budget caps what one dispatch may spend; see Manager.Charge.
+	Budget Budget
 }
 
 // Clone produces a deep copy to maintain isolation between runs.
 func (c Context) Clone() Context {
-	cloned := Context{SessionID: c.SessionID, Model: c.Model}
+	cloned := Context{SessionID: c.SessionID, Model: c.Model, Budget: c.Budget}
 	if len(c.Metadata) > 0 {
 		cloned.Metadata = maps.Clone(c.Metadata)
 	}
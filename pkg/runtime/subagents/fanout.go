@@ -0,0 +1,65 @@
+package subagents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/cexll/agentsdk-go/pkg/internal/pool"
+)
+
+// DispatchAll runs every subagent whose matchers fire for req.Activation
+// concurrently, after mutex filtering and the WithMinScore floor, and returns
+// one result per subagent ordered by priority, score and name. req.Target is
+// ignored. Each run goes through Dispatch, so req.Timeout and cancellation of
+// ctx reach every in-flight handler; a subagent not yet started when ctx is
+// cancelled is skipped and reports the context error.
+//
+// A failing handler does not stop the others: its result carries Error and
+// the returned error joins every failure, each prefixed with the subagent
+// name. It is ErrNoMatchingSubagent or ErrNoConfidentMatch when nothing runs.
+func (m *Manager) DispatchAll(ctx context.Context, req Request) ([]Result, error) {
+	if dispatchSource(ctx) != DispatchSourceTaskTool {
+		return nil, ErrDispatchUnauthorized
+	}
+	if strings.TrimSpace(req.Instruction) == "" {
+		return nil, ErrEmptyInstruction
+	}
+	matches := m.scoredMatches(req.Activation)
+	if len(matches) == 0 {
+		return nil, ErrNoMatchingSubagent
+	}
+	m.mu.RLock()
+	floor := m.minScore
+	m.mu.RUnlock()
+	targets := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if floor <= 0 || match.score >= floor {
+			targets = append(targets, match.sub.definition.Name)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, ErrNoConfidentMatch
+	}
+
+	dispatched := pool.Map(ctx, 0, len(targets), func(ctx context.Context, i int) (Result, error) {
+		sub := req
+		sub.Target = targets[i]
+		sub.Metadata = maps.Clone(req.Metadata)
+		return m.Dispatch(ctx, sub)
+	})
+	results := make([]Result, len(targets))
+	var errs []error
+	for i, d := range dispatched {
+		res := d.Value
+		if d.Err != nil {
+			res.Subagent = targets[i]
+			res.Error = d.Err.Error()
+			errs = append(errs, fmt.Errorf("%s: %w", targets[i], d.Err))
+		}
+		results[i] = res
+	}
+	return results, errors.Join(errs...)
+}
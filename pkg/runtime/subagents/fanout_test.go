@@ -0,0 +1,85 @@
+package subagents
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cexll/agentsdk-go/pkg/runtime/skills"
+)
+
+func TestManagerDispatchAll(t *testing.T) {
+	m := NewManager()
+	match := []skills.Matcher{skills.KeywordMatcher{Any: []string{"compare"}}}
+	var running, peak atomic.Int32
+	barrier := make(chan struct{})
+	explorer := func(out string, err error) Handler {
+		return HandlerFunc(func(ctx context.Context, _ Context, _ Request) (Result, error) {
+			n := running.Add(1)
+			if n > peak.Load() {
+				peak.Store(n)
+			}
+			if n == 3 {
+				close(barrier)
+			}
+			select {
+			case <-barrier:
+			case <-ctx.Done():
+				return Result{}, ctx.Err()
+			}
+			return Result{Output: out}, err
+		})
+	}
+	defs := []struct {
+		def Definition
+		h   Handler
+	}{
+		{Definition{Name: "explore-a", Priority: 1, Matchers: match}, explorer("a", nil)},
+		{Definition{Name: "explore-b", Priority: 3, Matchers: match}, explorer("b", nil)},
+		{Definition{Name: "explore-c", Priority: 2, Matchers: match}, explorer("", errors.New("lost"))},
+		{Definition{Name: "other", Matchers: []skills.Matcher{skills.KeywordMatcher{Any: []string{"unrelated"}}}}, explorer("x", nil)},
+	}
+	for _, d := range defs {
+		if err := m.Register(d.def, d.h); err != nil {
+			t.Fatalf("register %s: %v", d.def.Name, err)
+		}
+	}
+
+	req := Request{Instruction: "compare", Activation: skills.ActivationContext{Prompt: "compare approaches"}, Timeout: time.Second}
+	results, err := m.DispatchAll(taskDispatchCtx(), req)
+	if peak.Load() != 3 {
+		t.Fatalf("expected all three handlers in flight together, peak %d", peak.Load())
+	}
+	if len(results) != 3 || results[0].Subagent != "explore-b" || results[1].Subagent != "explore-c" || results[2].Subagent != "explore-a" {
+		t.Fatalf("expected priority order b,c,a, got %+v", results)
+	}
+	if results[0].Output != "b" || results[2].Output != "a" || results[1].Error != "lost" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if err == nil || !strings.Contains(err.Error(), "explore-c: lost") {
+		t.Fatalf("expected aggregated error for explore-c, got %v", err)
+	}
+
+	// A handler stuck past the request timeout is cancelled; the others
+	// still report.
+	stuck := HandlerFunc(func(ctx context.Context, _ Context, _ Request) (Result, error) {
+		<-ctx.Done()
+		return Result{}, ctx.Err()
+	})
+	if err := m.Register(Definition{Name: "slow", Matchers: match}, stuck); err != nil {
+		t.Fatalf("register slow: %v", err)
+	}
+	running.Store(-1) // keep the explorers from closing the barrier twice
+	req.Timeout = 20 * time.Millisecond
+	results, err = m.DispatchAll(taskDispatchCtx(), req)
+	if len(results) != 4 || results[3].Subagent != "slow" || results[0].Output != "b" || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected slow to time out alone, got %v %+v", err, results)
+	}
+
+	if _, err := m.DispatchAll(taskDispatchCtx(), Request{Instruction: "x", Activation: skills.ActivationContext{Prompt: "nothing"}}); !errors.Is(err, ErrNoMatchingSubagent) {
+		t.Fatalf("expected ErrNoMatchingSubagent, got %v", err)
+	}
+}
@@ -258,6 +258,8 @@ func validateMetadata(meta SubagentMetadata) error {
 	return nil
 }
 
+// normalizeModel maps "inherit" to the empty model so Dispatch falls back to
+// the enclosing or Manager default model.
 func normalizeModel(model string) (string, error) {
 	if model == "" || model == "inherit" {
 		return "", nil
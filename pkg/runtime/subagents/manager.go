@@ -8,8 +8,10 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/cexll/agentsdk-go/pkg/runtime/skills"
+	"github.com/cexll/agentsdk-go/pkg/tool"
 )
 
 const (
@@ -27,8 +29,30 @@ var (
 	ErrNoMatchingSubagent   = errors.New("subagents: no matching subagent")
 	ErrEmptyInstruction     = errors.New("subagents: instruction is empty")
 	ErrDispatchUnauthorized = errors.New("subagents: dispatch not authorized")
+	ErrNoConfidentMatch     = errors.New("subagents: no match above minimum score")
+	ErrSubagentCycle        = errors.New("subagents: dispatch cycle")
+	ErrChainTooLong         = errors.New("subagents: chain exceeds max length")
+	ErrMaxDepthExceeded     = errors.New("subagents: max dispatch depth exceeded")
+	ErrBudgetExhausted      = errors.New("subagents: budget exhausted")
 )
 
+// DepthMetadataKey is the Result.Metadata key holding the dispatch depth: 1
+// for a top-level dispatch, 2 for one issued from its handler, and so on.
+const DepthMetadataKey = "subagent.depth"
+
+// CycleError reports a dispatch whose target already appears in the parent
+// chain. Chain lists the subagents from the first occurrence of the target
+// through the rejected hop, e.g. [a b a].
+type CycleError struct {
+	Chain []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrSubagentCycle, strings.Join(e.Chain, " -> "))
+}
+
+func (e *CycleError) Unwrap() error { return ErrSubagentCycle }
+
 var builtinSubagentTypes = map[string]Definition{
 	TypeGeneralPurpose: {
 		Name:         TypeGeneralPurpose,
@@ -129,10 +153,21 @@ type Request struct {
 	Activation    skills.ActivationContext
 	ToolWhitelist []string
 	Metadata      map[string]any
+	// ParentChain lists the subagents, outermost first, whose handlers led to
+	// this dispatch. When empty, Dispatch uses the chain carried by ctx.
+	ParentChain []string
+	// Timeout bounds the handler through its ctx. Zero disables the bound.
+	Timeout time.Duration
+	// Model overrides the subagent's model for this dispatch only.
+	Model string
 }
 
 type dispatchSourceKey struct{}
 
+type parentChainKey struct{}
+
+type parentModelKey struct{}
+
 const DispatchSourceTaskTool = "task_tool"
 
 // WithDispatchSource tags ctx with an allowed dispatch origin.
@@ -163,6 +198,37 @@ func dispatchSource(ctx context.Context) string {
 	return value
 }
 
+// ParentChainFromContext returns the subagents currently dispatching on ctx,
+// outermost first. Dispatch extends it before invoking a handler.
+func ParentChainFromContext(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	chain, _ := ctx.Value(parentChainKey{}).([]string)
+	return append([]string(nil), chain...)
+}
+
+// DepthFromContext returns the dispatch depth of a handler running on ctx, or
+// zero outside any dispatch.
+func DepthFromContext(ctx context.Context) int {
+	if ctx == nil {
+		return 0
+	}
+	chain, _ := ctx.Value(parentChainKey{}).([]string)
+	return len(chain)
+}
+
+// detectCycle returns a CycleError when name already appears in chain.
+func detectCycle(chain []string, name string) error {
+	for i, parent := range chain {
+		if strings.EqualFold(parent, name) {
+			cycle := append(append([]string(nil), chain[i:]...), name)
+			return &CycleError{Chain: cycle}
+		}
+	}
+	return nil
+}
+
 // Result captures handler output.
 type Result struct {
 	Subagent string
@@ -181,6 +247,12 @@ func (r Result) clone() Result {
 type Manager struct {
 	mu        sync.RWMutex
 	subagents map[string]*registeredSubagent
+	minScore  float64
+	maxChain  int
+	maxDepth  int
+	// enforceTools scopes dispatch contexts with tool.WithAllowedTools.
+	enforceTools bool
+	defaultModel string
 }
 
 // NewManager builds a new manager.
@@ -188,6 +260,53 @@ func NewManager() *Manager {
 	return &Manager{subagents: map[string]*registeredSubagent{}}
 }
 
+// WithMinScore rejects automatic matches scoring below score. When no match
+// clears the threshold Dispatch falls back to the general-purpose subagent if
+// registered, otherwise it returns ErrNoConfidentMatch. Explicit targets are
+// unaffected. Zero (the default) accepts any match.
+func (m *Manager) WithMinScore(score float64) *Manager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.minScore = score
+	return m
+}
+
+// WithMaxDepth rejects dispatches nested more than n levels deep with
+// ErrMaxDepthExceeded before their handler runs. Zero (the default) or a
+// negative n disables the limit; dispatch cycles are rejected regardless.
+func (m *Manager) WithMaxDepth(n int) *Manager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxDepth = max(n, 0)
+	return m
+}
+
+// WithToolEnforcement makes Dispatch carry the subagent's effective
+// ToolWhitelist in the handler ctx via tool.WithAllowedTools, so a shared
+// tool.Executor rejects other tools with tool.ErrToolNotAllowed. Subagents
+// without a whitelist keep full access.
+func (m *Manager) WithToolEnforcement(enabled bool) *Manager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.enforceTools = enabled
+	return m
+}
+
+// WithDefaultModel sets the model inherited by subagents whose definition
+// names none, such as those loaded with `model: inherit`. Dispatch resolves
+// Context.Model with this precedence:
+//
+//  1. Request.Model, when set;
+//  2. the definition's model (BaseContext.Model, else DefaultModel);
+//  3. the model of the enclosing dispatch, for nested dispatches;
+//  4. the default set here.
+func (m *Manager) WithDefaultModel(model string) *Manager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.defaultModel = strings.TrimSpace(model)
+	return m
+}
+
 // Register installs a subagent definition + handler.
 func (m *Manager) Register(def Definition, handler Handler) error {
 	if err := def.Validate(); err != nil {
@@ -258,6 +377,21 @@ func (m *Manager) Dispatch(ctx context.Context, req Request) (Result, error) {
 	if err != nil {
 		return Result{}, err
 	}
+	chain := req.ParentChain
+	if len(chain) == 0 {
+		chain = ParentChainFromContext(ctx)
+	}
+	if err := detectCycle(chain, target.definition.Name); err != nil {
+		return Result{}, err
+	}
+	depth := len(chain) + 1
+	m.mu.RLock()
+	maxDepth := m.maxDepth
+	m.mu.RUnlock()
+	if maxDepth > 0 && depth > maxDepth {
+		return Result{}, fmt.Errorf("%w: %s at depth %d, max %d", ErrMaxDepthExceeded, target.definition.Name, depth, maxDepth)
+	}
+	req.ParentChain = append(append([]string(nil), chain...), target.definition.Name)
 	runCtx := target.definition.BaseContext.Clone()
 	if len(req.Metadata) > 0 {
 		runCtx = runCtx.WithMetadata(req.Metadata)
@@ -272,10 +406,44 @@ func (m *Manager) Dispatch(ctx context.Context, req Request) (Result, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	runCtx.Model = m.resolveModel(ctx, req.Model, runCtx.Model)
+	if runCtx.Model != "" {
+		ctx = context.WithValue(ctx, parentModelKey{}, runCtx.Model)
+	}
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		defer cancel()
+	}
+	ctx = context.WithValue(ctx, parentChainKey{}, req.ParentChain)
+	m.mu.RLock()
+	enforce := m.enforceTools
+	m.mu.RUnlock()
+	if enforce && len(runCtx.ToolWhitelist) > 0 {
+		ctx = tool.WithAllowedTools(ctx, runCtx.ToolList()...)
+	}
+
+	var meter *budgetMeter
+	if !runCtx.Budget.IsZero() {
+		meter = newBudgetMeter(runCtx.Budget, budgetMeterFromContext(ctx))
+		ctx = context.WithValue(ctx, budgetKey{}, meter)
+	}
 
 	result, execErr := target.handler.Handle(ctx, runCtx, req)
 	result.Subagent = target.definition.Name
 	result = result.clone()
+	if result.Metadata == nil {
+		result.Metadata = map[string]any{}
+	}
+	result.Metadata[DepthMetadataKey] = depth
+	if meter != nil {
+		used, overErr := meter.snapshot()
+		result.Metadata[BudgetUsedMetadataKey] = used
+		// A handler that ignored Charge's error still fails the dispatch.
+		if execErr == nil {
+			execErr = overErr
+		}
+	}
 	if execErr != nil {
 		result.Error = execErr.Error()
 		return result, execErr
@@ -283,6 +451,32 @@ func (m *Manager) Dispatch(ctx context.Context, req Request) (Result, error) {
 	return result, nil
 }
 
+// Resolve reports which subagent Dispatch would pick for req without running
+// it. Target, score ranking and the confidence floor apply as in Dispatch;
+// cycle and depth checks do not, since they depend on the dispatch context.
+func (m *Manager) Resolve(req Request) (Definition, error) {
+	target, err := m.selectTarget(req)
+	if err != nil {
+		return Definition{}, err
+	}
+	return target.definition, nil
+}
+
+func (m *Manager) resolveModel(ctx context.Context, override, defined string) string {
+	if model := strings.TrimSpace(override); model != "" {
+		return model
+	}
+	if defined != "" {
+		return defined
+	}
+	if parent, ok := ctx.Value(parentModelKey{}).(string); ok && parent != "" {
+		return parent
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.defaultModel
+}
+
 func (m *Manager) selectTarget(req Request) (*registeredSubagent, error) {
 	if target := strings.TrimSpace(req.Target); target != "" {
 		m.mu.RLock()
@@ -293,14 +487,45 @@ func (m *Manager) selectTarget(req Request) (*registeredSubagent, error) {
 		}
 		return sub, nil
 	}
-	matches := m.matching(req.Activation)
+	matches := m.scoredMatches(req.Activation)
 	if len(matches) == 0 {
 		return nil, ErrNoMatchingSubagent
 	}
-	return matches[0], nil
+	m.mu.RLock()
+	floor := m.minScore
+	fallback := m.subagents[TypeGeneralPurpose]
+	m.mu.RUnlock()
+	if floor <= 0 {
+		return matches[0].sub, nil
+	}
+	for _, match := range matches {
+		if match.score >= floor {
+			return match.sub, nil
+		}
+	}
+	if fallback != nil {
+		return fallback, nil
+	}
+	return nil, ErrNoConfidentMatch
 }
 
 func (m *Manager) matching(ctx skills.ActivationContext) []*registeredSubagent {
+	scored := m.scoredMatches(ctx)
+	subs := make([]*registeredSubagent, 0, len(scored))
+	for _, cand := range scored {
+		subs = append(subs, cand.sub)
+	}
+	return subs
+}
+
+type scoredSubagent struct {
+	sub   *registeredSubagent
+	score float64
+}
+
+// scoredMatches returns matching subagents ordered by priority then score,
+// keeping only the skills.MutexWinners entry of each mutex group.
+func (m *Manager) scoredMatches(ctx skills.ActivationContext) []scoredSubagent {
 	m.mu.RLock()
 	snapshot := make([]*registeredSubagent, 0, len(m.subagents))
 	for _, sub := range m.subagents {
@@ -308,14 +533,10 @@ func (m *Manager) matching(ctx skills.ActivationContext) []*registeredSubagent {
 	}
 	m.mu.RUnlock()
 
-	type candidate struct {
-		sub   *registeredSubagent
-		score float64
-	}
-	var candidates []candidate
+	var candidates []scoredSubagent
 	for _, sub := range snapshot {
 		if len(sub.definition.Matchers) == 0 {
-			candidates = append(candidates, candidate{sub, 0.5})
+			candidates = append(candidates, scoredSubagent{sub, 0.5})
 			continue
 		}
 		var best skills.MatchResult
@@ -336,7 +557,7 @@ func (m *Manager) matching(ctx skills.ActivationContext) []*registeredSubagent {
 		if !matched {
 			continue
 		}
-		candidates = append(candidates, candidate{sub: sub, score: best.Score})
+		candidates = append(candidates, scoredSubagent{sub: sub, score: best.Score})
 	}
 	sort.SliceStable(candidates, func(i, j int) bool {
 		di := candidates[i].sub.definition
@@ -350,19 +571,18 @@ func (m *Manager) matching(ctx skills.ActivationContext) []*registeredSubagent {
 		return di.Name < dj.Name
 	})
 
-	seen := map[string]struct{}{}
-	filtered := make([]*registeredSubagent, 0, len(candidates))
-	for _, cand := range candidates {
-		key := cand.sub.definition.MutexKey
-		if key == "" {
-			filtered = append(filtered, cand.sub)
-			continue
-		}
-		if _, ok := seen[key]; ok {
+	cands := make([]skills.MutexCandidate, len(candidates))
+	for i, cand := range candidates {
+		def := cand.sub.definition
+		cands[i] = skills.MutexCandidate{Key: def.MutexKey, Priority: def.Priority, Name: def.Name}
+	}
+	winners := skills.MutexWinners(cands)
+	filtered := make([]scoredSubagent, 0, len(candidates))
+	for i, cand := range candidates {
+		if key := cand.sub.definition.MutexKey; key != "" && winners[key] != i {
 			continue
 		}
-		seen[key] = struct{}{}
-		filtered = append(filtered, cand.sub)
+		filtered = append(filtered, cand)
 	}
 	return filtered
 }
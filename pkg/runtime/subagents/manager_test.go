@@ -3,11 +3,14 @@ package subagents
 import (
 	"context"
 	"errors"
+	"reflect"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 
 	"github.com/cexll/agentsdk-go/pkg/runtime/skills"
+	"github.com/cexll/agentsdk-go/pkg/tool"
 )
 
 func taskDispatchCtx() context.Context {
@@ -470,3 +473,241 @@ func TestManagerDispatchConcurrent(t *testing.T) {
 		t.Fatalf("expected %d handler invocations, got %d", workers, counter)
 	}
 }
+
+func TestManagerMinScoreRejectsWeakMatches(t *testing.T) {
+	weak := skills.MatcherFunc(func(skills.ActivationContext) skills.MatchResult {
+		return skills.MatchResult{Matched: true, Score: 0.3, Reason: "weak"}
+	})
+	strong := skills.MatcherFunc(func(ac skills.ActivationContext) skills.MatchResult {
+		if ac.Prompt != "strong" {
+			return skills.MatchResult{}
+		}
+		return skills.MatchResult{Matched: true, Score: 0.9, Reason: "strong"}
+	})
+	handler := HandlerFunc(func(context.Context, Context, Request) (Result, error) { return Result{}, nil })
+
+	m := NewManager().WithMinScore(0.6)
+	if err := m.Register(Definition{Name: "weakling", Priority: 5, Matchers: []skills.Matcher{weak}}, handler); err != nil {
+		t.Fatalf("register weak: %v", err)
+	}
+	if err := m.Register(Definition{Name: "expert", Matchers: []skills.Matcher{strong}}, handler); err != nil {
+		t.Fatalf("register strong: %v", err)
+	}
+
+	_, err := m.Dispatch(taskDispatchCtx(), Request{Instruction: "go", Activation: skills.ActivationContext{Prompt: "vague"}})
+	if !errors.Is(err, ErrNoConfidentMatch) {
+		t.Fatalf("expected ErrNoConfidentMatch, got %v", err)
+	}
+
+	res, err := m.Dispatch(taskDispatchCtx(), Request{Instruction: "go", Activation: skills.ActivationContext{Prompt: "strong"}})
+	if err != nil || res.Subagent != "expert" {
+		t.Fatalf("expected strong match to dispatch normally, got %q err=%v", res.Subagent, err)
+	}
+
+	res, err = m.Dispatch(taskDispatchCtx(), Request{Target: "weakling", Instruction: "go"})
+	if err != nil || res.Subagent != "weakling" {
+		t.Fatalf("expected explicit target to bypass threshold, got %q err=%v", res.Subagent, err)
+	}
+
+	if err := m.Register(Definition{Name: TypeGeneralPurpose, Matchers: []skills.Matcher{weak}}, handler); err != nil {
+		t.Fatalf("register general-purpose: %v", err)
+	}
+	res, err = m.Dispatch(taskDispatchCtx(), Request{Instruction: "go", Activation: skills.ActivationContext{Prompt: "vague"}})
+	if err != nil || res.Subagent != TypeGeneralPurpose {
+		t.Fatalf("expected general-purpose fallback, got %q err=%v", res.Subagent, err)
+	}
+
+	unset := NewManager()
+	if err := unset.Register(Definition{Name: "weakling", Matchers: []skills.Matcher{weak}}, handler); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if res, err := unset.Dispatch(taskDispatchCtx(), Request{Instruction: "go", Activation: skills.ActivationContext{Prompt: "vague"}}); err != nil || res.Subagent != "weakling" {
+		t.Fatalf("expected weak match accepted without threshold, got %q err=%v", res.Subagent, err)
+	}
+}
+
+func TestManagerDispatchRejectsCycle(t *testing.T) {
+	m := NewManager()
+	var aRuns, bRuns int32
+	var cycleErr error
+	handlerA := HandlerFunc(func(ctx context.Context, _ Context, req Request) (Result, error) {
+		atomic.AddInt32(&aRuns, 1)
+		return m.Dispatch(ctx, Request{Target: "b", Instruction: "hand off"})
+	})
+	handlerB := HandlerFunc(func(ctx context.Context, _ Context, req Request) (Result, error) {
+		atomic.AddInt32(&bRuns, 1)
+		if got := ParentChainFromContext(ctx); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+			t.Errorf("unexpected parent chain %v", got)
+		}
+		_, cycleErr = m.Dispatch(ctx, Request{Target: "a", Instruction: "hand back"})
+		return Result{Output: "b done"}, cycleErr
+	})
+	if err := m.Register(Definition{Name: "a"}, handlerA); err != nil {
+		t.Fatalf("register a: %v", err)
+	}
+	if err := m.Register(Definition{Name: "b"}, handlerB); err != nil {
+		t.Fatalf("register b: %v", err)
+	}
+
+	_, err := m.Dispatch(taskDispatchCtx(), Request{Target: "a", Instruction: "start"})
+	if !errors.Is(err, ErrSubagentCycle) {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+	var ce *CycleError
+	if !errors.As(cycleErr, &ce) || len(ce.Chain) != 3 || ce.Chain[0] != "a" || ce.Chain[2] != "a" {
+		t.Fatalf("unexpected cycle detail %+v", cycleErr)
+	}
+	if got := ce.Error(); got != "subagents: dispatch cycle: a -> b -> a" {
+		t.Fatalf("unexpected message %q", got)
+	}
+	if aRuns != 1 || bRuns != 1 {
+		t.Fatalf("expected a and b to run once, got a=%d b=%d", aRuns, bRuns)
+	}
+
+	_, err = m.Dispatch(taskDispatchCtx(), Request{Target: "b", Instruction: "x", ParentChain: []string{"b", "a"}})
+	if !errors.Is(err, ErrSubagentCycle) || bRuns != 1 {
+		t.Fatalf("explicit parent chain should reject before running b: %v", err)
+	}
+}
+
+type namedTool struct{ name string }
+
+func (n namedTool) Name() string             { return n.name }
+func (n namedTool) Description() string      { return n.name }
+func (n namedTool) Schema() *tool.JSONSchema { return nil }
+func (n namedTool) Execute(context.Context, map[string]any) (*tool.ToolResult, error) {
+	return &tool.ToolResult{Success: true, Output: n.name}, nil
+}
+
+func TestManagerToolEnforcementRejectsNonWhitelisted(t *testing.T) {
+	reg := tool.NewRegistry()
+	for _, name := range []string{"bash", "read", "write"} {
+		if err := reg.Register(namedTool{name: name}); err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+	}
+	exec := tool.NewExecutor(reg, nil)
+
+	var writeErr, bashErr error
+	handler := HandlerFunc(func(ctx context.Context, _ Context, _ Request) (Result, error) {
+		_, bashErr = exec.Execute(ctx, tool.Call{Name: "bash"})
+		_, writeErr = exec.Execute(ctx, tool.Call{Name: "write"})
+		return Result{}, nil
+	})
+	m := NewManager().WithToolEnforcement(true)
+	def := Definition{Name: "deploy_guard", BaseContext: Context{ToolWhitelist: []string{"bash", "read"}}}
+	if err := m.Register(def, handler); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if _, err := m.Dispatch(taskDispatchCtx(), Request{Target: "deploy_guard", Instruction: "ship"}); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if bashErr != nil {
+		t.Fatalf("expected bash allowed, got %v", bashErr)
+	}
+	if !errors.Is(writeErr, tool.ErrToolNotAllowed) || !strings.Contains(writeErr.Error(), "allowed: bash, read") {
+		t.Fatalf("expected write rejected, got %v", writeErr)
+	}
+
+	// Without enforcement the whitelist stays advisory.
+	m.WithToolEnforcement(false)
+	if _, err := m.Dispatch(taskDispatchCtx(), Request{Target: "deploy_guard", Instruction: "ship"}); err != nil || writeErr != nil {
+		t.Fatalf("expected write allowed without enforcement: %v %v", err, writeErr)
+	}
+}
+
+func TestManagerMaxDepth(t *testing.T) {
+	m := NewManager().WithMaxDepth(2)
+	var innerErr error
+	var innerDepth any
+	spawn := func(next string) Handler {
+		return HandlerFunc(func(ctx context.Context, _ Context, _ Request) (Result, error) {
+			if next == "" {
+				return Result{Output: DepthFromContext(ctx)}, nil
+			}
+			res, err := m.Dispatch(ctx, Request{Target: next, Instruction: "go deeper"})
+			if err != nil {
+				innerErr = err
+				return Result{}, nil
+			}
+			innerDepth = res.Metadata[DepthMetadataKey]
+			return res, nil
+		})
+	}
+	for name, next := range map[string]string{"outer": "middle", "middle": "leaf", "leaf": "", "top": "leaf"} {
+		if err := m.Register(Definition{Name: name}, spawn(next)); err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+	}
+
+	res, err := m.Dispatch(taskDispatchCtx(), Request{Target: "top", Instruction: "run"})
+	if err != nil || innerErr != nil {
+		t.Fatalf("depth 2 must be allowed: %v %v", err, innerErr)
+	}
+	if res.Metadata[DepthMetadataKey] != 1 || innerDepth != 2 || res.Output != 2 {
+		t.Fatalf("unexpected depth reporting: meta=%v inner=%v output=%v", res.Metadata, innerDepth, res.Output)
+	}
+
+	innerErr = nil
+	if _, err := m.Dispatch(taskDispatchCtx(), Request{Target: "outer", Instruction: "run"}); err != nil {
+		t.Fatalf("outer dispatch: %v", err)
+	}
+	if !errors.Is(innerErr, ErrMaxDepthExceeded) {
+		t.Fatalf("expected ErrMaxDepthExceeded from depth 3, got %v", innerErr)
+	}
+}
+
+func TestManagerModelResolution(t *testing.T) {
+	m := NewManager().WithDefaultModel("opus")
+	var seen []string
+	var nested []string
+	record := HandlerFunc(func(_ context.Context, subCtx Context, _ Request) (Result, error) {
+		seen = append(seen, subCtx.Model)
+		return Result{}, nil
+	})
+	if err := m.Register(Definition{Name: "inheritor"}, record); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := m.Register(Definition{Name: "pinned", DefaultModel: ModelHaiku}, record); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	parent := HandlerFunc(func(ctx context.Context, subCtx Context, _ Request) (Result, error) {
+		nested = append(nested, subCtx.Model)
+		return m.Dispatch(ctx, Request{Target: "inheritor", Instruction: "child"})
+	})
+	if err := m.Register(Definition{Name: "parent", BaseContext: Context{Model: ModelSonnet}}, parent); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	dispatch := func(req Request) {
+		t.Helper()
+		req.Instruction = "go"
+		if _, err := m.Dispatch(taskDispatchCtx(), req); err != nil {
+			t.Fatalf("dispatch %s: %v", req.Target, err)
+		}
+	}
+	dispatch(Request{Target: "inheritor"})
+	dispatch(Request{Target: "pinned"})
+	dispatch(Request{Target: "pinned", Model: "opus"})
+	dispatch(Request{Target: "parent"})
+
+	want := []string{"opus", ModelHaiku, "opus", ModelSonnet}
+	if !reflect.DeepEqual(seen, want) {
+		t.Fatalf("resolved models %v, want %v", seen, want)
+	}
+	if !reflect.DeepEqual(nested, []string{ModelSonnet}) {
+		t.Fatalf("parent model %v", nested)
+	}
+
+	bare := NewManager()
+	if err := bare.Register(Definition{Name: "inheritor"}, record); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	seen = nil
+	if _, err := bare.Dispatch(taskDispatchCtx(), Request{Target: "inheritor", Instruction: "go"}); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if len(seen) != 1 || seen[0] != "" {
+		t.Fatalf("expected empty model without default, got %v", seen)
+	}
+}
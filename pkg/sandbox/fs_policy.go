@@ -2,18 +2,24 @@ package sandbox
 
 import (
 	"fmt"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
 
+	"github.com/cexll/agentsdk-go/pkg/internal/pathglob"
 	"github.com/cexll/agentsdk-go/pkg/security"
 )
 
 // FileSystemAllowList enforces path boundaries using PathResolver to block traversal and symlinks.
+// Glob rules added with AllowGlob and DenyGlob refine the prefix allowlist;
+// a deny glob always wins.
 type FileSystemAllowList struct {
-	mu       sync.RWMutex
-	allow    []string
-	resolver *security.PathResolver
+	mu         sync.RWMutex
+	allow      []string
+	allowGlobs []string
+	denyGlobs  []string
+	resolver   *security.PathResolver
 }
 
 // NewFileSystemAllowList initialises a policy rooted at root with optional extra allowed prefixes.
@@ -49,6 +55,67 @@ func (p *FileSystemAllowList) Allow(path string) {
 	p.allow = append(p.allow, clean)
 }
 
+// AllowGlob allows every path matching pattern, or lying under a directory
+// that matches it. Patterns use filepath.Match syntax per path segment plus
+// "**" for any number of segments. Relative patterns, including those
+// starting with "**", are anchored at the first allowed root; absolute
+// patterns must start with a literal directory so an allow glob can never
+// match anywhere on the filesystem.
+//
+// A glob whose literal prefix lies inside an allowed root scopes that root:
+// once a root has such globs, paths inside it must match one of them, so
+// AllowGlob("src/**") limits the first root to its src directory. A glob
+// outside every root extends the allowlist to the paths it matches.
+func (p *FileSystemAllowList) AllowGlob(pattern string) error {
+	return p.addGlob(pattern, false)
+}
+
+// DenyGlob rejects every path matching pattern, or lying under a directory
+// that matches it, even when an allowed root or AllowGlob covers it. Pattern
+// syntax is the same as AllowGlob, except that patterns starting with "**"
+// match anywhere, e.g. "**/node_modules".
+func (p *FileSystemAllowList) DenyGlob(pattern string) error {
+	return p.addGlob(pattern, true)
+}
+
+func (p *FileSystemAllowList) addGlob(pattern string, deny bool) error {
+	if p == nil {
+		return fmt.Errorf("%w: policy not initialised", ErrPathDenied)
+	}
+	pattern = filepath.ToSlash(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return fmt.Errorf("sandbox: empty glob pattern")
+	}
+	for _, seg := range strings.Split(pattern, "/") {
+		if _, err := path.Match(seg, ""); err != nil {
+			return fmt.Errorf("sandbox: invalid glob %q: %w", pattern, err)
+		}
+	}
+
+	relative := !strings.HasPrefix(pattern, "/") && !filepath.IsAbs(filepath.FromSlash(pattern))
+	if !deny && !relative {
+		if first, _, _ := strings.Cut(strings.TrimPrefix(pattern, "/"), "/"); strings.ContainsAny(first, `*?[\`) {
+			return fmt.Errorf("sandbox: absolute allow glob %q must start with a literal directory", pattern)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if relative && (!deny || !strings.HasPrefix(pattern, "**")) {
+		if len(p.allow) == 0 {
+			return fmt.Errorf("sandbox: relative glob %q needs an allowed root", pattern)
+		}
+		pattern = filepath.ToSlash(p.allow[0]) + "/" + pattern
+	}
+	pattern = strings.TrimSuffix(path.Clean(pattern), "/")
+	if deny {
+		p.denyGlobs = append(p.denyGlobs, pattern)
+	} else {
+		p.allowGlobs = append(p.allowGlobs, pattern)
+	}
+	return nil
+}
+
 // Roots returns a copy of the allowlist.
 func (p *FileSystemAllowList) Roots() []string {
 	if p == nil {
@@ -86,13 +153,86 @@ func (p *FileSystemAllowList) Validate(path string) error {
 	clean := normalize(resolved)
 	p.mu.RLock()
 	roots := append([]string(nil), p.allow...)
+	allowGlobs := append([]string(nil), p.allowGlobs...)
+	denyGlobs := append([]string(nil), p.denyGlobs...)
 	p.mu.RUnlock()
+	if pattern, ok := matchAnyGlob(denyGlobs, clean); ok {
+		return fmt.Errorf("%w: %s matches deny pattern %q", ErrPathDenied, clean, pattern)
+	}
+	inRoot := ""
 	for _, root := range roots {
 		if within(clean, root) {
-			return nil
+			inRoot = root
+			break
+		}
+	}
+	if inRoot == "" {
+		pattern, ok := matchAnyGlob(allowGlobs, clean)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrPathDenied, clean)
+		}
+		// The glob's literal prefix stands in for the root it extends to.
+		roots = []string{globPrefix(pattern)}
+	} else if scope := scopingGlobs(allowGlobs, inRoot); len(scope) > 0 {
+		if _, ok := matchAnyGlob(scope, clean); !ok {
+			return fmt.Errorf("%w: %s matches no allow pattern", ErrPathDenied, clean)
+		}
+	}
+	real, ok, err := resolver.WithinRealRoots(clean, roots)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrPathDenied, err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s resolves to %s", ErrSymlinkDetected, clean, real)
+	}
+	if pattern, ok := matchAnyGlob(denyGlobs, normalize(real)); ok {
+		return fmt.Errorf("%w: %s matches deny pattern %q", ErrPathDenied, real, pattern)
+	}
+	return nil
+}
+
+// scopingGlobs returns the patterns whose literal prefix lies inside root.
+func scopingGlobs(patterns []string, root string) []string {
+	var out []string
+	for _, pattern := range patterns {
+		if within(globPrefix(pattern), root) {
+			out = append(out, pattern)
+		}
+	}
+	return out
+}
+
+// globPrefix returns the leading segments of pattern that contain no glob
+// metacharacters.
+func globPrefix(pattern string) string {
+	segs := strings.Split(pattern, "/")
+	n := 0
+	for n < len(segs) && !strings.ContainsAny(segs[n], `*?[\`) {
+		n++
+	}
+	prefix := strings.Join(segs[:n], "/")
+	if prefix == "" {
+		prefix = "/"
+	}
+	return filepath.FromSlash(prefix)
+}
+
+// matchAnyGlob reports the first pattern matching target or one of its
+// parent directories.
+func matchAnyGlob(patterns []string, target string) (string, bool) {
+	if len(patterns) == 0 {
+		return "", false
+	}
+	segs := strings.Split(strings.Trim(filepath.ToSlash(target), "/"), "/")
+	for _, pattern := range patterns {
+		patSegs := strings.Split(strings.Trim(pattern, "/"), "/")
+		for n := len(segs); n > 0; n-- {
+			if ok, _ := pathglob.MatchSegments(patSegs, segs[:n]); ok {
+				return pattern, true
+			}
 		}
 	}
-	return fmt.Errorf("%w: %s", ErrPathDenied, clean)
+	return "", false
 }
 
 func normalize(path string) string {
@@ -66,6 +66,21 @@ func TestFileSystemAllowListSymlink(t *testing.T) {
 	}
 }
 
+func TestFileSystemAllowListSymlinkDirEscape(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlink support varies on windows")
+	}
+	root := canonicalTempDir(t)
+	if err := os.Symlink("/etc", filepath.Join(root, "etc")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	policy := NewFileSystemAllowList(root)
+	err := policy.Validate(filepath.Join(root, "etc", "passwd"))
+	if !errors.Is(err, ErrSymlinkDetected) {
+		t.Fatalf("expected symlink rejection, got %v", err)
+	}
+}
+
 func TestFileSystemAllowListAdditionalRoots(t *testing.T) {
 	root := canonicalTempDir(t)
 	shared := canonicalTempDir(t)
@@ -102,6 +117,97 @@ func TestFileSystemAllowListRootsSnapshot(t *testing.T) {
 	}
 }
 
+func TestFileSystemAllowListGlobs(t *testing.T) {
+	root := canonicalTempDir(t)
+	other := canonicalTempDir(t)
+	policy := NewFileSystemAllowList(root)
+
+	if err := policy.DenyGlob("**/node_modules"); err != nil {
+		t.Fatalf("deny glob: %v", err)
+	}
+	if err := policy.AllowGlob(filepath.ToSlash(other) + "/src/**"); err != nil {
+		t.Fatalf("allow glob: %v", err)
+	}
+	if err := policy.DenyGlob("secrets/*.key"); err != nil {
+		t.Fatalf("relative deny glob: %v", err)
+	}
+	if err := policy.AllowGlob("[bad"); err == nil {
+		t.Fatal("expected invalid pattern error")
+	}
+	if err := policy.AllowGlob("/**/*.md"); err == nil {
+		t.Fatal("expected unanchored absolute allow glob to fail")
+	}
+
+	cases := []struct {
+		path  string
+		allow bool
+	}{
+		{filepath.Join(root, "main.go"), true},
+		{filepath.Join(root, "node_modules"), false},
+		{filepath.Join(root, "web", "node_modules", "pkg", "index.js"), false},
+		{filepath.Join(root, "secrets", "prod.key"), false},
+		{filepath.Join(root, "secrets", "README"), true},
+		{filepath.Join(other, "src", "lib", "a.go"), true},
+		{filepath.Join(other, "src"), true},
+		{filepath.Join(other, "docs", "a.md"), false},
+		{filepath.Join(other, "src", "node_modules", "x"), false},
+	}
+	for _, tc := range cases {
+		err := policy.Validate(tc.path)
+		if tc.allow && err != nil {
+			t.Errorf("%s: unexpected error %v", tc.path, err)
+		}
+		if !tc.allow && !errors.Is(err, ErrPathDenied) {
+			t.Errorf("%s: expected ErrPathDenied, got %v", tc.path, err)
+		}
+	}
+}
+
+func TestFileSystemAllowListAllowGlobScopesRoot(t *testing.T) {
+	root := canonicalTempDir(t)
+	extra := canonicalTempDir(t)
+	outside := canonicalTempDir(t)
+	policy := NewFileSystemAllowList(root, extra)
+	if err := policy.AllowGlob("src/**"); err != nil {
+		t.Fatalf("allow glob: %v", err)
+	}
+	if err := policy.AllowGlob("**/*.md"); err != nil {
+		t.Fatalf("allow glob: %v", err)
+	}
+	if err := policy.AllowGlob(filepath.ToSlash(outside) + "/pub/**"); err != nil {
+		t.Fatalf("allow glob: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(outside, "pub"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	escape := filepath.Join(outside, "pub", "escape")
+	if err := os.Symlink(t.TempDir(), escape); err != nil {
+		t.Skipf("symlink unsupported: %v", err)
+	}
+
+	cases := []struct {
+		path  string
+		allow bool
+	}{
+		{filepath.Join(root, "src", "main.go"), true},
+		{filepath.Join(root, "docs", "a.md"), true},
+		{filepath.Join(root, "other", "x"), false},
+		{filepath.Join(extra, "other", "x"), true},
+		{filepath.Join(outside, "pub", "a.txt"), true},
+		{filepath.Join(outside, "private", "a.md"), false},
+		{filepath.Join(escape, "secret"), false},
+	}
+	for _, tc := range cases {
+		err := policy.Validate(tc.path)
+		if tc.allow && err != nil {
+			t.Errorf("%s: unexpected error %v", tc.path, err)
+		}
+		if !tc.allow && err == nil {
+			t.Errorf("%s: expected denial", tc.path)
+		}
+	}
+}
+
 func TestResourceLimiter(t *testing.T) {
 	limiter := NewResourceLimiter(ResourceLimits{MaxCPUPercent: 50, MaxMemoryBytes: 1024, MaxDiskBytes: 2048})
 	if err := limiter.Validate(ResourceUsage{CPUPercent: 40, MemoryBytes: 512, DiskBytes: 1024}); err != nil {
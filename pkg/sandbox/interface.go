@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/cexll/agentsdk-go/pkg/security"
 )
@@ -46,6 +47,14 @@ type ResourceLimits struct {
 	MaxCPUPercent  float64
 	MaxMemoryBytes uint64
 	MaxDiskBytes   uint64
+	// MaxProcesses and MaxOpenFiles are applied as rlimits (RLIMIT_NPROC,
+	// RLIMIT_NOFILE) to commands started by BashTool where the platform
+	// supports it; Validate does not check them. RLIMIT_NPROC counts every
+	// process and thread of the real user on the host, not only the
+	// command's, and is ignored for root: a MaxProcesses at or below the
+	// user's current count would make every fork fail, so BashTool skips it.
+	MaxProcesses uint64
+	MaxOpenFiles uint64
 }
 
 // ResourcePolicy enforces resource ceilings.
@@ -56,7 +65,9 @@ type ResourcePolicy interface {
 
 // ResourceLimiter is a minimal implementation of ResourcePolicy.
 type ResourceLimiter struct {
-	limits ResourceLimits
+	limits   ResourceLimits
+	probe    UsageProbe
+	interval time.Duration
 }
 
 // NewResourceLimiter builds a limiter with the provided ceilings.
@@ -1,20 +1,48 @@
 package sandbox
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/url"
 	"strings"
 	"sync"
+	"time"
 )
 
+// dnsLookupTimeout bounds the resolution done for CIDR checks.
+const dnsLookupTimeout = 2 * time.Second
+
 // DomainAllowList guards outbound hosts against a normalized white-list.
+//
+// Entries are hostnames (exact or suffix match), optionally restricted to one
+// port as "host:port", or CIDR ranges added with AllowCIDR. An IP literal is
+// checked against the ranges directly. A hostname that matches no host entry
+// is resolved and allowed only when every address it resolves to lies inside
+// a range. That check is advisory: DNS can answer differently by the time the
+// caller connects (rebinding), so strict egress control must dial the
+// validated addresses itself or enforce the ranges at the network layer.
 type DomainAllowList struct {
-	mu    sync.RWMutex
-	allow []string
+	mu     sync.RWMutex
+	allow  []hostRule
+	cidrs  []*net.IPNet
+	lookup func(ctx context.Context, host string) ([]net.IP, error)
+}
+
+type hostRule struct {
+	host string
+	port string // empty means any port
+}
+
+func (r hostRule) String() string {
+	if r.port == "" {
+		return r.host
+	}
+	return net.JoinHostPort(r.host, r.port)
 }
 
-// NewDomainAllowList creates an allowlist seeded with hosts.
+// NewDomainAllowList creates an allowlist seeded with hosts. Entries that
+// parse as CIDR ranges are added as with AllowCIDR.
 func NewDomainAllowList(allowed ...string) *DomainAllowList {
 	p := &DomainAllowList{}
 	for _, host := range allowed {
@@ -23,55 +51,160 @@ func NewDomainAllowList(allowed ...string) *DomainAllowList {
 	return p
 }
 
-// Allow permits traffic towards host (exact or suffix match).
+// Allow permits traffic towards host (exact or suffix match). A "host:port"
+// entry only permits that port; a CIDR entry is passed to AllowCIDR.
 func (p *DomainAllowList) Allow(host string) {
 	if p == nil {
 		return
 	}
-	norm := normalizeHost(host)
+	if strings.Contains(host, "/") && !strings.Contains(host, "://") {
+		if err := p.AllowCIDR(host); err == nil {
+			return
+		}
+	}
+	norm, port := splitHostPort(host)
 	if norm == "" {
 		return
 	}
+	rule := hostRule{host: norm, port: port}
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	for _, existing := range p.allow {
-		if existing == norm {
+		if existing == rule {
 			return
 		}
 	}
-	p.allow = append(p.allow, norm)
+	p.allow = append(p.allow, rule)
+}
+
+// AllowCIDR permits traffic towards any address in cidr, e.g. "10.0.0.0/8".
+func (p *DomainAllowList) AllowCIDR(cidr string) error {
+	if p == nil {
+		return fmt.Errorf("%w: policy not initialised", ErrDomainDenied)
+	}
+	_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+	if err != nil {
+		return fmt.Errorf("sandbox: invalid CIDR %q: %w", cidr, err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, existing := range p.cidrs {
+		if existing.String() == network.String() {
+			return nil
+		}
+	}
+	p.cidrs = append(p.cidrs, network)
+	return nil
 }
 
-// Allowed returns the normalised domains kept by the policy.
+// Allowed returns the normalised domains and CIDR ranges kept by the policy.
 func (p *DomainAllowList) Allowed() []string {
 	if p == nil {
 		return nil
 	}
 	p.mu.RLock()
 	defer p.mu.RUnlock()
-	out := make([]string, len(p.allow))
-	copy(out, p.allow)
+	out := make([]string, 0, len(p.allow)+len(p.cidrs))
+	for _, rule := range p.allow {
+		out = append(out, rule.String())
+	}
+	for _, network := range p.cidrs {
+		out = append(out, network.String())
+	}
 	return out
 }
 
-// Validate ensures host belongs to the allowlist.
+// Validate ensures host belongs to the allowlist. host may carry a port or
+// be a URL; a URL without an explicit port uses its scheme's default.
 func (p *DomainAllowList) Validate(host string) error {
+	return p.ValidateContext(context.Background(), host)
+}
+
+// ValidateContext is Validate bound to ctx: the DNS lookup done for CIDR
+// rules stops when ctx is cancelled or after dnsLookupTimeout.
+func (p *DomainAllowList) ValidateContext(ctx context.Context, host string) error {
 	if p == nil {
 		return fmt.Errorf("%w: policy not initialised", ErrDomainDenied)
 	}
-	target := normalizeHost(host)
+	target, port := splitHostPort(host)
 	if target == "" {
 		return fmt.Errorf("%w: empty host", ErrDomainDenied)
 	}
 
 	p.mu.RLock()
-	defer p.mu.RUnlock()
-	for _, allowed := range p.allow {
-		if matchesHost(target, allowed) {
+	rules := append([]hostRule(nil), p.allow...)
+	cidrs := append([]*net.IPNet(nil), p.cidrs...)
+	lookup := p.lookup
+	p.mu.RUnlock()
+
+	for _, rule := range rules {
+		if matchesHost(target, rule.host) && (rule.port == "" || rule.port == port) {
 			return nil
 		}
 	}
-	return fmt.Errorf("%w: %s", ErrDomainDenied, target)
+	if len(cidrs) == 0 {
+		return fmt.Errorf("%w: %s", ErrDomainDenied, target)
+	}
+
+	var ips []net.IP
+	if ip := net.ParseIP(target); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		if lookup == nil {
+			lookup = func(ctx context.Context, host string) ([]net.IP, error) {
+				return net.DefaultResolver.LookupIP(ctx, "ip", host)
+			}
+		}
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx, cancel := context.WithTimeout(ctx, dnsLookupTimeout)
+		defer cancel()
+		resolved, err := lookup(ctx, target)
+		if err != nil || len(resolved) == 0 {
+			return fmt.Errorf("%w: %s (resolve: %v)", ErrDomainDenied, target, err)
+		}
+		ips = resolved
+	}
+	for _, ip := range ips {
+		if !ipInAny(ip, cidrs) {
+			return fmt.Errorf("%w: %s (%s)", ErrDomainDenied, target, ip)
+		}
+	}
+	return nil
+}
+
+func ipInAny(ip net.IP, cidrs []*net.IPNet) bool {
+	for _, network := range cidrs {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitHostPort normalises input to a lower-case host and optional port.
+func splitHostPort(input string) (string, string) {
+	raw := strings.TrimSpace(strings.ToLower(input))
+	if raw == "" {
+		return "", ""
+	}
+	var port string
+	if strings.Contains(raw, "://") {
+		if u, err := url.Parse(raw); err == nil {
+			raw = u.Host
+			switch u.Scheme {
+			case "https", "wss":
+				port = "443"
+			case "http", "ws":
+				port = "80"
+			}
+		}
+	}
+	if h, p, err := net.SplitHostPort(raw); err == nil {
+		raw, port = h, p
+	}
+	return normalizeHost(raw), port
 }
 
 func normalizeHost(input string) string {
@@ -1,6 +1,13 @@
 package sandbox
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
 
 func TestDomainAllowListValidate(t *testing.T) {
 	policy := NewDomainAllowList("example.com", "*.svc.local")
@@ -41,3 +48,73 @@ func TestDomainAllowListValidate(t *testing.T) {
 		t.Fatalf("empty host should be ignored, got %v", policy.Allowed())
 	}
 }
+
+func TestDomainAllowListPortsAndCIDR(t *testing.T) {
+	policy := NewDomainAllowList("api.example.com:443", "10.0.0.0/8")
+	if err := policy.AllowCIDR("fd00::/8"); err != nil {
+		t.Fatalf("allow cidr: %v", err)
+	}
+	if err := policy.AllowCIDR("not-a-cidr"); err == nil {
+		t.Fatal("expected invalid CIDR error")
+	}
+	policy.lookup = func(_ context.Context, host string) ([]net.IP, error) {
+		switch host {
+		case "internal.corp":
+			return []net.IP{net.ParseIP("10.1.2.3")}, nil
+		case "mixed.corp":
+			return []net.IP{net.ParseIP("10.1.2.3"), net.ParseIP("8.8.8.8")}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	want := []string{"api.example.com:443", "10.0.0.0/8", "fd00::/8"}
+	if got := policy.Allowed(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("allowed = %v, want %v", got, want)
+	}
+
+	cases := []struct {
+		host string
+		ok   bool
+	}{
+		{"api.example.com:443", true},
+		{"https://api.example.com/v1", true},
+		{"api.example.com:80", false},
+		{"http://api.example.com/v1", false},
+		{"api.example.com", false},
+		{"10.9.8.7", true},
+		{"10.9.8.7:22", true},
+		{"11.0.0.1", false},
+		{"[fd00::1]:443", true},
+		{"internal.corp", true},
+		{"mixed.corp", false},
+		{"unknown.corp", false},
+	}
+	for _, tc := range cases {
+		err := policy.Validate(tc.host)
+		if tc.ok && err != nil {
+			t.Errorf("expected %s allowed: %v", tc.host, err)
+		}
+		if !tc.ok && !errors.Is(err, ErrDomainDenied) {
+			t.Errorf("expected %s denied, got %v", tc.host, err)
+		}
+	}
+}
+
+func TestDomainAllowListValidateContextCancelsLookup(t *testing.T) {
+	policy := NewDomainAllowList("10.0.0.0/8")
+	policy.lookup = func(ctx context.Context, _ string) ([]net.IP, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	err := policy.ValidateContext(ctx, "internal.corp")
+	if !errors.Is(err, ErrDomainDenied) {
+		t.Fatalf("expected denial, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= dnsLookupTimeout {
+		t.Fatalf("lookup ignored cancelled ctx, took %v", elapsed)
+	}
+}
@@ -0,0 +1,78 @@
+package sandbox
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultUsageInterval is how often MonitorUsage samples when no interval is
+// given.
+const DefaultUsageInterval = 500 * time.Millisecond
+
+// MonitorUsage samples pid with probe every interval and validates each
+// sample against policy. On the first rejected sample it calls onViolation
+// (if set) and stops sampling; onViolation is expected to terminate the
+// process. Probe errors are ignored because the process may exit between
+// ticks. The returned stop func halts sampling and reports the violation, if
+// any; it must be called once the process has exited.
+func MonitorUsage(ctx context.Context, pid int, policy ResourcePolicy, probe UsageProbe, interval time.Duration, onViolation func(error)) (stop func() error) {
+	if policy == nil {
+		return func() error { return nil }
+	}
+	if probe == nil {
+		probe = DefaultUsageProbe()
+	}
+	if interval <= 0 {
+		interval = DefaultUsageInterval
+	}
+	monitorCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	var violation error
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-monitorCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			usage, err := probe.Sample(pid)
+			if err != nil {
+				continue
+			}
+			if err := policy.Validate(usage); err != nil {
+				violation = err
+				if onViolation != nil {
+					onViolation(err)
+				}
+				return
+			}
+		}
+	}()
+	return func() error {
+		cancel()
+		<-done
+		return violation
+	}
+}
+
+// SetUsageProbe configures how Monitor samples processes. A nil probe selects
+// the platform default and a non-positive interval DefaultUsageInterval.
+func (r *ResourceLimiter) SetUsageProbe(probe UsageProbe, interval time.Duration) {
+	if r == nil {
+		return
+	}
+	r.probe = probe
+	r.interval = interval
+}
+
+// Monitor enforces the limiter's ceilings on a running process, see
+// MonitorUsage. It is a no-op when no ceiling is configured.
+func (r *ResourceLimiter) Monitor(ctx context.Context, pid int, onViolation func(error)) (stop func() error) {
+	if r == nil || r.limits == (ResourceLimits{}) {
+		return func() error { return nil }
+	}
+	return MonitorUsage(ctx, pid, r, r.probe, r.interval, onViolation)
+}
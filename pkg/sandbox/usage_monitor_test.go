@@ -0,0 +1,64 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeUsageProbe struct {
+	samples atomic.Int32
+	usage   ResourceUsage
+}
+
+func (p *fakeUsageProbe) Sample(int) (ResourceUsage, error) {
+	if p.samples.Add(1) < 3 {
+		return ResourceUsage{}, nil
+	}
+	return p.usage, nil
+}
+
+func TestResourceLimiterMonitorReportsViolation(t *testing.T) {
+	limiter := NewResourceLimiter(ResourceLimits{MaxMemoryBytes: 1 << 20})
+	probe := &fakeUsageProbe{usage: ResourceUsage{MemoryBytes: 1 << 30}}
+	limiter.SetUsageProbe(probe, time.Millisecond)
+
+	violated := make(chan error, 1)
+	stop := limiter.Monitor(context.Background(), 1, func(err error) { violated <- err })
+	select {
+	case err := <-violated:
+		if !errors.Is(err, ErrResourceExceeded) {
+			t.Fatalf("unexpected violation %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("monitor never reported the violation")
+	}
+	if err := stop(); !errors.Is(err, ErrResourceExceeded) {
+		t.Fatalf("stop returned %v", err)
+	}
+	if n := probe.samples.Load(); n != 3 {
+		t.Fatalf("expected sampling to stop at the violation, got %d samples", n)
+	}
+}
+
+func TestResourceLimiterMonitorNoLimits(t *testing.T) {
+	probe := &fakeUsageProbe{usage: ResourceUsage{MemoryBytes: 1 << 30}}
+	limiter := NewResourceLimiter(ResourceLimits{})
+	limiter.SetUsageProbe(probe, time.Millisecond)
+
+	stop := limiter.Monitor(context.Background(), 1, func(error) { t.Error("unexpected violation") })
+	time.Sleep(10 * time.Millisecond)
+	if err := stop(); err != nil {
+		t.Fatalf("stop returned %v", err)
+	}
+	if n := probe.samples.Load(); n != 0 {
+		t.Fatalf("expected no sampling without limits, got %d", n)
+	}
+
+	var nilLimiter *ResourceLimiter
+	if err := nilLimiter.Monitor(context.Background(), 1, nil)(); err != nil {
+		t.Fatalf("nil limiter stop returned %v", err)
+	}
+}
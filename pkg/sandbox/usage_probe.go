@@ -0,0 +1,17 @@
+package sandbox
+
+// UsageProbe samples the resource consumption of a running process so that
+// ResourcePolicy limits can be enforced while a command executes rather than
+// only checked against caller-supplied figures.
+type UsageProbe interface {
+	Sample(pid int) (ResourceUsage, error)
+}
+
+// NoopUsageProbe reports zero usage. It is the fallback on platforms without a
+// native probe and keeps limits advisory.
+type NoopUsageProbe struct{}
+
+// Sample implements UsageProbe.
+func (NoopUsageProbe) Sample(int) (ResourceUsage, error) {
+	return ResourceUsage{}, nil
+}
@@ -0,0 +1,190 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clockTicks mirrors USER_HZ, which is fixed at 100 on every mainstream Linux
+// architecture and is what /proc/<pid>/stat reports CPU time in.
+const clockTicks = 100
+
+// staleSampleAge bounds how long CPU baselines for exited processes linger.
+const staleSampleAge = time.Minute
+
+// ProcUsageProbe samples usage from /proc. The sampled pid is treated as the
+// root of a process tree: CPU, resident memory and written bytes are summed
+// over it and every live descendant, so work done by children of a shell is
+// accounted to the command. CPU percent is computed from the delta between
+// consecutive samples of the same root pid; the first sample reports zero CPU.
+type ProcUsageProbe struct {
+	root string
+
+	mu   sync.Mutex
+	last map[int]cpuSample
+}
+
+type cpuSample struct {
+	ticks uint64
+	at    time.Time
+}
+
+// NewProcUsageProbe builds a probe reading from /proc.
+func NewProcUsageProbe() *ProcUsageProbe {
+	return &ProcUsageProbe{root: "/proc", last: map[int]cpuSample{}}
+}
+
+// DefaultUsageProbe returns the native probe for the current platform.
+func DefaultUsageProbe() UsageProbe {
+	return NewProcUsageProbe()
+}
+
+// Sample implements UsageProbe.
+func (p *ProcUsageProbe) Sample(pid int) (ResourceUsage, error) {
+	if p == nil {
+		return ResourceUsage{}, nil
+	}
+	rootStat, err := readProcStat(filepath.Join(p.root, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return ResourceUsage{}, err
+	}
+
+	ticks := rootStat.ticks
+	var usage ResourceUsage
+	for _, member := range p.processTree(pid) {
+		dir := filepath.Join(p.root, strconv.Itoa(member))
+		if member != pid {
+			// Descendants may exit between the scan and this read; their CPU
+			// then shows up in the parent's cutime/cstime instead.
+			stat, err := readProcStat(filepath.Join(dir, "stat"))
+			if err != nil {
+				continue
+			}
+			ticks += stat.ticks
+		}
+		if rss, err := readProcField(filepath.Join(dir, "status"), "VmRSS:"); err == nil {
+			usage.MemoryBytes += rss * 1024
+		}
+		// io is only readable by the process owner; treat failures as unknown.
+		if written, err := readProcField(filepath.Join(dir, "io"), "write_bytes:"); err == nil {
+			usage.DiskBytes += written
+		}
+	}
+	usage.CPUPercent = p.cpuPercent(pid, ticks, time.Now())
+	return usage, nil
+}
+
+// processTree returns pid followed by all of its live descendants.
+func (p *ProcUsageProbe) processTree(pid int) []int {
+	tree := []int{pid}
+	entries, err := os.ReadDir(p.root)
+	if err != nil {
+		return tree
+	}
+	children := map[int][]int{}
+	for _, entry := range entries {
+		child, err := strconv.Atoi(entry.Name())
+		if err != nil || child == pid {
+			continue
+		}
+		stat, err := readProcStat(filepath.Join(p.root, entry.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		children[stat.ppid] = append(children[stat.ppid], child)
+	}
+	for i := 0; i < len(tree); i++ {
+		tree = append(tree, children[tree[i]]...)
+	}
+	return tree
+}
+
+func (p *ProcUsageProbe) cpuPercent(pid int, ticks uint64, now time.Time) float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	prev, ok := p.last[pid]
+	for other, sample := range p.last {
+		if now.Sub(sample.at) > staleSampleAge {
+			delete(p.last, other)
+		}
+	}
+	p.last[pid] = cpuSample{ticks: ticks, at: now}
+	if !ok || ticks < prev.ticks {
+		return 0
+	}
+	elapsed := now.Sub(prev.at).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(ticks-prev.ticks) / clockTicks / elapsed * 100
+}
+
+type procStat struct {
+	ppid int
+	// ticks is utime+stime plus cutime+cstime of reaped children.
+	ticks uint64
+}
+
+func readProcStat(path string) (procStat, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return procStat{}, err
+	}
+	// The comm field may contain spaces; fields after the closing paren are
+	// fixed. ppid is field 4 overall and utime, stime, cutime and cstime are
+	// fields 14 through 17.
+	end := bytes.LastIndexByte(data, ')')
+	if end < 0 {
+		return procStat{}, fmt.Errorf("sandbox: malformed %s", path)
+	}
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 15 {
+		return procStat{}, fmt.Errorf("sandbox: malformed %s", path)
+	}
+	ppid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return procStat{}, err
+	}
+	stat := procStat{ppid: ppid}
+	for _, field := range fields[11:15] {
+		n, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return procStat{}, err
+		}
+		stat.ticks += n
+	}
+	return stat, nil
+}
+
+func readProcField(path, key string) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, key) {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, key))
+		if len(fields) == 0 {
+			break
+		}
+		return strconv.ParseUint(fields[0], 10, 64)
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("sandbox: %s missing from %s", strings.TrimSuffix(key, ":"), path)
+}
@@ -0,0 +1,51 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestProcUsageProbeSumsProcessTree(t *testing.T) {
+	root := t.TempDir()
+	writeProc := func(pid, ppid int, rssKB, written uint64) {
+		dir := filepath.Join(root, strconv.Itoa(pid))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		stat := fmt.Sprintf("%d (bash -c) S %d 0 0 0 0 0 0 0 0 0 10 5 0 0 20 0\n", pid, ppid)
+		files := map[string]string{
+			"stat":   stat,
+			"status": fmt.Sprintf("Name:\tbash\nVmRSS:\t%d kB\n", rssKB),
+			"io":     fmt.Sprintf("rchar: 0\nwrite_bytes: %d\n", written),
+		}
+		for name, body := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	writeProc(100, 1, 1000, 10)   // sampled shell
+	writeProc(101, 100, 2000, 20) // child
+	writeProc(102, 101, 3000, 30) // grandchild
+	writeProc(200, 1, 9000, 90)   // unrelated process
+
+	probe := &ProcUsageProbe{root: root, last: map[int]cpuSample{}}
+	usage, err := probe.Sample(100)
+	if err != nil {
+		t.Fatalf("sample: %v", err)
+	}
+	if usage.MemoryBytes != 6000*1024 {
+		t.Fatalf("expected tree RSS 6000 kB, got %d bytes", usage.MemoryBytes)
+	}
+	if usage.DiskBytes != 60 {
+		t.Fatalf("expected tree write_bytes 60, got %d", usage.DiskBytes)
+	}
+	if got := probe.last[100].ticks; got != 45 {
+		t.Fatalf("expected tree cpu ticks 45, got %d", got)
+	}
+}
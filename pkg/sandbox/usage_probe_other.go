@@ -0,0 +1,8 @@
+//go:build !linux
+
+package sandbox
+
+// DefaultUsageProbe returns the native probe for the current platform.
+func DefaultUsageProbe() UsageProbe {
+	return NoopUsageProbe{}
+}
@@ -0,0 +1,31 @@
+package sandbox
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestNoopUsageProbeReportsZero(t *testing.T) {
+	usage, err := NoopUsageProbe{}.Sample(os.Getpid())
+	if err != nil || usage != (ResourceUsage{}) {
+		t.Fatalf("expected zero usage, got %+v err=%v", usage, err)
+	}
+}
+
+func TestDefaultUsageProbeSamplesCurrentProcess(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("proc probe only available on linux")
+	}
+	probe := DefaultUsageProbe()
+	usage, err := probe.Sample(os.Getpid())
+	if err != nil {
+		t.Fatalf("sample: %v", err)
+	}
+	if usage.MemoryBytes == 0 {
+		t.Fatalf("expected resident memory to be reported, got %+v", usage)
+	}
+	if _, err := probe.Sample(-1); err == nil {
+		t.Fatal("expected error for missing pid")
+	}
+}
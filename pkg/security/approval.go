@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -45,6 +46,8 @@ type ApprovalQueue struct {
 	records   map[string]*ApprovalRecord
 	whitelist map[string]time.Time
 	clock     func() time.Time
+
+	whitelistStore WhitelistStore // optional; see NewApprovalQueueWithWhitelist
 }
 
 // NewApprovalQueue restores queue state from disk or creates a fresh one.
@@ -171,8 +174,18 @@ func (q *ApprovalQueue) Deny(id, approver, reason string) (*ApprovalRecord, erro
 	return cloneRecord(rec), nil
 }
 
-// ListPending returns outstanding approvals for review.
+// ListPending returns outstanding approvals for review, oldest first. Records
+// requested at the same instant are ordered by ID so the result is stable.
 func (q *ApprovalQueue) ListPending() []*ApprovalRecord {
+	return q.listPending(false)
+}
+
+// ListPendingNewestFirst is ListPending in reverse order.
+func (q *ApprovalQueue) ListPendingNewestFirst() []*ApprovalRecord {
+	return q.listPending(true)
+}
+
+func (q *ApprovalQueue) listPending(newestFirst bool) []*ApprovalRecord {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
@@ -182,6 +195,16 @@ func (q *ApprovalQueue) ListPending() []*ApprovalRecord {
 			pending = append(pending, cloneRecord(rec))
 		}
 	}
+	sort.Slice(pending, func(i, j int) bool {
+		a, b := pending[i], pending[j]
+		if newestFirst {
+			a, b = b, a
+		}
+		if !a.RequestedAt.Equal(b.RequestedAt) {
+			return a.RequestedAt.Before(b.RequestedAt)
+		}
+		return a.ID < b.ID
+	})
 	return pending
 }
 
@@ -279,6 +302,11 @@ func (q *ApprovalQueue) load() error {
 }
 
 func (q *ApprovalQueue) persistLocked() error {
+	if q.whitelistStore != nil {
+		if err := q.whitelistStore.Save(q.liveWhitelistLocked()); err != nil {
+			return err
+		}
+	}
 	if q.storePath == "" {
 		return nil
 	}
@@ -289,8 +317,11 @@ func (q *ApprovalQueue) persistLocked() error {
 	for _, rec := range q.records {
 		snapshot.Records = append(snapshot.Records, rec)
 	}
-	for session, expiry := range q.whitelist {
-		snapshot.Whitelist[session] = expiry
+	// With a dedicated store the record log no longer carries the whitelist.
+	if q.whitelistStore == nil {
+		for session, expiry := range q.whitelist {
+			snapshot.Whitelist[session] = expiry
+		}
 	}
 
 	data, err := json.MarshalIndent(snapshot, "", "  ")
@@ -3,6 +3,7 @@ package security
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -218,6 +219,46 @@ func TestApprovalQueueListPendingAndClone(t *testing.T) {
 	}
 }
 
+func TestApprovalQueueListPendingOrdering(t *testing.T) {
+	q, clock := newTestQueue(t)
+	var ids []string
+	for i := 0; i < 4; i++ {
+		rec, err := q.Request(fmt.Sprintf("s%d", i), "cmd", nil)
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		ids = append(ids, rec.ID)
+		clock.Advance(time.Second)
+	}
+	// Two records sharing a timestamp fall back to ID order.
+	tieA, _ := q.Request("tie-a", "cmd", nil)
+	tieB, _ := q.Request("tie-b", "cmd", nil)
+	if tieA.ID < tieB.ID {
+		ids = append(ids, tieA.ID, tieB.ID)
+	} else {
+		ids = append(ids, tieB.ID, tieA.ID)
+	}
+
+	for round := 0; round < 5; round++ {
+		pending := q.ListPending()
+		if len(pending) != len(ids) {
+			t.Fatalf("expected %d pending, got %d", len(ids), len(pending))
+		}
+		for i, rec := range pending {
+			if rec.ID != ids[i] {
+				t.Fatalf("round %d: position %d = %s, want %s", round, i, rec.ID, ids[i])
+			}
+		}
+	}
+
+	newest := q.ListPendingNewestFirst()
+	for i, rec := range newest {
+		if want := ids[len(ids)-1-i]; rec.ID != want {
+			t.Fatalf("newest-first position %d = %s, want %s", i, rec.ID, want)
+		}
+	}
+}
+
 func TestApprovalQueueWhitelistExpiry(t *testing.T) {
 	q, clock := newTestQueue(t)
 	if q.IsWhitelisted("sess") {
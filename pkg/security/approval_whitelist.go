@@ -0,0 +1,123 @@
+package security
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WhitelistStore durably holds session whitelist expiries apart from the
+// approval record log, so pruning old records never revokes a whitelist.
+type WhitelistStore interface {
+	// Load returns the stored expiries keyed by session. A nil map means the
+	// store holds no data yet.
+	Load() (map[string]time.Time, error)
+	// Save replaces the stored expiries.
+	Save(entries map[string]time.Time) error
+}
+
+// FileWhitelistStore is a WhitelistStore backed by a JSON file written
+// atomically.
+type FileWhitelistStore struct {
+	Path string
+}
+
+// NewFileWhitelistStore returns a store persisting to path.
+func NewFileWhitelistStore(path string) *FileWhitelistStore {
+	return &FileWhitelistStore{Path: path}
+}
+
+// Load implements WhitelistStore. A missing file yields a nil map.
+func (s *FileWhitelistStore) Load() (map[string]time.Time, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("security: load whitelist: %w", err)
+	}
+	entries := map[string]time.Time{}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("security: parse whitelist: %w", err)
+	}
+	return entries, nil
+}
+
+// Save implements WhitelistStore.
+func (s *FileWhitelistStore) Save(entries map[string]time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o755); err != nil {
+		return fmt.Errorf("security: create whitelist dir: %w", err)
+	}
+	if entries == nil {
+		entries = map[string]time.Time{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("security: encode whitelist: %w", err)
+	}
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("security: write whitelist: %w", err)
+	}
+	if err := os.Rename(tmp, s.Path); err != nil {
+		return fmt.Errorf("security: atomically replace whitelist: %w", err)
+	}
+	return nil
+}
+
+// NewApprovalQueueWithWhitelist is NewApprovalQueue with whitelist expiries
+// kept in a dedicated store. The whitelist is loaded from the store when it
+// holds data and otherwise migrated from the record log. Expired entries are
+// dropped whenever the store is written; expiry is still checked on every
+// lookup.
+func NewApprovalQueueWithWhitelist(storePath string, whitelist WhitelistStore) (*ApprovalQueue, error) {
+	q, err := NewApprovalQueue(storePath)
+	if err != nil {
+		return nil, err
+	}
+	if whitelist == nil {
+		return q, nil
+	}
+	entries, err := whitelist.Load()
+	if err != nil {
+		return nil, err
+	}
+	q.whitelistStore = whitelist
+	if entries != nil {
+		q.whitelist = entries
+	}
+	return q, nil
+}
+
+// PruneResolved drops approved and denied records requested before cutoff
+// and returns how many were removed. Pending records are always kept.
+// Whitelists are unaffected.
+func (q *ApprovalQueue) PruneResolved(cutoff time.Time) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	removed := 0
+	for id, rec := range q.records {
+		if rec.State != ApprovalPending && rec.RequestedAt.Before(cutoff) {
+			delete(q.records, id)
+			removed++
+		}
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, q.persistLocked()
+}
+
+// liveWhitelistLocked returns the unexpired whitelist entries.
+func (q *ApprovalQueue) liveWhitelistLocked() map[string]time.Time {
+	now := q.clock()
+	live := make(map[string]time.Time, len(q.whitelist))
+	for session, expiry := range q.whitelist {
+		if expiry.After(now) {
+			live[session] = expiry
+		}
+	}
+	return live
+}
@@ -0,0 +1,87 @@
+package security
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestApprovalQueueWhitelistStoreSurvivesPrune(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "approvals.json")
+	store := NewFileWhitelistStore(filepath.Join(dir, "whitelist.json"))
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	open := func() *ApprovalQueue {
+		t.Helper()
+		q, err := NewApprovalQueueWithWhitelist(logPath, store)
+		if err != nil {
+			t.Fatalf("queue: %v", err)
+		}
+		q.clock = clock.Now
+		return q
+	}
+
+	q := open()
+	rec, err := q.Request("sess", "rm -rf build", nil)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if _, err := q.Approve(rec.ID, "ops", time.Hour); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if n, err := q.PruneResolved(clock.Now().Add(time.Second)); err != nil || n != 1 {
+		t.Fatalf("prune: n=%d err=%v", n, err)
+	}
+
+	q = open()
+	if len(q.records) != 0 {
+		t.Fatalf("expected pruned records to stay gone, got %d", len(q.records))
+	}
+	if !q.IsWhitelisted("sess") {
+		t.Fatal("expected whitelist to survive record pruning")
+	}
+	if plain, err := NewApprovalQueue(logPath); err != nil || len(plain.whitelist) != 0 {
+		t.Fatalf("record log must not carry the whitelist: %v %v", plain.whitelist, err)
+	}
+
+	clock.Advance(2 * time.Hour)
+	if q.IsWhitelisted("sess") {
+		t.Fatal("expected whitelist to expire")
+	}
+	entries, err := store.Load()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected expired entry dropped from store, got %v %v", entries, err)
+	}
+}
+
+func TestApprovalQueueWhitelistStoreMigratesFromLog(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "approvals.json")
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+
+	legacy, err := NewApprovalQueue(logPath)
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	legacy.clock = clock.Now
+	rec, _ := legacy.Request("sess", "make", nil)
+	if _, err := legacy.Approve(rec.ID, "ops", time.Hour); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+
+	store := NewFileWhitelistStore(filepath.Join(dir, "whitelist.json"))
+	q, err := NewApprovalQueueWithWhitelist(logPath, store)
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	q.clock = clock.Now
+	if !q.IsWhitelisted("sess") {
+		t.Fatal("expected whitelist replayed from the record log")
+	}
+	if _, err := q.Request("other", "ls", nil); err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if entries, err := store.Load(); err != nil || !entries["sess"].Equal(clock.Now().Add(time.Hour)) {
+		t.Fatalf("expected migrated entry in store, got %v %v", entries, err)
+	}
+}
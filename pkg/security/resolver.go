@@ -67,6 +67,52 @@ func (r *PathResolver) Resolve(path string) (string, error) {
 	return current, nil
 }
 
+// RealPath returns path with every symlink in its existing portion resolved
+// via filepath.EvalSymlinks; components that do not exist yet are appended
+// unchanged. Validators use it as a second check after Resolve, because Lstat
+// does not report every kind of link (e.g. Windows junctions) as a symlink.
+func (r *PathResolver) RealPath(path string) (string, error) {
+	abs, err := filepath.Abs(strings.TrimSpace(path))
+	if err != nil {
+		return "", fmt.Errorf("security: abs path failed: %w", err)
+	}
+	existing := filepath.Clean(abs)
+	var tail []string
+	for {
+		real, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			return filepath.Join(append([]string{real}, tail...)...), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("security: eval symlinks failed for %s: %w", existing, err)
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return filepath.Clean(abs), nil
+		}
+		tail = append([]string{filepath.Base(existing)}, tail...)
+		existing = parent
+	}
+}
+
+// WithinRealRoots reports whether the real location of path lies inside one
+// of roots, comparing against both each root and its own real location.
+func (r *PathResolver) WithinRealRoots(path string, roots []string) (string, bool, error) {
+	real, err := r.RealPath(path)
+	if err != nil {
+		return "", false, err
+	}
+	for _, root := range roots {
+		if withinSandbox(real, root) {
+			return real, true, nil
+		}
+		if realRoot, err := r.RealPath(root); err == nil && withinSandbox(real, realRoot) {
+			return real, true, nil
+		}
+	}
+	return real, false, nil
+}
+
 func ensureNoSymlink(path string) error {
 	info, err := os.Lstat(path)
 	if err != nil {
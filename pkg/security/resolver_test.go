@@ -174,3 +174,45 @@ func TestResolveRootPath(t *testing.T) {
 		t.Fatalf("expected root to resolve to %q, got %q", string(filepath.Separator), root)
 	}
 }
+
+func TestPathResolverRealPath(t *testing.T) {
+	root := tempDirClean(t)
+	outside := tempDirClean(t)
+	link := filepath.Join(root, "link")
+	mustSymlink(t, outside, link)
+
+	r := NewPathResolver()
+	got, err := r.RealPath(filepath.Join(link, "missing", "file.txt"))
+	if err != nil {
+		t.Fatalf("real path: %v", err)
+	}
+	if want := filepath.Join(outside, "missing", "file.txt"); got != want {
+		t.Fatalf("real path = %q, want %q", got, want)
+	}
+
+	if _, ok, err := r.WithinRealRoots(filepath.Join(link, "x"), []string{root}); err != nil || ok {
+		t.Fatalf("expected escape through link, ok=%v err=%v", ok, err)
+	}
+	// A root that is itself a link still admits its own contents.
+	if _, ok, err := r.WithinRealRoots(filepath.Join(outside, "x"), []string{link}); err != nil || !ok {
+		t.Fatalf("expected real root match, ok=%v err=%v", ok, err)
+	}
+}
+
+func TestSandboxValidatePathRejectsSymlinkEscape(t *testing.T) {
+	root := tempDirClean(t)
+	mustSymlink(t, "/etc", filepath.Join(root, "etc-link"))
+
+	sb := NewSandbox(root)
+	for _, path := range []string{
+		filepath.Join(root, "etc-link"),
+		filepath.Join(root, "etc-link", "passwd"),
+	} {
+		if err := sb.ValidatePath(path); err == nil {
+			t.Fatalf("expected %s to be rejected", path)
+		}
+	}
+	if err := sb.ValidatePath(filepath.Join(root, "plain.txt")); err != nil {
+		t.Fatalf("plain path rejected: %v", err)
+	}
+}
@@ -111,6 +111,13 @@ func (s *Sandbox) ValidatePath(path string) error {
 
 	for _, allowed := range allowCopy {
 		if withinSandbox(abs, allowed) {
+			real, ok, err := s.resolver.WithinRealRoots(abs, allowCopy)
+			if err != nil {
+				return fmt.Errorf("security: resolve failed: %w", err)
+			}
+			if !ok {
+				return fmt.Errorf("%w: %s resolves to %s", ErrPathNotAllowed, abs, real)
+			}
 			return nil
 		}
 	}
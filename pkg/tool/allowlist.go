@@ -0,0 +1,67 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrToolNotAllowed is returned by Executor.Execute for a call outside the
+// allowlist carried by its context.
+var ErrToolNotAllowed = errors.New("tool: not in allowed tools")
+
+type allowedToolsKey struct{}
+
+// WithAllowedTools limits the tools an Executor runs for ctx to names, matched
+// case-insensitively. When ctx already carries an allowlist the result is the
+// intersection, so nested scopes can only narrow access. Empty names leave ctx
+// unchanged.
+func WithAllowedTools(ctx context.Context, names ...string) context.Context {
+	set := map[string]struct{}{}
+	for _, name := range names {
+		if key := strings.ToLower(strings.TrimSpace(name)); key != "" {
+			set[key] = struct{}{}
+		}
+	}
+	if len(set) == 0 {
+		return ctx
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if outer, ok := ctx.Value(allowedToolsKey{}).(map[string]struct{}); ok {
+		for name := range set {
+			if _, ok := outer[name]; !ok {
+				delete(set, name)
+			}
+		}
+	}
+	return context.WithValue(ctx, allowedToolsKey{}, set)
+}
+
+// AllowedToolsFromContext returns the sorted allowlist carried by ctx, if any.
+func AllowedToolsFromContext(ctx context.Context) ([]string, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	set, ok := ctx.Value(allowedToolsKey{}).(map[string]struct{})
+	if !ok {
+		return nil, false
+	}
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, true
+}
+
+func checkAllowedTool(ctx context.Context, name string) error {
+	allowed, ok := AllowedToolsFromContext(ctx)
+	if !ok || slices.Contains(allowed, strings.ToLower(strings.TrimSpace(name))) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s (allowed: %s)", ErrToolNotAllowed, name, strings.Join(allowed, ", "))
+}
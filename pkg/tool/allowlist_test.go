@@ -0,0 +1,25 @@
+package tool
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestWithAllowedToolsNarrows(t *testing.T) {
+	ctx := context.Background()
+	if _, ok := AllowedToolsFromContext(WithAllowedTools(ctx)); ok {
+		t.Fatal("empty names must not install an allowlist")
+	}
+	outer := WithAllowedTools(ctx, "Bash", "read")
+	inner := WithAllowedTools(outer, "read", "write")
+	if got, _ := AllowedToolsFromContext(inner); !slices.Equal(got, []string{"read"}) {
+		t.Fatalf("expected intersection [read], got %v", got)
+	}
+	if err := checkAllowedTool(outer, "BASH"); err != nil {
+		t.Fatalf("expected case-insensitive match, got %v", err)
+	}
+	if err := checkAllowedTool(inner, "bash"); err == nil {
+		t.Fatal("expected inner scope to reject bash")
+	}
+}
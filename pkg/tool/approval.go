@@ -0,0 +1,72 @@
+package tool
+
+import (
+	"strings"
+
+	"github.com/cexll/agentsdk-go/pkg/security"
+)
+
+// ApprovalRequiredRule is the rule reported on decisions raised because a
+// tool requires approval by default rather than by a permission rule.
+const ApprovalRequiredRule = "approval-required"
+
+// ApprovalRequirer is implemented by tools that should be approved before
+// every call unless a permission rule explicitly allows or denies it.
+type ApprovalRequirer interface {
+	RequiresApproval() bool
+}
+
+// WithApprovalRequired returns a shallow copy that treats the named tools as
+// requiring approval by default, in addition to tools implementing
+// ApprovalRequirer. Names match case-insensitively.
+func (e *Executor) WithApprovalRequired(names ...string) *Executor {
+	if e == nil {
+		e = NewExecutor(nil, nil)
+	}
+	clone := *e
+	clone.approvalRequired = make(map[string]struct{}, len(e.approvalRequired)+len(names))
+	for name := range e.approvalRequired {
+		clone.approvalRequired[name] = struct{}{}
+	}
+	for _, name := range names {
+		if key := strings.ToLower(strings.TrimSpace(name)); key != "" {
+			clone.approvalRequired[key] = struct{}{}
+		}
+	}
+	return &clone
+}
+
+// applyApprovalDefault turns a decision no rule matched into PermissionAsk
+// when the tool requires approval. Explicit allow and deny rules win.
+func (e *Executor) applyApprovalDefault(call Call, decision security.PermissionDecision) security.PermissionDecision {
+	switch {
+	case decision.Action == security.PermissionUnknown:
+	case decision.Action == security.PermissionAllow && decision.Rule == "":
+	default:
+		return decision
+	}
+	if !e.requiresApproval(call.Name) {
+		return decision
+	}
+	decision.Action = security.PermissionAsk
+	decision.Rule = ApprovalRequiredRule
+	if decision.Tool == "" {
+		decision.Tool = call.Name
+	}
+	if decision.Target == "" {
+		decision.Target = call.Name
+	}
+	return decision
+}
+
+func (e *Executor) requiresApproval(name string) bool {
+	if _, ok := e.approvalRequired[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return true
+	}
+	impl, err := e.registry.Get(name)
+	if err != nil {
+		return false
+	}
+	req, ok := impl.(ApprovalRequirer)
+	return ok && req.RequiresApproval()
+}
@@ -0,0 +1,57 @@
+package tool
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cexll/agentsdk-go/pkg/security"
+)
+
+type gatedTool struct{ stubTool }
+
+func (g *gatedTool) RequiresApproval() bool { return true }
+
+func TestExecutorApprovalRequiredDefaults(t *testing.T) {
+	reg := NewRegistry()
+	free := &stubTool{name: "grep"}
+	named := &stubTool{name: "bash"}
+	gated := &gatedTool{stubTool{name: "deploy"}}
+	for _, impl := range []Tool{free, named, gated} {
+		if err := reg.Register(impl); err != nil {
+			t.Fatalf("register %s: %v", impl.Name(), err)
+		}
+	}
+
+	var asked []security.PermissionDecision
+	exec := NewExecutor(reg, nil).WithApprovalRequired("BASH").WithPermissionResolver(
+		func(_ context.Context, call Call, decision security.PermissionDecision) (security.PermissionDecision, error) {
+			asked = append(asked, decision)
+			return decision, nil
+		})
+
+	if _, err := exec.Execute(context.Background(), Call{Name: "grep"}); err != nil || free.called != 1 {
+		t.Fatalf("free tool should run immediately: err=%v calls=%d", err, free.called)
+	}
+	for _, name := range []string{"bash", "deploy"} {
+		_, err := exec.Execute(context.Background(), Call{Name: name})
+		if err == nil || !strings.Contains(err.Error(), "requires approval") {
+			t.Fatalf("%s: expected approval error, got %v", name, err)
+		}
+	}
+	if named.called != 0 || gated.called != 0 {
+		t.Fatalf("approval-required tools must not run, bash=%d deploy=%d", named.called, gated.called)
+	}
+	if len(asked) != 2 || asked[0].Rule != ApprovalRequiredRule || asked[0].Target != "bash" || asked[1].Tool != "deploy" {
+		t.Fatalf("unexpected resolver decisions %+v", asked)
+	}
+
+	// An explicit allow rule overrides the default.
+	explicit := exec.applyApprovalDefault(Call{Name: "bash"}, security.PermissionDecision{Action: security.PermissionAllow, Rule: "Bash(ls:*)"})
+	if explicit.Action != security.PermissionAllow {
+		t.Fatalf("explicit allow rule should win, got %+v", explicit)
+	}
+	if NewExecutor(reg, nil).requiresApproval("bash") {
+		t.Fatal("WithApprovalRequired must not mutate the original executor")
+	}
+}
@@ -85,6 +85,13 @@ func (m *AsyncTaskManager) Start(id, command string) error {
 }
 
 func (m *AsyncTaskManager) startWithContext(ctx context.Context, id, command, workdir string, timeout time.Duration) error {
+	return m.startWithEnv(ctx, id, command, workdir, timeout, nil, nil, nil)
+}
+
+// startWithEnv starts the task with env as its environment; nil inherits the
+// host environment. A non-nil redact masks the task's output line by line,
+// and rlimits are applied to the task's shell as in BashTool.SetProcessLimits.
+func (m *AsyncTaskManager) startWithEnv(ctx context.Context, id, command, workdir string, timeout time.Duration, env []string, redact Redactor, rlimits map[string]uint64) error {
 	if m == nil {
 		return errors.New("async task manager is nil")
 	}
@@ -143,13 +150,17 @@ func (m *AsyncTaskManager) startWithContext(ctx context.Context, id, command, wo
 	task.cancel = cancel
 	task.mu.Unlock()
 
-	cmd := exec.CommandContext(execCtx, "bash", "-c", trimmedCmd)
-	cmd.Env = os.Environ()
+	cmd := exec.CommandContext(execCtx, "bash", bashArgs(trimmedCmd, rlimits)...)
+	if env == nil {
+		env = os.Environ()
+	}
+	cmd.Env = env
 	if strings.TrimSpace(workdir) != "" {
 		cmd.Dir = workdir
 	}
-	cmd.Stdout = task.output
-	cmd.Stderr = task.output
+	output := newRedactWriter(task.output, redact, 0)
+	cmd.Stdout = output
+	cmd.Stderr = output
 
 	if err := cmd.Start(); err != nil {
 		cancel()
@@ -166,6 +177,7 @@ func (m *AsyncTaskManager) startWithContext(ctx context.Context, id, command, wo
 
 	go func() {
 		err := cmd.Wait()
+		_ = output.Flush()
 		_ = task.output.Close()
 		task.mu.Lock()
 		task.Error = err
@@ -13,10 +13,13 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/cexll/agentsdk-go/pkg/contextkeys"
 	"github.com/cexll/agentsdk-go/pkg/middleware"
 	"github.com/cexll/agentsdk-go/pkg/model"
+	"github.com/cexll/agentsdk-go/pkg/sandbox"
 	"github.com/cexll/agentsdk-go/pkg/security"
 	"github.com/cexll/agentsdk-go/pkg/tool"
 )
@@ -203,6 +206,11 @@ var bashSchema = &tool.JSONSchema{
 			"type":        "string",
 			"description": "Optional async task id to use when async=true.",
 		},
+		"env": map[string]interface{}{
+			"type":                 "object",
+			"description":          "Optional environment variables for this command, merged over the filtered host environment. Loader and shell start-up variables (LD_PRELOAD, BASH_ENV, ...) cannot be set.",
+			"additionalProperties": map[string]interface{}{"type": "string"},
+		},
 	},
 	Required: []string{"command"},
 }
@@ -214,6 +222,21 @@ type BashTool struct {
 	timeout time.Duration
 
 	outputThresholdBytes int
+	streamBufferBytes    int
+
+	usagePolicy   sandbox.ResourcePolicy
+	usageProbe    sandbox.UsageProbe
+	usageInterval time.Duration
+
+	// isolatedEnv starts commands from an empty environment instead of the
+	// filtered host environment.
+	isolatedEnv bool
+	// redactor masks secrets in streamed output; nil leaves it untouched.
+	redactor Redactor
+	// procLimits holds the MaxProcesses/MaxOpenFiles rlimits for commands.
+	procLimits sandbox.ResourceLimits
+	// nprocWarned is set once a too-low MaxProcesses has been reported.
+	nprocWarned atomic.Bool
 }
 
 // NewBashTool builds a BashTool rooted at the current directory.
@@ -261,6 +284,36 @@ func (b *BashTool) effectiveOutputThresholdBytes() int {
 	return b.outputThresholdBytes
 }
 
+// SetStreamBufferBytes sets the longest line StreamExecute emits in one
+// piece. Longer lines are emitted as chunks of at most n bytes instead of
+// failing the read. Non-positive values restore the 1MB default.
+func (b *BashTool) SetStreamBufferBytes(n int) {
+	if b == nil {
+		return
+	}
+	b.streamBufferBytes = n
+}
+
+// SetResourceMonitor enables live resource sampling for foreground commands. The
+// probe is polled every interval while the command runs and the command is
+// terminated as soon as the policy rejects a sample. A nil probe selects the
+// platform default; a non-positive interval uses defaultUsageInterval. A nil
+// policy disables monitoring.
+func (b *BashTool) SetResourceMonitor(policy sandbox.ResourcePolicy, probe sandbox.UsageProbe, interval time.Duration) {
+	if b == nil {
+		return
+	}
+	if probe == nil && policy != nil {
+		probe = sandbox.DefaultUsageProbe()
+	}
+	if interval <= 0 {
+		interval = defaultUsageInterval
+	}
+	b.usagePolicy = policy
+	b.usageProbe = probe
+	b.usageInterval = interval
+}
+
 // SetCommandLimits overrides the maximum command length (bytes) and argument count
 // enforced by the security validator. Use this for code-generation scenarios where
 // agents write files via bash heredocs or long cat commands.
@@ -270,6 +323,47 @@ func (b *BashTool) SetCommandLimits(maxBytes, maxArgs int) {
 	}
 }
 
+// SetProcessLimits applies limits.MaxProcesses and limits.MaxOpenFiles as
+// rlimits to every command, capping fork bombs and descriptor leaks. Values
+// above the current hard limit are clamped to it. It is a no-op on platforms
+// without rlimit support; the applied values are reported in the result's
+// "rlimits" metadata.
+//
+// RLIMIT_NPROC is checked against every process and thread of the real user
+// on the host, not only the command's own tree, so a MaxProcesses at or below the
+// user's current process count would make each fork in the command fail
+// with EAGAIN. Such a value is skipped with a logged warning rather than
+// applied; size it as headroom above the user's normal process count.
+func (b *BashTool) SetProcessLimits(limits sandbox.ResourceLimits) {
+	if b != nil {
+		b.procLimits = limits
+	}
+}
+
+// SetOutputRedactor masks secrets in command output, from Execute,
+// StreamExecute and async tasks alike, before it reaches the emit callback,
+// the result or a spool file. Lines longer than the stream buffer are
+// redacted per chunk, so a secret straddling a chunk boundary can slip
+// through. Pass RedactSecrets for the built-in patterns, or nil to disable
+// redaction.
+func (b *BashTool) SetOutputRedactor(r Redactor) {
+	if b != nil {
+		b.redactor = r
+	}
+}
+
+// SetIsolatedEnv makes commands start from an empty environment, so only the
+// variables passed through the env param are visible. By default commands
+// inherit the host environment minus loader and shell start-up variables
+// (LD_PRELOAD, LD_LIBRARY_PATH, LD_AUDIT, DYLD_*, BASH_ENV, ENV, SHELLOPTS,
+// BASHOPTS, PROMPT_COMMAND, IFS, BASH_FUNC_*) and credentials named *_API_KEY,
+// *_TOKEN, *_SECRET, *_SECRET_KEY, *_SECRET_ACCESS_KEY or *_PASSWORD.
+func (b *BashTool) SetIsolatedEnv(isolated bool) {
+	if b != nil {
+		b.isolatedEnv = isolated
+	}
+}
+
 // AllowShellMetachars enables shell pipes and metacharacters (CLI mode).
 func (b *BashTool) AllowShellMetachars(allow bool) {
 	if b != nil && b.sandbox != nil {
@@ -311,6 +405,11 @@ func (b *BashTool) Execute(ctx context.Context, params map[string]interface{}) (
 	if err != nil {
 		return nil, err
 	}
+	env, err := parseBashEnv(params)
+	if err != nil {
+		return nil, err
+	}
+	rlimits := b.processRlimits()
 
 	if async {
 		id, err := optionalAsyncTaskID(params)
@@ -320,13 +419,16 @@ func (b *BashTool) Execute(ctx context.Context, params map[string]interface{}) (
 		if id == "" {
 			id = generateAsyncTaskID()
 		}
-		if err := DefaultAsyncTaskManager().startWithContext(ctx, id, command, workdir, timeout); err != nil {
+		if err := DefaultAsyncTaskManager().startWithEnv(ctx, id, command, workdir, timeout, b.commandEnv(env), b.redactor, rlimits); err != nil {
 			return nil, err
 		}
 		payload := map[string]interface{}{
 			"task_id": id,
 			"status":  "running",
 		}
+		if len(rlimits) > 0 {
+			payload["rlimits"] = rlimits
+		}
 		out, err := json.Marshal(payload)
 		if err != nil {
 			return nil, fmt.Errorf("marshal async result: %w", err)
@@ -334,24 +436,35 @@ func (b *BashTool) Execute(ctx context.Context, params map[string]interface{}) (
 		return &tool.ToolResult{Success: true, Output: string(out), Data: payload}, nil
 	}
 
-	execCtx := ctx
-	var cancel context.CancelFunc
+	execCtx, abort := context.WithCancel(ctx)
+	defer abort()
 	if timeout > 0 {
-		execCtx, cancel = context.WithTimeout(ctx, timeout)
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(execCtx, timeout)
 		defer cancel()
 	}
 
-	cmd := exec.CommandContext(execCtx, "bash", "-c", command)
-	cmd.Env = os.Environ()
+	cmd := exec.CommandContext(execCtx, "bash", bashArgs(command, rlimits)...)
+	cmd.Env = b.commandEnv(env)
 	cmd.Dir = workdir
 
 	spool := newBashOutputSpool(ctx, b.effectiveOutputThresholdBytes())
-	cmd.Stdout = spool.StdoutWriter()
-	cmd.Stderr = spool.StderrWriter()
+	stdout := newRedactWriter(spool.StdoutWriter(), b.redactor, b.streamBufferBytes)
+	stderr := newRedactWriter(spool.StderrWriter(), b.redactor, b.streamBufferBytes)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
 	start := time.Now()
-	runErr := cmd.Run()
+	var violation error
+	runErr := cmd.Start()
+	if runErr == nil {
+		stopMonitor := b.startUsageMonitor(execCtx, cmd.Process.Pid, abort)
+		runErr = cmd.Wait()
+		violation = stopMonitor()
+	}
 	duration := time.Since(start)
+	_ = stdout.Flush() //nolint:errcheck // best-effort spool
+	_ = stderr.Flush() //nolint:errcheck // best-effort spool
 
 	output, outputFile, spoolErr := spool.Finalize()
 
@@ -366,13 +479,22 @@ func (b *BashTool) Execute(ctx context.Context, params map[string]interface{}) (
 	if spoolErr != nil {
 		data["spool_error"] = spoolErr.Error()
 	}
+	if len(rlimits) > 0 {
+		data["rlimits"] = rlimits
+	}
+	if violation != nil {
+		data["resource_violation"] = violation.Error()
+	}
 
 	result := &tool.ToolResult{
-		Success: runErr == nil,
+		Success: runErr == nil && violation == nil,
 		Output:  output,
 		Data:    data,
 	}
 
+	if violation != nil {
+		return result, fmt.Errorf("command terminated: %w", violation)
+	}
 	if runErr != nil {
 		if errors.Is(execCtx.Err(), context.DeadlineExceeded) {
 			return result, fmt.Errorf("command timeout after %s", timeout)
@@ -804,6 +926,9 @@ func bashSessionID(ctx context.Context) string {
 				}
 			}
 		}
+		if session == "" {
+			session = contextkeys.SessionIDFrom(ctx)
+		}
 		if session == "" {
 			if value, ok := ctx.Value(middleware.TraceSessionIDContextKey).(string); ok {
 				session = value
@@ -0,0 +1,138 @@
+package toolbuiltin
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// bashUnsafeEnv lists variables that change how bash or the dynamic loader
+// start up. They are dropped from the base environment and rejected in the
+// env param, because they let a command run code the validator never saw.
+var bashUnsafeEnv = map[string]struct{}{
+	"BASH_ENV":        {},
+	"ENV":             {},
+	"SHELLOPTS":       {},
+	"BASHOPTS":        {},
+	"PROMPT_COMMAND":  {},
+	"IFS":             {},
+	"LD_PRELOAD":      {},
+	"LD_LIBRARY_PATH": {},
+	"LD_AUDIT":        {},
+}
+
+// bashUnsafeEnvPrefixes covers exported bash functions and the macOS loader.
+var bashUnsafeEnvPrefixes = []string{"BASH_FUNC_", "DYLD_"}
+
+// bashSecretEnvSuffixes marks host credentials such as ANTHROPIC_API_KEY or
+// GITHUB_TOKEN. They are dropped from the base environment but may still be
+// passed explicitly through the env param.
+var bashSecretEnvSuffixes = []string{"_API_KEY", "_TOKEN", "_SECRET", "_SECRET_KEY", "_SECRET_ACCESS_KEY", "_PASSWORD"}
+
+func bashEnvUnsafe(key string) bool {
+	upper := strings.ToUpper(key)
+	if _, ok := bashUnsafeEnv[upper]; ok {
+		return true
+	}
+	for _, prefix := range bashUnsafeEnvPrefixes {
+		if strings.HasPrefix(upper, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func bashEnvSecret(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, suffix := range bashSecretEnvSuffixes {
+		if strings.HasSuffix(upper, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// commandEnv builds the environment for one command: the filtered host
+// environment (or nothing when isolated) with extra applied on top.
+func (b *BashTool) commandEnv(extra map[string]string) []string {
+	var base []string
+	if !b.isolatedEnv {
+		base = os.Environ()
+	}
+	out := make([]string, 0, len(base)+len(extra))
+	for _, entry := range base {
+		key, _, ok := strings.Cut(entry, "=")
+		if !ok || key == "" || bashEnvUnsafe(key) || bashEnvSecret(key) {
+			continue
+		}
+		if _, overridden := extra[key]; overridden {
+			continue
+		}
+		out = append(out, entry)
+	}
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		out = append(out, key+"="+extra[key])
+	}
+	return out
+}
+
+func parseBashEnv(params map[string]interface{}) (map[string]string, error) {
+	if params == nil {
+		return nil, nil
+	}
+	raw, ok := params["env"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	var entries map[string]interface{}
+	switch v := raw.(type) {
+	case map[string]interface{}:
+		entries = v
+	case map[string]string:
+		entries = make(map[string]interface{}, len(v))
+		for key, value := range v {
+			entries[key] = value
+		}
+	default:
+		return nil, fmt.Errorf("env must be an object, got %T", raw)
+	}
+	env := make(map[string]string, len(entries))
+	for key, value := range entries {
+		if !validEnvName(key) {
+			return nil, fmt.Errorf("env: invalid variable name %q", key)
+		}
+		if bashEnvUnsafe(key) {
+			return nil, fmt.Errorf("env: %s cannot be set", key)
+		}
+		str, err := coerceString(value)
+		if err != nil {
+			return nil, fmt.Errorf("env %s must be string: %w", key, err)
+		}
+		if strings.ContainsRune(str, 0) {
+			return nil, fmt.Errorf("env %s contains a NUL byte", key)
+		}
+		env[key] = str
+	}
+	return env, nil
+}
+
+func validEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
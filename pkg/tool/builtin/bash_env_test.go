@@ -0,0 +1,108 @@
+package toolbuiltin
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cexll/agentsdk-go/pkg/security"
+)
+
+func envLines(output string) map[string]string {
+	vars := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok {
+			vars[key] = value
+		}
+	}
+	return vars
+}
+
+func TestBashToolEnvParam(t *testing.T) {
+	skipIfWindows(t)
+	t.Setenv("AGENTSDK_TEST_VISIBLE", "host")
+	t.Setenv("AGENTSDK_TEST_API_KEY", "leak")
+	t.Setenv("LD_PRELOAD", "/tmp/evil.so")
+
+	tool := NewBashToolWithSandbox("", security.NewDisabledSandbox())
+	res, err := tool.Execute(context.Background(), map[string]interface{}{
+		"command": "env",
+		"env":     map[string]interface{}{"TASK_STAGE": "build", "AGENTSDK_TEST_VISIBLE": "override"},
+	})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	vars := envLines(res.Output)
+	if vars["TASK_STAGE"] != "build" || vars["AGENTSDK_TEST_VISIBLE"] != "override" {
+		t.Fatalf("env param not applied: %v", vars)
+	}
+	if _, ok := vars["AGENTSDK_TEST_API_KEY"]; ok {
+		t.Fatal("credential leaked into command environment")
+	}
+	if _, ok := vars["LD_PRELOAD"]; ok {
+		t.Fatal("LD_PRELOAD leaked into command environment")
+	}
+	if vars["PATH"] == "" {
+		t.Fatal("PATH should be inherited from the host")
+	}
+
+	var streamed strings.Builder
+	if _, err := tool.StreamExecute(context.Background(), map[string]interface{}{
+		"command": "env",
+		"env":     map[string]string{"AGENTSDK_TEST_API_KEY": "explicit"},
+	}, func(chunk string, _ bool) { streamed.WriteString(chunk + "\n") }); err != nil {
+		t.Fatalf("stream execute: %v", err)
+	}
+	if vars := envLines(streamed.String()); vars["AGENTSDK_TEST_API_KEY"] != "explicit" {
+		t.Fatalf("explicit credential should be passed through, got %v", vars)
+	}
+}
+
+func TestBashToolIsolatedEnv(t *testing.T) {
+	skipIfWindows(t)
+	t.Setenv("AGENTSDK_TEST_VISIBLE", "host")
+
+	tool := NewBashToolWithSandbox("", security.NewDisabledSandbox())
+	tool.SetIsolatedEnv(true)
+	res, err := tool.Execute(context.Background(), map[string]interface{}{
+		"command": "/usr/bin/env",
+		"env":     map[string]interface{}{"ONLY": "this"},
+	})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	vars := envLines(res.Output)
+	if vars["ONLY"] != "this" {
+		t.Fatalf("env param not applied: %v", vars)
+	}
+	if _, ok := vars["AGENTSDK_TEST_VISIBLE"]; ok {
+		t.Fatalf("isolated env should not inherit host variables: %v", vars)
+	}
+}
+
+func TestParseBashEnvRejectsUnsafeVariables(t *testing.T) {
+	cases := []struct {
+		env  interface{}
+		want string
+	}{
+		{env: "A=B", want: "must be an object"},
+		{env: map[string]interface{}{"1BAD": "x"}, want: "invalid variable name"},
+		{env: map[string]interface{}{"A=B": "x"}, want: "invalid variable name"},
+		{env: map[string]interface{}{"BASH_ENV": "/tmp/rc"}, want: "cannot be set"},
+		{env: map[string]interface{}{"ld_preload": "x"}, want: "cannot be set"},
+		{env: map[string]interface{}{"BASH_FUNC_ls%%": "() { :; }"}, want: "invalid variable name"},
+		{env: map[string]interface{}{"DYLD_INSERT_LIBRARIES": "x"}, want: "cannot be set"},
+		{env: map[string]interface{}{"N": 3}, want: "must be string"},
+		{env: map[string]interface{}{"N": "a\x00b"}, want: "NUL"},
+	}
+	for _, tc := range cases {
+		_, err := parseBashEnv(map[string]interface{}{"env": tc.env})
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%v: expected %q error, got %v", tc.env, tc.want, err)
+		}
+	}
+	env, err := parseBashEnv(map[string]interface{}{"env": map[string]interface{}{"_OK1": "v"}})
+	if err != nil || env["_OK1"] != "v" {
+		t.Fatalf("unexpected result %v %v", env, err)
+	}
+}
@@ -0,0 +1,101 @@
+package toolbuiltin
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/cexll/agentsdk-go/pkg/middleware"
+)
+
+const redactedPlaceholder = "[REDACTED]"
+
+// Redactor masks secrets in one chunk of command output before StreamExecute
+// emits or stores it. stdout and stderr are redacted from separate goroutines,
+// so implementations must be safe for concurrent use.
+type Redactor func(chunk string) string
+
+// RedactSecrets is a Redactor for the credential shapes in
+// middleware.SecretPatterns: NAME=value pairs whose name mentions a key,
+// token, secret or password, bearer and Authorization headers, and sk-,
+// ghp_/gho_/ghs_, xox*- and AKIA-prefixed tokens. It is a best-effort filter,
+// not a guarantee.
+func RedactSecrets(chunk string) string {
+	return middleware.RedactSecrets(chunk, func(string) string { return redactedPlaceholder })
+}
+
+// RedactValues returns a Redactor that masks every occurrence of the given
+// literal values, e.g. secrets passed to the command through the env param.
+// Empty values are ignored.
+func RedactValues(values ...string) Redactor {
+	pairs := make([]string, 0, len(values)*2)
+	for _, v := range values {
+		if v != "" {
+			pairs = append(pairs, v, redactedPlaceholder)
+		}
+	}
+	if len(pairs) == 0 {
+		return func(chunk string) string { return chunk }
+	}
+	replacer := strings.NewReplacer(pairs...)
+	return replacer.Replace
+}
+
+// redactWriter applies a Redactor line by line to output written through
+// it, for command output that does not pass through consumeStream. Lines
+// longer than limit are redacted per chunk. Flush writes a trailing partial
+// line. A nil Redactor passes writes straight through.
+type redactWriter struct {
+	w      io.Writer
+	redact Redactor
+	limit  int
+	buf    []byte
+}
+
+func newRedactWriter(w io.Writer, redact Redactor, limit int) *redactWriter {
+	if limit <= 0 {
+		limit = defaultStreamBufferBytes
+	}
+	return &redactWriter{w: w, redact: redact, limit: limit}
+}
+
+func (r *redactWriter) Write(p []byte) (int, error) {
+	if r.redact == nil {
+		return r.w.Write(p)
+	}
+	r.buf = append(r.buf, p...)
+	start := 0
+	for start < len(r.buf) {
+		rest := r.buf[start:]
+		var chunk string
+		if idx := bytes.IndexByte(rest, '\n'); idx >= 0 {
+			chunk = r.redact(string(rest[:idx])) + "\n"
+			start += idx + 1
+		} else if len(rest) >= r.limit {
+			cut := lastRuneBoundary(rest[:r.limit])
+			if cut == 0 {
+				cut = r.limit
+			}
+			chunk = r.redact(string(rest[:cut]))
+			start += cut
+		} else {
+			break
+		}
+		if _, err := io.WriteString(r.w, chunk); err != nil {
+			return len(p), err
+		}
+	}
+	r.buf = append(r.buf[:0], r.buf[start:]...)
+	return len(p), nil
+}
+
+// Flush redacts and writes any buffered partial line.
+func (r *redactWriter) Flush() error {
+	if len(r.buf) == 0 {
+		return nil
+	}
+	chunk := r.redact(string(r.buf))
+	r.buf = r.buf[:0]
+	_, err := io.WriteString(r.w, chunk)
+	return err
+}
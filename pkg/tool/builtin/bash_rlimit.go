@@ -0,0 +1,59 @@
+package toolbuiltin
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// bashRlimitFlags maps the metadata names of applied rlimits to the bash
+// ulimit flag that sets them.
+var bashRlimitFlags = map[string]string{
+	"max_processes":  "-u",
+	"max_open_files": "-n",
+}
+
+// countUserProcesses reports how many processes the current real user runs
+// host-wide; ok is false when unknown or when RLIMIT_NPROC does not apply.
+var countUserProcesses = userProcessCount
+
+// processRlimits returns the configured process limits, clamped to the
+// host's hard limits, keyed by their metadata name. A max_processes limit
+// the user already reaches is dropped, as no command could fork under it.
+func (b *BashTool) processRlimits() map[string]uint64 {
+	if b == nil {
+		return nil
+	}
+	limits := effectiveRlimits(b.procLimits)
+	if want, ok := limits["max_processes"]; ok {
+		if count, known := countUserProcesses(); known && uint64(count) >= want {
+			delete(limits, "max_processes")
+			if b.nprocWarned.CompareAndSwap(false, true) {
+				log.Printf("bash: MaxProcesses %d not applied: user already runs %d processes", want, count)
+			}
+		}
+	}
+	if len(limits) == 0 {
+		return nil
+	}
+	return limits
+}
+
+// bashArgs returns the bash arguments that run command under limits. With
+// limits, a wrapper shell sets them on itself and then execs a fresh
+// `bash -c command`, so they are in place before any of the command runs,
+// the host keeps its own limits and the command string stays as given. The
+// wrapper exits with status 126 if a limit cannot be set.
+func bashArgs(command string, limits map[string]uint64) []string {
+	if len(limits) == 0 {
+		return []string{"-c", command}
+	}
+	var wrapper strings.Builder
+	for _, name := range []string{"max_processes", "max_open_files"} {
+		if value, ok := limits[name]; ok {
+			fmt.Fprintf(&wrapper, "ulimit -S -H %s %d || exit 126\n", bashRlimitFlags[name], value)
+		}
+	}
+	wrapper.WriteString(`exec "$BASH" -c "$1" bash`)
+	return []string{"-c", wrapper.String(), "bash", command}
+}
@@ -0,0 +1,86 @@
+//go:build linux
+
+package toolbuiltin
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/cexll/agentsdk-go/pkg/sandbox"
+)
+
+// effectiveRlimits clamps the requested limits to the current hard limits,
+// since an unprivileged process can only lower them.
+func effectiveRlimits(limits sandbox.ResourceLimits) map[string]uint64 {
+	out := map[string]uint64{}
+	clamp := func(name string, resource int, want uint64) {
+		if want == 0 {
+			return
+		}
+		var current unix.Rlimit
+		if err := unix.Getrlimit(resource, &current); err == nil && current.Max != unix.RLIM_INFINITY && want > current.Max {
+			want = current.Max
+		}
+		out[name] = want
+	}
+	clamp("max_processes", unix.RLIMIT_NPROC, limits.MaxProcesses)
+	clamp("max_open_files", unix.RLIMIT_NOFILE, limits.MaxOpenFiles)
+	return out
+}
+
+// userProcessCount counts the threads in /proc whose real UID is the
+// caller's: on Linux RLIMIT_NPROC is checked against that count. Root is
+// exempt from the limit, so ok is false for it.
+func userProcessCount() (int, bool) {
+	uid := os.Getuid()
+	if uid == 0 {
+		return 0, false
+	}
+	statuses, err := filepath.Glob("/proc/[0-9]*/status")
+	if err != nil || len(statuses) == 0 {
+		return 0, false
+	}
+	want := strconv.Itoa(uid)
+	count := 0
+	for _, status := range statuses {
+		if owner, threads := procStatus(status); owner == want {
+			count += threads
+		}
+	}
+	return count, true
+}
+
+// procStatus returns the real UID and thread count from a /proc status file.
+func procStatus(path string) (uid string, threads int) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		switch key {
+		case "Uid":
+			uid = fields[0]
+		case "Threads":
+			threads, _ = strconv.Atoi(fields[0])
+		}
+		if uid != "" && threads > 0 {
+			break
+		}
+	}
+	return uid, threads
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package toolbuiltin
+
+import "github.com/cexll/agentsdk-go/pkg/sandbox"
+
+// effectiveRlimits reports no limits: process rlimits are only applied on Linux.
+func effectiveRlimits(sandbox.ResourceLimits) map[string]uint64 {
+	return nil
+}
+
+// userProcessCount is unknown off Linux.
+func userProcessCount() (int, bool) {
+	return 0, false
+}
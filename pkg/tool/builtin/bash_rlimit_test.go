@@ -0,0 +1,92 @@
+package toolbuiltin
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/cexll/agentsdk-go/pkg/sandbox"
+	"github.com/cexll/agentsdk-go/pkg/security"
+)
+
+func TestBashToolProcessLimits(t *testing.T) {
+	skipIfWindows(t)
+
+	tool := NewBashToolWithSandbox("", security.NewDisabledSandbox())
+	tool.SetProcessLimits(sandbox.ResourceLimits{MaxProcesses: 1 << 40, MaxOpenFiles: 64})
+
+	res, err := tool.Execute(context.Background(), map[string]interface{}{"command": "ulimit -n; ulimit -u"})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	data := res.Data.(map[string]interface{})
+	if runtime.GOOS != "linux" {
+		if _, ok := data["rlimits"]; ok {
+			t.Fatalf("expected no rlimits off linux, got %v", data["rlimits"])
+		}
+		return
+	}
+	applied, ok := data["rlimits"].(map[string]uint64)
+	if !ok || applied["max_open_files"] != 64 {
+		t.Fatalf("unexpected rlimits %v", data["rlimits"])
+	}
+	if applied["max_processes"] == 0 || applied["max_processes"] > 1<<40 {
+		t.Fatalf("expected max_processes clamped, got %d", applied["max_processes"])
+	}
+	want := fmt.Sprintf("64\n%d", applied["max_processes"])
+	if got := strings.TrimSpace(res.Output); got != want {
+		t.Fatalf("ulimit output = %q, want %q", got, want)
+	}
+
+	mgr := newAsyncTaskManager()
+	if err := mgr.startWithEnv(context.Background(), "rlimit-task", "ulimit -n", "", 0, nil, nil, applied); err != nil {
+		t.Fatalf("start task: %v", err)
+	}
+	task, _ := mgr.lookup("rlimit-task")
+	<-task.Done
+	out, _, err := mgr.GetOutput("rlimit-task")
+	if err != nil || strings.TrimSpace(out) != "64" || task.Command != "ulimit -n" {
+		t.Fatalf("unexpected async task output %q command %q err %v", out, task.Command, err)
+	}
+
+	tool.SetProcessLimits(sandbox.ResourceLimits{})
+	res, err = tool.Execute(context.Background(), map[string]interface{}{"command": "true"})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if _, ok := res.Data.(map[string]interface{})["rlimits"]; ok {
+		t.Fatal("expected no rlimits without limits")
+	}
+}
+
+func TestBashToolSkipsMaxProcessesBelowUserCount(t *testing.T) {
+	skipIfWindows(t)
+	if runtime.GOOS != "linux" {
+		t.Skip("process rlimits are only applied on linux")
+	}
+	orig := countUserProcesses
+	countUserProcesses = func() (int, bool) { return 50, true }
+	t.Cleanup(func() { countUserProcesses = orig })
+
+	tool := NewBashToolWithSandbox("", security.NewDisabledSandbox())
+	tool.SetProcessLimits(sandbox.ResourceLimits{MaxProcesses: 50, MaxOpenFiles: 64})
+	res, err := tool.Execute(context.Background(), map[string]interface{}{"command": "true | true"})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	applied := res.Data.(map[string]interface{})["rlimits"].(map[string]uint64)
+	if _, ok := applied["max_processes"]; ok || applied["max_open_files"] != 64 {
+		t.Fatalf("expected max_processes skipped, got %v", applied)
+	}
+
+	tool.SetProcessLimits(sandbox.ResourceLimits{MaxProcesses: 51})
+	res, err = tool.Execute(context.Background(), map[string]interface{}{"command": "true"})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if applied := res.Data.(map[string]interface{})["rlimits"].(map[string]uint64); applied["max_processes"] == 0 {
+		t.Fatalf("expected max_processes above the count applied, got %v", applied)
+	}
+}
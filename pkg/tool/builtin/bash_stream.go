@@ -6,14 +6,24 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
 	"os/exec"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/cexll/agentsdk-go/pkg/sandbox"
 	"github.com/cexll/agentsdk-go/pkg/tool"
 )
 
+// defaultUsageInterval is how often commands are sampled when a resource
+// monitor is configured.
+const defaultUsageInterval = sandbox.DefaultUsageInterval
+
+// defaultStreamBufferBytes is the longest line StreamExecute emits whole;
+// longer lines are emitted in chunks of this size.
+const defaultStreamBufferBytes = 1024 * 1024
+
 // StreamExecute runs the bash command while emitting incremental output. It
 // preserves backwards compatibility by sharing validation and metadata with
 // Execute, and spools output to disk after crossing the configured threshold.
@@ -40,16 +50,22 @@ func (b *BashTool) StreamExecute(ctx context.Context, params map[string]interfac
 	if err != nil {
 		return nil, err
 	}
+	env, err := parseBashEnv(params)
+	if err != nil {
+		return nil, err
+	}
+	rlimits := b.processRlimits()
 
-	execCtx := ctx
-	var cancel context.CancelFunc
+	execCtx, abort := context.WithCancel(ctx)
+	defer abort()
 	if timeout > 0 {
-		execCtx, cancel = context.WithTimeout(ctx, timeout)
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(execCtx, timeout)
 		defer cancel()
 	}
 
-	cmd := exec.CommandContext(execCtx, "bash", "-c", command)
-	cmd.Env = os.Environ()
+	cmd := exec.CommandContext(execCtx, "bash", bashArgs(command, rlimits)...)
+	cmd.Env = b.commandEnv(env)
 	cmd.Dir = workdir
 
 	stdoutPipe, err := cmd.StdoutPipe()
@@ -67,24 +83,27 @@ func (b *BashTool) StreamExecute(ctx context.Context, params map[string]interfac
 		return nil, fmt.Errorf("start command: %w", err)
 	}
 
+	stopMonitor := b.startUsageMonitor(execCtx, cmd.Process.Pid, abort)
+
 	var stdoutErr, stderrErr error
 	var wg sync.WaitGroup
 
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		stdoutErr = consumeStream(execCtx, stdoutPipe, emit, spool, false)
+		stdoutErr = consumeStream(execCtx, stdoutPipe, b.streamBufferBytes, b.redactor, emit, spool, false)
 	}()
 
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		stderrErr = consumeStream(execCtx, stderrPipe, emit, spool, true)
+		stderrErr = consumeStream(execCtx, stderrPipe, b.streamBufferBytes, b.redactor, emit, spool, true)
 	}()
 
 	wg.Wait()
 	waitErr := cmd.Wait()
 	duration := time.Since(start)
+	violation := stopMonitor()
 
 	runErr := waitErr
 	if stdoutErr != nil {
@@ -107,13 +126,22 @@ func (b *BashTool) StreamExecute(ctx context.Context, params map[string]interfac
 	if spoolErr != nil {
 		data["spool_error"] = spoolErr.Error()
 	}
+	if len(rlimits) > 0 {
+		data["rlimits"] = rlimits
+	}
+	if violation != nil {
+		data["resource_violation"] = violation.Error()
+	}
 
 	result := &tool.ToolResult{
-		Success: runErr == nil,
+		Success: runErr == nil && violation == nil,
 		Output:  output,
 		Data:    data,
 	}
 
+	if violation != nil {
+		return result, fmt.Errorf("command terminated: %w", violation)
+	}
 	if runErr != nil {
 		if errors.Is(execCtx.Err(), context.DeadlineExceeded) {
 			return result, fmt.Errorf("command timeout after %s", timeout)
@@ -126,25 +154,82 @@ func (b *BashTool) StreamExecute(ctx context.Context, params map[string]interfac
 	return result, nil
 }
 
-func consumeStream(ctx context.Context, r io.ReadCloser, emit func(chunk string, isStderr bool), spool *bashOutputSpool, isStderr bool) error {
+// startUsageMonitor samples the command's usage while it runs and calls
+// abort on the first sample the resource policy rejects. The returned func
+// stops sampling and reports that violation.
+func (b *BashTool) startUsageMonitor(ctx context.Context, pid int, abort context.CancelFunc) func() error {
+	if b == nil || b.usagePolicy == nil || b.usageProbe == nil {
+		return func() error { return nil }
+	}
+	return sandbox.MonitorUsage(ctx, pid, b.usagePolicy, b.usageProbe, b.usageInterval, func(error) { abort() })
+}
+
+// consumeStream emits r line by line. A line longer than bufSize is emitted
+// as consecutive chunks of at most bufSize bytes, split on rune boundaries,
+// and spooled without separators so the captured output stays intact.
+func consumeStream(ctx context.Context, r io.ReadCloser, bufSize int, redact Redactor, emit func(chunk string, isStderr bool), spool *bashOutputSpool, isStderr bool) error {
 	defer r.Close()
-	scanner := bufio.NewScanner(r)
-	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if emit != nil {
-			emit(line, isStderr)
+	if bufSize <= 0 {
+		bufSize = defaultStreamBufferBytes
+	}
+	reader := bufio.NewReaderSize(r, bufSize)
+	var carry []byte
+	for {
+		segment, err := reader.ReadSlice('\n')
+		if len(carry) > 0 {
+			segment = append(carry, segment...)
+			carry = nil
+		}
+		chunk, endOfLine := "", false
+		switch {
+		case errors.Is(err, bufio.ErrBufferFull):
+			cut := lastRuneBoundary(segment)
+			carry = append(carry, segment[cut:]...)
+			chunk = string(segment[:cut])
+		case len(segment) > 0:
+			chunk = strings.TrimSuffix(strings.TrimSuffix(string(segment), "\n"), "\r")
+			endOfLine = true
+		}
+		if chunk != "" && redact != nil {
+			chunk = redact(chunk)
+		}
+		if chunk != "" || endOfLine {
+			if emit != nil {
+				emit(chunk, isStderr)
+			}
+			if spool != nil {
+				_ = spool.Append(chunk, isStderr) //nolint:errcheck // best-effort spool
+				if endOfLine {
+					_ = spool.Append("\n", isStderr) //nolint:errcheck // best-effort spool
+				}
+			}
 		}
-		if spool != nil {
-			_ = spool.Append(line, isStderr) //nolint:errcheck // best-effort spool
-			_ = spool.Append("\n", isStderr) //nolint:errcheck // best-effort spool
+		if err != nil && !errors.Is(err, bufio.ErrBufferFull) {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
 		}
 		if ctx.Err() != nil {
-			break
+			return nil
 		}
 	}
-	if err := scanner.Err(); err != nil && !errors.Is(err, io.EOF) {
-		return err
+}
+
+// lastRuneBoundary returns the length of the longest prefix of b that does
+// not end in a truncated UTF-8 sequence.
+func lastRuneBoundary(b []byte) int {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(b[i]) {
+			continue
+		}
+		if utf8.FullRune(b[i:]) {
+			return len(b)
+		}
+		if i == 0 {
+			return len(b)
+		}
+		return i
 	}
-	return nil
+	return len(b)
 }
@@ -3,9 +3,14 @@ package toolbuiltin
 import (
 	"context"
 	"errors"
+	"io"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+	"unicode/utf8"
 
+	"github.com/cexll/agentsdk-go/pkg/sandbox"
 	"github.com/cexll/agentsdk-go/pkg/security"
 )
 
@@ -67,14 +72,129 @@ func TestBashToolStreamExecuteInvalidWorkdir(t *testing.T) {
 
 func TestConsumeStreamReadError(t *testing.T) {
 	reader := &errReadCloser{err: errors.New("read failed")}
-	if err := consumeStream(context.Background(), reader, nil, nil, false); err == nil {
+	if err := consumeStream(context.Background(), reader, 0, nil, nil, nil, false); err == nil {
 		t.Fatalf("expected read error")
 	}
 }
 
+func TestBashToolStreamExecuteChunksOverlongLine(t *testing.T) {
+	t.Parallel()
+
+	tool := NewBashToolWithSandbox("", security.NewDisabledSandbox())
+	var chunks []string
+	res, err := tool.StreamExecute(context.Background(), map[string]interface{}{
+		"command": "head -c 2500000 /dev/zero | tr '\\0' 'a'; echo; echo tail",
+	}, func(chunk string, isStderr bool) {
+		if !isStderr {
+			chunks = append(chunks, chunk)
+		}
+	})
+	if err != nil {
+		t.Fatalf("stream execute failed: %v", err)
+	}
+	if !res.Success {
+		t.Fatalf("expected success, got %+v", res)
+	}
+	if len(chunks) != 4 || chunks[3] != "tail" {
+		t.Fatalf("expected three chunks plus tail line, got %d chunks", len(chunks))
+	}
+	for i, chunk := range chunks[:3] {
+		if len(chunk) > defaultStreamBufferBytes {
+			t.Fatalf("chunk %d exceeds buffer: %d bytes", i, len(chunk))
+		}
+	}
+	if got := len(strings.Join(chunks[:3], "")); got != 2500000 {
+		t.Fatalf("expected the whole line across chunks, got %d bytes", got)
+	}
+	if data, ok := res.Data.(map[string]interface{}); !ok || data["output_file"] == nil {
+		t.Fatalf("expected oversized output to be spooled, got %+v", res.Data)
+	}
+}
+
+func TestConsumeStreamChunksOnRuneBoundaries(t *testing.T) {
+	line := strings.Repeat("é", 20) + "\r\nnext"
+	var chunks []string
+	err := consumeStream(context.Background(), io.NopCloser(strings.NewReader(line)), 16, nil, func(chunk string, _ bool) {
+		chunks = append(chunks, chunk)
+	}, nil, false)
+	if err != nil {
+		t.Fatalf("consume: %v", err)
+	}
+	if len(chunks) < 3 || chunks[len(chunks)-1] != "next" {
+		t.Fatalf("unexpected chunks %q", chunks)
+	}
+	joined := strings.Join(chunks[:len(chunks)-1], "")
+	if joined != strings.Repeat("é", 20) {
+		t.Fatalf("chunks do not reassemble the line: %q", joined)
+	}
+	for _, chunk := range chunks {
+		if !utf8.ValidString(chunk) || len(chunk) > 16 {
+			t.Fatalf("chunk split a rune or exceeded the buffer: %q", chunk)
+		}
+	}
+}
+
 type errReadCloser struct {
 	err error
 }
 
 func (e *errReadCloser) Read([]byte) (int, error) { return 0, e.err }
 func (e *errReadCloser) Close() error             { return nil }
+
+type overLimitProbe struct{ samples int32 }
+
+func (p *overLimitProbe) Sample(int) (sandbox.ResourceUsage, error) {
+	atomic.AddInt32(&p.samples, 1)
+	return sandbox.ResourceUsage{MemoryBytes: 1 << 30}, nil
+}
+
+func TestBashToolStreamExecuteTerminatesOnResourceViolation(t *testing.T) {
+	t.Parallel()
+
+	tool := NewBashToolWithSandbox("", security.NewDisabledSandbox())
+	probe := &overLimitProbe{}
+	tool.SetResourceMonitor(sandbox.NewResourceLimiter(sandbox.ResourceLimits{MaxMemoryBytes: 1 << 20}), probe, 10*time.Millisecond)
+
+	start := time.Now()
+	res, err := tool.StreamExecute(context.Background(), map[string]interface{}{
+		"command": "sleep 5",
+	}, nil)
+	if !errors.Is(err, sandbox.ErrResourceExceeded) {
+		t.Fatalf("expected resource violation, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("expected command to be terminated early, ran for %s", elapsed)
+	}
+	if res == nil || res.Success {
+		t.Fatalf("expected failed result, got %+v", res)
+	}
+	data, ok := res.Data.(map[string]interface{})
+	if !ok {
+		t.Fatalf("unexpected data type %T", res.Data)
+	}
+	if msg, _ := data["resource_violation"].(string); !strings.Contains(msg, "memory") {
+		t.Fatalf("expected memory violation recorded, got %v", data["resource_violation"])
+	}
+	if atomic.LoadInt32(&probe.samples) == 0 {
+		t.Fatal("expected probe to be sampled")
+	}
+}
+
+func TestBashToolExecuteTerminatesOnResourceViolation(t *testing.T) {
+	t.Parallel()
+
+	tool := NewBashToolWithSandbox("", security.NewDisabledSandbox())
+	tool.SetResourceMonitor(sandbox.NewResourceLimiter(sandbox.ResourceLimits{MaxMemoryBytes: 1 << 20}), &overLimitProbe{}, 10*time.Millisecond)
+
+	start := time.Now()
+	res, err := tool.Execute(context.Background(), map[string]interface{}{"command": "sleep 5"})
+	if !errors.Is(err, sandbox.ErrResourceExceeded) {
+		t.Fatalf("expected resource violation, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("expected command to be terminated early, ran for %s", elapsed)
+	}
+	if res == nil || res.Success {
+		t.Fatalf("expected failed result, got %+v", res)
+	}
+}
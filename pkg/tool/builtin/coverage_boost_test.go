@@ -132,10 +132,10 @@ func TestWebFetchHelpers(t *testing.T) {
 	if _, err := tool.extractURL(map[string]interface{}{"url": ""}); err == nil {
 		t.Fatalf("expected empty url error")
 	}
-	if normalized, err := tool.normaliseURL("http://example.com"); err != nil || !strings.HasPrefix(normalized, "https://") {
+	if normalized, err := tool.normaliseURL(context.Background(), "http://example.com"); err != nil || !strings.HasPrefix(normalized, "https://") {
 		t.Fatalf("expected https normalization, got %q err=%v", normalized, err)
 	}
-	if _, err := tool.normaliseURL("ftp://example.com"); err == nil {
+	if _, err := tool.normaliseURL(context.Background(), "ftp://example.com"); err == nil {
 		t.Fatalf("expected unsupported scheme error")
 	}
 
@@ -29,6 +29,10 @@ var editSchema = &tool.JSONSchema{
 			"type":        "string",
 			"description": "The absolute path to the file to modify",
 		},
+		"path": map[string]interface{}{
+			"type":        "string",
+			"description": "Alias for file_path; may be relative to the workspace root.",
+		},
 		"old_string": map[string]interface{}{
 			"type":        "string",
 			"description": "The text to replace",
@@ -43,7 +47,8 @@ var editSchema = &tool.JSONSchema{
 			"description": "Replace all occurences of old_string (default false)",
 		},
 	},
-	Required: []string{"file_path", "old_string", "new_string"},
+	// file_path or its path alias is checked in Execute.
+	Required: []string{"old_string", "new_string"},
 }
 
 // EditTool applies safe in-place replacements.
@@ -134,6 +139,8 @@ func (e *EditTool) Execute(ctx context.Context, params map[string]interface{}) (
 		updated = strings.Replace(content, oldString, newString, 1)
 		replacements = 1
 	}
+	display := displayPath(path, e.base.root)
+	diff := unifiedEditDiff(display, content, oldString, newString, matchOffsets(content, oldString, replacements))
 
 	if e.base.maxBytes > 0 && int64(len(updated)) > e.base.maxBytes {
 		return nil, fmt.Errorf("edited content exceeds %d bytes limit", e.base.maxBytes)
@@ -141,18 +148,21 @@ func (e *EditTool) Execute(ctx context.Context, params map[string]interface{}) (
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	if err := os.WriteFile(path, []byte(updated), info.Mode()); err != nil {
-		return nil, fmt.Errorf("write file: %w", err)
+	// Truncating the existing file in place keeps its mode; write validates
+	// a symlink's target before writing through it.
+	if _, err := e.base.write(path, updated, false); err != nil {
+		return nil, err
 	}
 
 	return &tool.ToolResult{
 		Success: true,
 		Output:  fmt.Sprintf("applied %d replacement(s)", replacements),
 		Data: map[string]interface{}{
-			"path":        displayPath(path, e.base.root),
+			"path":        display,
 			"matches":     matches,
 			"replaced":    replacements,
 			"replace_all": replaceAll,
+			"diff":        diff,
 		},
 	}, nil
 }
@@ -162,8 +172,10 @@ func (e *EditTool) resolveFilePath(params map[string]interface{}) (string, error
 		return "", errors.New("params is nil")
 	}
 	raw, ok := params["file_path"]
-	if !ok {
-		return "", errors.New("file_path is required")
+	if !ok || raw == nil {
+		if raw, ok = params["path"]; !ok {
+			return "", errors.New("file_path is required")
+		}
 	}
 	return e.base.resolvePath(raw)
 }
@@ -0,0 +1,150 @@
+package toolbuiltin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	editDiffContext  = 3
+	editDiffMaxLines = 200
+)
+
+// editChange replaces original lines [from, to] with lines.
+type editChange struct {
+	from, to int
+	lines    []string
+}
+
+// unifiedEditDiff renders a unified diff of replacing oldString with
+// newString at each byte offset in starts. It works from the known edit sites
+// rather than diffing whole files, so it stays cheap on large files. Output
+// past editDiffMaxLines is cut off with a marker line.
+func unifiedEditDiff(name, original, oldString, newString string, starts []int) string {
+	lines := splitKeepNewlines(original)
+	offsets := make([]int, len(lines))
+	pos := 0
+	for i, line := range lines {
+		offsets[i] = pos
+		pos += len(line)
+	}
+	lineOf := func(offset int) int {
+		return sort.Search(len(offsets), func(i int) bool { return offsets[i] > offset }) - 1
+	}
+
+	var changes []editChange
+	for i := 0; i < len(starts); {
+		from := lineOf(starts[i])
+		to := lineOf(starts[i] + max(len(oldString)-1, 0))
+		j := i + 1
+		for j < len(starts) && lineOf(starts[j]) <= to {
+			to = max(to, lineOf(starts[j]+max(len(oldString)-1, 0)))
+			j++
+		}
+		segStart := offsets[from]
+		segEnd := len(original)
+		if to+1 < len(offsets) {
+			segEnd = offsets[to+1]
+		}
+		var b strings.Builder
+		cursor := segStart
+		for _, s := range starts[i:j] {
+			b.WriteString(original[cursor:s])
+			b.WriteString(newString)
+			cursor = s + len(oldString)
+		}
+		b.WriteString(original[cursor:segEnd])
+		changes = append(changes, editChange{from: from, to: to, lines: splitKeepNewlines(b.String())})
+		i = j
+	}
+
+	var out []string
+	out = append(out, "--- a/"+name, "+++ b/"+name)
+	delta := 0
+	for i := 0; i < len(changes); {
+		// Merge changes whose context windows touch into one hunk.
+		j := i + 1
+		for j < len(changes) && changes[j].from-changes[j-1].to-1 <= 2*editDiffContext {
+			j++
+		}
+		start := max(changes[i].from-editDiffContext, 0)
+		end := min(changes[j-1].to+editDiffContext, len(lines)-1)
+		var body []string
+		oldCount, newCount := 0, 0
+		cursor := start
+		hunkDelta := 0
+		for _, c := range changes[i:j] {
+			for ; cursor < c.from; cursor++ {
+				body = append(body, " "+trimNewline(lines[cursor]))
+				oldCount++
+				newCount++
+			}
+			for k := c.from; k <= c.to; k++ {
+				body = append(body, "-"+trimNewline(lines[k]))
+				oldCount++
+			}
+			for _, line := range c.lines {
+				body = append(body, "+"+trimNewline(line))
+				newCount++
+			}
+			hunkDelta += len(c.lines) - (c.to - c.from + 1)
+			cursor = c.to + 1
+		}
+		for ; cursor <= end; cursor++ {
+			body = append(body, " "+trimNewline(lines[cursor]))
+			oldCount++
+			newCount++
+		}
+		out = append(out, fmt.Sprintf("@@ -%s +%s @@", hunkRange(start+1, oldCount), hunkRange(start+1+delta, newCount)))
+		out = append(out, body...)
+		delta += hunkDelta
+		i = j
+	}
+	if len(out) > editDiffMaxLines {
+		out = append(out[:editDiffMaxLines], fmt.Sprintf("... diff truncated (%d more lines)", len(out)-editDiffMaxLines))
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		// Unified diff addresses the line before an empty range.
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func splitKeepNewlines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func trimNewline(line string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+}
+
+// matchOffsets returns the byte offset of each non-overlapping occurrence of
+// sub in s, in the order strings.Replace visits them, stopping after limit
+// matches when limit is positive.
+func matchOffsets(s, sub string, limit int) []int {
+	var offsets []int
+	for pos := 0; limit <= 0 || len(offsets) < limit; {
+		idx := strings.Index(s[pos:], sub)
+		if idx < 0 {
+			break
+		}
+		offsets = append(offsets, pos+idx)
+		pos += idx + len(sub)
+	}
+	return offsets
+}
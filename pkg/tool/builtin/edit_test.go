@@ -252,3 +252,79 @@ func TestEditToolHelperErrors(t *testing.T) {
 		t.Fatalf("expected type error for replace_all helper")
 	}
 }
+
+func TestEditToolDiffAndMode(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	path := filepath.Join(dir, "run.sh")
+	lines := []string{"#!/bin/sh", "a", "b", "c", "old", "d", "e", "f", "g", "h", "i", "j", "old old", "k"}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	if err := os.Chmod(path, 0o750); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	tool := NewEditToolWithRoot(dir)
+
+	res, err := tool.Execute(context.Background(), map[string]any{
+		"path":        "run.sh",
+		"old_string":  "old",
+		"new_string":  "new\nline",
+		"replace_all": true,
+	})
+	if err != nil {
+		t.Fatalf("edit failed: %v", err)
+	}
+	data := res.Data.(map[string]interface{})
+	if data["replaced"] != 3 {
+		t.Fatalf("unexpected replacements %v", data["replaced"])
+	}
+	wantDiff := `--- a/run.sh
++++ b/run.sh
+@@ -2,7 +2,8 @@
+ a
+ b
+ c
+-old
++new
++line
+ d
+ e
+ f
+@@ -10,5 +11,7 @@
+ h
+ i
+ j
+-old old
++new
++line new
++line
+ k
+`
+	if data["diff"] != wantDiff {
+		t.Fatalf("diff mismatch:\n%s\nwant:\n%s", data["diff"], wantDiff)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm() != 0o750 {
+		t.Fatalf("mode not preserved: %v", info.Mode().Perm())
+	}
+
+	res, err = tool.Execute(context.Background(), map[string]any{"file_path": path, "old_string": "k\n", "new_string": ""})
+	if err != nil {
+		t.Fatalf("edit failed: %v", err)
+	}
+	wantDiff = `--- a/run.sh
++++ b/run.sh
+@@ -14,4 +14,3 @@
+ new
+ line new
+ line
+-k
+`
+	if got := res.Data.(map[string]interface{})["diff"]; got != wantDiff {
+		t.Fatalf("deletion diff mismatch:\n%s\nwant:\n%s", got, wantDiff)
+	}
+}
@@ -289,6 +289,43 @@ func TestGrepToolRespectsGitignore(t *testing.T) {
 	}
 }
 
+// TestGrepToolScopesNestedGitignore verifies that a nested .gitignore only
+// hides paths below its own directory.
+func TestGrepToolScopesNestedGitignore(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+
+	for _, sub := range []string{"web/dist", "zzz/dist"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "web", ".gitignore"), []byte("dist/\n*.log\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(dir, "web", "dist", "app.js"), []byte("needle"), 0600)
+	_ = os.WriteFile(filepath.Join(dir, "web", "trace.log"), []byte("needle"), 0600)
+	_ = os.WriteFile(filepath.Join(dir, "zzz", "dist", "x.js"), []byte("needle"), 0600)
+	_ = os.WriteFile(filepath.Join(dir, "zzz", "trace.log"), []byte("needle"), 0600)
+
+	for _, path := range []string{dir, filepath.Join(dir, "zzz")} {
+		res, err := NewGrepToolWithRoot(dir).Execute(context.Background(), map[string]any{
+			"pattern":     "needle",
+			"path":        path,
+			"output_mode": "files_with_matches",
+		})
+		if err != nil {
+			t.Fatalf("grep %s failed: %v", path, err)
+		}
+		if !strings.Contains(res.Output, filepath.Join("zzz", "dist", "x.js")) || !strings.Contains(res.Output, filepath.Join("zzz", "trace.log")) {
+			t.Errorf("expected sibling files to be searched from %s: %s", path, res.Output)
+		}
+		if strings.Contains(res.Output, "app.js") || strings.Contains(res.Output, filepath.Join("web", "trace.log")) {
+			t.Errorf("expected web/.gitignore to hide its own files: %s", res.Output)
+		}
+	}
+}
+
 // TestGrepToolDisableGitignore verifies that SetRespectGitignore(false) disables filtering.
 func TestGrepToolDisableGitignore(t *testing.T) {
 	skipIfWindows(t)
@@ -324,3 +361,62 @@ func TestGrepToolDisableGitignore(t *testing.T) {
 		t.Errorf("Expected debug.log in output when gitignore disabled: %s", res.Output)
 	}
 }
+
+// TestGrepToolRespectGitignoreParam verifies nested .gitignore files and the per-call override.
+func TestGrepToolRespectGitignoreParam(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	writeGrepFixture(t, dir, ".gitignore", "*.log\n")
+	writeGrepFixture(t, dir, "main.go", "needle")
+	writeGrepFixture(t, dir, "debug.log", "needle")
+	writeGrepFixture(t, dir, "web/.gitignore", "dist/\n")
+	writeGrepFixture(t, dir, "web/app.js", "needle")
+	writeGrepFixture(t, dir, "web/dist/bundle.js", "needle")
+	writeGrepFixture(t, dir, "web/trace.log", "needle")
+	writeGrepFixture(t, dir, "web/src/.gitignore", "gen/\n")
+	writeGrepFixture(t, dir, "web/src/gen/api.js", "needle")
+	writeGrepFixture(t, dir, "web/src/index.js", "needle")
+
+	tool := NewGrepToolWithRoot(dir)
+	tool.SetRespectGitignore(false)
+
+	cases := []struct {
+		name   string
+		path   string
+		params map[string]any
+		want   []string
+	}{
+		{"tool_default_off", dir, nil, []string{"main.go", "debug.log", "web/app.js", "web/dist/bundle.js", "web/trace.log", "web/src/gen/api.js", "web/src/index.js"}},
+		{"nested_from_root", dir, map[string]any{"respect_gitignore": true}, []string{"main.go", "web/app.js", "web/src/index.js"}},
+		{"nested_from_subdir", filepath.Join(dir, "web", "src"), map[string]any{"respect_gitignore": true}, []string{"web/src/index.js"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			params := map[string]any{"pattern": "needle", "path": tc.path}
+			for k, v := range tc.params {
+				params[k] = v
+			}
+			res, err := tool.Execute(context.Background(), params)
+			if err != nil {
+				t.Fatalf("grep failed: %v", err)
+			}
+			files, _ := grepData(t, res)["files"].([]string)
+			if !sameSet(files, tc.want) {
+				t.Fatalf("files mismatch got %v want %v", files, tc.want)
+			}
+		})
+	}
+
+	// Loading nested patterns for one call must not leak into the shared matcher.
+	res, err := tool.Execute(context.Background(), map[string]any{"pattern": "needle", "path": dir, "include": "*.js", "respect_gitignore": true})
+	if err != nil {
+		t.Fatalf("grep failed: %v", err)
+	}
+	if tool.gitignoreMatcher.Match("web/dist", true) {
+		t.Fatal("nested .gitignore leaked into the tool's matcher")
+	}
+	if files, _ := grepData(t, res)["files"].([]string); !sameSet(files, []string{"web/app.js", "web/src/index.js"}) {
+		t.Fatalf("unexpected files %v", files)
+	}
+
+}
@@ -1,9 +1,11 @@
 package toolbuiltin
 
 import (
+	"bufio"
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,7 +13,12 @@ import (
 	"github.com/cexll/agentsdk-go/pkg/security"
 )
 
-const defaultMaxFileBytes = 1 << 20 // 1 MiB
+const (
+	defaultMaxFileBytes = 1 << 20 // 1 MiB
+	// binarySniffBytes is how much of a file's head is inspected for NUL
+	// bytes when deciding whether it is binary.
+	binarySniffBytes = 8000
+)
 
 // fileSandbox enforces sandboxed filesystem operations shared by file tools.
 type fileSandbox struct {
@@ -87,18 +94,73 @@ func (f *fileSandbox) readFile(path string) (string, error) {
 }
 
 func (f *fileSandbox) writeFile(path string, content string) error {
+	_, err := f.write(path, content, true)
+	return err
+}
+
+// write stores content at path and reports whether the file was created.
+// Parent directories are created only when createDirs is set. The path is
+// validated again after the directories exist. An existing symlink at path is
+// resolved and its target validated and written instead; the final open does
+// not follow symlinks, so a link planted after validation cannot redirect the
+// write out of the sandbox.
+func (f *fileSandbox) write(path string, content string, createDirs bool) (bool, error) {
 	if f == nil || f.sandbox == nil {
-		return errors.New("file sandbox is not initialised")
+		return false, errors.New("file sandbox is not initialised")
 	}
 	data := []byte(content)
 	if f.maxBytes > 0 && int64(len(data)) > f.maxBytes {
-		return fmt.Errorf("content exceeds %d bytes limit", f.maxBytes)
+		return false, fmt.Errorf("content exceeds %d bytes limit", f.maxBytes)
+	}
+	dir := filepath.Dir(path)
+	if createDirs {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return false, fmt.Errorf("ensure directory: %w", err)
+		}
+	} else if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return false, fmt.Errorf("parent directory %s does not exist", displayPath(dir, f.root))
+	}
+	if err := f.sandbox.ValidatePath(path); err != nil {
+		return false, err
+	}
+	info, statErr := os.Lstat(path)
+	if statErr == nil && info.Mode()&os.ModeSymlink != 0 {
+		target, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return false, fmt.Errorf("resolve symlink: %w", err)
+		}
+		if err := f.sandbox.ValidatePath(target); err != nil {
+			return false, err
+		}
+		path = target
+	}
+	created := errors.Is(statErr, os.ErrNotExist)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|openFlagNoFollow, 0o666) //nolint:gosec // respect umask for created files
+	if err != nil {
+		return false, fmt.Errorf("write file: %w", err)
 	}
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return fmt.Errorf("ensure directory: %w", err)
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return false, fmt.Errorf("write file: %w", err)
 	}
-	if err := os.WriteFile(path, data, 0o666); err != nil { //nolint:gosec // respect umask for created files
-		return fmt.Errorf("write file: %w", err)
+	if err := file.Close(); err != nil {
+		return false, fmt.Errorf("write file: %w", err)
 	}
-	return nil
+	return created, nil
+}
+
+// newSniffReader wraps r in a reader whose buffer can hold the binary sniff
+// window, so looksBinary never has to consume input.
+func newSniffReader(r io.Reader) *bufio.Reader {
+	return bufio.NewReaderSize(r, binarySniffBytes)
+}
+
+// looksBinary reports whether the head of reader contains a NUL byte. reader
+// must come from newSniffReader; nothing is consumed.
+func looksBinary(reader *bufio.Reader) (bool, error) {
+	head, err := reader.Peek(binarySniffBytes)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return false, fmt.Errorf("read file: %w", err)
+	}
+	return bytes.IndexByte(head, 0) >= 0, nil
 }
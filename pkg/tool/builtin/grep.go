@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"strings"
 
 	"github.com/cexll/agentsdk-go/pkg/gitignore"
 	"github.com/cexll/agentsdk-go/pkg/security"
@@ -16,16 +17,26 @@ const (
 	grepResultLimit = 100
 	grepMaxDepth    = 8
 	grepMaxContext  = 5
-	grepToolDesc    = `A powerful search tool built on ripgrep.
+	// grepMaxFileBytes is the default size above which files are scanned line
+	// by line instead of being read into memory.
+	grepMaxFileBytes = 10 << 20
+	// grepMaxLineBytes caps a single line when streaming an oversized file.
+	grepMaxLineBytes = 1 << 20
+	grepToolDesc     = `A powerful search tool built on ripgrep.
 
 Usage:
   - ALWAYS use Grep for search tasks. NEVER invoke 'grep' or 'rg' as a Bash command.
   - Supports full regex syntax (e.g., "log.*Error", "function\s+\w+").
   - Filter files with glob (e.g., "*.js", "**/*.tsx") or type (e.g., "js", "py", "rust", "go").
-  - Output modes: "files_with_matches" (default), "content", or "count" via output_mode.
+  - Scope directory searches with include/exclude (comma-separated globs, e.g., "*.go" or "vendor/**, testdata").
+  - Output modes: "files_with_matches" (default, alias "files"), "content" (alias "matches"), or "count" via output_mode. Data.file_counts always holds per-file match counts.
   - Context controls: legacy context_lines or -A/-B/-C for after/before/both sides; -n toggles line numbers (default true).
   - Result shaping: head_limit caps results, offset skips initial matches.
   - Multiline matching: set multiline: true for cross-line patterns like 'struct \{[\s\S]*?field'.
+  - Paths ignored by .gitignore (including nested .gitignore files) are skipped; set respect_gitignore to override per call.
+  - Files above max_file_bytes are scanned line by line; multiline searches skip them and list them in oversize_skipped.
+  - Binary files (NUL byte in the first 8KB) are skipped unless search_binary: true; binary_skipped reports how many.
+  - Literal search: set fixed_string: true to match the pattern text exactly, so '.' and '(' need no escaping; case_insensitive: true (or -i) ignores case.
   - Use Task tool for open-ended searches requiring multiple rounds.
   - Pattern syntax: Uses ripgrep (not grep) - literal braces need escaping (use 'interface\{\}' to find 'interface{}' in Go code).`
 )
@@ -44,8 +55,8 @@ var (
 			},
 			"output_mode": map[string]interface{}{
 				"type":        "string",
-				"description": "Output format: content | files_with_matches | count.",
-				"enum":        []interface{}{"content", "files_with_matches", "count"},
+				"description": "Output format: content | files_with_matches | count. matches and files are accepted as aliases for content and files_with_matches.",
+				"enum":        []interface{}{"content", "files_with_matches", "count", "matches", "files"},
 				"default":     "files_with_matches",
 			},
 			"glob": map[string]interface{}{
@@ -56,6 +67,14 @@ var (
 				"type":        "string",
 				"description": "File type filter (e.g., js, py, rust, go).",
 			},
+			"include": map[string]interface{}{
+				"type":        "string",
+				"description": "Comma-separated globs a file path (relative to the search path) must match, e.g. *.go,cmd/**.",
+			},
+			"exclude": map[string]interface{}{
+				"type":        "string",
+				"description": "Comma-separated globs for paths to skip, e.g. vendor/**,*_test.go. Excluded directories are not descended into.",
+			},
 			"-A": map[string]interface{}{
 				"type":        "integer",
 				"description": "Show N lines after each match.",
@@ -81,6 +100,14 @@ var (
 				"type":        "boolean",
 				"description": "Case-insensitive search.",
 			},
+			"case_insensitive": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Case-insensitive search; same as -i.",
+			},
+			"fixed_string": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Treat pattern as a literal string instead of a regular expression.",
+			},
 			"head_limit": map[string]interface{}{
 				"type":        "integer",
 				"description": fmt.Sprintf("Limit output to first N results (0-%d).", grepResultLimit),
@@ -93,6 +120,18 @@ var (
 				"type":        "boolean",
 				"description": "Enable multiline regex mode for cross-line patterns.",
 			},
+			"respect_gitignore": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Skip paths ignored by .gitignore files (root and nested). Defaults to the tool configuration.",
+			},
+			"max_file_bytes": map[string]interface{}{
+				"type":        "integer",
+				"description": "Stream files larger than this many bytes line by line instead of loading them; multiline searches skip them. Cannot exceed the tool limit.",
+			},
+			"search_binary": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Also search files that look binary (NUL byte in the first 8KB); skipped by default.",
+			},
 		},
 		Required: []string{"pattern"},
 	}
@@ -117,6 +156,14 @@ type GrepTool struct {
 	maxContext       int
 	respectGitignore bool
 	gitignoreMatcher *gitignore.Matcher
+	// contextWidth caps each before/after context line in runes; zero keeps
+	// lines whole.
+	contextWidth int
+	// collapseBlank folds runs of blank context lines into one.
+	collapseBlank bool
+	// maxFileBytes is the size above which files are streamed rather than
+	// read whole; non-positive reads every file whole.
+	maxFileBytes int64
 }
 
 // NewGrepTool builds a GrepTool rooted at the current directory.
@@ -131,6 +178,7 @@ func NewGrepToolWithRoot(root string) *GrepTool {
 		maxResults:       grepResultLimit,
 		maxDepth:         grepMaxDepth,
 		maxContext:       grepMaxContext,
+		maxFileBytes:     grepMaxFileBytes,
 		respectGitignore: true, // Default to respecting .gitignore
 	}
 }
@@ -144,10 +192,33 @@ func NewGrepToolWithSandbox(root string, sandbox *security.Sandbox) *GrepTool {
 		maxResults:       grepResultLimit,
 		maxDepth:         grepMaxDepth,
 		maxContext:       grepMaxContext,
+		maxFileBytes:     grepMaxFileBytes,
 		respectGitignore: true, // Default to respecting .gitignore
 	}
 }
 
+// gitignoreFor returns a copy of the root matcher with the .gitignore files of
+// every directory from the tool root down to dir loaded. searchDirectory adds
+// nested files as it descends, so each call gets its own copy.
+func (g *GrepTool) gitignoreFor(dir string) *gitignore.Matcher {
+	if g.gitignoreMatcher == nil {
+		g.gitignoreMatcher, _ = gitignore.NewMatcher(g.root) //nolint:errcheck // best-effort gitignore
+	}
+	m := g.gitignoreMatcher.Clone()
+	if m == nil {
+		return nil
+	}
+	rel, err := filepath.Rel(g.root, dir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return m
+	}
+	parts := strings.Split(rel, string(filepath.Separator))
+	for i := range parts {
+		_ = m.LoadNestedGitignore(filepath.Join(parts[:i+1]...)) //nolint:errcheck // best-effort gitignore
+	}
+	return m
+}
+
 // SetRespectGitignore configures whether the tool should respect .gitignore patterns.
 func (g *GrepTool) SetRespectGitignore(respect bool) {
 	g.respectGitignore = respect
@@ -156,6 +227,34 @@ func (g *GrepTool) SetRespectGitignore(respect bool) {
 	}
 }
 
+// SetContextLineWidth trims each before/after context line to at most n runes,
+// ending trimmed lines with an ellipsis. Matched lines are never trimmed.
+// Non-positive values disable trimming.
+func (g *GrepTool) SetContextLineWidth(n int) {
+	if n < 0 {
+		n = 0
+	}
+	g.contextWidth = n
+}
+
+// SetCollapseBlankContext folds consecutive blank context lines into a single
+// blank line. Context offsets in the text output then count the lines shown
+// rather than file lines; GrepMatch.Line is unaffected.
+func (g *GrepTool) SetCollapseBlankContext(collapse bool) {
+	g.collapseBlank = collapse
+}
+
+// SetMaxFileBytes sets the size above which files are scanned line by line
+// instead of being loaded whole. Streamed files keep their context lines but
+// cannot serve multiline patterns, so those skip them. Non-positive values
+// disable the guard. The max_file_bytes param can lower it per call.
+func (g *GrepTool) SetMaxFileBytes(n int64) {
+	if n < 0 {
+		n = 0
+	}
+	g.maxFileBytes = n
+}
+
 func (g *GrepTool) Name() string { return "Grep" }
 
 func (g *GrepTool) Description() string { return grepToolDesc }
@@ -186,6 +285,14 @@ func (g *GrepTool) Execute(ctx context.Context, params map[string]interface{}) (
 	if err != nil {
 		return nil, err
 	}
+	include, err := parseGlobList(params, "include")
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := parseGlobList(params, "exclude")
+	if err != nil {
+		return nil, err
+	}
 	headLimit, err := parseHeadLimit(params)
 	if err != nil {
 		return nil, err
@@ -213,6 +320,15 @@ func (g *GrepTool) Execute(ctx context.Context, params map[string]interface{}) (
 	if err != nil {
 		return nil, err
 	}
+	caseInsensitiveLong, _, err := parseBoolParam(params, "case_insensitive")
+	if err != nil {
+		return nil, err
+	}
+	caseInsensitive = caseInsensitive || caseInsensitiveLong
+	fixedString, _, err := parseBoolParam(params, "fixed_string")
+	if err != nil {
+		return nil, err
+	}
 	showLineNumbers, providedLineNumbers, err := parseBoolParam(params, "-n")
 	if err != nil {
 		return nil, err
@@ -224,6 +340,21 @@ func (g *GrepTool) Execute(ctx context.Context, params map[string]interface{}) (
 	if err != nil {
 		return nil, err
 	}
+	searchBinary, _, err := parseBoolParam(params, "search_binary")
+	if err != nil {
+		return nil, err
+	}
+	maxFileBytes, err := parseMaxFileBytes(params, g.maxFileBytes)
+	if err != nil {
+		return nil, err
+	}
+	respectGitignore, providedGitignore, err := parseBoolParam(params, "respect_gitignore")
+	if err != nil {
+		return nil, err
+	}
+	if !providedGitignore {
+		respectGitignore = g.respectGitignore
+	}
 
 	targetPath, info, err := g.resolveSearchPath(params)
 	if err != nil {
@@ -233,7 +364,11 @@ func (g *GrepTool) Execute(ctx context.Context, params map[string]interface{}) (
 		return nil, err
 	}
 
-	patternWithFlags := applyRegexFlags(pattern, caseInsensitive, multiline)
+	expr := pattern
+	if fixedString {
+		expr = regexp.QuoteMeta(pattern)
+	}
+	patternWithFlags := applyRegexFlags(expr, caseInsensitive, multiline)
 
 	re, err := regexp.Compile(patternWithFlags)
 	if err != nil {
@@ -246,9 +381,9 @@ func (g *GrepTool) Execute(ctx context.Context, params map[string]interface{}) (
 		searchRoot = filepath.Dir(targetPath)
 	}
 
-	// Initialize gitignore matcher lazily if needed
-	if g.respectGitignore && g.gitignoreMatcher == nil {
-		g.gitignoreMatcher, _ = gitignore.NewMatcher(g.root) //nolint:errcheck // best-effort gitignore
+	var ignore *gitignore.Matcher
+	if respectGitignore {
+		ignore = g.gitignoreFor(searchRoot)
 	}
 
 	options := grepSearchOptions{
@@ -256,10 +391,20 @@ func (g *GrepTool) Execute(ctx context.Context, params map[string]interface{}) (
 		after:            afterCtx,
 		glob:             glob,
 		typeGlobs:        resolveTypeGlobs(fileType),
+		include:          include,
+		exclude:          exclude,
 		root:             searchRoot,
 		multiline:        multiline,
-		gitignoreMatcher: g.gitignoreMatcher,
+		gitignoreMatcher: ignore,
+		contextWidth:     g.contextWidth,
+		collapseBlank:    g.collapseBlank,
+		searchBinary:     searchBinary,
+		maxFileBytes:     maxFileBytes,
 	}
+	var binarySkipped int
+	options.binarySkipped = &binarySkipped
+	oversizeSkipped := []string{}
+	options.oversizeSkipped = &oversizeSkipped
 
 	var truncated bool
 	if info.IsDir() {
@@ -273,28 +418,43 @@ func (g *GrepTool) Execute(ctx context.Context, params map[string]interface{}) (
 
 	formatted := formatGrepOutput(outputMode, matches, showLineNumbers, headLimit, offset, truncated)
 	data := map[string]interface{}{
-		"pattern":          pattern,
-		"compiled_pattern": patternWithFlags,
-		"path":             displayPath(targetPath, g.root),
-		"matches":          formatted.matches,
-		"count":            len(matches),
-		"display_count":    formatted.displayCount,
-		"total_matches":    len(matches),
-		"output_mode":      outputMode,
-		"head_limit":       headLimit,
-		"offset":           offset,
-		"before_context":   beforeCtx,
-		"after_context":    afterCtx,
-		"line_numbers":     showLineNumbers,
-		"case_insensitive": caseInsensitive,
-		"multiline":        multiline,
-		"glob":             glob,
-		"type":             fileType,
-		"truncated":        formatted.truncated,
+		"pattern":           pattern,
+		"compiled_pattern":  patternWithFlags,
+		"path":              displayPath(targetPath, g.root),
+		"matches":           formatted.matches,
+		"count":             len(matches),
+		"display_count":     formatted.displayCount,
+		"total_matches":     len(matches),
+		"output_mode":       outputMode,
+		"head_limit":        headLimit,
+		"offset":            offset,
+		"before_context":    beforeCtx,
+		"after_context":     afterCtx,
+		"line_numbers":      showLineNumbers,
+		"case_insensitive":  caseInsensitive,
+		"fixed_string":      fixedString,
+		"multiline":         multiline,
+		"glob":              glob,
+		"type":              fileType,
+		"include":           include,
+		"exclude":           exclude,
+		"search_binary":     searchBinary,
+		"binary_skipped":    binarySkipped,
+		"respect_gitignore": respectGitignore,
+		"max_file_bytes":    maxFileBytes,
+		"oversize_skipped":  oversizeSkipped,
+		"truncated":         formatted.truncated,
 	}
 	if len(formatted.files) > 0 {
 		data["files"] = formatted.files
 	}
+	// Per-file counts cover every match found, whatever the output mode or
+	// pagination, so callers can summarise without a second search.
+	fileCounts := countsToMap(collectFileCounts(matches))
+	if fileCounts == nil {
+		fileCounts = map[string]int{}
+	}
+	data["file_counts"] = fileCounts
 	if len(formatted.counts) > 0 {
 		data["counts"] = formatted.counts
 	}
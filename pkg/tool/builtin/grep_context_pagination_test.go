@@ -2,6 +2,8 @@ package toolbuiltin
 
 import (
 	"context"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -271,3 +273,48 @@ func TestGrepHeadLimitAndOffset(t *testing.T) {
 		})
 	}
 }
+
+func TestGrepContextTrimmingAndBlankCollapse(t *testing.T) {
+	skipIfWindows(t)
+	long := strings.Repeat("x", 40)
+	content := long + "\n\n\n\nfirst target\nafter\n\n\n" + long + "\nsecond target"
+	dir := cleanTempDir(t)
+	file := writeGrepFixture(t, dir, "ctx.txt", content)
+	params := map[string]any{"pattern": "target", "path": file, "output_mode": "content", "-C": 4}
+
+	plain := NewGrepToolWithRoot(dir)
+	res, err := plain.Execute(context.Background(), params)
+	if err != nil {
+		t.Fatalf("grep execute: %v", err)
+	}
+	matches := grepData(t, res)["matches"].([]GrepMatch)
+	if len(matches[0].Before) != 4 || matches[0].Before[0] != long {
+		t.Fatalf("default context should be untouched: %#v", matches[0].Before)
+	}
+
+	shaped := NewGrepToolWithRoot(dir)
+	shaped.SetContextLineWidth(10)
+	shaped.SetCollapseBlankContext(true)
+	res, err = shaped.Execute(context.Background(), params)
+	if err != nil {
+		t.Fatalf("grep execute: %v", err)
+	}
+	matches = grepData(t, res)["matches"].([]GrepMatch)
+	if len(matches) != 2 {
+		t.Fatalf("expected two matches, got %#v", matches)
+	}
+	first, second := matches[0], matches[1]
+	if first.Line != 5 || second.Line != 10 {
+		t.Fatalf("match line numbers changed: %d, %d", first.Line, second.Line)
+	}
+	trimmed := strings.Repeat("x", 9) + "…"
+	if !reflect.DeepEqual(first.Before, []string{trimmed, ""}) {
+		t.Fatalf("unexpected shaped before context %#v", first.Before)
+	}
+	if !reflect.DeepEqual(first.After, []string{"after", "", trimmed}) {
+		t.Fatalf("unexpected shaped after context %#v", first.After)
+	}
+	if second.Match != "second target" {
+		t.Fatalf("match line must not be trimmed: %q", second.Match)
+	}
+}
@@ -382,6 +382,61 @@ func TestGrepCaseInsensitive(t *testing.T) {
 	}
 }
 
+func TestGrepCaseInsensitiveAndFixedString(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	file := writeGrepFixture(t, dir, "literal.txt", "call Foo(a.b)\ncall fooXa-b)\n")
+	tool := NewGrepToolWithRoot(dir)
+
+	cases := []struct {
+		name         string
+		params       map[string]any
+		wantLines    []int
+		wantCompiled string
+		wantErr      bool
+	}{
+		{name: "long_case_flag", params: map[string]any{"pattern": "foo", "case_insensitive": true}, wantLines: []int{1, 2}, wantCompiled: "(?i)foo"},
+		{name: "fixed_string_literal", params: map[string]any{"pattern": "Foo(a.b)", "fixed_string": true}, wantLines: []int{1}, wantCompiled: `Foo\(a\.b\)`},
+		{name: "fixed_string_case_insensitive", params: map[string]any{"pattern": "foo(a.b)", "fixed_string": "true", "case_insensitive": true}, wantLines: []int{1}, wantCompiled: `(?i)foo\(a\.b\)`},
+		{name: "regex_dot_matches_any", params: map[string]any{"pattern": "oo.a-b", "-i": true}, wantLines: []int{2}, wantCompiled: "(?i)oo.a-b"},
+		{name: "regex_unbalanced_paren", params: map[string]any{"pattern": "Foo(a"}, wantErr: true},
+		{name: "invalid_fixed_string", params: map[string]any{"pattern": "x", "fixed_string": "yes"}, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.params["path"] = file
+			tc.params["output_mode"] = "content"
+			res, err := tool.Execute(context.Background(), tc.params)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", res)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("grep execute: %v", err)
+			}
+			data := grepData(t, res)
+			matches, _ := data["matches"].([]GrepMatch)
+			var lines []int
+			for _, m := range matches {
+				lines = append(lines, m.Line)
+			}
+			if !reflect.DeepEqual(lines, tc.wantLines) {
+				t.Fatalf("matched lines %v, want %v", lines, tc.wantLines)
+			}
+			if data["compiled_pattern"] != tc.wantCompiled || data["pattern"] != tc.params["pattern"] {
+				t.Fatalf("unexpected patterns: %#v / %#v", data["compiled_pattern"], data["pattern"])
+			}
+			wantFixed := tc.params["fixed_string"] != nil
+			if data["fixed_string"] != wantFixed || data["case_insensitive"] != strings.Contains(tc.wantCompiled, "(?i)") {
+				t.Fatalf("flags not reported: fixed=%#v case=%#v", data["fixed_string"], data["case_insensitive"])
+			}
+		})
+	}
+}
+
 func TestGrepLineNumbers(t *testing.T) {
 	skipIfWindows(t)
 	dir := cleanTempDir(t)
@@ -458,3 +513,241 @@ func TestGrepMultiline(t *testing.T) {
 		})
 	}
 }
+
+func TestGrepIncludeExclude(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	writeGrepFixture(t, dir, "main.go", "needle")
+	writeGrepFixture(t, dir, "main_test.go", "needle")
+	writeGrepFixture(t, dir, "notes.md", "needle")
+	writeGrepFixture(t, dir, "vendor/lib/lib.go", "needle")
+	writeGrepFixture(t, dir, "pkg/api/api.go", "needle")
+	writeGrepFixture(t, dir, "pkg/api/testdata/golden.go", "needle")
+	writeGrepFixture(t, dir, "pkg/testdata/fixture.go", "needle")
+	writeGrepFixture(t, dir, "pkg/internal/gen/gen.go", "needle")
+	tool := NewGrepToolWithRoot(dir)
+
+	cases := []struct {
+		name    string
+		include string
+		exclude string
+		want    []string
+	}{
+		{"include_ext", "*.go", "", []string{"main.go", "main_test.go", "vendor/lib/lib.go", "pkg/api/api.go", "pkg/api/testdata/golden.go", "pkg/testdata/fixture.go", "pkg/internal/gen/gen.go"}},
+		{"include_subtree", "pkg/**/*.go, *.md", "", []string{"notes.md", "pkg/api/api.go", "pkg/api/testdata/golden.go", "pkg/testdata/fixture.go", "pkg/internal/gen/gen.go"}},
+		{"exclude_dir_glob", "", "vendor/**", []string{"main.go", "main_test.go", "notes.md", "pkg/api/api.go", "pkg/api/testdata/golden.go", "pkg/testdata/fixture.go", "pkg/internal/gen/gen.go"}},
+		{"exclude_nested_name", "*.go", "testdata,*_test.go", []string{"main.go", "vendor/lib/lib.go", "pkg/api/api.go", "pkg/internal/gen/gen.go"}},
+		{"exclude_nested_path", "", "pkg/**/gen, vendor, *.md", []string{"main.go", "main_test.go", "pkg/api/api.go", "pkg/api/testdata/golden.go", "pkg/testdata/fixture.go"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			params := map[string]any{"pattern": "needle", "path": dir}
+			if tc.include != "" {
+				params["include"] = tc.include
+			}
+			if tc.exclude != "" {
+				params["exclude"] = tc.exclude
+			}
+			res, err := tool.Execute(context.Background(), params)
+			if err != nil {
+				t.Fatalf("grep execute: %v", err)
+			}
+			files, _ := grepData(t, res)["files"].([]string)
+			if !sameSet(files, tc.want) {
+				t.Fatalf("include=%q exclude=%q files mismatch got %v want %v", tc.include, tc.exclude, files, tc.want)
+			}
+		})
+	}
+
+	if _, err := tool.Execute(context.Background(), map[string]any{"pattern": "needle", "path": dir, "exclude": "vendor/[a"}); err == nil || !strings.Contains(err.Error(), "invalid exclude pattern") {
+		t.Fatalf("expected invalid pattern error, got %v", err)
+	}
+}
+
+func TestMatchPathGlob(t *testing.T) {
+	cases := []struct {
+		pattern string
+		rel     string
+		want    bool
+	}{
+		{"*.go", "a/b/c.go", true},
+		{"vendor", "x/vendor", true},
+		{"vendor/**", "vendor", true},
+		{"vendor/**", "vendor/a/b.go", true},
+		{"vendor/**", "src/vendor/a.go", false},
+		{"**/testdata", "a/b/testdata", true},
+		{"pkg/**/gen", "pkg/gen", true},
+		{"pkg/*/gen", "pkg/a/b/gen", false},
+		{"cmd/*.go", "cmd/main.go", true},
+		{"cmd/*.go", "cmd/sub/main.go", false},
+	}
+	for _, tc := range cases {
+		got, err := matchPathGlob(tc.pattern, tc.rel)
+		if err != nil || got != tc.want {
+			t.Fatalf("matchPathGlob(%q, %q) = %t, %v; want %t", tc.pattern, tc.rel, got, err, tc.want)
+		}
+	}
+}
+
+func TestGrepSkipsBinaryFiles(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	writeGrepFixture(t, dir, "src/main.go", "needle\n")
+	writeGrepFixture(t, dir, "bin/app", "\x7fELF\x00\x00needle\x00\n")
+	late := strings.Repeat("a", binarySniffBytes) + "\x00needle\n"
+	writeGrepFixture(t, dir, "late.dat", late)
+	tool := NewGrepToolWithRoot(dir)
+
+	res, err := tool.Execute(context.Background(), map[string]any{"pattern": "needle", "path": dir})
+	if err != nil {
+		t.Fatalf("grep execute: %v", err)
+	}
+	data := grepData(t, res)
+	files, _ := data["files"].([]string)
+	if !sameSet(files, []string{"src/main.go", "late.dat"}) {
+		t.Fatalf("binary file should be skipped, got %v", files)
+	}
+	if data["binary_skipped"] != 1 || data["search_binary"] != false {
+		t.Fatalf("unexpected binary stats: %v %v", data["binary_skipped"], data["search_binary"])
+	}
+
+	res, err = tool.Execute(context.Background(), map[string]any{"pattern": "needle", "path": dir, "search_binary": true})
+	if err != nil {
+		t.Fatalf("grep execute: %v", err)
+	}
+	data = grepData(t, res)
+	files, _ = data["files"].([]string)
+	if !sameSet(files, []string{"src/main.go", "bin/app", "late.dat"}) || data["binary_skipped"] != 0 {
+		t.Fatalf("search_binary should include binaries, got %v skipped=%v", files, data["binary_skipped"])
+	}
+
+	res, err = tool.Execute(context.Background(), map[string]any{"pattern": "needle", "path": filepath.Join(dir, "bin", "app")})
+	if err != nil {
+		t.Fatalf("grep execute: %v", err)
+	}
+	if data = grepData(t, res); data["count"] != 0 || data["binary_skipped"] != 1 {
+		t.Fatalf("explicit binary target should be skipped too: %v", data)
+	}
+}
+
+func TestGrepStreamsOversizedFiles(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	var b strings.Builder
+	for i := 1; i <= 200; i++ {
+		if i%50 == 0 {
+			b.WriteString("needle " + strconv.Itoa(i) + "\n")
+			continue
+		}
+		b.WriteString("filler line " + strconv.Itoa(i) + "\n")
+	}
+	b.WriteString("needle at end")
+	big := b.String()
+	writeGrepFixture(t, dir, "big.log", big)
+	writeGrepFixture(t, dir, "small.txt", "needle\n")
+
+	params := map[string]any{"pattern": "needle", "path": dir, "output_mode": "content", "-C": 2}
+	whole := NewGrepToolWithRoot(dir)
+	want, err := whole.Execute(context.Background(), params)
+	if err != nil {
+		t.Fatalf("grep execute: %v", err)
+	}
+
+	streamed := NewGrepToolWithRoot(dir)
+	streamed.SetMaxFileBytes(int64(len(big)) - 1)
+	got, err := streamed.Execute(context.Background(), params)
+	if err != nil {
+		t.Fatalf("grep execute: %v", err)
+	}
+	wantMatches := grepData(t, want)["matches"].([]GrepMatch)
+	gotMatches := grepData(t, got)["matches"].([]GrepMatch)
+	if len(gotMatches) != 6 || !reflect.DeepEqual(gotMatches, wantMatches) {
+		t.Fatalf("streamed matches differ:\n got %+v\nwant %+v", gotMatches, wantMatches)
+	}
+	if got.Output != want.Output {
+		t.Fatalf("streamed output differs:\n%s\nwant:\n%s", got.Output, want.Output)
+	}
+
+	// The per-call param lowers the limit; multiline searches skip oversized files.
+	res, err := whole.Execute(context.Background(), map[string]any{"pattern": "needle", "path": dir, "multiline": true, "max_file_bytes": 100})
+	if err != nil {
+		t.Fatalf("grep execute: %v", err)
+	}
+	data := grepData(t, res)
+	if skipped := data["oversize_skipped"].([]string); !reflect.DeepEqual(skipped, []string{"big.log"}) || data["max_file_bytes"] != int64(100) {
+		t.Fatalf("unexpected oversize data %v %v", data["oversize_skipped"], data["max_file_bytes"])
+	}
+	if files := data["files"].([]string); !reflect.DeepEqual(files, []string{"small.txt"}) {
+		t.Fatalf("unexpected files %v", files)
+	}
+
+	// The param cannot raise the tool limit.
+	res, err = streamed.Execute(context.Background(), map[string]any{"pattern": "needle", "path": dir, "max_file_bytes": 1 << 30})
+	if err != nil {
+		t.Fatalf("grep execute: %v", err)
+	}
+	if limit := grepData(t, res)["max_file_bytes"]; limit != int64(len(big))-1 {
+		t.Fatalf("per-call limit should be capped, got %v", limit)
+	}
+	if _, err := whole.Execute(context.Background(), map[string]any{"pattern": "needle", "path": dir, "max_file_bytes": -1}); err == nil {
+		t.Fatal("expected negative max_file_bytes to fail")
+	}
+}
+
+func TestGrepStreamSkipsOverlongLines(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	writeGrepFixture(t, dir, "huge.txt", "needle\n"+strings.Repeat("x", grepMaxLineBytes+1)+"\n")
+	tool := NewGrepToolWithRoot(dir)
+	tool.SetMaxFileBytes(1)
+	res, err := tool.Execute(context.Background(), map[string]any{"pattern": "needle", "path": dir})
+	if err != nil {
+		t.Fatalf("grep execute: %v", err)
+	}
+	data := grepData(t, res)
+	if data["count"] != 0 || !reflect.DeepEqual(data["oversize_skipped"], []string{"huge.txt"}) {
+		t.Fatalf("overlong line should skip the file: %v", data)
+	}
+}
+
+func TestGrepOutputModeAliasesAndFileCounts(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	writeGrepFixture(t, dir, "first.txt", "hit\nhit\nhit")
+	writeGrepFixture(t, dir, "second.txt", "hit once")
+	tool := NewGrepToolWithRoot(dir)
+	wantCounts := map[string]int{"first.txt": 3, "second.txt": 1}
+
+	cases := []struct {
+		mode string
+		want string
+	}{
+		{"matches", "content"},
+		{"files", "files_with_matches"},
+		{"count", "count"},
+		{"content", "content"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.mode, func(t *testing.T) {
+			res, err := tool.Execute(context.Background(), map[string]any{"pattern": "hit", "path": dir, "output_mode": tc.mode, "head_limit": 1})
+			if err != nil {
+				t.Fatalf("grep execute: %v", err)
+			}
+			data := grepData(t, res)
+			if data["output_mode"] != tc.want {
+				t.Fatalf("mode %s resolved to %v, want %s", tc.mode, data["output_mode"], tc.want)
+			}
+			if counts := data["file_counts"].(map[string]int); !reflect.DeepEqual(counts, wantCounts) {
+				t.Fatalf("file_counts should ignore mode and pagination, got %v", counts)
+			}
+		})
+	}
+
+	res, err := tool.Execute(context.Background(), map[string]any{"pattern": "absent", "path": dir})
+	if err != nil {
+		t.Fatalf("grep execute: %v", err)
+	}
+	if counts, ok := grepData(t, res)["file_counts"].(map[string]int); !ok || len(counts) != 0 {
+		t.Fatalf("expected empty file_counts, got %#v", counts)
+	}
+}
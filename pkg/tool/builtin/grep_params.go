@@ -69,8 +69,12 @@ func parseOutputMode(params map[string]interface{}) (string, error) {
 	switch value {
 	case "content", "files_with_matches", "count":
 		return value, nil
+	case "matches":
+		return "content", nil
+	case "files":
+		return "files_with_matches", nil
 	default:
-		return "", errors.New("output_mode must be one of content, files_with_matches, count")
+		return "", errors.New("output_mode must be one of content (matches), files_with_matches (files), count")
 	}
 }
 
@@ -115,6 +119,37 @@ func parseGlobFilter(params map[string]interface{}) (string, error) {
 	return strings.TrimSpace(value), nil
 }
 
+// parseGlobList reads a comma-separated list of path globs such as
+// "*.go, vendor/**". Each pattern is validated up front so a typo fails the
+// call instead of silently matching nothing.
+func parseGlobList(params map[string]interface{}, key string) ([]string, error) {
+	if params == nil {
+		return nil, nil
+	}
+	raw, ok := params[key]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	value, err := coerceString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be string: %w", key, err)
+	}
+	var patterns []string
+	for _, part := range strings.Split(value, ",") {
+		pattern := filepath.ToSlash(strings.TrimSpace(part))
+		if pattern == "" {
+			continue
+		}
+		for _, segment := range strings.Split(pattern, "/") {
+			if _, err := filepath.Match(segment, ""); err != nil {
+				return nil, fmt.Errorf("invalid %s pattern %q: %w", key, pattern, err)
+			}
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
 func parseFileTypeFilter(params map[string]interface{}) (string, error) {
 	if params == nil {
 		return "", nil
@@ -148,6 +183,29 @@ func parseHeadLimit(params map[string]interface{}) (int, error) {
 	return value, nil
 }
 
+// parseMaxFileBytes returns the per-call streaming threshold. It may lower the
+// tool limit but never raise it; zero or absent keeps the tool limit.
+func parseMaxFileBytes(params map[string]interface{}, limit int64) (int64, error) {
+	if params == nil {
+		return limit, nil
+	}
+	raw, ok := params["max_file_bytes"]
+	if !ok || raw == nil {
+		return limit, nil
+	}
+	value, err := intFromParam(raw)
+	if err != nil {
+		return 0, fmt.Errorf("max_file_bytes must be integer: %w", err)
+	}
+	if value < 0 {
+		return 0, errors.New("max_file_bytes cannot be negative")
+	}
+	if value == 0 || (limit > 0 && int64(value) > limit) {
+		return limit, nil
+	}
+	return int64(value), nil
+}
+
 func parseOffset(params map[string]interface{}) (int, error) {
 	if params == nil {
 		return 0, nil
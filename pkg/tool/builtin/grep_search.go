@@ -1,16 +1,21 @@
 package toolbuiltin
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/cexll/agentsdk-go/pkg/gitignore"
+	"github.com/cexll/agentsdk-go/pkg/internal/pathglob"
 )
 
 type grepSearchOptions struct {
@@ -18,9 +23,21 @@ type grepSearchOptions struct {
 	after            int
 	glob             string
 	typeGlobs        []string
+	include          []string
+	exclude          []string
 	root             string
 	multiline        bool
 	gitignoreMatcher *gitignore.Matcher
+	contextWidth     int
+	collapseBlank    bool
+	searchBinary     bool
+	// binarySkipped, when set, counts files skipped because they look binary.
+	binarySkipped *int
+	// maxFileBytes is the size above which files are streamed; non-positive
+	// reads every file whole.
+	maxFileBytes int64
+	// oversizeSkipped, when set, collects files too large to search.
+	oversizeSkipped *[]string
 }
 
 type fileCount struct {
@@ -54,10 +71,10 @@ func (g *GrepTool) searchDirectory(ctx context.Context, root string, re *regexp.
 			return nil
 		}
 
-		// Filter out gitignored paths
-		if opts.gitignoreMatcher != nil {
-			relPath := displayPath(path, root)
-			if opts.gitignoreMatcher.Match(relPath, d.IsDir()) {
+		// Filter out gitignored paths. The matcher is rooted at the tool root,
+		// which may sit above the search root.
+		if opts.gitignoreMatcher != nil && path != root {
+			if opts.gitignoreMatcher.Match(displayPath(path, g.root), d.IsDir()) {
 				if d.IsDir() {
 					return filepath.SkipDir
 				}
@@ -69,6 +86,19 @@ func (g *GrepTool) searchDirectory(ctx context.Context, root string, re *regexp.
 			if relativeDepth(root, path) > g.maxDepth {
 				return filepath.SkipDir
 			}
+			// Prune excluded directories instead of filtering their files later.
+			if path != root {
+				excluded, err := matchAnyPathGlob(opts.exclude, displayPath(path, root))
+				if err != nil {
+					return err
+				}
+				if excluded {
+					return filepath.SkipDir
+				}
+				if opts.gitignoreMatcher != nil {
+					_ = opts.gitignoreMatcher.LoadNestedGitignore(displayPath(path, g.root)) //nolint:errcheck // best-effort gitignore
+				}
+			}
 			return nil
 		}
 		truncated, err := g.searchFile(ctx, path, re, opts, matches)
@@ -103,9 +133,28 @@ func (g *GrepTool) searchFile(ctx context.Context, path string, re *regexp.Regex
 	if !allowed {
 		return false, nil
 	}
-	data, err := os.ReadFile(path)
+	if opts.maxFileBytes > 0 {
+		info, err := os.Stat(path)
+		if err != nil {
+			return false, fmt.Errorf("read file: %w", err)
+		}
+		if info.Size() > opts.maxFileBytes {
+			if opts.multiline {
+				opts.skipOversize(displayPath(path, g.root))
+				return false, nil
+			}
+			return g.streamFile(ctx, path, re, opts, matches)
+		}
+	}
+	data, binary, err := readGrepFile(path, opts.searchBinary)
 	if err != nil {
-		return false, fmt.Errorf("read file: %w", err)
+		return false, err
+	}
+	if binary {
+		if opts.binarySkipped != nil {
+			*opts.binarySkipped++
+		}
+		return false, nil
 	}
 	contents := string(data)
 	lines := splitGrepLines(contents)
@@ -123,7 +172,7 @@ func (g *GrepTool) searchFile(ctx context.Context, path string, re *regexp.Regex
 				Line:  lineNumber,
 				Match: strings.TrimRight(contents[loc[0]:loc[1]], "\r\n"),
 			}
-			if before, after := surroundingLines(lines, lineNumber-1, opts.before, opts.after); len(before) > 0 || len(after) > 0 {
+			if before, after := opts.contextLines(lines, lineNumber-1); len(before) > 0 || len(after) > 0 {
 				if len(before) > 0 {
 					match.Before = before
 				}
@@ -148,7 +197,7 @@ func (g *GrepTool) searchFile(ctx context.Context, path string, re *regexp.Regex
 			Line:  idx + 1,
 			Match: line,
 		}
-		if before, after := surroundingLines(lines, idx, opts.before, opts.after); len(before) > 0 || len(after) > 0 {
+		if before, after := opts.contextLines(lines, idx); len(before) > 0 || len(after) > 0 {
 			if len(before) > 0 {
 				match.Before = before
 			}
@@ -164,6 +213,134 @@ func (g *GrepTool) searchFile(ctx context.Context, path string, re *regexp.Regex
 	return false, nil
 }
 
+// streamFile scans an oversized file line by line, holding only the context
+// window in memory. Each match gets the same context readGrepFile would give
+// it. A line longer than grepMaxLineBytes skips the whole file.
+func (g *GrepTool) streamFile(ctx context.Context, path string, re *regexp.Regexp, opts grepSearchOptions, matches *[]GrepMatch) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("read file: %w", err)
+	}
+	defer f.Close()
+
+	reader := newSniffReader(f)
+	if !opts.searchBinary {
+		binary, err := looksBinary(reader)
+		if err != nil {
+			return false, err
+		}
+		if binary {
+			if opts.binarySkipped != nil {
+				*opts.binarySkipped++
+			}
+			return false, nil
+		}
+	}
+
+	display := displayPath(path, g.root)
+	first := len(*matches)
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), grepMaxLineBytes)
+	var (
+		window  []string // the last opts.before lines
+		pending []int    // matches still collecting after-context
+		limited bool
+	)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if lineNumber%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return false, err
+			}
+		}
+		line := strings.TrimRight(scanner.Text(), "\r")
+		waiting := pending[:0]
+		for _, i := range pending {
+			m := &(*matches)[i]
+			m.After = append(m.After, line)
+			if len(m.After) < opts.after {
+				waiting = append(waiting, i)
+			}
+		}
+		pending = waiting
+		if limited {
+			if len(pending) == 0 {
+				break
+			}
+			continue
+		}
+		if re.MatchString(line) {
+			match := GrepMatch{File: display, Line: lineNumber, Match: line}
+			if len(window) > 0 {
+				match.Before = append([]string(nil), window...)
+			}
+			*matches = append(*matches, match)
+			if opts.after > 0 {
+				pending = append(pending, len(*matches)-1)
+			}
+			if len(*matches) >= g.maxResults {
+				limited = true
+				if len(pending) == 0 {
+					break
+				}
+			}
+		}
+		if opts.before > 0 {
+			window = append(window, line)
+			if len(window) > opts.before {
+				window = window[1:]
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			*matches = (*matches)[:first]
+			opts.skipOversize(display)
+			return false, nil
+		}
+		return false, fmt.Errorf("read file: %w", err)
+	}
+	for i := first; i < len(*matches); i++ {
+		m := &(*matches)[i]
+		if m.Before = opts.shapeContext(m.Before); len(m.Before) == 0 {
+			m.Before = nil
+		}
+		if m.After = opts.shapeContext(m.After); len(m.After) == 0 {
+			m.After = nil
+		}
+	}
+	return limited, nil
+}
+
+func (opts grepSearchOptions) skipOversize(display string) {
+	if opts.oversizeSkipped != nil {
+		*opts.oversizeSkipped = append(*opts.oversizeSkipped, display)
+	}
+}
+
+// readGrepFile returns the file contents, or reports binary=true without
+// reading past the sniffed prefix when it contains a NUL byte and
+// searchBinary is off.
+func readGrepFile(path string, searchBinary bool) ([]byte, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("read file: %w", err)
+	}
+	defer f.Close()
+
+	reader := newSniffReader(f)
+	if !searchBinary {
+		binary, err := looksBinary(reader)
+		if err != nil || binary {
+			return nil, binary, err
+		}
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, false, fmt.Errorf("read file: %w", err)
+	}
+	return data, false, nil
+}
+
 func (opts grepSearchOptions) allow(path string) (bool, error) {
 	rel := path
 	if opts.root != "" {
@@ -183,6 +360,16 @@ func (opts grepSearchOptions) allow(path string) (bool, error) {
 			return false, nil
 		}
 	}
+	slashRel := filepath.ToSlash(rel)
+	if len(opts.include) > 0 {
+		ok, err := matchAnyPathGlob(opts.include, slashRel)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	if excluded, err := matchAnyPathGlob(opts.exclude, slashRel); err != nil || excluded {
+		return false, err
+	}
 	if len(opts.typeGlobs) > 0 {
 		base := filepath.Base(path)
 		matched := false
@@ -203,6 +390,32 @@ func (opts grepSearchOptions) allow(path string) (bool, error) {
 	return true, nil
 }
 
+func matchAnyPathGlob(patterns []string, rel string) (bool, error) {
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range patterns {
+		ok, err := matchPathGlob(pattern, rel)
+		if err != nil {
+			return false, fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchPathGlob matches a slash-separated relative path against pattern.
+// Patterns without a slash match the base name at any depth, so "*.go" and
+// "vendor" behave as in .gitignore. Otherwise the pattern is matched segment
+// by segment and "**" spans zero or more directories, so "vendor/**" matches
+// vendor itself as well as everything below it.
+func matchPathGlob(pattern, rel string) (bool, error) {
+	if !strings.Contains(pattern, "/") {
+		return path.Match(pattern, path.Base(rel))
+	}
+	return pathglob.MatchSegments(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(rel, "/"))
+}
+
 func relativeDepth(base, target string) int {
 	if base == target {
 		return 0
@@ -247,6 +460,34 @@ func surroundingLines(lines []string, idx, before, after int) ([]string, []strin
 	return beforeLines, afterLines
 }
 
+// contextLines returns the context around lines[idx], shaped by the width and
+// blank-collapsing options.
+func (opts grepSearchOptions) contextLines(lines []string, idx int) ([]string, []string) {
+	before, after := surroundingLines(lines, idx, opts.before, opts.after)
+	return opts.shapeContext(before), opts.shapeContext(after)
+}
+
+func (opts grepSearchOptions) shapeContext(lines []string) []string {
+	if opts.contextWidth <= 0 && !opts.collapseBlank {
+		return lines
+	}
+	out := lines[:0]
+	prevBlank := false
+	for _, line := range lines {
+		blank := strings.TrimSpace(line) == ""
+		if opts.collapseBlank && blank && prevBlank {
+			continue
+		}
+		prevBlank = blank
+		if opts.contextWidth > 0 && utf8.RuneCountInString(line) > opts.contextWidth {
+			runes := []rune(line)
+			line = string(runes[:max(opts.contextWidth-1, 0)]) + "…"
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
 func applyWindow[T any](items []T, offset, head int) ([]T, bool) {
 	if len(items) == 0 {
 		return nil, false
@@ -1,11 +1,14 @@
 package toolbuiltin
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math"
+	"os"
 	"strconv"
 	"strings"
 
@@ -16,6 +19,7 @@ import (
 const (
 	readDefaultLineLimit = 2000
 	readMaxLineLength    = 2000
+	readDefaultMaxBytes  = defaultMaxFileBytes // per page
 	readDescription      = `Reads a text file from the local filesystem within the configured sandbox.
 If the User provides a path, assume that path is valid. It is okay to read a file that does not exist; an error will be returned.
 
@@ -24,6 +28,7 @@ Usage:
 - By default, it reads up to 2000 lines starting from the beginning of the file
 - You can optionally specify a line offset and limit (especially handy for long files), but it's recommended to read the whole file by not providing these parameters
 - Any lines longer than 2000 characters will be truncated
+- Large files are read page by page: a page stops early once its byte budget is exhausted, and next_offset reports where to continue
 - Results are returned using cat -n format, with line numbers starting at 1
 - This tool reads text files only; it does not decode images, PDFs, or Jupyter notebooks.
 - If the target looks like a binary file, an error will be returned instead of garbage output.
@@ -51,11 +56,13 @@ var readSchema = &tool.JSONSchema{
 	Required: []string{"file_path"},
 }
 
-// ReadTool streams files with strict sandbox boundaries.
+// ReadTool streams files with strict sandbox boundaries. Files are read page
+// by page, so their size only bounds how long a read takes, not memory.
 type ReadTool struct {
 	base          *fileSandbox
 	defaultLimit  int
 	maxLineLength int
+	maxBytes      int
 }
 
 // NewReadTool builds a ReadTool rooted at the current directory.
@@ -69,6 +76,7 @@ func NewReadToolWithRoot(root string) *ReadTool {
 		base:          newFileSandbox(root),
 		defaultLimit:  readDefaultLineLimit,
 		maxLineLength: readMaxLineLength,
+		maxBytes:      readDefaultMaxBytes,
 	}
 }
 
@@ -78,9 +86,22 @@ func NewReadToolWithSandbox(root string, sandbox *security.Sandbox) *ReadTool {
 		base:          newFileSandboxWithSandbox(root, sandbox),
 		defaultLimit:  readDefaultLineLimit,
 		maxLineLength: readMaxLineLength,
+		maxBytes:      readDefaultMaxBytes,
 	}
 }
 
+// SetMaxBytes caps the number of content bytes returned in a single page.
+// Non-positive values restore the default.
+func (r *ReadTool) SetMaxBytes(n int) {
+	if r == nil {
+		return
+	}
+	if n <= 0 {
+		n = readDefaultMaxBytes
+	}
+	r.maxBytes = n
+}
+
 func (r *ReadTool) Name() string { return "Read" }
 
 func (r *ReadTool) Description() string { return readDescription }
@@ -110,45 +131,35 @@ func (r *ReadTool) Execute(ctx context.Context, params map[string]interface{}) (
 		return nil, err
 	}
 
-	content, err := r.base.readFile(path)
+	page, err := r.readPage(ctx, path, offset, limit)
 	if err != nil {
 		return nil, err
 	}
 
-	lines := splitFileLines(content)
-	totalLines := len(lines)
-	formatted, returned, truncatedLineCount, truncated := r.formatLines(lines, offset, limit)
-	if returned == 0 {
-		message := fmt.Sprintf("no content in requested range (file has %d lines)", totalLines)
+	data := map[string]interface{}{
+		"path":             displayPath(path, r.base.root),
+		"offset":           offset,
+		"limit":            limit,
+		"total_lines":      page.totalLines,
+		"returned_lines":   page.returned,
+		"returned_bytes":   page.bytes,
+		"line_truncations": page.lineTruncations,
+		"byte_limited":     page.byteLimited,
+	}
+	if next := offset + page.returned; page.returned > 0 && next <= page.totalLines {
+		data["next_offset"] = next
+	}
+	if page.returned == 0 {
+		data["truncated"] = true
+		data["range_out_of_file"] = true
 		return &tool.ToolResult{
 			Success: true,
-			Output:  message,
-			Data: map[string]interface{}{
-				"path":              displayPath(path, r.base.root),
-				"offset":            offset,
-				"limit":             limit,
-				"total_lines":       totalLines,
-				"returned_lines":    returned,
-				"line_truncations":  truncatedLineCount,
-				"truncated":         true,
-				"range_out_of_file": true,
-			},
+			Output:  fmt.Sprintf("no content in requested range (file has %d lines)", page.totalLines),
+			Data:    data,
 		}, nil
 	}
-
-	return &tool.ToolResult{
-		Success: true,
-		Output:  formatted,
-		Data: map[string]interface{}{
-			"path":             displayPath(path, r.base.root),
-			"offset":           offset,
-			"limit":            limit,
-			"total_lines":      totalLines,
-			"returned_lines":   returned,
-			"line_truncations": truncatedLineCount,
-			"truncated":        truncated,
-		},
-	}, nil
+	data["truncated"] = offset > 1 || offset+page.returned <= page.totalLines || page.lineTruncations > 0
+	return &tool.ToolResult{Success: true, Output: page.text, Data: data}, nil
 }
 
 func (r *ReadTool) resolveFilePath(params map[string]interface{}) (string, error) {
@@ -189,40 +200,123 @@ func (r *ReadTool) parseLimit(params map[string]interface{}) (int, error) {
 	}
 }
 
-func (r *ReadTool) formatLines(lines []string, offset, limit int) (string, int, int, bool) {
-	if len(lines) == 0 {
-		return "", 0, 0, false
+type filePage struct {
+	text            string
+	totalLines      int
+	returned        int
+	bytes           int
+	lineTruncations int
+	byteLimited     bool
+}
+
+// readPage streams the file once, counting every line but retaining only the
+// requested window, so file size does not bound memory use. Collection stops
+// early once the page reaches maxBytes.
+func (r *ReadTool) readPage(ctx context.Context, path string, offset, limit int) (filePage, error) {
+	var page filePage
+	info, err := os.Stat(path)
+	if err != nil {
+		return page, fmt.Errorf("stat file: %w", err)
 	}
-	start := offset - 1
-	if start < 0 {
-		start = 0
+	if info.IsDir() {
+		return page, fmt.Errorf("%s is a directory", path)
 	}
-	if start >= len(lines) {
-		return "", 0, 0, false
+	f, err := os.Open(path)
+	if err != nil {
+		return page, fmt.Errorf("open file: %w", err)
 	}
-	if limit <= 0 || limit > len(lines)-start {
-		limit = len(lines) - start
+	defer f.Close()
+
+	reader := newSniffReader(f)
+	binary, err := looksBinary(reader)
+	if err != nil {
+		return page, err
+	}
+	if binary {
+		return page, fmt.Errorf("binary file %s is not supported", path)
+	}
+
+	if info.Size() == 0 {
+		return page, nil
 	}
 
 	var b strings.Builder
-	returned := 0
-	truncatedLines := 0
-	truncated := start > 0 || start+limit < len(lines)
+	collecting := true
+	for lineNumber := 1; ; lineNumber++ {
+		if lineNumber%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return page, err
+			}
+		}
+		line, last, err := r.readLine(reader, collecting && lineNumber >= offset)
+		if err != nil {
+			return page, fmt.Errorf("read file: %w", err)
+		}
+		page.totalLines++
+		if collecting && lineNumber >= offset {
+			collecting = r.appendLine(&b, &page, lineNumber, line, limit)
+		}
+		if last {
+			break
+		}
+	}
+	page.text = b.String()
+	return page, nil
+}
 
-	for i := start; i < start+limit; i++ {
-		lineNumber := i + 1
-		formatted, lineTruncated := r.applyLineTruncation(strings.TrimRight(lines[i], "\r"))
-		if lineTruncated {
-			truncatedLines++
-			truncated = true
+// appendLine adds a formatted line to the page and reports whether the page
+// can take more lines.
+func (r *ReadTool) appendLine(b *strings.Builder, page *filePage, lineNumber int, line []byte, limit int) bool {
+	formatted, truncated := r.applyLineTruncation(strings.TrimRight(string(line), "\r"))
+	entry := fmt.Sprintf("%6d\t%s", lineNumber, formatted)
+	if page.returned > 0 && r.maxBytes > 0 && page.bytes+1+len(entry) > r.maxBytes {
+		page.byteLimited = true
+		return false
+	}
+	if page.returned > 0 {
+		b.WriteByte('\n')
+		page.bytes++
+	}
+	b.WriteString(entry)
+	page.bytes += len(entry)
+	page.returned++
+	if truncated {
+		page.lineTruncations++
+	}
+	return page.returned < limit
+}
+
+// readLine returns the next newline-separated segment without its
+// terminator; last reports that it ended at EOF. Like strings.Split, a
+// trailing newline yields a final empty segment. When keep is false the
+// content is discarded so skipped lines cost no memory; kept lines are capped
+// one byte past maxLineLength, which is enough for applyLineTruncation to
+// notice the overflow.
+func (r *ReadTool) readLine(reader *bufio.Reader, keep bool) ([]byte, bool, error) {
+	var line []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if err == nil {
+			chunk = chunk[:len(chunk)-1]
+		}
+		if keep {
+			room := len(chunk)
+			if r.maxLineLength > 0 {
+				room = min(room, max(r.maxLineLength+1-len(line), 0))
+			}
+			line = append(line, chunk[:room]...)
 		}
-		fmt.Fprintf(&b, "%6d\t%s", lineNumber, formatted)
-		returned++
-		if i < start+limit-1 {
-			b.WriteByte('\n')
+		switch {
+		case err == nil:
+			return line, false, nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case errors.Is(err, io.EOF):
+			return line, true, nil
+		default:
+			return nil, false, err
 		}
 	}
-	return b.String(), returned, truncatedLines, truncated
 }
 
 func (r *ReadTool) applyLineTruncation(line string) (string, bool) {
@@ -237,13 +331,6 @@ func (r *ReadTool) applyLineTruncation(line string) (string, bool) {
 	return line[:cutoff] + suffix, true
 }
 
-func splitFileLines(content string) []string {
-	if content == "" {
-		return nil
-	}
-	return strings.Split(content, "\n")
-}
-
 func parseLineNumber(params map[string]interface{}, key string) (int, error) {
 	if params == nil {
 		return 0, nil
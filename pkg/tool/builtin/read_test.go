@@ -3,6 +3,7 @@ package toolbuiltin
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"math"
 	"os"
 	"path/filepath"
@@ -170,12 +171,6 @@ func TestReadToolMetadataAndHelpers(t *testing.T) {
 	if tool.Description() == "" || tool.Schema() == nil {
 		t.Fatalf("metadata should not be empty")
 	}
-	if got := splitFileLines(""); got != nil {
-		t.Fatalf("expected nil lines for empty content")
-	}
-	if got := splitFileLines("a\nb"); len(got) != 2 {
-		t.Fatalf("expected two lines got %v", got)
-	}
 
 	cases := []struct {
 		name    string
@@ -255,3 +250,158 @@ func TestReadToolMetadataAndHelpers(t *testing.T) {
 		t.Fatalf("expected truncation suffix, got %q truncated=%v", got, truncated)
 	}
 }
+
+func writeNumberedFile(t *testing.T, dir string, lines int) string {
+	t.Helper()
+	numbered := make([]string, lines)
+	for i := range numbered {
+		numbered[i] = fmt.Sprintf("line-%d", i+1)
+	}
+	path := filepath.Join(dir, "big.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(numbered, "\n")), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	return path
+}
+
+func TestReadToolReadsMiddlePage(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	path := writeNumberedFile(t, dir, 100)
+	tool := NewReadToolWithRoot(dir)
+
+	res, err := tool.Execute(context.Background(), map[string]any{"file_path": "big.txt", "offset": 40, "limit": 10})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	lines := strings.Split(res.Output, "\n")
+	if len(lines) != 10 {
+		t.Fatalf("expected 10 lines, got %d: %q", len(lines), res.Output)
+	}
+	if lines[0] != "    40\tline-40" || lines[9] != "    49\tline-49" {
+		t.Fatalf("unexpected page bounds: first=%q last=%q", lines[0], lines[9])
+	}
+	data := res.Data.(map[string]any)
+	if data["path"] != displayPath(path, dir) {
+		t.Fatalf("unexpected path %v", data["path"])
+	}
+	if data["offset"].(int) != 40 || data["limit"].(int) != 10 {
+		t.Fatalf("unexpected offset/limit: %v/%v", data["offset"], data["limit"])
+	}
+	if data["total_lines"].(int) != 100 {
+		t.Fatalf("expected 100 total lines, got %v", data["total_lines"])
+	}
+	if data["returned_lines"].(int) != 10 {
+		t.Fatalf("expected 10 returned lines, got %v", data["returned_lines"])
+	}
+	if data["next_offset"].(int) != 50 {
+		t.Fatalf("expected next_offset 50, got %v", data["next_offset"])
+	}
+
+	res, err = tool.Execute(context.Background(), map[string]any{"file_path": path, "offset": 95, "limit": 10})
+	if err != nil {
+		t.Fatalf("execute tail: %v", err)
+	}
+	data = res.Data.(map[string]any)
+	if data["returned_lines"].(int) != 6 {
+		t.Fatalf("expected 6 trailing lines, got %v", data["returned_lines"])
+	}
+	if _, ok := data["next_offset"]; ok {
+		t.Fatalf("did not expect next_offset on last page: %v", data)
+	}
+}
+
+func TestReadToolByteCapAndRange(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	writeNumberedFile(t, dir, 100)
+	tool := NewReadToolWithRoot(dir)
+	tool.SetMaxBytes(40)
+
+	res, err := tool.Execute(context.Background(), map[string]any{"file_path": "big.txt", "offset": 1, "limit": 50})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	data := res.Data.(map[string]any)
+	returned := data["returned_lines"].(int)
+	if returned == 0 || returned >= 50 || !data["byte_limited"].(bool) {
+		t.Fatalf("expected byte-limited page, got %v", data)
+	}
+	if len(res.Output) > 40 || data["returned_bytes"].(int) != len(res.Output) {
+		t.Fatalf("page exceeds cap: %d bytes %q", len(res.Output), res.Output)
+	}
+	if data["next_offset"].(int) != returned+1 {
+		t.Fatalf("unexpected next_offset %v for %d lines", data["next_offset"], returned)
+	}
+
+	res, err = tool.Execute(context.Background(), map[string]any{"file_path": "big.txt", "offset": 500})
+	if err != nil {
+		t.Fatalf("execute out of range: %v", err)
+	}
+	data = res.Data.(map[string]any)
+	if !data["range_out_of_file"].(bool) || data["total_lines"].(int) != 100 {
+		t.Fatalf("unexpected out-of-range metadata: %v", data)
+	}
+}
+
+func TestReadToolPagingRejectsBinaryAndEscapes(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	bin := filepath.Join(dir, "blob.bin")
+	if err := os.WriteFile(bin, []byte{'a', 0, 'b'}, 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	tool := NewReadToolWithRoot(dir)
+
+	if _, err := tool.Execute(context.Background(), map[string]any{"file_path": bin}); err == nil || !strings.Contains(err.Error(), "binary") {
+		t.Fatalf("expected binary rejection, got %v", err)
+	}
+	if _, err := tool.Execute(context.Background(), map[string]any{"file_path": "../outside.txt"}); err == nil {
+		t.Fatalf("expected sandbox rejection")
+	}
+	if _, err := tool.Execute(context.Background(), map[string]any{"file_path": "big.txt", "offset": -1}); err == nil {
+		t.Fatalf("expected offset validation error")
+	}
+}
+
+func TestReadToolTruncatesStreamedLongLines(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	path := filepath.Join(dir, "wide.txt")
+	content := strings.Repeat("x", readMaxLineLength*3) + "\nshort"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	tool := NewReadToolWithRoot(dir)
+
+	res, err := tool.Execute(context.Background(), map[string]any{"file_path": path})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	data := res.Data.(map[string]any)
+	if data["line_truncations"].(int) != 1 || data["total_lines"].(int) != 2 {
+		t.Fatalf("unexpected metadata: %v", data)
+	}
+	if !strings.Contains(res.Output, "...(truncated)") || !strings.HasSuffix(res.Output, "     2\tshort") {
+		t.Fatalf("unexpected output: %q", res.Output[len(res.Output)-40:])
+	}
+}
+
+func TestReadToolPagesFilesLargerThanSandboxLimit(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	path := filepath.Join(dir, "huge.txt")
+	line := strings.Repeat("y", 1023) + "\n"
+	if err := os.WriteFile(path, []byte(strings.Repeat(line, 2048)+"tail"), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	tool := NewReadToolWithRoot(dir)
+
+	res, err := tool.Execute(context.Background(), map[string]any{"file_path": path, "offset": 2049})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Output != "  2049\ttail" {
+		t.Fatalf("unexpected last page %q", res.Output)
+	}
+}
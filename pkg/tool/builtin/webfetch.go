@@ -36,6 +36,7 @@ const (
 			- This tool is read-only and does not modify any files
 			- Results may be summarized if the content is very large
 		- Includes a self-cleaning 15-minute cache for faster responses when repeatedly accessing the same URL
+		- When POST is enabled, set method to POST with a body to send data; POST responses are never cached
     - When a URL redirects to a different host, the tool will inform you and provide the redirect URL in a special format. You should then make a new WebFetch request with the redirect URL to fetch the content.
 
 	`
@@ -48,8 +49,18 @@ const (
 	defaultFetchUserAgent   = "agentsdk-webfetch/1.0"
 	redirectNoticePrefix    = "redirect://"
 	markdownSnippetMaxLines = 12
+	fetchBodyPreviewBytes   = 8 << 10
 )
 
+// fetchHeaderSubset lists the response headers copied into the result.
+var fetchHeaderSubset = []string{"Content-Type", "Content-Length", "Content-Encoding", "Last-Modified", "ETag", "Cache-Control", "Location"}
+
+// HostPolicy decides whether outbound requests may reach host.
+// *sandbox.DomainAllowList satisfies it.
+type HostPolicy interface {
+	ValidateContext(ctx context.Context, host string) error
+}
+
 var webFetchSchema = &tool.JSONSchema{
 	Type: "object",
 	Properties: map[string]interface{}{
@@ -62,10 +73,33 @@ var webFetchSchema = &tool.JSONSchema{
 			"type":        "string",
 			"description": "The prompt to run on the fetched content",
 		},
+		"method": map[string]interface{}{
+			"type":        "string",
+			"enum":        []interface{}{"GET", "POST"},
+			"description": "HTTP method (default GET). POST responses are never cached.",
+		},
+		"body": map[string]interface{}{
+			"type":        "string",
+			"description": "Request body for POST.",
+		},
+		"content_type": map[string]interface{}{
+			"type":        "string",
+			"description": "Content-Type of the POST body (default application/json).",
+		},
 	},
 	Required: []string{"url", "prompt"},
 }
 
+// webFetchGetSchema is advertised while POST is disabled.
+var webFetchGetSchema = &tool.JSONSchema{
+	Type: "object",
+	Properties: map[string]interface{}{
+		"url":    webFetchSchema.Properties["url"],
+		"prompt": webFetchSchema.Properties["prompt"],
+	},
+	Required: webFetchSchema.Required,
+}
+
 // WebFetchOptions configures WebFetchTool behaviour.
 type WebFetchOptions struct {
 	HTTPClient        *http.Client
@@ -75,6 +109,12 @@ type WebFetchOptions struct {
 	AllowedHosts      []string
 	BlockedHosts      []string
 	AllowPrivateHosts bool
+	// NetworkPolicy, when set, must also accept every requested host and
+	// redirect target, e.g. the sandbox's network allowlist.
+	NetworkPolicy HostPolicy
+	// AllowPost lets the model send POST requests with a body. It is off by
+	// default because a POST can change state on the remote side.
+	AllowPost bool
 }
 
 // WebFetchTool fetches remote web pages and returns Markdown content.
@@ -84,6 +124,8 @@ type WebFetchTool struct {
 	maxBytes  int64
 	cache     *fetchCache
 	validator hostValidator
+	policy    HostPolicy
+	allowPost bool
 	now       func() time.Time
 }
 
@@ -118,6 +160,8 @@ func NewWebFetchTool(opts *WebFetchOptions) *WebFetchTool {
 		maxBytes:  maxBytes,
 		cache:     newFetchCache(cacheTTL),
 		validator: newHostValidator(cfg.AllowedHosts, cfg.BlockedHosts, cfg.AllowPrivateHosts),
+		policy:    cfg.NetworkPolicy,
+		allowPost: cfg.AllowPost,
 		now:       time.Now,
 	}
 	tool.client.CheckRedirect = tool.redirectPolicy()
@@ -128,7 +172,12 @@ func (w *WebFetchTool) Name() string { return "WebFetch" }
 
 func (w *WebFetchTool) Description() string { return webFetchDescription }
 
-func (w *WebFetchTool) Schema() *tool.JSONSchema { return webFetchSchema }
+func (w *WebFetchTool) Schema() *tool.JSONSchema {
+	if w != nil && w.allowPost {
+		return webFetchSchema
+	}
+	return webFetchGetSchema
+}
 
 // Execute fetches the requested URL, converts it to Markdown and returns metadata.
 func (w *WebFetchTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
@@ -151,7 +200,12 @@ func (w *WebFetchTool) Execute(ctx context.Context, params map[string]interface{
 		return nil, err
 	}
 
-	normalized, err := w.normaliseURL(rawURL)
+	method, body, contentType, err := parseFetchRequest(params, w.allowPost)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized, err := w.normaliseURL(ctx, rawURL)
 	if err != nil {
 		return nil, err
 	}
@@ -163,7 +217,7 @@ func (w *WebFetchTool) Execute(ctx context.Context, params map[string]interface{
 		defer cancel()
 	}
 
-	fetched, notice, err := w.fetch(reqCtx, normalized)
+	fetched, notice, err := w.fetch(reqCtx, method, normalized, body, contentType)
 	if err != nil {
 		return nil, err
 	}
@@ -180,6 +234,11 @@ func (w *WebFetchTool) Execute(ctx context.Context, params map[string]interface{
 
 	markdown := htmlToMarkdown(string(fetched.Body))
 	snippet := summariseMarkdown(markdown)
+	preview, previewTruncated := fetched.Body, false
+	if len(preview) > fetchBodyPreviewBytes {
+		preview = preview[:lastRuneBoundary(preview[:fetchBodyPreviewBytes])]
+		previewTruncated = true
+	}
 
 	result := &tool.ToolResult{
 		Success: true,
@@ -193,6 +252,10 @@ func (w *WebFetchTool) Execute(ctx context.Context, params map[string]interface{
 			"from_cache":       fetched.FromCache,
 			"fetched_at":       w.now().UTC().Format(time.RFC3339),
 			"content_bytes":    len(fetched.Body),
+			"method":           method,
+			"headers":          fetched.Headers,
+			"body":             string(preview),
+			"body_truncated":   previewTruncated,
 		},
 	}
 	return result, nil
@@ -214,7 +277,7 @@ func (w *WebFetchTool) extractURL(params map[string]interface{}) (string, error)
 	return value, nil
 }
 
-func (w *WebFetchTool) normaliseURL(raw string) (string, error) {
+func (w *WebFetchTool) normaliseURL(ctx context.Context, raw string) (string, error) {
 	parsed, err := url.Parse(raw)
 	if err != nil {
 		return "", fmt.Errorf("invalid url: %w", err)
@@ -238,26 +301,55 @@ func (w *WebFetchTool) normaliseURL(raw string) (string, error) {
 	if parsed.Path == "" {
 		parsed.Path = "/"
 	}
-	host := parsed.Hostname()
-	if err := w.validator.Validate(host); err != nil {
+	if err := w.validateHost(ctx, parsed); err != nil {
 		return "", err
 	}
 	return parsed.String(), nil
 }
 
-func (w *WebFetchTool) fetch(ctx context.Context, normalized string) (*fetchResult, *redirectNotice, error) {
-	if cached, ok := w.cache.Get(normalized); ok {
+// validateHost checks u's host against the tool's own rules and, when set,
+// the network policy. The policy sees host:port so port-scoped entries apply,
+// and ctx so a cancelled call does not wait on its DNS lookups.
+func (w *WebFetchTool) validateHost(ctx context.Context, u *url.URL) error {
+	if err := w.validator.Validate(u.Hostname()); err != nil {
+		return err
+	}
+	if w.policy != nil {
+		port := u.Port()
+		if port == "" {
+			port = "443"
+			if strings.EqualFold(u.Scheme, "http") {
+				port = "80"
+			}
+		}
+		if err := w.policy.ValidateContext(ctx, net.JoinHostPort(u.Hostname(), port)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *WebFetchTool) fetch(ctx context.Context, method, normalized, body, contentType string) (*fetchResult, *redirectNotice, error) {
+	cacheable := method == http.MethodGet
+	if cached, ok := w.cache.Get(normalized); ok && cacheable {
 		clone := *cached
 		clone.Body = append([]byte(nil), cached.Body...)
 		clone.FromCache = true
 		return &clone, nil, nil
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, normalized, nil)
+	var reqBody io.Reader
+	if method == http.MethodPost {
+		reqBody = strings.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, normalized, reqBody)
 	if err != nil {
 		return nil, nil, fmt.Errorf("build request: %w", err)
 	}
 	req.Header.Set("User-Agent", defaultFetchUserAgent)
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", contentType)
+	}
 	resp, err := w.client.Do(req)
 	if err != nil {
 		if notice := detectRedirectNotice(err); notice != nil {
@@ -277,21 +369,74 @@ func (w *WebFetchTool) fetch(ctx context.Context, normalized string) (*fetchResu
 	}
 
 	result := &fetchResult{
-		URL:    resp.Request.URL.String(),
-		Status: resp.StatusCode,
-		Body:   data,
+		URL:     resp.Request.URL.String(),
+		Status:  resp.StatusCode,
+		Headers: headerSubset(resp.Header),
+		Body:    data,
+	}
+	if cacheable {
+		w.cache.Set(normalized, result)
 	}
-	w.cache.Set(normalized, result)
 	return result, nil, nil
 }
 
 type fetchResult struct {
 	URL       string
 	Status    int
+	Headers   map[string]string
 	Body      []byte
 	FromCache bool
 }
 
+func headerSubset(h http.Header) map[string]string {
+	out := make(map[string]string, len(fetchHeaderSubset))
+	for _, name := range fetchHeaderSubset {
+		if v := h.Get(name); v != "" {
+			out[strings.ToLower(name)] = v
+		}
+	}
+	return out
+}
+
+func parseFetchRequest(params map[string]interface{}, allowPost bool) (method, body, contentType string, err error) {
+	method = http.MethodGet
+	if raw, ok := params["method"]; ok && raw != nil {
+		value, err := stringValue(raw)
+		if err != nil {
+			return "", "", "", fmt.Errorf("method must be string: %w", err)
+		}
+		switch strings.ToUpper(strings.TrimSpace(value)) {
+		case "", http.MethodGet:
+		case http.MethodPost:
+			if !allowPost {
+				return "", "", "", errors.New("method POST is disabled; set WebFetchOptions.AllowPost to enable it")
+			}
+			method = http.MethodPost
+		default:
+			return "", "", "", fmt.Errorf("unsupported method %q", value)
+		}
+	}
+	if raw, ok := params["body"]; ok && raw != nil {
+		if method != http.MethodPost {
+			return "", "", "", errors.New("body requires method POST")
+		}
+		if body, err = stringValue(raw); err != nil {
+			return "", "", "", fmt.Errorf("body must be string: %w", err)
+		}
+	}
+	contentType = "application/json"
+	if raw, ok := params["content_type"]; ok && raw != nil {
+		value, err := stringValue(raw)
+		if err != nil {
+			return "", "", "", fmt.Errorf("content_type must be string: %w", err)
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			contentType = value
+		}
+	}
+	return method, body, contentType, nil
+}
+
 type redirectNotice struct {
 	URL string
 }
@@ -309,7 +454,7 @@ func (w *WebFetchTool) redirectPolicy() func(req *http.Request, via []*http.Requ
 		if !strings.EqualFold(original, next) {
 			return &hostRedirectError{target: req.URL.String()}
 		}
-		return nil
+		return w.validateHost(req.Context(), req.URL)
 	}
 }
 
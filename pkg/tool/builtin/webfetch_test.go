@@ -3,6 +3,7 @@ package toolbuiltin
 import (
 	"context"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -62,10 +63,10 @@ func TestWebFetchValidationHelpers(t *testing.T) {
 	if _, err := tool.extractURL(map[string]interface{}{}); err == nil {
 		t.Fatalf("expected missing url error")
 	}
-	if _, err := tool.normaliseURL("ftp://example.com"); err == nil {
+	if _, err := tool.normaliseURL(context.Background(), "ftp://example.com"); err == nil {
 		t.Fatalf("expected unsupported scheme error")
 	}
-	if _, err := tool.normaliseURL("https://"); err == nil {
+	if _, err := tool.normaliseURL(context.Background(), "https://"); err == nil {
 		t.Fatalf("expected missing host error")
 	}
 
@@ -203,3 +204,105 @@ func TestWebFetchHelperBranches(t *testing.T) {
 		t.Fatalf("expected stringValue error")
 	}
 }
+
+type hostPolicyFunc func(host string) error
+
+func (f hostPolicyFunc) ValidateContext(_ context.Context, host string) error { return f(host) }
+
+func TestWebFetchNetworkPolicy(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	denied := errors.New("denied")
+	var seen []string
+	tool := NewWebFetchTool(&WebFetchOptions{
+		HTTPClient:        server.Client(),
+		AllowPrivateHosts: true,
+		NetworkPolicy: hostPolicyFunc(func(host string) error {
+			seen = append(seen, host)
+			return denied
+		}),
+	})
+	_, err := tool.Execute(context.Background(), map[string]interface{}{"url": server.URL, "prompt": "p"})
+	if !errors.Is(err, denied) {
+		t.Fatalf("expected policy error, got %v", err)
+	}
+	if len(seen) != 1 || seen[0] != strings.TrimPrefix(server.URL, "https://") {
+		t.Fatalf("policy saw %v", seen)
+	}
+}
+
+func TestWebFetchPostReturnsHeadersAndBody(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s", r.Method)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("content-type = %q", ct)
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("X-Internal", "hidden")
+		_, _ = w.Write([]byte("echo:" + string(body) + strings.Repeat("x", fetchBodyPreviewBytes)))
+	}))
+	defer server.Close()
+
+	getOnly := NewWebFetchTool(&WebFetchOptions{HTTPClient: server.Client(), AllowPrivateHosts: true})
+	if _, ok := getOnly.Schema().Properties["method"]; ok {
+		t.Fatalf("method should not be advertised while POST is disabled")
+	}
+	if _, err := getOnly.Execute(context.Background(), map[string]interface{}{"url": server.URL, "prompt": "p", "method": "POST"}); err == nil || calls != 0 {
+		t.Fatalf("expected POST to be refused by default, err=%v calls=%d", err, calls)
+	}
+
+	tool := NewWebFetchTool(&WebFetchOptions{HTTPClient: server.Client(), AllowPrivateHosts: true, AllowPost: true})
+	if _, ok := tool.Schema().Properties["method"]; !ok {
+		t.Fatalf("method should be advertised when POST is enabled")
+	}
+	params := map[string]interface{}{
+		"url":    server.URL,
+		"prompt": "p",
+		"method": "post",
+		"body":   `{"a":1}`,
+	}
+	for i := 0; i < 2; i++ {
+		res, err := tool.Execute(context.Background(), params)
+		if err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+		data := res.Data.(map[string]interface{})
+		if data["method"] != http.MethodPost || data["from_cache"] != false {
+			t.Fatalf("unexpected data %v %v", data["method"], data["from_cache"])
+		}
+		headers := data["headers"].(map[string]string)
+		if headers["content-type"] != "text/plain" || headers["etag"] != `"v1"` {
+			t.Fatalf("unexpected headers %v", headers)
+		}
+		if _, ok := headers["x-internal"]; ok {
+			t.Fatalf("unexpected header subset %v", headers)
+		}
+		body := data["body"].(string)
+		if !strings.HasPrefix(body, `echo:{"a":1}`) || len(body) != fetchBodyPreviewBytes || data["body_truncated"] != true {
+			t.Fatalf("unexpected body preview len=%d truncated=%v", len(body), data["body_truncated"])
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("POST should bypass the cache, got %d calls", calls)
+	}
+
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"url": server.URL, "prompt": "p", "body": "x"}); err == nil {
+		t.Fatalf("expected body without POST to fail")
+	}
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"url": server.URL, "prompt": "p", "method": "DELETE"}); err == nil {
+		t.Fatalf("expected unsupported method to fail")
+	}
+}
@@ -26,12 +26,22 @@ var writeSchema = &tool.JSONSchema{
 			"type":        "string",
 			"description": "The absolute path to the file to write (must be absolute, not relative)",
 		},
+		"path": map[string]interface{}{
+			"type":        "string",
+			"description": "Alias for file_path; may be relative to the workspace root.",
+		},
 		"content": map[string]interface{}{
 			"type":        "string",
 			"description": "The content to write to the file",
 		},
+		"create_dirs": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Create missing parent directories (default true).",
+			"default":     true,
+		},
 	},
-	Required: []string{"file_path", "content"},
+	// file_path or its path alias is checked in Execute.
+	Required: []string{"content"},
 }
 
 // WriteTool writes files within the sandbox root.
@@ -75,11 +85,19 @@ func (w *WriteTool) Execute(ctx context.Context, params map[string]interface{})
 	if err != nil {
 		return nil, err
 	}
+	createDirs, provided, err := parseBoolParam(params, "create_dirs")
+	if err != nil {
+		return nil, err
+	}
+	if !provided {
+		createDirs = true
+	}
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	if err := w.base.writeFile(path, content); err != nil {
+	created, err := w.base.write(path, content, createDirs)
+	if err != nil {
 		return nil, err
 	}
 
@@ -87,8 +105,9 @@ func (w *WriteTool) Execute(ctx context.Context, params map[string]interface{})
 		Success: true,
 		Output:  fmt.Sprintf("wrote %d bytes to %s", len(content), displayPath(path, w.base.root)),
 		Data: map[string]interface{}{
-			"path":  displayPath(path, w.base.root),
-			"bytes": len(content),
+			"path":    displayPath(path, w.base.root),
+			"bytes":   len(content),
+			"created": created,
 		},
 	}, nil
 }
@@ -98,8 +117,10 @@ func (w *WriteTool) resolveFilePath(params map[string]interface{}) (string, erro
 		return "", errors.New("params is nil")
 	}
 	raw, ok := params["file_path"]
-	if !ok {
-		return "", errors.New("file_path is required")
+	if !ok || raw == nil {
+		if raw, ok = params["path"]; !ok {
+			return "", errors.New("file_path is required")
+		}
 	}
 	return w.base.resolvePath(raw)
 }
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package toolbuiltin
+
+import "syscall"
+
+// openFlagNoFollow makes the final open fail if the target was swapped for a
+// symlink after sandbox validation.
+const openFlagNoFollow = syscall.O_NOFOLLOW
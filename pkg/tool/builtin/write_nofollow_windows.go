@@ -0,0 +1,7 @@
+//go:build windows
+
+package toolbuiltin
+
+// openFlagNoFollow is unavailable on Windows; the sandbox's Lstat walk is the
+// only symlink guard there.
+const openFlagNoFollow = 0
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/cexll/agentsdk-go/pkg/security"
 )
 
 func TestWriteToolCreatesFile(t *testing.T) {
@@ -107,3 +109,99 @@ func TestWriteToolHelperErrors(t *testing.T) {
 		t.Fatalf("expected error for non-string content")
 	}
 }
+
+func TestWriteToolPathAliasAndCreateDirs(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	tool := NewWriteToolWithRoot(dir)
+
+	if _, err := tool.Execute(context.Background(), map[string]any{
+		"path":        filepath.Join("missing", "a.txt"),
+		"content":     "x",
+		"create_dirs": false,
+	}); err == nil || !strings.Contains(err.Error(), "parent directory missing does not exist") {
+		t.Fatalf("expected missing parent error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Fatalf("create_dirs=false must not create directories: %v", err)
+	}
+
+	res, err := tool.Execute(context.Background(), map[string]any{"path": "top.txt", "content": "hello", "create_dirs": false})
+	if err != nil {
+		t.Fatalf("write execute failed: %v", err)
+	}
+	data := res.Data.(map[string]interface{})
+	if data["bytes"] != 5 || data["created"] != true || data["path"] != "top.txt" {
+		t.Fatalf("unexpected data %v", data)
+	}
+	res, err = tool.Execute(context.Background(), map[string]any{"path": "top.txt", "content": "hi"})
+	if err != nil {
+		t.Fatalf("overwrite failed: %v", err)
+	}
+	if data := res.Data.(map[string]interface{}); data["created"] != false || data["bytes"] != 2 {
+		t.Fatalf("unexpected overwrite data %v", data)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "top.txt")); string(got) != "hi" {
+		t.Fatalf("overwrite should truncate, got %q", got)
+	}
+}
+
+func TestWriteToolRefusesSymlinkEscape(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	outside := cleanTempDir(t)
+	target := filepath.Join(outside, "victim.txt")
+	if err := os.WriteFile(target, []byte("original"), 0o600); err != nil {
+		t.Fatalf("write victim: %v", err)
+	}
+	if err := os.Symlink(target, filepath.Join(dir, "link.txt")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "linkdir")); err != nil {
+		t.Fatalf("symlink dir: %v", err)
+	}
+	tool := NewWriteToolWithRoot(dir)
+	for _, path := range []string{"link.txt", filepath.Join("linkdir", "victim.txt"), filepath.Join("linkdir", "new", "x.txt")} {
+		if _, err := tool.Execute(context.Background(), map[string]any{"path": path, "content": "pwned"}); err == nil {
+			t.Fatalf("write through symlink %s should fail", path)
+		}
+	}
+	if got, _ := os.ReadFile(target); string(got) != "original" {
+		t.Fatalf("file outside root was modified: %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "new")); !os.IsNotExist(err) {
+		t.Fatalf("directories created outside root: %v", err)
+	}
+}
+
+func TestWriteAndEditThroughInRootSymlink(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	target := filepath.Join(dir, "real.txt")
+	if err := os.WriteFile(target, []byte("original"), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	link := filepath.Join(dir, "link.txt")
+	if err := os.Symlink("real.txt", link); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	write := NewWriteToolWithSandbox(dir, security.NewDisabledSandbox())
+	res, err := write.Execute(context.Background(), map[string]any{"path": "link.txt", "content": "first"})
+	if err != nil {
+		t.Fatalf("write through symlink: %v", err)
+	}
+	if data := res.Data.(map[string]interface{}); data["created"] != false {
+		t.Fatalf("unexpected data %v", data)
+	}
+	edit := NewEditToolWithSandbox(dir, security.NewDisabledSandbox())
+	if _, err := edit.Execute(context.Background(), map[string]any{"file_path": link, "old_string": "first", "new_string": "second"}); err != nil {
+		t.Fatalf("edit through symlink: %v", err)
+	}
+	if got, _ := os.ReadFile(target); string(got) != "second" {
+		t.Fatalf("target content = %q", got)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("link should be left in place: %v", err)
+	}
+}
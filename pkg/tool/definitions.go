@@ -0,0 +1,72 @@
+package tool
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cexll/agentsdk-go/pkg/model"
+)
+
+// ToolDefinitions converts the registered tools into the definitions sent to
+// the model, sorted by name. When whitelist is non-empty only tools whose
+// names match an entry (case-insensitively) are included.
+func (r *Registry) ToolDefinitions(whitelist ...string) []model.ToolDefinition {
+	if r == nil {
+		return nil
+	}
+	allow := make(map[string]struct{}, len(whitelist))
+	for _, name := range whitelist {
+		if key := strings.ToLower(strings.TrimSpace(name)); key != "" {
+			allow[key] = struct{}{}
+		}
+	}
+	tools := r.List()
+	defs := make([]model.ToolDefinition, 0, len(tools))
+	for _, impl := range tools {
+		if impl == nil {
+			continue
+		}
+		name := strings.TrimSpace(impl.Name())
+		if name == "" {
+			continue
+		}
+		if len(allow) > 0 {
+			if _, ok := allow[strings.ToLower(name)]; !ok {
+				continue
+			}
+		}
+		defs = append(defs, model.ToolDefinition{
+			Name:        name,
+			Description: strings.TrimSpace(impl.Description()),
+			Parameters:  schemaToMap(impl.Schema()),
+		})
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs
+}
+
+// ToolDefinitions returns the model tool definitions for the executor's
+// registry. See Registry.ToolDefinitions.
+func (e *Executor) ToolDefinitions(whitelist ...string) []model.ToolDefinition {
+	if e == nil {
+		return nil
+	}
+	return e.registry.ToolDefinitions(whitelist...)
+}
+
+func schemaToMap(schema *JSONSchema) map[string]any {
+	if schema == nil {
+		return nil
+	}
+	payload := map[string]any{}
+	if schema.Type != "" {
+		payload["type"] = schema.Type
+	}
+	if len(schema.Properties) > 0 {
+		payload["properties"] = schema.Properties
+	}
+	if len(schema.Required) > 0 {
+		payload["required"] = append([]string(nil), schema.Required...)
+	}
+	return payload
+}
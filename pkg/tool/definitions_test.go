@@ -0,0 +1,77 @@
+package tool
+
+import (
+	"context"
+	"testing"
+)
+
+type schemaTool struct {
+	name   string
+	schema *JSONSchema
+}
+
+func (s *schemaTool) Name() string        { return s.name }
+func (s *schemaTool) Description() string { return "  describes " + s.name + "  " }
+func (s *schemaTool) Schema() *JSONSchema { return s.schema }
+func (s *schemaTool) Execute(context.Context, map[string]interface{}) (*ToolResult, error) {
+	return &ToolResult{Success: true}, nil
+}
+
+func TestToolDefinitionsMatchRegisteredTools(t *testing.T) {
+	reg := NewRegistry()
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]interface{}{"cmd": map[string]interface{}{"type": "string"}},
+		Required:   []string{"cmd"},
+	}
+	for _, impl := range []Tool{&schemaTool{name: "Bash", schema: schema}, &schemaTool{name: "Glob"}, &stubTool{name: "Echo"}} {
+		if err := reg.Register(impl); err != nil {
+			t.Fatalf("register %s: %v", impl.Name(), err)
+		}
+	}
+	exec := NewExecutor(reg, nil)
+
+	defs := exec.ToolDefinitions()
+	if len(defs) != 3 || defs[0].Name != "Bash" || defs[1].Name != "Echo" || defs[2].Name != "Glob" {
+		t.Fatalf("expected sorted definitions for all tools, got %+v", defs)
+	}
+	bash := defs[0]
+	if bash.Description != "describes Bash" {
+		t.Fatalf("expected trimmed description, got %q", bash.Description)
+	}
+	if bash.Parameters["type"] != "object" || bash.Parameters["properties"] == nil {
+		t.Fatalf("unexpected parameters %+v", bash.Parameters)
+	}
+	if req, ok := bash.Parameters["required"].([]string); !ok || len(req) != 1 || req[0] != "cmd" {
+		t.Fatalf("unexpected required list %+v", bash.Parameters["required"])
+	}
+	if defs[2].Parameters != nil {
+		t.Fatalf("nil schema should produce nil parameters, got %+v", defs[2].Parameters)
+	}
+}
+
+func TestToolDefinitionsWhitelist(t *testing.T) {
+	reg := NewRegistry()
+	for _, name := range []string{"Bash", "Read", "Write"} {
+		if err := reg.Register(&stubTool{name: name}); err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+	}
+	exec := NewExecutor(reg, nil)
+
+	defs := exec.ToolDefinitions(" read ", "WRITE")
+	if len(defs) != 2 || defs[0].Name != "Read" || defs[1].Name != "Write" {
+		t.Fatalf("expected whitelisted tools only, got %+v", defs)
+	}
+	if defs := exec.ToolDefinitions("missing"); len(defs) != 0 {
+		t.Fatalf("expected no definitions, got %+v", defs)
+	}
+	if defs := exec.ToolDefinitions(""); len(defs) != 3 {
+		t.Fatalf("blank whitelist entries should not filter, got %+v", defs)
+	}
+
+	var nilExec *Executor
+	if defs := nilExec.ToolDefinitions(); defs != nil {
+		t.Fatalf("expected nil definitions from nil executor, got %+v", defs)
+	}
+}
@@ -5,9 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"strings"
-	"sync"
 	"time"
 
+	"github.com/cexll/agentsdk-go/pkg/internal/pool"
 	"github.com/cexll/agentsdk-go/pkg/sandbox"
 	"github.com/cexll/agentsdk-go/pkg/security"
 )
@@ -19,6 +19,8 @@ type Executor struct {
 	sandbox   *sandbox.Manager
 	persister *OutputPersister
 	permCheck PermissionResolver
+
+	approvalRequired map[string]struct{} // lower-cased tool names
 }
 
 // NewExecutor constructs an executor backed by the provided registry. When
@@ -43,23 +45,25 @@ func (e *Executor) Execute(ctx context.Context, call Call) (*CallResult, error)
 	if strings.TrimSpace(call.Name) == "" {
 		return nil, errors.New("tool name is empty")
 	}
+	if err := checkAllowedTool(ctx, call.Name); err != nil {
+		return nil, err
+	}
 
+	decision, err := e.Decide(call)
+	if err != nil {
+		return nil, err
+	}
+	decision, err = e.resolvePermission(ctx, call, decision)
+	if err != nil {
+		return nil, err
+	}
+	switch decision.Action {
+	case security.PermissionDeny:
+		return nil, fmt.Errorf("tool %s denied by rule %q for %s", call.Name, decision.Rule, decision.Target)
+	case security.PermissionAsk:
+		return nil, fmt.Errorf("tool %s requires approval (rule %q for %s)", call.Name, decision.Rule, decision.Target)
+	}
 	if e.sandbox != nil {
-		decision, err := e.sandbox.CheckToolPermission(call.Name, call.Params)
-		if err != nil {
-			return nil, err
-		}
-		decision, err = e.resolvePermission(ctx, call, decision)
-		if err != nil {
-			return nil, err
-		}
-		switch decision.Action {
-		case security.PermissionDeny:
-			return nil, fmt.Errorf("tool %s denied by rule %q for %s", call.Name, decision.Rule, decision.Target)
-		case security.PermissionAsk:
-			return nil, fmt.Errorf("tool %s requires approval (rule %q for %s)", call.Name, decision.Rule, decision.Target)
-		}
-
 		if err := e.sandbox.Enforce(call.Path, call.Host, call.Usage); err != nil {
 			return nil, err
 		}
@@ -95,33 +99,45 @@ func (e *Executor) Execute(ctx context.Context, call Call) (*CallResult, error)
 	return cr, execErr
 }
 
+// Decide reports the permission decision Execute would start from for call:
+// the sandbox rule verdict with the approval default applied. It runs neither
+// the tool nor the PermissionResolver, so it is safe for dry runs.
+func (e *Executor) Decide(call Call) (security.PermissionDecision, error) {
+	if e == nil || e.registry == nil {
+		return security.PermissionDecision{}, errors.New("executor is not initialised")
+	}
+	decision := security.PermissionDecision{Action: security.PermissionAllow, Tool: call.Name}
+	if e.sandbox != nil {
+		var err error
+		decision, err = e.sandbox.CheckToolPermission(call.Name, call.Params)
+		if err != nil {
+			return security.PermissionDecision{}, err
+		}
+	}
+	return e.applyApprovalDefault(call, decision), nil
+}
+
 // ExecuteAll runs the provided calls concurrently and preserves ordering in the
 // returned slice. Each call is isolated with its own parameter copy. Execution
 // stops early when the context is cancelled; tools observe ctx directly.
 func (e *Executor) ExecuteAll(ctx context.Context, calls []Call) []CallResult {
+	outcomes := pool.Map(ctx, 0, len(calls), func(ctx context.Context, idx int) (CallResult, error) {
+		cr, err := e.Execute(ctx, calls[idx])
+		if cr != nil {
+			return *cr, nil
+		}
+		// When executor is nil, propagate error without result payload.
+		return CallResult{Call: calls[idx], Err: err}, nil
+	})
+
 	results := make([]CallResult, len(calls))
-	var wg sync.WaitGroup
-	wg.Add(len(calls))
-
-	for i := range calls {
-		call := calls[i]
-		go func(idx int) {
-			defer wg.Done()
-			if ctx != nil && ctx.Err() != nil {
-				results[idx] = CallResult{Call: call, Err: ctx.Err()}
-				return
-			}
-			cr, err := e.Execute(ctx, call)
-			if cr != nil {
-				results[idx] = *cr
-				return
-			}
-			// When executor is nil, propagate error without result payload.
-			results[idx] = CallResult{Call: call, Err: err}
-		}(i)
-	}
-
-	wg.Wait()
+	for i, out := range outcomes {
+		if out.Err != nil {
+			results[i] = CallResult{Call: calls[i], Err: out.Err}
+			continue
+		}
+		results[i] = out.Value
+	}
 	return results
 }
 